@@ -8,20 +8,72 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	corsService "github.com/buildyow/byow-user-service/infrastructure/cors"
 	"github.com/buildyow/byow-user-service/routes"
+	"github.com/buildyow/byow-user-service/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
-// setupServer creates and configures the Gin router
-func setupServer() *gin.Engine {
+// newRouter creates the Gin engine with the routing behaviour shared by all routes
+func newRouter() *gin.Engine {
 	r := gin.Default()
+	// trimTrailingSlash already serves "/path/" as "/path", a redirect would
+	// only cost clients a round trip and break CORS preflights
+	r.RedirectTrailingSlash = false
+	return r
+}
+
+// wildcardPrefixes are the routes whose wildcard takes the path as-is,
+// trailing slash included
+var wildcardPrefixes = []string{"/swagger/"}
+
+// trimTrailingSlash serves "/path/" as "/path" before it is routed, so both
+// variants reach the same handler with the method and body untouched
+func trimTrailingSlash(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path := req.URL.Path
+		if len(path) > 1 && strings.HasSuffix(path, "/") && !hasWildcardPrefix(path) {
+			trimmed := strings.TrimRight(path, "/")
+			if trimmed == "" {
+				trimmed = "/"
+			}
+			req.URL.Path = trimmed
+			if req.URL.RawPath != "" {
+				req.URL.RawPath = strings.TrimRight(req.URL.RawPath, "/")
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func hasWildcardPrefix(path string) bool {
+	for _, prefix := range wildcardPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// shutdownTimeout bounds how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// setupServer creates and configures the Gin router, ctx is canceled on shutdown
+func setupServer(ctx context.Context) *gin.Engine {
+	r := newRouter()
 	r.Use(corsService.SetupCors())
-	routes.InitRoutes(r)
+	routes.InitRoutes(ctx, r)
 	return r
 }
 
@@ -41,10 +93,32 @@ func loadEnv() {
 
 func main() {
 	loadEnv()
+	// OTPs can't be issued or checked without a valid key, refuse to start
+	if err := utils.ValidateDecryptKey(); err != nil {
+		log.Fatal(err)
+	}
+
+	// SIGINT and SIGTERM cancel ctx, aborting in-flight emails and SMS
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	r := setupServer()
+	r := setupServer(ctx)
 	port := getPort()
-	
-	log.Println("Running on port", port)
-	log.Fatal(r.Run(":" + port))
+	srv := &http.Server{Addr: ":" + port, Handler: trimTrailingSlash(r)}
+
+	go func() {
+		log.Println("Running on port", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Fatal("Forced shutdown: ", err)
+	}
 }
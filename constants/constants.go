@@ -17,6 +17,7 @@ const (
 
 	// Default values
 	DefaultPageSize = 20
+	MaxOTPAttempts  = 5
 
 	// OTP Types (still used for email sending)
 	FORGOT_PASSWORD  = "forgot_password"
@@ -24,4 +25,136 @@ const (
 	EMAIL_CHANGED    = "email_changed"
 	PASSWORD_CHANGED = "password_changed"
 	PHONE_CHANGED    = "phone_changed"
+
+	// OTP delivery channels, email unless the request asks for sms
+	OTP_CHANNEL_EMAIL = "email"
+	OTP_CHANNEL_SMS   = "sms"
+
+	// Roles, users without a stored role are ROLE_USER
+	ROLE_USER  = "user"
+	ROLE_ADMIN = "admin"
+
+	// Permissions, named resource:action
+	PERMISSION_PROFILE_READ          = "profile:read"
+	PERMISSION_PROFILE_UPDATE        = "profile:update"
+	PERMISSION_COMPANIES_READ        = "companies:read"
+	PERMISSION_COMPANIES_CREATE      = "companies:create"
+	PERMISSION_COMPANIES_DELETE      = "companies:delete"
+	PERMISSION_ADMIN_ACCESS          = "admin:access" // reach /api/admin at all
+	PERMISSION_ADMIN_USERS_READ      = "admin:users:read"
+	PERMISSION_ADMIN_USERS_WRITE     = "admin:users:write"
+	PERMISSION_ADMIN_COMPANIES_READ  = "admin:companies:read"
+	PERMISSION_ADMIN_COMPANIES_WRITE = "admin:companies:write"
+	PERMISSION_ADMIN_FLAGS_READ      = "admin:flags:read"
+	PERMISSION_ADMIN_SYSTEM_WRITE    = "admin:system:write"
+	PERMISSION_ADMIN_API_KEYS_READ   = "admin:api_keys:read"
+	PERMISSION_ADMIN_API_KEYS_WRITE  = "admin:api_keys:write"
+	PERMISSION_ADMIN_IMPERSONATE     = "admin:users:impersonate"
+
+	// API key scopes, what another BYOW service may call with a key
+	API_SCOPE_USERS_READ = "internal:users:read"
+
+	// Notice emails, password and email change notices are security notices
+	// and can't be turned off
+	NOTICE_WELCOME              = "welcome"
+	NOTICE_PASSWORD_CHANGED     = "password_changed"
+	NOTICE_EMAIL_CHANGED        = "email_changed"
+	NOTICE_VERIFICATION_REVOKED = "verification_revoked"
+
+	// Company change events streamed to the owner
+	COMPANY_EVENT_CREATED  = "company.created"
+	COMPANY_EVENT_UPDATED  = "company.updated"
+	COMPANY_EVENT_DELETED  = "company.deleted"
+	COMPANY_EVENT_RESTORED = "company.restored"
+
+	// Audit actions
+	AUDIT_ACCOUNT_DELETED    = "account_deleted"
+	AUDIT_ACCOUNTS_MERGED    = "accounts_merged"
+	AUDIT_INDEXES_REBUILT    = "indexes_rebuilt"
+	AUDIT_COMPANIES_VERIFIED = "companies_verified"
+	AUDIT_USERS_EXPORTED     = "users_exported"
+	AUDIT_LOGIN_FAILED       = "login_failed"
+	AUDIT_LOGIN_SUCCEEDED    = "login_succeeded"
+	AUDIT_COMPANIES_PURGED   = "companies_purged"
+	AUDIT_COMPANY_UNVERIFIED = "company_unverified"
+	AUDIT_OTPS_REENCRYPTED   = "otps_reencrypted"
+	AUDIT_OTP_ATTEMPTS_RESET = "otp_attempts_reset"
+	AUDIT_ROLE_CHANGED       = "role_changed"
+	AUDIT_LOGGED_OUT_ALL     = "logged_out_all"
+	AUDIT_PASSKEY_ADDED      = "passkey_added"
+	AUDIT_DELETION_SCHEDULED = "account_deletion_scheduled"
+	AUDIT_DELETION_CANCELLED = "account_deletion_cancelled"
+	AUDIT_USER_SUSPENDED     = "user_suspended"
+	AUDIT_USER_REACTIVATED   = "user_reactivated"
+	AUDIT_IMPERSONATION      = "impersonation_started"
+	AUDIT_IMPERSONATED_CALL  = "impersonated_request"
+	AUDIT_API_KEY_CREATED    = "api_key_created"
+	AUDIT_API_KEY_REVOKED    = "api_key_revoked"
 )
+
+// otpTypes are the OTP types that can be issued, PASSWORD_CHANGED is only
+// used for notifications and never carries an OTP
+var otpTypes = map[string]bool{
+	FORGOT_PASSWORD: true,
+	VERIFICATION:    true,
+	EMAIL_CHANGED:   true,
+	PHONE_CHANGED:   true,
+}
+
+// IsValidOTPType reports whether an OTP can be issued for otpType
+func IsValidOTPType(otpType string) bool {
+	return otpTypes[otpType]
+}
+
+// apiKeyScopes are the scopes an API key can be given
+var apiKeyScopes = map[string]bool{
+	API_SCOPE_USERS_READ: true,
+}
+
+// IsValidAPIKeyScope reports whether an API key can be given scope
+func IsValidAPIKeyScope(scope string) bool {
+	return apiKeyScopes[scope]
+}
+
+// rolePermissions are the permissions each role adds on top of ROLE_USER's
+var rolePermissions = map[string][]string{
+	ROLE_USER: {
+		PERMISSION_PROFILE_READ,
+		PERMISSION_PROFILE_UPDATE,
+		PERMISSION_COMPANIES_READ,
+		PERMISSION_COMPANIES_CREATE,
+		PERMISSION_COMPANIES_DELETE,
+	},
+	ROLE_ADMIN: {
+		PERMISSION_ADMIN_ACCESS,
+		PERMISSION_ADMIN_USERS_READ,
+		PERMISSION_ADMIN_USERS_WRITE,
+		PERMISSION_ADMIN_COMPANIES_READ,
+		PERMISSION_ADMIN_COMPANIES_WRITE,
+		PERMISSION_ADMIN_FLAGS_READ,
+		PERMISSION_ADMIN_SYSTEM_WRITE,
+		PERMISSION_ADMIN_API_KEYS_READ,
+		PERMISSION_ADMIN_API_KEYS_WRITE,
+		PERMISSION_ADMIN_IMPERSONATE,
+	},
+}
+
+// PermissionsFor lists what role may do. Every role can do what ROLE_USER
+// can, an unknown role gets nothing more.
+func PermissionsFor(role string) []string {
+	permissions := append([]string{}, rolePermissions[ROLE_USER]...)
+	if role != ROLE_USER {
+		permissions = append(permissions, rolePermissions[role]...)
+	}
+	return permissions
+}
+
+// HasPermission reports whether role may do permission
+func HasPermission(role, permission string) bool {
+	for _, granted := range PermissionsFor(role) {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
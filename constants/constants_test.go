@@ -50,6 +50,9 @@ func TestDefaultValues(t *testing.T) {
 	if DefaultPageSize != 20 {
 		t.Errorf("Expected DefaultPageSize to be 20, got %v", DefaultPageSize)
 	}
+	if MaxOTPAttempts != 5 {
+		t.Errorf("Expected MaxOTPAttempts to be 5, got %v", MaxOTPAttempts)
+	}
 }
 
 func TestOTPTypeConstants(t *testing.T) {
@@ -111,4 +114,42 @@ func TestDefaultPageSizeType(t *testing.T) {
 	if DefaultPageSize > 1000 {
 		t.Errorf("DefaultPageSize seems too large: %v", DefaultPageSize)
 	}
-}
\ No newline at end of file
+}
+func TestIsValidOTPType(t *testing.T) {
+	for _, otpType := range []string{FORGOT_PASSWORD, VERIFICATION, EMAIL_CHANGED, PHONE_CHANGED} {
+		if !IsValidOTPType(otpType) {
+			t.Errorf("Expected %q to be a valid OTP type", otpType)
+		}
+	}
+	for _, otpType := range []string{PASSWORD_CHANGED, "", "bogus"} {
+		if IsValidOTPType(otpType) {
+			t.Errorf("Expected %q to be rejected", otpType)
+		}
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission string
+		expected   bool
+	}{
+		{ROLE_USER, PERMISSION_COMPANIES_DELETE, true},
+		{ROLE_USER, PERMISSION_ADMIN_IMPERSONATE, false},
+		{ROLE_USER, PERMISSION_ADMIN_ACCESS, false},
+		{ROLE_ADMIN, PERMISSION_ADMIN_ACCESS, true},
+		{ROLE_ADMIN, PERMISSION_ADMIN_IMPERSONATE, true},
+		{ROLE_ADMIN, PERMISSION_COMPANIES_DELETE, true},
+		{"", PERMISSION_PROFILE_READ, true},
+		{"auditor", PERMISSION_ADMIN_USERS_READ, false},
+		{ROLE_ADMIN, "companies:launch", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.permission, func(t *testing.T) {
+			if got := HasPermission(tt.role, tt.permission); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/authctx"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/buildyow/byow-user-service/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	Usecase *usecase.AdminUsecase
+}
+
+func NewAdminHandler(uc *usecase.AdminUsecase) *AdminHandler {
+	return &AdminHandler{Usecase: uc}
+}
+
+// actor is the authenticated user of the request, for usecases that
+// authorize it themselves
+func actor(c *gin.Context) (usecase.Actor, error) {
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		return usecase.Actor{}, err
+	}
+	role, err := authctx.Role(c)
+	if err != nil {
+		return usecase.Actor{}, err
+	}
+	return usecase.Actor{ID: userID, Role: role}, nil
+}
+
+// @Summary Merge Accounts
+// @Description Move an unverified duplicate account's companies to a verified account and delete the duplicate. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.MergeAccountsRequest true "Account to keep & account to merge"
+// @Success 200 {object} dto.MergeAccountsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/admin/users/merge [post]
+func (h *AdminHandler) MergeAccounts(c *gin.Context) {
+	var req dto.MergeAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	reassigned, err := h.Usecase.MergeAccounts(userID, req.KeepEmail, req.MergeEmail)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Accounts merged successfully", dto.MergeAccountsResponse{CompaniesReassigned: reassigned})
+}
+
+// @Summary Feature Flags
+// @Description Current feature flag values. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /api/admin/flags [get]
+func (h *AdminHandler) FeatureFlags(c *gin.Context) {
+	response.FetchSuccess(c, "Feature flags", h.Usecase.FeatureFlags())
+}
+
+// @Summary Rebuild Indexes
+// @Description Create any missing database indexes without a redeploy. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} dto.RebuildIndexesResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/admin/db/indexes/rebuild [post]
+func (h *AdminHandler) RebuildIndexes(c *gin.Context) {
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	indexes, err := h.Usecase.RebuildIndexes(userID)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Indexes rebuilt successfully", dto.RebuildIndexesResponse{Indexes: indexes, Count: len(indexes)})
+}
+
+// @Summary Re-encrypt OTPs
+// @Description After rotating DECRYPT_KEY, re-encrypt unexpired OTPs from DECRYPT_KEY_PREVIOUS to the current key, or invalidate them all with mode "invalidate". Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.ReencryptOTPsRequest false "Mode, reencrypt by default"
+// @Success 200 {object} dto.ReencryptOTPsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/admin/security/reencrypt-otps [post]
+func (h *AdminHandler) ReencryptOTPs(c *gin.Context) {
+	var req dto.ReencryptOTPsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	result, err := h.Usecase.ReencryptOTPs(c.Request.Context(), userID, req.Mode)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "OTPs processed successfully", result)
+}
+
+// @Summary Reset OTP Attempts
+// @Description Clear a locked out user's wrong OTP attempts so they can enter their code again. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.ResetOTPAttemptsResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/admin/users/{id}/reset-otp-attempts [post]
+func (h *AdminHandler) ResetOTPAttempts(c *gin.Context) {
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	result, err := h.Usecase.ResetOTPAttempts(userID, c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "OTP attempts reset successfully", result)
+}
+
+// @Summary Set User Role
+// @Description Give a user the user or admin role. Their existing tokens stop working right away, so the new role applies on their next login. Admins can't change their own role. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.SetRoleRequest true "Role"
+// @Success 200 {object} dto.SetRoleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/admin/users/{id}/role [put]
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	var req dto.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	result, err := h.Usecase.SetRole(userID, c.Param("id"), req.Role)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.UpdateSuccess(c, "Role", result)
+}
+
+// @Summary Export Users
+// @Description Stream every user as newline-delimited JSON. Passwords and OTP data are never included. Requires the admin role.
+// @Tags Admin
+// @Produce application/x-ndjson
+// @Success 200 {object} dto.UserExportRecord
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/admin/users/export [get]
+func (h *AdminHandler) ExportUsers(c *gin.Context) {
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	filename := fmt.Sprintf("users-%s.ndjson", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+
+	_, err = h.Usecase.ExportUsers(c.Request.Context(), userID, c.Writer)
+	if err != nil && !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		response.ErrorFromAppError(c, err)
+	}
+}
+
+// @Summary List Users
+// @Description List users, searching their email, phone number and name. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param keyword query string false "Email, phone or name"
+// @Param suspended query bool false "Filter by suspension status"
+// @Param limit query string false "Limit"
+// @Param offset query string false "Offset"
+// @Success 200 {object} dto.AdminUserListResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /api/admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	keyword := c.Query("keyword")
+	limit, offset := parsePagination(c)
+
+	var suspended *bool
+	if suspendedStr := c.Query("suspended"); suspendedStr != "" {
+		s, err := strconv.ParseBool(suspendedStr)
+		if err != nil {
+			response.ErrorFromAppError(c, appErrors.NewBadRequestError("suspended must be true or false"))
+			return
+		}
+		suspended = &s
+	}
+
+	users, rowCount, err := h.Usecase.ListUsers(keyword, suspended, limit, offset)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+
+	response.ListSuccess(c, "Users", users, rowCount)
+}
+
+// @Summary Get User
+// @Description A user's account details. Credentials and OTP data are never included. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user, err := h.Usecase.GetUser(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "User", user)
+}
+
+// @Summary Suspend User
+// @Description Stop a user logging in. Their existing tokens stop working right away. Admins can't suspend themselves. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.SuspendUserRequest false "Reason"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/admin/users/{id}/suspend [post]
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	var req dto.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	user, err := h.Usecase.SuspendUser(userID, c.Param("id"), req.Reason)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "User suspended successfully", user)
+}
+
+// @Summary Reactivate User
+// @Description Lift a user's suspension so they can log in again. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/admin/users/{id}/reactivate [post]
+func (h *AdminHandler) ReactivateUser(c *gin.Context) {
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	user, err := h.Usecase.ReactivateUser(userID, c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "User reactivated successfully", user)
+}
+
+// @Summary Impersonate User
+// @Description Issue a short-lived token to act as a user, for reproducing what they see. The token names the admin too, and every request made with it is audited. Send it as an Authorization: Bearer header from a client without your own token cookie. Admins and suspended users can't be impersonated. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.ImpersonationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/admin/users/{id}/impersonate [post]
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	admin, err := actor(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	result, err := h.Usecase.Impersonate(admin, c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Impersonation token issued", result)
+}
@@ -0,0 +1,310 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
+	"github.com/buildyow/byow-user-service/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func setupAdminRouter(role string, uc *usecase.AdminUsecase) *gin.Engine {
+	handler := NewAdminHandler(uc)
+	router := gin.New()
+	admin := router.Group("/api/admin")
+	admin.Use(func(c *gin.Context) {
+		c.Set("user_id", "admin-123")
+		c.Set("role", role)
+		c.Next()
+	}, jwt.RequirePermission(constants.PERMISSION_ADMIN_ACCESS))
+	admin.POST("/db/indexes/rebuild", handler.RebuildIndexes)
+	admin.GET("/users/export", handler.ExportUsers)
+	admin.POST("/users/:id/reset-otp-attempts", handler.ResetOTPAttempts)
+	return router
+}
+
+func TestAdminHandler_RebuildIndexes(t *testing.T) {
+	setupGinTestMode()
+
+	calls := 0
+	uc := &usecase.AdminUsecase{
+		BuildIndexes: func(ctx context.Context) ([]string, error) {
+			calls++
+			return []string{"email_unique", "phone_unique"}, nil
+		},
+	}
+
+	t.Run("non-admin is rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/admin/db/indexes/rebuild", nil)
+		setupAdminRouter("", uc).ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", w.Code)
+		}
+		if calls != 0 {
+			t.Error("Expected indexes not to be built for a non-admin")
+		}
+	})
+
+	t.Run("admin gets the index names", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/admin/db/indexes/rebuild", nil)
+		setupAdminRouter(constants.ROLE_ADMIN, uc).ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var body struct {
+			Status   string `json:"status"`
+			Response struct {
+				Data struct {
+					Indexes []string `json:"indexes"`
+					Count   int      `json:"count"`
+				} `json:"data"`
+			} `json:"response"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if body.Status != "SUCCESS" || body.Response.Data.Count != 2 || len(body.Response.Data.Indexes) != 2 {
+			t.Errorf("Unexpected response body: %s", w.Body.String())
+		}
+	})
+}
+
+func TestAdminHandler_ExportUsers(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-1", Email: "john@example.com", Password: "bcrypt-hash", OTP: "encrypted-otp", Verified: true},
+		"jane@example.com": {ID: "user-2", Email: "jane@example.com", Password: "bcrypt-hash", PasswordResetTokenHash: "reset-hash"},
+		"joe@example.com":  {ID: "user-3", Email: "joe@example.com", Password: "bcrypt-hash", Role: constants.ROLE_ADMIN},
+	}}
+	uc := &usecase.AdminUsecase{UserRepo: repo}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/users/export", nil)
+	setupAdminRouter("", uc).ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/admin/users/export", nil)
+	setupAdminRouter(constants.ROLE_ADMIN, uc).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") || !strings.Contains(cd, ".ndjson") {
+		t.Errorf("Expected attachment disposition, got %q", cd)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, got %d: %s", len(lines), w.Body.String())
+	}
+	emails := map[string]bool{}
+	for _, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected each line to be JSON, got %q", line)
+		}
+		emails[record["email"].(string)] = true
+		for _, field := range []string{"password", "otp", "password_reset_token_hash"} {
+			if _, ok := record[field]; ok {
+				t.Errorf("Expected %s to be omitted, got %v", field, record)
+			}
+		}
+	}
+	if len(emails) != 3 {
+		t.Errorf("Expected every user exported once, got %v", emails)
+	}
+	if strings.Contains(w.Body.String(), "bcrypt-hash") || strings.Contains(w.Body.String(), "encrypted-otp") {
+		t.Error("Expected no sensitive values in the export")
+	}
+}
+
+func TestAdminHandler_ResetOTPAttempts(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-1", Email: "john@example.com", OTPAttempts: constants.MaxOTPAttempts},
+	}}
+	uc := &usecase.AdminUsecase{UserRepo: repo}
+	path := "/api/admin/users/user-1/reset-otp-attempts"
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, nil)
+	setupAdminRouter("", uc).ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", w.Code)
+	}
+	if repo.users["john@example.com"].OTPAttempts != constants.MaxOTPAttempts {
+		t.Error("Expected a non-admin not to reset anything")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", path, nil)
+	setupAdminRouter(constants.ROLE_ADMIN, uc).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"previous_attempts":5`) {
+		t.Errorf("Expected the cleared attempts in the response, got %s", w.Body.String())
+	}
+	if repo.users["john@example.com"].OTPAttempts != 0 {
+		t.Error("Expected the attempts to be reset")
+	}
+}
+
+func TestAdminHandler_SetRole_DowngradeRevokesAccess(t *testing.T) {
+	setupGinTestMode()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"boss@example.com": {ID: "admin-1", Email: "boss@example.com", Role: constants.ROLE_ADMIN},
+		"jane@example.com": {ID: "admin-2", Email: "jane@example.com", Role: constants.ROLE_ADMIN},
+	}}
+	users := &usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 60}
+	handler := NewAdminHandler(&usecase.AdminUsecase{UserRepo: repo})
+
+	router := gin.New()
+	admin := router.Group("/api/admin")
+	admin.Use(jwt.JWTMiddleware(nil), jwt.RequireTokenVersion(users.TokenVersion), jwt.RequirePermission(constants.PERMISSION_ADMIN_ACCESS))
+	admin.PUT("/users/:id/role", handler.SetRole)
+
+	login := func(email string) string {
+		user, err := users.LoginWithoutPassword(email)
+		if err != nil {
+			t.Fatalf("Failed to log in %s: %v", email, err)
+		}
+		return user.Token
+	}
+	setRole := func(token, userID, role string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/admin/users/"+userID+"/role", strings.NewReader(`{"role":"`+role+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	janeToken := login("jane@example.com")
+	if w := setRole(janeToken, "admin-1", constants.ROLE_ADMIN); w.Code != http.StatusOK {
+		t.Fatalf("Expected an admin to pass, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := setRole(login("boss@example.com"), "admin-2", constants.ROLE_USER); w.Code != http.StatusOK {
+		t.Fatalf("Expected the downgrade to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The token issued while Jane was an admin still says so, but is refused
+	if w := setRole(janeToken, "admin-1", constants.ROLE_ADMIN); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the pre-downgrade token to be refused, got %d", w.Code)
+	}
+	if w := setRole(login("jane@example.com"), "admin-1", constants.ROLE_ADMIN); w.Code != http.StatusForbidden {
+		t.Errorf("Expected a fresh token to carry the new role, got %d", w.Code)
+	}
+}
+
+func TestAdminHandler_Users(t *testing.T) {
+	setupGinTestMode()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"boss@example.com": {ID: "admin-1", Email: "boss@example.com", Role: constants.ROLE_ADMIN},
+		"john@example.com": {ID: "user-1", Email: "john@example.com"},
+	}}
+	users := &usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 60}
+	handler := NewAdminHandler(&usecase.AdminUsecase{UserRepo: repo})
+
+	router := gin.New()
+	router.GET("/api/users/me", jwt.JWTMiddleware(nil), jwt.RequireTokenVersion(users.TokenVersion), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	admin := router.Group("/api/admin")
+	admin.Use(jwt.JWTMiddleware(nil), jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_WRITE))
+	admin.GET("/users", handler.ListUsers)
+	admin.GET("/users/export", handler.ExportUsers)
+	admin.GET("/users/:id", handler.GetUser)
+	admin.POST("/users/:id/suspend", handler.SuspendUser)
+	admin.POST("/users/:id/reactivate", handler.ReactivateUser)
+
+	login := func(email string) string {
+		user, err := users.LoginWithoutPassword(email)
+		if err != nil {
+			t.Fatalf("Failed to log in %s: %v", email, err)
+		}
+		return user.Token
+	}
+	bossToken := login("boss@example.com")
+	johnToken := login("john@example.com")
+	call := func(method, path, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(""))
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("non-admin is rejected", func(t *testing.T) {
+		if w := call("GET", "/api/admin/users", johnToken); w.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("list and search", func(t *testing.T) {
+		w := call("GET", "/api/admin/users?keyword=john", bossToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var body struct {
+			Response struct {
+				Data     []dto.AdminUserResponse `json:"data"`
+				RowCount int64                   `json:"row_count"`
+			} `json:"response"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if body.Response.RowCount != 1 || body.Response.Data[0].Email != "john@example.com" {
+			t.Errorf("Unexpected response body: %s", w.Body.String())
+		}
+		if w := call("GET", "/api/admin/users?suspended=maybe", bossToken); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for a bad suspended filter, got %d", w.Code)
+		}
+	})
+
+	t.Run("suspend revokes access", func(t *testing.T) {
+		if w := call("GET", "/api/users/me", johnToken); w.Code != http.StatusOK {
+			t.Fatalf("Expected John's token to work before the suspension, got %d", w.Code)
+		}
+		if w := call("POST", "/api/admin/users/user-1/suspend", bossToken); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := call("GET", "/api/users/me", johnToken); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected John's token to be refused once suspended, got %d", w.Code)
+		}
+		if w := call("GET", "/api/admin/users/user-1", bossToken); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"suspended":true`) {
+			t.Errorf("Expected John to show as suspended, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := call("POST", "/api/admin/users/user-1/reactivate", bossToken); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := call("POST", "/api/admin/users/user-1/reactivate", bossToken); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 reactivating twice, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/buildyow/byow-user-service/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	Usecase *usecase.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(uc *usecase.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{Usecase: uc}
+}
+
+// @Summary Create API Key
+// @Description Issue an API key another BYOW service calls internal endpoints with. The key is only shown in this response. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyRequest true "Key name & scopes"
+// @Success 201 {object} dto.CreatedAPIKeyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /api/admin/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	admin, err := actor(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	created, err := h.Usecase.Create(admin, req)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralCreated(c, "API key created", created)
+}
+
+// @Summary List API Keys
+// @Description Every API key, revoked ones included, newest first. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /api/admin/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	admin, err := actor(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	keys, err := h.Usecase.List(admin)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "API keys", keys)
+}
+
+// @Summary Revoke API Key
+// @Description Stop an API key from authenticating. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	admin, err := actor(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if err := h.Usecase.Revoke(admin, c.Param("id")); err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "API key revoked", nil)
+}
+
+// @Summary Get User (internal)
+// @Description Look a user up by id. For other BYOW services, authenticated with an API key holding the internal:users:read scope.
+// @Tags Internal
+// @Produce json
+// @Param X-API-Key header string true "API key"
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.InternalUserResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /internal/users/{id} [get]
+func (h *APIKeyHandler) GetUser(c *gin.Context) {
+	user, err := h.Usecase.InternalUser(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "User", user)
+}
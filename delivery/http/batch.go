@@ -0,0 +1,59 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/gin-gonic/gin"
+)
+
+// batchRequest is a request body carrying an array of items
+type batchRequest interface {
+	BatchSize() int
+}
+
+// maxBatchItemBytes is the most body a single batch item may take, enough
+// for an email address of the longest allowed length with its quotes,
+// separator and some whitespace
+const maxBatchItemBytes = 512
+
+// batchBodyOverhead leaves room for the object around the array
+const batchBodyOverhead = 1024
+
+// bindBatch binds the JSON body of a batch endpoint into req and rejects it
+// unless it carries between one and limit items, so every batch endpoint
+// fails the same way before any work is done. The body is read through a
+// limit sized from limit, a body too large to hold that many items is
+// refused with 413 before it is decoded. It reports whether the handler may
+// continue.
+func bindBatch(c *gin.Context, req batchRequest, limit int) bool {
+	maxBytes := int64(limit)*maxBatchItemBytes + batchBodyOverhead
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+	if err := c.ShouldBindJSON(req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.ErrorFromAppError(c, appErrors.ErrRequestTooLarge)
+			return false
+		}
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return false
+	}
+
+	size := req.BatchSize()
+	if size == 0 {
+		err := appErrors.NewBadRequestError("Batch is empty")
+		err.Details = "At least one item is required"
+		response.ErrorFromAppError(c, err)
+		return false
+	}
+	if size > limit {
+		err := appErrors.NewBadRequestError(fmt.Sprintf("Batch of %d items is too large", size))
+		err.Details = fmt.Sprintf("At most %d items are accepted per request", limit)
+		response.ErrorFromAppError(c, err)
+		return false
+	}
+	return true
+}
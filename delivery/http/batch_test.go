@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+	"github.com/buildyow/byow-user-service/usecase"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBindBatch(t *testing.T) {
+	setupGinTestMode()
+
+	companyHandler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{}},
+		UserID: func(c *gin.Context) string { return "admin-123" },
+	})
+	companyHandler.MaxBatchSize = 3
+	userHandler := NewUserHandler(&usecase.UserUsecase{Repo: &stubUserRepository{users: map[string]*entity.User{}}})
+
+	router := gin.New()
+	router.POST("/api/companies/ownership-batch", companyHandler.OwnershipBatch)
+	router.POST("/api/admin/companies/verify-batch", companyHandler.AdminVerifyBatch)
+	router.POST("/auth/users/availability-batch", userHandler.EmailAvailabilityBatch)
+
+	items := func(field string, size int) string {
+		values := make([]string, size)
+		for i := range values {
+			values[i] = `"item` + strings.Repeat("x", i) + `@example.com"`
+		}
+		return `{"` + field + `":[` + strings.Join(values, ",") + `]}`
+	}
+
+	endpoints := []struct {
+		path  string
+		field string
+		limit int
+	}{
+		{"/api/companies/ownership-batch", "ids", 3},
+		{"/api/admin/companies/verify-batch", "ids", 3},
+		{"/auth/users/availability-batch", "emails", usecase.MaxEmailAvailabilityBatch},
+	}
+	for _, endpoint := range endpoints {
+		cases := []struct {
+			name    string
+			body    string
+			message string
+		}{
+			{"malformed JSON", `{"` + endpoint.field + `":[`, "Invalid JSON format"},
+			{"wrong type", `{"` + endpoint.field + `":"not-an-array"}`, "Invalid JSON format"},
+			{"missing body", ``, "Invalid JSON format"},
+			{"empty array", `{"` + endpoint.field + `":[]}`, "Batch is empty"},
+			{"missing array", `{}`, "Batch is empty"},
+			{"oversized array", items(endpoint.field, endpoint.limit+1), "is too large"},
+		}
+		for _, tc := range cases {
+			t.Run(endpoint.path+"/"+tc.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("POST", endpoint.path, strings.NewReader(tc.body))
+				req.Header.Set("Content-Type", "application/json")
+				router.ServeHTTP(w, req)
+
+				if w.Code != http.StatusBadRequest {
+					t.Fatalf("Expected 400, got %d: %s", w.Code, w.Body.String())
+				}
+				var body struct {
+					Status string `json:"status"`
+					Error  struct {
+						Code    string `json:"code"`
+						Message string `json:"message"`
+					} `json:"error"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("Expected the standard error envelope, got %s", w.Body.String())
+				}
+				if body.Status != "ERROR" || body.Error.Code != "BAD_REQUEST" || !strings.Contains(body.Error.Message, tc.message) {
+					t.Errorf("Expected a BAD_REQUEST envelope mentioning %q, got %s", tc.message, w.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestBindBatch_BodyTooLarge(t *testing.T) {
+	setupGinTestMode()
+
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{}},
+		UserID: func(c *gin.Context) string { return "admin-123" },
+	})
+	handler.MaxBatchSize = 3
+
+	router := gin.New()
+	router.POST("/api/companies/ownership-batch", handler.OwnershipBatch)
+
+	// Far more than three IDs could take, refused before it is decoded
+	body := `{"ids":["` + strings.Repeat("a", 3*maxBatchItemBytes+batchBodyOverhead) + `"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/companies/ownership-batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"code":"REQUEST_TOO_LARGE"`) {
+		t.Errorf("Expected the REQUEST_TOO_LARGE envelope, got %s", w.Body.String())
+	}
+}
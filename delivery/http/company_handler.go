@@ -1,12 +1,14 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/validation"
 	"github.com/buildyow/byow-user-service/lib"
 	"github.com/buildyow/byow-user-service/response"
 	"github.com/buildyow/byow-user-service/usecase"
@@ -16,23 +18,45 @@ import (
 
 type CompanyHandler struct {
 	Usecase *usecase.CompanyUsecase
+	// PublicMaxAge is how long CDNs may cache public company responses,
+	// DefaultPublicMaxAge when zero
+	PublicMaxAge time.Duration
+	// MaxBatchSize caps the IDs accepted by batch endpoints,
+	// DefaultMaxBatchSize when zero and never above usecase.MaxOwnershipBatch
+	MaxBatchSize int
+}
+
+// DefaultMaxBatchSize is how many IDs a batch request may carry by default
+const DefaultMaxBatchSize = 100
+
+// batchLimit is MaxBatchSize, or DefaultMaxBatchSize when it isn't set,
+// clamped so a misconfigured limit can't let huge batches reach the database
+func (h *CompanyHandler) batchLimit() int {
+	if h.MaxBatchSize <= 0 {
+		return DefaultMaxBatchSize
+	}
+	return min(h.MaxBatchSize, usecase.MaxOwnershipBatch)
+}
+
+// DefaultPublicMaxAge is the CDN cache lifetime of public company responses
+const DefaultPublicMaxAge = 5 * time.Minute
+
+// setCacheHeaders marks public responses cacheable and keeps everything
+// owner-specific out of shared caches
+func (h *CompanyHandler) setCacheHeaders(c *gin.Context, public bool) {
+	maxAge := h.PublicMaxAge
+	if maxAge == 0 {
+		maxAge = DefaultPublicMaxAge
+	}
+	response.CacheHeaders(c, public, maxAge)
 }
 
 func NewCompanyHandler(uc *usecase.CompanyUsecase) *CompanyHandler {
 	return &CompanyHandler{Usecase: uc}
 }
 
-// @Summary Find All Companies
-// @Tags Companies
-// @Produce plain
-// @Param keyword query string false "Keyword"
-// @Param limit query string false "Limit"
-// @Param offset query string false "Offset"
-// @Success 200 {object} dto.CompanyListResponseSwagger
-// @Failure 400 {object} dto.ErrorResponse
-// @Router /api/companies/all [get]
-func (h *CompanyHandler) FindAll(c *gin.Context) {
-	keyword := c.Query("keyword")
+// parsePagination reads limit and offset query params, falling back to 10 and 0
+func parsePagination(c *gin.Context) (int64, int64) {
 	limitStr := c.Query("limit")
 	offsetStr := c.Query("offset")
 
@@ -50,8 +74,82 @@ func (h *CompanyHandler) FindAll(c *gin.Context) {
 			offset = o
 		}
 	}
+	return limit, offset
+}
+
+// @Summary Find All Companies
+// @Tags Companies
+// @Produce plain
+// @Param keyword query string false "Keyword"
+// @Param limit query string false "Limit"
+// @Param offset query string false "Offset"
+// @Param deleted query bool false "List only soft-deleted companies"
+// @Success 200 {object} dto.CompanyListResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/companies/all [get]
+func (h *CompanyHandler) FindAll(c *gin.Context) {
+	keyword := c.Query("keyword")
+	deleted := c.Query("deleted") == "true"
+	limit, offset := parsePagination(c)
+
+	companies, rowCount, err := h.Usecase.GetAll(c, keyword, deleted, limit, offset)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+
+	response.ListSuccess(c, "Companies", companies, rowCount)
+}
+
+// @Summary Recently Updated Companies
+// @Description The authenticated user's companies, most recently updated first
+// @Tags Companies
+// @Produce json
+// @Param limit query int false "Number of companies (max 10)"
+// @Success 200 {object} dto.CompanySummaryListResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/companies/recent [get]
+func (h *CompanyHandler) FindRecent(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.Query("limit"), 10, 64)
+
+	companies, err := h.Usecase.GetRecent(c, limit)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+
+	response.FetchSuccess(c, "Companies", companies)
+}
+
+// @Summary Admin List Companies
+// @Description List companies across all users. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param keyword query string false "Keyword"
+// @Param verified query bool false "Filter by verification status"
+// @Param user_id query string false "Filter by owner"
+// @Param limit query string false "Limit"
+// @Param offset query string false "Offset"
+// @Success 200 {object} dto.CompanyListResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /api/admin/companies [get]
+func (h *CompanyHandler) AdminFindAll(c *gin.Context) {
+	keyword := c.Query("keyword")
+	userID := c.Query("user_id")
+	limit, offset := parsePagination(c)
+
+	var verified *bool
+	if verifiedStr := c.Query("verified"); verifiedStr != "" {
+		v, err := strconv.ParseBool(verifiedStr)
+		if err != nil {
+			response.ErrorFromAppError(c, appErrors.NewBadRequestError("verified must be true or false"))
+			return
+		}
+		verified = &v
+	}
 
-	companies, rowCount, err := h.Usecase.GetAll(c, keyword, limit, offset)
+	companies, rowCount, err := h.Usecase.AdminGetAll(keyword, userID, verified, limit, offset)
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
@@ -60,6 +158,186 @@ func (h *CompanyHandler) FindAll(c *gin.Context) {
 	response.ListSuccess(c, "Companies", companies, rowCount)
 }
 
+// @Summary Admin Batch Verify Companies
+// @Description Set the verification status of many companies at once. Malformed IDs are skipped. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyCompaniesRequest true "Company IDs & target status"
+// @Success 200 {object} dto.VerifyCompaniesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /api/admin/companies/verify-batch [post]
+func (h *CompanyHandler) AdminVerifyBatch(c *gin.Context) {
+	var req dto.VerifyCompaniesRequest
+	if !bindBatch(c, &req, h.batchLimit()) {
+		return
+	}
+
+	ids := []primitive.ObjectID{}
+	skipped := []string{}
+	for _, idStr := range req.IDs {
+		id, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			skipped = append(skipped, idStr)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	modified, err := h.Usecase.SetVerifiedMany(c, ids, req.Verified)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Companies updated successfully", dto.VerifyCompaniesResponse{Modified: modified, SkippedIDs: skipped})
+}
+
+// @Summary Admin Unverify Company
+// @Description Revoke a company's verification with a reason, which is audited and emailed to the owner. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Param request body dto.UnverifyCompanyRequest true "Reason"
+// @Success 200 {object} dto.CompanyRequestSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/admin/companies/{id}/unverify [post]
+func (h *CompanyHandler) AdminUnverify(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.ErrInvalidId)
+		return
+	}
+	var req dto.UnverifyCompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	company, err := h.Usecase.Unverify(c, id, req.Reason)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Company verification revoked", usecase.ToCompanyResponse(company))
+}
+
+// @Summary Admin Purge Deleted Companies
+// @Description Permanently remove companies soft-deleted more than the given number of days ago, along with their logos. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param days query int true "Minimum days since deletion" example(30)
+// @Success 200 {object} dto.PurgeCompaniesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /api/admin/companies/purge [post]
+func (h *CompanyHandler) AdminPurgeDeleted(c *gin.Context) {
+	days, err := strconv.Atoi(c.Query("days"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.NewValidationError("Days must be a positive number"))
+		return
+	}
+
+	purged, err := h.Usecase.PurgeDeleted(c, days)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Companies purged successfully", dto.PurgeCompaniesResponse{Purged: purged})
+}
+
+// @Summary Public Company Directory
+// @Description List verified companies that opted into the public directory. No authentication required.
+// @Tags Companies
+// @Produce json
+// @Param keyword query string false "Keyword"
+// @Param limit query string false "Limit"
+// @Param offset query string false "Offset"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} dto.PublicCompanyListResponseSwagger
+// @Success 304 "Not modified"
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /companies/public [get]
+func (h *CompanyHandler) FindPublic(c *gin.Context) {
+	keyword := c.Query("keyword")
+	limit, offset := parsePagination(c)
+
+	companies, rowCount, err := h.Usecase.GetPublic(keyword, limit, offset)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+
+	h.setCacheHeaders(c, true)
+	if response.NotModified(c, gin.H{"data": companies, "row_count": rowCount}) {
+		return
+	}
+	response.ListSuccess(c, "Companies", companies, rowCount)
+}
+
+// @Summary Public Company By ID
+// @Description Get a verified company from the public directory. No authentication required, responses are cacheable by CDNs.
+// @Tags Companies
+// @Produce json
+// @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} dto.PublicCompanyResponseSwagger
+// @Success 304 "Not modified"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /companies/public/{id} [get]
+func (h *CompanyHandler) FindPublicByID(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.ErrInvalidId)
+		return
+	}
+
+	company, err := h.Usecase.GetPublicByID(id)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+
+	h.setCacheHeaders(c, true)
+	if response.NotModified(c, company) {
+		return
+	}
+	response.FetchSuccess(c, "Company", company)
+}
+
+// @Summary Public Company vCard
+// @Description Download a verified company from the public directory as a vCard. Email and phone are only included when the owner opted in. No authentication required.
+// @Tags Companies
+// @Produce text/vcard
+// @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Success 200 {string} string "vCard document"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /companies/public/{id}/vcard [get]
+func (h *CompanyHandler) PublicVCard(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.ErrInvalidId)
+		return
+	}
+
+	company, err := h.Usecase.GetPublicByID(id)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+
+	h.setCacheHeaders(c, true)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.vcf"`, vCardFilename(company.CompanyName)))
+	c.Data(http.StatusOK, "text/vcard; charset=utf-8", []byte(companyVCard(company)))
+}
+
 // @Summary Create Company
 // @Description Register a new company
 // @Tags Companies
@@ -70,29 +348,34 @@ func (h *CompanyHandler) FindAll(c *gin.Context) {
 // @Param company_phone formData string true "Company Phone" example(628112123123)
 // @Param company_address formData string true "Company Address" example("123 Cemerlang St, Tech City")
 // @Param company_logo formData file false "Company Logo"
+// @Param public_listing formData bool false "List the company in the public directory once verified"
+// @Param public_contact formData bool false "Show email and phone in the public directory"
 // @Success 201 {object} dto.CompanyRequestSwagger
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /api/companies/create [post]
 func (h *CompanyHandler) Create(c *gin.Context) {
+	// Parse multipart form before reading fields, PostForm would otherwise
+	// swallow a truncated body
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		response.ErrorFromAppError(c, validation.MultipartParseError(c.Request.Context(), err))
+		return
+	}
+
 	var req dto.CompanyRequest
 	// Bind form values to struct
 	req.CompanyName = c.PostForm("company_name")
 	req.CompanyEmail = c.PostForm("company_email")
 	req.CompanyPhone = c.PostForm("company_phone")
 	req.CompanyAddress = c.PostForm("company_address")
-
-	// Parse multipart form
-	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
-		response.ErrorFromAppError(c, appErrors.ErrFailedParseMultipart)
-		return
-	}
+	req.PublicListing = c.PostForm("public_listing") == "true"
+	req.PublicContact = c.PostForm("public_contact") == "true"
 
 	// Upload File
 	file, _, err := c.Request.FormFile("company_logo")
 	if err == nil {
 		companyLogoUrl, err := lib.CloudinaryUpload(file)
 		if err != nil {
-			response.Error(c, http.StatusBadRequest, err.Error())
+			response.ErrorFromAppError(c, err)
 			return
 		}
 		req.CompanyLogo = companyLogoUrl
@@ -104,16 +387,7 @@ func (h *CompanyHandler) Create(c *gin.Context) {
 		response.ErrorFromAppError(c, err)
 		return
 	}
-	companyResponse := dto.CompanyResponse{
-		CompanyID:      company.ID,
-		CompanyName:    company.CompanyName,
-		CompanyEmail:   company.CompanyEmail,
-		CompanyPhone:   company.CompanyPhone,
-		CompanyAddress: company.CompanyAddress,
-		CompanyLogo:    company.CompanyLogo,
-		UserID:         company.UserID,
-		CreatedAt:      company.CreatedAt.Format(time.RFC3339),
-	}
+	companyResponse := usecase.ToCompanyResponse(company)
 	response.CreateSuccess(c, "Company", companyResponse)
 }
 
@@ -123,7 +397,9 @@ func (h *CompanyHandler) Create(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} dto.CompanyRequestSwagger
+// @Success 304 "Not modified"
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /api/companies/{id} [get]
 func (h *CompanyHandler) FindByID(c *gin.Context) {
@@ -139,15 +415,217 @@ func (h *CompanyHandler) FindByID(c *gin.Context) {
 		response.ErrorFromAppError(c, err)
 		return
 	}
-	companyResponse := dto.CompanyResponse{
-		CompanyID:      company.ID,
-		CompanyName:    company.CompanyName,
-		CompanyEmail:   company.CompanyEmail,
-		CompanyPhone:   company.CompanyPhone,
-		CompanyAddress: company.CompanyAddress,
-		CompanyLogo:    company.CompanyLogo,
-		UserID:         company.UserID,
-		CreatedAt:      company.CreatedAt.Format(time.RFC3339),
+	companyResponse := usecase.ToCompanyResponse(company)
+	h.setCacheHeaders(c, false)
+	if response.NotModified(c, companyResponse) {
+		return
 	}
 	response.FetchSuccess(c, "Company", companyResponse)
 }
+
+// @Summary Check Company Ownership
+// @Description Report for each company ID whether it is one of the authenticated user's active companies. Malformed IDs are reported as not owned.
+// @Tags Companies
+// @Accept json
+// @Produce json
+// @Param request body dto.OwnershipBatchRequest true "Company IDs (max MAX_BATCH_SIZE, 100 by default)"
+// @Success 200 {object} dto.OwnershipBatchResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Router /api/companies/ownership-batch [post]
+func (h *CompanyHandler) OwnershipBatch(c *gin.Context) {
+	var req dto.OwnershipBatchRequest
+	if !bindBatch(c, &req, h.batchLimit()) {
+		return
+	}
+
+	ownership, err := h.Usecase.CheckOwnership(c, req.IDs)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Company ownership", ownership)
+}
+
+// @Summary Get Company With Owner
+// @Description Get a company with its owner's public profile. Visible to the owner and, for companies in the public directory, to everyone.
+// @Tags Companies
+// @Produce json
+// @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Success 200 {object} dto.CompanyWithOwnerResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/companies/{id}/details [get]
+func (h *CompanyHandler) FindByIDWithOwner(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.ErrInvalidId)
+		return
+	}
+
+	company, err := h.Usecase.FindByIDWithOwner(c, id)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	// Contact details depend on who is asking, never share the response
+	h.setCacheHeaders(c, false)
+	response.FetchSuccess(c, "Company", company)
+}
+
+// @Summary Find Company By Name
+// @Description Find one of the authenticated user's companies by exact name, ignoring case
+// @Tags Companies
+// @Produce json
+// @Param name query string true "Company name" example(Cemerlang Jaya)
+// @Success 200 {object} dto.CompanyRequestSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/companies/by-name [get]
+func (h *CompanyHandler) FindByName(c *gin.Context) {
+	company, err := h.Usecase.FindByName(c, c.Query("name"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Company", usecase.ToCompanyResponse(company))
+}
+
+// @Summary Check Company Availability
+// @Description Check whether a company email and/or phone is still free. Only the given fields are checked.
+// @Tags Companies
+// @Produce json
+// @Param email query string false "Company email" example(info@company.com)
+// @Param phone query string false "Company phone" example(628112123123)
+// @Success 200 {object} dto.CompanyAvailabilityResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/companies/availability [get]
+func (h *CompanyHandler) CheckAvailability(c *gin.Context) {
+	availability, err := h.Usecase.CheckAvailability(c, c.Query("email"), c.Query("phone"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Availability", availability)
+}
+
+// @Summary Delete Company
+// @Description Soft-delete a company owned by the authenticated user
+// @Tags Companies
+// @Produce json
+// @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/companies/{id} [delete]
+func (h *CompanyHandler) Delete(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.ErrInvalidId)
+		return
+	}
+
+	if err := h.Usecase.Delete(c, id); err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.DeleteSuccess(c, "Company")
+}
+
+// @Summary Restore Company
+// @Description Restore a soft-deleted company owned by the authenticated user
+// @Tags Companies
+// @Produce json
+// @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Success 200 {object} dto.CompanyRequestSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/companies/{id}/restore [post]
+func (h *CompanyHandler) Restore(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.ErrInvalidId)
+		return
+	}
+
+	company, err := h.Usecase.Restore(c, id)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Company restored successfully", usecase.ToCompanyResponse(company))
+}
+
+// @Summary Set Primary Company
+// @Description Make a company owned by the authenticated user their primary company, replacing the previous one
+// @Tags Companies
+// @Produce json
+// @Param id path string true "Company ID" example("60d5ec49f1c2b14c88f3c5e5")
+// @Success 200 {object} dto.CompanyRequestSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/companies/{id}/set-primary [post]
+func (h *CompanyHandler) SetPrimary(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.ErrorFromAppError(c, appErrors.ErrInvalidId)
+		return
+	}
+
+	company, err := h.Usecase.SetPrimary(c, id)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Primary company updated successfully", usecase.ToCompanyResponse(company))
+}
+
+// companyEventHeartbeat keeps idle event streams open through proxies
+const companyEventHeartbeat = 25 * time.Second
+
+// @Summary Company Change Events
+// @Description Server-Sent Events stream of changes to the authenticated user's companies (company.created, company.updated, company.deleted, company.restored)
+// @Tags Companies
+// @Produce text/event-stream
+// @Success 200 {object} dto.CompanyEvent
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/companies/events [get]
+func (h *CompanyHandler) Events(c *gin.Context) {
+	events, unsubscribe, err := h.Usecase.SubscribeEvents(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(companyEventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
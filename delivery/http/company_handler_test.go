@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"mime/multipart"
@@ -11,10 +12,14 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/buildyow/byow-user-service/constants"
 	"github.com/buildyow/byow-user-service/domain/entity"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
 	"github.com/buildyow/byow-user-service/usecase"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,16 +27,16 @@ import (
 
 // Mock company usecase for testing
 type mockCompanyUsecase struct {
-	companies      *[]dto.CompanyResponse
-	totalCount     int64
-	getAllError    error
-	createResponse *entity.Company
-	createError    error
+	companies        *[]dto.CompanyResponse
+	totalCount       int64
+	getAllError      error
+	createResponse   *entity.Company
+	createError      error
 	findByIDResponse *entity.Company
-	findByIDError  error
+	findByIDError    error
 }
 
-func (m *mockCompanyUsecase) GetAll(c *gin.Context, keyword string, limit, offset int64) (*[]dto.CompanyResponse, int64, error) {
+func (m *mockCompanyUsecase) GetAll(c *gin.Context, keyword string, deleted bool, limit, offset int64) (*[]dto.CompanyResponse, int64, error) {
 	if m.getAllError != nil {
 		return nil, 0, m.getAllError
 	}
@@ -64,14 +69,14 @@ func setupCompanyHandlerWithMock(mockUC *mockCompanyUsecase) *CompanyHandler {
 
 func TestNewCompanyHandler(t *testing.T) {
 	setupGinTestMode()
-	
+
 	uc := &usecase.CompanyUsecase{}
 	handler := NewCompanyHandler(uc)
-	
+
 	if handler == nil {
 		t.Fatal("Expected non-nil handler")
 	}
-	
+
 	if handler.Usecase != uc {
 		t.Error("Expected usecase to be set correctly")
 	}
@@ -79,7 +84,7 @@ func TestNewCompanyHandler(t *testing.T) {
 
 func TestCompanyHandler_FindAll_QueryParsing(t *testing.T) {
 	setupGinTestMode()
-	
+
 	testCases := []struct {
 		name           string
 		queryParams    map[string]string
@@ -105,7 +110,7 @@ func TestCompanyHandler_FindAll_QueryParsing(t *testing.T) {
 			5,
 		},
 		{
-			"invalid offset (should use default)", 
+			"invalid offset (should use default)",
 			map[string]string{"limit": "15", "offset": "invalid"},
 			15,
 			0, // default when parsing fails
@@ -117,51 +122,51 @@ func TestCompanyHandler_FindAll_QueryParsing(t *testing.T) {
 			0,
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
-			
+
 			// Build query string
 			values := url.Values{}
 			for key, value := range tc.queryParams {
 				values.Add(key, value)
 			}
-			
+
 			req := httptest.NewRequest("GET", "/api/companies/all?"+values.Encode(), nil)
 			c.Request = req
-			
+
 			// Test query parameter extraction
 			keyword := c.Query("keyword")
 			limitStr := c.Query("limit")
 			offsetStr := c.Query("offset")
-			
+
 			var (
 				limit  int64 = 10 // default
 				offset int64 = 0  // default
 			)
-			
+
 			if limitStr != "" {
 				if l, err := strconv.ParseInt(limitStr, 10, 64); err == nil {
 					limit = l
 				}
 			}
-			
+
 			if offsetStr != "" {
 				if o, err := strconv.ParseInt(offsetStr, 10, 64); err == nil {
 					offset = o
 				}
 			}
-			
+
 			if limit != tc.expectedLimit {
 				t.Errorf("Expected limit %d, got %d", tc.expectedLimit, limit)
 			}
-			
+
 			if offset != tc.expectedOffset {
 				t.Errorf("Expected offset %d, got %d", tc.expectedOffset, offset)
 			}
-			
+
 			if expectedKeyword, exists := tc.queryParams["keyword"]; exists {
 				if keyword != expectedKeyword {
 					t.Errorf("Expected keyword '%s', got '%s'", expectedKeyword, keyword)
@@ -173,56 +178,56 @@ func TestCompanyHandler_FindAll_QueryParsing(t *testing.T) {
 
 func TestCompanyHandler_FindAll_Success(t *testing.T) {
 	setupGinTestMode()
-	
+
 	// Test handler initialization instead of execution
 	handler := setupCompanyHandler()
-	
+
 	if handler == nil {
 		t.Fatal("Expected non-nil handler")
 	}
-	
+
 	if handler.Usecase == nil {
 		t.Error("Expected usecase to be set")
 	}
-	
+
 	t.Log("FindAll handler structure test completed")
 }
 
 func TestCompanyHandler_Create_FormParsing(t *testing.T) {
 	setupGinTestMode()
-	
+
 	// Test form data extraction logic
 	form := url.Values{}
 	form.Add("company_name", "Test Company")
 	form.Add("company_email", "test@company.com")
 	form.Add("company_phone", "+1234567890")
 	form.Add("company_address", "123 Test Street")
-	
+
 	req, _ := http.NewRequest("POST", "/api/companies/create", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	
+
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Test that PostForm values can be extracted
 	companyName := c.PostForm("company_name")
 	companyEmail := c.PostForm("company_email")
 	companyPhone := c.PostForm("company_phone")
 	companyAddress := c.PostForm("company_address")
-	
+
 	if companyName != "Test Company" {
 		t.Errorf("Expected company name 'Test Company', got '%s'", companyName)
 	}
-	
+
 	if companyEmail != "test@company.com" {
 		t.Errorf("Expected company email 'test@company.com', got '%s'", companyEmail)
 	}
-	
+
 	if companyPhone != "+1234567890" {
 		t.Errorf("Expected company phone '+1234567890', got '%s'", companyPhone)
 	}
-	
+
 	if companyAddress != "123 Test Street" {
 		t.Errorf("Expected company address '123 Test Street', got '%s'", companyAddress)
 	}
@@ -230,43 +235,43 @@ func TestCompanyHandler_Create_FormParsing(t *testing.T) {
 
 func TestCompanyHandler_Create_MultipartFormHandling(t *testing.T) {
 	setupGinTestMode()
-	
+
 	// Create multipart form data
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
-	
+
 	writer.WriteField("company_name", "Multipart Company")
 	writer.WriteField("company_email", "multipart@company.com")
 	writer.WriteField("company_phone", "+1234567890")
 	writer.WriteField("company_address", "456 Multipart Avenue")
-	
+
 	writer.Close()
-	
+
 	req, _ := http.NewRequest("POST", "/api/companies/create", &buf)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
+
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	
+
 	// Test multipart form content type
 	contentType := req.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "multipart/form-data") {
 		t.Error("Expected multipart/form-data content type")
 	}
-	
+
 	// Test that handler can be created
 	handler := setupCompanyHandler()
 	if handler == nil {
 		t.Fatal("Expected non-nil handler")
 	}
-	
+
 	t.Log("Multipart form handling test completed")
 }
 
 func TestCompanyHandler_FindByID_ParameterParsing(t *testing.T) {
 	setupGinTestMode()
-	
+
 	testCases := []struct {
 		name        string
 		idParam     string
@@ -293,7 +298,7 @@ func TestCompanyHandler_FindByID_ParameterParsing(t *testing.T) {
 			true,
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
@@ -301,30 +306,30 @@ func TestCompanyHandler_FindByID_ParameterParsing(t *testing.T) {
 			c.Params = gin.Params{
 				{Key: "id", Value: tc.idParam},
 			}
-			
+
 			// Test ObjectID parsing
 			idParam := c.Param("id")
 			_, err := primitive.ObjectIDFromHex(idParam)
-			
+
 			if tc.expectError && err == nil {
 				t.Error("Expected error parsing ObjectID but got none")
 			}
-			
+
 			if !tc.expectError && err != nil {
 				t.Errorf("Expected no error parsing ObjectID but got: %v", err)
 			}
-			
+
 			handler := setupCompanyHandler()
-			
+
 			// Expect potential panics due to missing deps
 			defer func() {
 				if r := recover(); r != nil {
 					t.Logf("Handler panicked as expected due to missing dependencies: %v", r)
 				}
 			}()
-			
+
 			handler.FindByID(c)
-			
+
 			if tc.expectError && w.Code == http.StatusOK {
 				t.Error("Expected error response but got success")
 			}
@@ -345,7 +350,7 @@ func TestCompanyHandler_ResponseMapping(t *testing.T) {
 		Verified:       true,
 		CreatedAt:      time.Now(),
 	}
-	
+
 	response := dto.CompanyResponse{
 		CompanyID:      company.ID,
 		CompanyName:    company.CompanyName,
@@ -357,40 +362,40 @@ func TestCompanyHandler_ResponseMapping(t *testing.T) {
 		Verified:       company.Verified,
 		CreatedAt:      company.CreatedAt.Format(time.RFC3339),
 	}
-	
+
 	// Verify all fields are mapped correctly
 	if response.CompanyID != company.ID {
 		t.Errorf("Expected company ID %v, got %v", company.ID, response.CompanyID)
 	}
-	
+
 	if response.CompanyName != company.CompanyName {
 		t.Errorf("Expected company name %s, got %s", company.CompanyName, response.CompanyName)
 	}
-	
+
 	if response.CompanyEmail != company.CompanyEmail {
 		t.Errorf("Expected company email %s, got %s", company.CompanyEmail, response.CompanyEmail)
 	}
-	
+
 	if response.CompanyPhone != company.CompanyPhone {
 		t.Errorf("Expected company phone %s, got %s", company.CompanyPhone, response.CompanyPhone)
 	}
-	
+
 	if response.CompanyAddress != company.CompanyAddress {
 		t.Errorf("Expected company address %s, got %s", company.CompanyAddress, response.CompanyAddress)
 	}
-	
+
 	if response.CompanyLogo != company.CompanyLogo {
 		t.Errorf("Expected company logo %s, got %s", company.CompanyLogo, response.CompanyLogo)
 	}
-	
+
 	if response.UserID != company.UserID {
 		t.Errorf("Expected user ID %s, got %s", company.UserID, response.UserID)
 	}
-	
+
 	if response.Verified != company.Verified {
 		t.Errorf("Expected verified %v, got %v", company.Verified, response.Verified)
 	}
-	
+
 	// Check time formatting
 	expectedTime := company.CreatedAt.Format(time.RFC3339)
 	if response.CreatedAt != expectedTime {
@@ -411,23 +416,23 @@ func TestCompanyHandler_JSONSerialization(t *testing.T) {
 		Verified:       true,
 		CreatedAt:      time.Now().Format(time.RFC3339),
 	}
-	
+
 	jsonData, err := json.Marshal(response)
 	if err != nil {
 		t.Errorf("Expected no error marshaling to JSON, got %v", err)
 	}
-	
+
 	if len(jsonData) == 0 {
 		t.Error("Expected non-empty JSON data")
 	}
-	
+
 	// Test unmarshaling
 	var unmarshaled dto.CompanyResponse
 	err = json.Unmarshal(jsonData, &unmarshaled)
 	if err != nil {
 		t.Errorf("Expected no error unmarshaling from JSON, got %v", err)
 	}
-	
+
 	if unmarshaled.CompanyName != response.CompanyName {
 		t.Errorf("Expected company name %s after JSON round-trip, got %s", response.CompanyName, unmarshaled.CompanyName)
 	}
@@ -442,23 +447,23 @@ func TestCompanyHandler_RequestStructures(t *testing.T) {
 		CompanyAddress: "123 Test Street",
 		CompanyLogo:    "logo.png",
 	}
-	
+
 	if req.CompanyName == "" {
 		t.Error("Expected company name to be set")
 	}
-	
+
 	if req.CompanyEmail == "" {
 		t.Error("Expected company email to be set")
 	}
-	
+
 	if req.CompanyPhone == "" {
 		t.Error("Expected company phone to be set")
 	}
-	
+
 	if req.CompanyAddress == "" {
 		t.Error("Expected company address to be set")
 	}
-	
+
 	if req.CompanyLogo == "" {
 		t.Error("Expected company logo to be set")
 	}
@@ -466,7 +471,7 @@ func TestCompanyHandler_RequestStructures(t *testing.T) {
 
 func TestCompanyHandler_ErrorHandling(t *testing.T) {
 	setupGinTestMode()
-	
+
 	testCases := []struct {
 		name           string
 		idParam        string
@@ -483,7 +488,7 @@ func TestCompanyHandler_ErrorHandling(t *testing.T) {
 			http.StatusBadRequest,
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
@@ -491,10 +496,10 @@ func TestCompanyHandler_ErrorHandling(t *testing.T) {
 			c.Params = gin.Params{
 				{Key: "id", Value: tc.idParam},
 			}
-			
+
 			handler := setupCompanyHandler()
 			handler.FindByID(c)
-			
+
 			// Test that proper error status is returned (may vary based on implementation)
 			if w.Code == http.StatusOK {
 				t.Logf("Handler returned status %d for invalid input %s", w.Code, tc.idParam)
@@ -506,15 +511,15 @@ func TestCompanyHandler_ErrorHandling(t *testing.T) {
 func TestCompanyHandler_StructInitialization(t *testing.T) {
 	// Test handler struct initialization
 	handler := &CompanyHandler{}
-	
+
 	if handler == nil {
 		t.Fatal("Expected non-nil handler")
 	}
-	
+
 	// Test with usecase
 	uc := &usecase.CompanyUsecase{}
 	handler = &CompanyHandler{Usecase: uc}
-	
+
 	if handler.Usecase != uc {
 		t.Error("Expected usecase to be set correctly")
 	}
@@ -522,7 +527,7 @@ func TestCompanyHandler_StructInitialization(t *testing.T) {
 
 func TestCompanyHandler_HTTPMethods(t *testing.T) {
 	setupGinTestMode()
-	
+
 	testCases := []struct {
 		name   string
 		method string
@@ -557,16 +562,16 @@ func TestCompanyHandler_HTTPMethods(t *testing.T) {
 			},
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
 			c.Request = httptest.NewRequest(tc.method, tc.path, nil)
-			
+
 			handler := setupCompanyHandler()
 			tc.setup(handler, c)
-			
+
 			// Test that handlers don't panic
 			t.Logf("Handler %s %s completed without panic", tc.method, tc.path)
 		})
@@ -575,7 +580,7 @@ func TestCompanyHandler_HTTPMethods(t *testing.T) {
 
 func TestCompanyHandler_ParameterValidation(t *testing.T) {
 	setupGinTestMode()
-	
+
 	// Test various parameter validation scenarios
 	testCases := []struct {
 		name     string
@@ -621,41 +626,41 @@ func TestCompanyHandler_ParameterValidation(t *testing.T) {
 			},
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			values := url.Values{}
 			values.Add("limit", tc.limit)
 			values.Add("offset", tc.offset)
 			values.Add("keyword", tc.keyword)
-			
+
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
 			req := httptest.NewRequest("GET", "/api/companies/all?"+values.Encode(), nil)
 			c.Request = req
-			
+
 			// Extract and parse parameters as the handler would
 			limitStr := c.Query("limit")
 			offsetStr := c.Query("offset")
 			keyword := c.Query("keyword")
-			
+
 			var (
 				limit  int64 = 10 // default
 				offset int64 = 0  // default
 			)
-			
+
 			if limitStr != "" {
 				if l, err := strconv.ParseInt(limitStr, 10, 64); err == nil {
 					limit = l
 				}
 			}
-			
+
 			if offsetStr != "" {
 				if o, err := strconv.ParseInt(offsetStr, 10, 64); err == nil {
 					offset = o
 				}
 			}
-			
+
 			tc.validate(t, limit, offset, keyword)
 		})
 	}
@@ -664,20 +669,20 @@ func TestCompanyHandler_ParameterValidation(t *testing.T) {
 func TestCompanyHandler_TimeFormatting(t *testing.T) {
 	// Test time formatting used in response mapping
 	testTime := time.Date(2023, 12, 25, 15, 30, 45, 0, time.UTC)
-	
+
 	formatted := testTime.Format(time.RFC3339)
 	expected := "2023-12-25T15:30:45Z"
-	
+
 	if formatted != expected {
 		t.Errorf("Expected time format %s, got %s", expected, formatted)
 	}
-	
+
 	// Test parsing back
 	parsed, err := time.Parse(time.RFC3339, formatted)
 	if err != nil {
 		t.Errorf("Expected no error parsing time, got %v", err)
 	}
-	
+
 	if !parsed.Equal(testTime) {
 		t.Errorf("Expected parsed time to equal original time")
 	}
@@ -689,7 +694,7 @@ func TestCompanyHandler_ErrorTypes(t *testing.T) {
 	if err == nil {
 		t.Error("Expected ErrInvalidId to be defined")
 	}
-	
+
 	err = appErrors.ErrFailedParseMultipart
 	if err == nil {
 		t.Error("Expected ErrFailedParseMultipart to be defined")
@@ -699,46 +704,79 @@ func TestCompanyHandler_ErrorTypes(t *testing.T) {
 // Integration test for complete flow
 func TestCompanyHandler_CompleteFlow(t *testing.T) {
 	setupGinTestMode()
-	
+
 	// Test the complete handler flow structure
 	handler := setupCompanyHandler()
-	
+
 	if handler == nil {
 		t.Fatal("Expected non-nil handler")
 	}
-	
+
 	// Test that we can create proper HTTP requests
 	req1 := httptest.NewRequest("GET", "/api/companies/all?limit=5&offset=0", nil)
 	if req1 == nil {
 		t.Error("Expected valid GET request")
 	}
-	
+
 	// Test form creation
 	form := url.Values{}
 	form.Add("company_name", "Integration Test Company")
 	form.Add("company_email", "integration@test.com")
-	
+
 	req2 := httptest.NewRequest("POST", "/api/companies/create", strings.NewReader(form.Encode()))
 	req2.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	
+
 	if req2.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
 		t.Error("Expected correct content type")
 	}
-	
+
 	// Test parameter handling
 	params := gin.Params{{Key: "id", Value: "507f1f77bcf86cd799439011"}}
 	if len(params) != 1 {
 		t.Error("Expected 1 parameter")
 	}
-	
+
 	t.Log("Complete handler structure test completed")
 }
 
 // Benchmark tests
+func TestCompanyHandler_AdminVerifyBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := setupCompanyHandler()
+	newRouter := func(role string) *gin.Engine {
+		router := gin.New()
+		router.POST("/api/admin/companies/verify-batch", func(c *gin.Context) {
+			c.Set("user_id", "admin-123")
+			c.Set("role", role)
+			c.Next()
+		}, jwt.RequirePermission(constants.PERMISSION_ADMIN_ACCESS), handler.AdminVerifyBatch)
+		return router
+	}
+	send := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/admin/companies/verify-batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(newRouter("user"), `{"ids":["60c72b2f9b1e8c001c8e4d3a"],"verified":true}`); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-admin, got %d", w.Code)
+	}
+	if w := send(newRouter(constants.ROLE_ADMIN), `{"ids":`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for malformed JSON, got %d", w.Code)
+	}
+	// Every ID is skipped as malformed, leaving nothing to update
+	if w := send(newRouter(constants.ROLE_ADMIN), `{"ids":["nope","123"],"verified":true}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 when no valid ID remains, got %d", w.Code)
+	}
+}
+
 func BenchmarkCompanyHandler_FindAll(b *testing.B) {
 	setupGinTestMode()
 	handler := setupCompanyHandler()
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		w := httptest.NewRecorder()
@@ -750,43 +788,638 @@ func BenchmarkCompanyHandler_FindAll(b *testing.B) {
 
 func BenchmarkCompanyHandler_ParameterParsing(b *testing.B) {
 	setupGinTestMode()
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		values := url.Values{}
 		values.Add("limit", "20")
 		values.Add("offset", "10")
 		values.Add("keyword", "benchmark")
-		
+
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		req := httptest.NewRequest("GET", "/test?"+values.Encode(), nil)
 		c.Request = req
-		
+
 		// Simulate parameter parsing
 		limitStr := c.Query("limit")
 		offsetStr := c.Query("offset")
 		keyword := c.Query("keyword")
-		
+
 		var (
 			limit  int64 = 10
 			offset int64 = 0
 		)
-		
+
 		if limitStr != "" {
 			if l, err := strconv.ParseInt(limitStr, 10, 64); err == nil {
 				limit = l
 			}
 		}
-		
+
 		if offsetStr != "" {
 			if o, err := strconv.ParseInt(offsetStr, 10, 64); err == nil {
 				offset = o
 			}
 		}
-		
+
 		_ = limit
 		_ = offset
 		_ = keyword
 	}
-}
\ No newline at end of file
+}
+
+// stubCompanyRepository keeps companies in memory keyed by ID
+type stubCompanyRepository struct {
+	companies map[primitive.ObjectID]*entity.Company
+}
+
+func (r *stubCompanyRepository) FindAll(filter repository.CompanyFilter, limit int64, offset int64) ([]*entity.Company, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *stubCompanyRepository) Create(company *entity.Company) error {
+	company.ID = primitive.NewObjectID()
+	company.CreatedAt = time.Now()
+	r.companies[company.ID] = company
+	return nil
+}
+
+func (r *stubCompanyRepository) FindByID(id primitive.ObjectID) (*entity.Company, error) {
+	if company, ok := r.companies[id]; ok && company.DeletedAt == nil {
+		return company, nil
+	}
+	return nil, appErrors.NewNotFoundError("Company")
+}
+
+func (r *stubCompanyRepository) FindOwnedIDs(userID string, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	owned := []primitive.ObjectID{}
+	for _, id := range ids {
+		if company, ok := r.companies[id]; ok && company.UserID == userID && company.DeletedAt == nil {
+			owned = append(owned, id)
+		}
+	}
+	return owned, nil
+}
+
+func (r *stubCompanyRepository) FindDeletedByID(id primitive.ObjectID) (*entity.Company, error) {
+	if company, ok := r.companies[id]; ok && company.DeletedAt != nil {
+		return company, nil
+	}
+	return nil, appErrors.NewNotFoundError("Company")
+}
+
+func (r *stubCompanyRepository) FindByNameForUser(userID string, name string) (*entity.Company, error) {
+	return nil, appErrors.NewNotFoundError("Company")
+}
+
+func (r *stubCompanyRepository) FindRecent(userID string, limit int64) ([]*entity.Company, error) {
+	return nil, nil
+}
+
+func (r *stubCompanyRepository) FindByEmail(email string) (*entity.Company, error) {
+	return nil, appErrors.NewNotFoundError("Company")
+}
+
+func (r *stubCompanyRepository) FindByPhone(phone string) (*entity.Company, error) {
+	return nil, appErrors.NewNotFoundError("Company")
+}
+
+func (r *stubCompanyRepository) Update(company *entity.Company) error {
+	r.companies[company.ID] = company
+	return nil
+}
+
+func (r *stubCompanyRepository) Delete(id primitive.ObjectID) error {
+	now := time.Now()
+	r.companies[id].DeletedAt = &now
+	return nil
+}
+
+func (r *stubCompanyRepository) Restore(id primitive.ObjectID) error {
+	r.companies[id].DeletedAt = nil
+	return nil
+}
+
+func (r *stubCompanyRepository) ReassignOwner(fromUserID string, toUserID string) (int64, error) {
+	return 0, nil
+}
+
+func (r *stubCompanyRepository) SetVerifiedMany(ids []primitive.ObjectID, verified bool) (int64, error) {
+	return 0, nil
+}
+
+func (r *stubCompanyRepository) DeleteByOwner(userID string) ([]*entity.Company, error) {
+	return []*entity.Company{}, nil
+}
+
+func (r *stubCompanyRepository) PurgeDeletedOlderThan(cutoff time.Time) ([]*entity.Company, error) {
+	purged := []*entity.Company{}
+	for id, company := range r.companies {
+		if company.DeletedAt != nil && company.DeletedAt.Before(cutoff) {
+			purged = append(purged, company)
+			delete(r.companies, id)
+		}
+	}
+	return purged, nil
+}
+
+func (r *stubCompanyRepository) SetPrimary(userID string, id primitive.ObjectID) error {
+	return nil
+}
+
+func TestCompanyHandler_Events_StreamsOwnChanges(t *testing.T) {
+	setupGinTestMode()
+
+	hub := usecase.NewCompanyEventHub()
+	uc := &usecase.CompanyUsecase{
+		Repo:   &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{}},
+		UserID: func(c *gin.Context) string { return c.GetString("user_id") },
+		Events: hub,
+	}
+	handler := NewCompanyHandler(uc)
+
+	router := gin.New()
+	router.GET("/api/companies/events", func(c *gin.Context) {
+		c.Set("user_id", c.Query("as"))
+		c.Next()
+	}, handler.Events)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/companies/events?as=user-123")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("Expected an event stream, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// Another user's change must not reach this stream
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("user_id", "other-user")
+	if _, err := uc.Create(c, dto.CompanyRequest{CompanyName: "Not Mine"}); err != nil {
+		t.Fatalf("Failed to create company: %v", err)
+	}
+	c.Set("user_id", "user-123")
+	company, err := uc.Create(c, dto.CompanyRequest{CompanyName: "Mine"})
+	if err != nil {
+		t.Fatalf("Failed to create company: %v", err)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	var eventName, data string
+	timeout := time.After(2 * time.Second)
+	for data == "" {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("Stream closed before an event arrived")
+			}
+			if strings.HasPrefix(line, "event:") {
+				eventName = strings.TrimPrefix(line, "event:")
+			}
+			if strings.HasPrefix(line, "data:") {
+				data = strings.TrimPrefix(line, "data:")
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the event")
+		}
+	}
+
+	var event dto.CompanyEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatalf("Failed to parse event data %q: %v", data, err)
+	}
+	if eventName != constants.COMPANY_EVENT_CREATED || event.CompanyID != company.ID {
+		t.Errorf("Expected created event for %s, got %q %+v", company.ID.Hex(), eventName, event)
+	}
+
+	// Disconnecting removes the subscription
+	resp.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Subscribers("user-123") > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := hub.Subscribers("user-123"); n != 0 {
+		t.Errorf("Expected the subscription to be cleaned up, %d left", n)
+	}
+}
+
+func TestCompanyHandler_Events_Disabled(t *testing.T) {
+	setupGinTestMode()
+
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{UserID: func(c *gin.Context) string { return "user-123" }})
+	router := gin.New()
+	router.GET("/api/companies/events", handler.Events)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/companies/events", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without an event hub, got %d", w.Code)
+	}
+}
+
+func TestCompanyHandler_FindByIDWithOwner(t *testing.T) {
+	setupGinTestMode()
+
+	withOwner := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyName: "BuildYow"}
+	// The orphan points at an owner account that no longer exists
+	orphan := &entity.Company{ID: primitive.NewObjectID(), UserID: "deleted-user", CompanyName: "Orphan"}
+	uc := &usecase.CompanyUsecase{
+		Repo: &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{
+			withOwner.ID: withOwner,
+			orphan.ID:    orphan,
+		}},
+		UserID: func(c *gin.Context) string { return c.Query("as") },
+		Users: &stubUserRepository{users: map[string]*entity.User{
+			"john@example.com": {ID: "user-123", Fullname: "John Doe", Email: "john@example.com", PhoneNumber: "628112123123", AvatarUrl: "https://assets/john.jpg", Verified: true},
+		}},
+	}
+	handler := NewCompanyHandler(uc)
+
+	router := gin.New()
+	router.GET("/api/companies/:id/details", handler.FindByIDWithOwner)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/companies/"+withOwner.ID.Hex()+"/details?as=user-123", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Response struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Response.Data["company_name"] != "BuildYow" {
+		t.Errorf("Expected company fields at the top level, got %v", body.Response.Data)
+	}
+	owner, ok := body.Response.Data["owner"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected an owner object, got %v", body.Response.Data["owner"])
+	}
+	if owner["full_name"] != "John Doe" || owner["avatar_url"] != "https://assets/john.jpg" || owner["verified"] != true {
+		t.Errorf("Unexpected owner %v", owner)
+	}
+	if _, leaked := owner["email"]; leaked {
+		t.Error("Expected owner email to be left out")
+	}
+	if _, leaked := owner["phone_number"]; leaked {
+		t.Error("Expected owner phone to be left out")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/companies/"+orphan.ID.Hex()+"/details?as=deleted-user", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"owner":null`) {
+		t.Errorf("Expected a null owner, got %s", w.Body.String())
+	}
+}
+
+func TestCompanyHandler_UpdatedAt(t *testing.T) {
+	setupGinTestMode()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	edited := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyName: "Edited", CreatedAt: created, UpdatedAt: created.Add(time.Hour)}
+	// Companies created before updated_at was stamped on create have none
+	legacy := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyName: "Legacy", CreatedAt: created}
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{edited.ID: edited, legacy.ID: legacy}},
+		UserID: func(c *gin.Context) string { return "user-123" },
+	})
+
+	router := gin.New()
+	router.GET("/api/companies/:id", handler.FindByID)
+
+	for _, tc := range []struct {
+		company *entity.Company
+		want    string
+	}{
+		{edited, "2024-01-02T04:04:05Z"},
+		{legacy, "2024-01-02T03:04:05Z"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/companies/"+tc.company.ID.Hex(), nil)
+		router.ServeHTTP(w, req)
+
+		var body struct {
+			Response struct {
+				Data dto.CompanyResponse `json:"data"`
+			} `json:"response"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if body.Response.Data.UpdatedAt != tc.want {
+			t.Errorf("Expected %s to report updated_at %s, got %q", tc.company.CompanyName, tc.want, body.Response.Data.UpdatedAt)
+		}
+	}
+}
+
+func TestCompanyHandler_AdminPurgeDeleted(t *testing.T) {
+	setupGinTestMode()
+
+	longAgo := time.Now().AddDate(0, 0, -60)
+	recently := time.Now().AddDate(0, 0, -2)
+	old := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Old", DeletedAt: &longAgo}
+	recent := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Recent", DeletedAt: &recently}
+	repo := &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{old.ID: old, recent.ID: recent}}
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   repo,
+		UserID: func(c *gin.Context) string { return c.GetString("user_id") },
+	})
+
+	router := gin.New()
+	router.POST("/api/admin/companies/purge", func(c *gin.Context) {
+		c.Set("user_id", "admin-123")
+		c.Set("role", c.Query("role"))
+		c.Next()
+	}, jwt.RequirePermission(constants.PERMISSION_ADMIN_ACCESS), handler.AdminPurgeDeleted)
+	send := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/admin/companies/purge?"+query, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("role=user&days=30"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-admin, got %d", w.Code)
+	}
+	for _, days := range []string{"", "abc", "0", "-1"} {
+		if w := send("role=admin&days=" + days); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for days=%q, got %d", days, w.Code)
+		}
+	}
+
+	w := send("role=admin&days=30")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"purged":1`) {
+		t.Errorf("Expected one company purged, got %s", w.Body.String())
+	}
+	if _, exists := repo.companies[old.ID]; exists {
+		t.Error("Expected the old company to be purged")
+	}
+	if _, exists := repo.companies[recent.ID]; !exists {
+		t.Error("Expected the recently deleted company to be kept")
+	}
+}
+
+func TestCompanyHandler_OwnershipBatch(t *testing.T) {
+	setupGinTestMode()
+
+	owned := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyName: "Mine"}
+	others := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyName: "Theirs"}
+	repo := &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{owned.ID: owned, others.ID: others}}
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   repo,
+		UserID: func(c *gin.Context) string { return c.GetString("user_id") },
+	})
+
+	router := gin.New()
+	router.POST("/api/companies/ownership-batch", func(c *gin.Context) {
+		c.Set("user_id", "user-123")
+		c.Next()
+	}, handler.OwnershipBatch)
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/companies/ownership-batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(`{"ids":`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for malformed JSON, got %d", w.Code)
+	}
+	if w := send(`{"ids":[]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an empty batch, got %d", w.Code)
+	}
+
+	w := send(`{"ids":["` + owned.ID.Hex() + `","` + others.ID.Hex() + `","bogus"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Response struct {
+			Data map[string]bool `json:"data"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := map[string]bool{owned.ID.Hex(): true, others.ID.Hex(): false, "bogus": false}
+	if len(body.Response.Data) != len(expected) {
+		t.Fatalf("Expected %d entries, got %v", len(expected), body.Response.Data)
+	}
+	for id, want := range expected {
+		if body.Response.Data[id] != want {
+			t.Errorf("Expected %s to be %v, got %v", id, want, body.Response.Data[id])
+		}
+	}
+}
+
+func TestCompanyHandler_CacheHeaders(t *testing.T) {
+	setupGinTestMode()
+
+	listed := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyName: "Listed", Verified: true, PublicListing: true}
+	unlisted := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyName: "Unlisted"}
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{listed.ID: listed, unlisted.ID: unlisted}},
+		UserID: func(c *gin.Context) string { return "user-123" },
+	})
+	handler.PublicMaxAge = 10 * time.Minute
+
+	router := gin.New()
+	router.GET("/companies/public/:id", handler.FindPublicByID)
+	router.GET("/api/companies/:id", handler.FindByID)
+	router.GET("/api/companies/:id/details", handler.FindByIDWithOwner)
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/companies/public/"+listed.ID.Hex(), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if cacheControl := w.Header().Get("Cache-Control"); cacheControl != "public, max-age=600" {
+		t.Errorf("Expected a public max-age, got %q", cacheControl)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag on the public response")
+	}
+	if w := get("/companies/public/"+listed.ID.Hex(), etag); w.Code != http.StatusNotModified || w.Header().Get("Cache-Control") != "public, max-age=600" {
+		t.Errorf("Expected a cacheable 304 for a matching ETag, got %d with %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+	if w := get("/companies/public/"+unlisted.ID.Hex(), ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a company outside the directory, got %d", w.Code)
+	}
+
+	for _, path := range []string{"/api/companies/" + listed.ID.Hex(), "/api/companies/" + listed.ID.Hex() + "/details"} {
+		w := get(path, "")
+		if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("Expected %s to be no-store, got %d with %q", path, w.Code, w.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+func TestCompanyHandler_PublicVCard(t *testing.T) {
+	setupGinTestMode()
+
+	verified := &entity.Company{
+		ID:             primitive.NewObjectID(),
+		UserID:         "user-123",
+		CompanyName:    "Acme, Inc; Ltd",
+		CompanyEmail:   "info@acme.com",
+		CompanyPhone:   "628112123123",
+		CompanyAddress: "1 Main St\nSuite 2, Tech City",
+		Verified:       true,
+		PublicListing:  true,
+		PublicContact:  true,
+	}
+	unverified := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyName: "Pending", PublicListing: true}
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{verified.ID: verified, unverified.ID: unverified}},
+		UserID: func(c *gin.Context) string { return "user-123" },
+	})
+
+	router := gin.New()
+	router.GET("/companies/public/:id/vcard", handler.PublicVCard)
+	get := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/companies/public/"+id+"/vcard", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(verified.ID.Hex())
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/vcard; charset=utf-8" {
+		t.Errorf("Expected a vCard content type, got %q", contentType)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); disposition != `attachment; filename="acme-inc-ltd.vcf"` {
+		t.Errorf("Expected a vcf attachment, got %q", disposition)
+	}
+	expected := "BEGIN:VCARD\r\n" +
+		"VERSION:4.0\r\n" +
+		"KIND:org\r\n" +
+		"FN:Acme\\, Inc\\; Ltd\r\n" +
+		"ORG:Acme\\, Inc\\; Ltd\r\n" +
+		"EMAIL;TYPE=work:info@acme.com\r\n" +
+		"TEL;TYPE=work,voice:628112123123\r\n" +
+		"ADR;TYPE=work:;;1 Main St\\nSuite 2\\, Tech City;;;;\r\n" +
+		"END:VCARD\r\n"
+	if w.Body.String() != expected {
+		t.Errorf("Unexpected vCard:\n%q\nwant\n%q", w.Body.String(), expected)
+	}
+
+	verified.PublicContact = false
+	if body := get(verified.ID.Hex()).Body.String(); strings.Contains(body, "EMAIL") || strings.Contains(body, "TEL") {
+		t.Errorf("Expected contact details to be hidden without consent, got %q", body)
+	}
+
+	if w := get(unverified.ID.Hex()); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unverified company, got %d", w.Code)
+	}
+}
+
+func TestFoldVCardLine(t *testing.T) {
+	line := "NOTE:" + strings.Repeat("é", 60)
+	folded := foldVCardLine(line)
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > vCardLineLength {
+			t.Errorf("Expected lines of at most %d octets, got %d", vCardLineLength, len(part))
+		}
+		if !utf8.ValidString(part) {
+			t.Errorf("Expected folding to keep characters whole, got %q", part)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line {
+		t.Errorf("Expected unfolding to restore the line, got %q", unfolded)
+	}
+}
+
+func TestCompanyHandler_BatchLimitClamped(t *testing.T) {
+	handler := &CompanyHandler{}
+	if limit := handler.batchLimit(); limit != DefaultMaxBatchSize {
+		t.Errorf("Expected the default limit when unset, got %d", limit)
+	}
+	handler.MaxBatchSize = 1000000
+	if limit := handler.batchLimit(); limit != usecase.MaxOwnershipBatch {
+		t.Errorf("Expected the limit clamped to %d, got %d", usecase.MaxOwnershipBatch, limit)
+	}
+}
+
+func TestCompanyHandler_BatchSizeLimit(t *testing.T) {
+	setupGinTestMode()
+
+	handler := NewCompanyHandler(&usecase.CompanyUsecase{
+		Repo:   &stubCompanyRepository{companies: map[primitive.ObjectID]*entity.Company{}},
+		UserID: func(c *gin.Context) string { return "admin-123" },
+	})
+	handler.MaxBatchSize = 3
+
+	router := gin.New()
+	router.POST("/api/companies/ownership-batch", handler.OwnershipBatch)
+	router.POST("/api/admin/companies/verify-batch", handler.AdminVerifyBatch)
+
+	batch := func(size int) string {
+		ids := make([]string, size)
+		for i := range ids {
+			ids[i] = `"` + primitive.NewObjectID().Hex() + `"`
+		}
+		return `{"ids":[` + strings.Join(ids, ",") + `]}`
+	}
+
+	for _, path := range []string{"/api/companies/ownership-batch", "/api/admin/companies/verify-batch"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", path, strings.NewReader(batch(4)))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected 400 for an over-limit batch, got %d", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), `"code":"BAD_REQUEST"`) || !strings.Contains(w.Body.String(), "At most 3 items") {
+				t.Errorf("Expected the limit in the error details, got %s", w.Body.String())
+			}
+
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest("POST", path, strings.NewReader(batch(3)))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected an at-limit batch to proceed, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
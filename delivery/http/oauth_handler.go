@@ -0,0 +1,93 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/buildyow/byow-user-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// The state of an OAuth login waits in a cookie between the redirect to the
+// provider and its callback, so a callback can't be forged from another site
+const (
+	oauthStateCookie = "oauth_state"
+	oauthStatePath   = "/auth/users/oauth"
+	oauthStateMaxAge = 10 * 60
+)
+
+// @Summary OAuth Login
+// @Tags Authentication
+// @Description Redirect to the provider's sign-in page, e.g. /auth/users/oauth/github. Only configured providers are available.
+// @Param provider path string true "Provider name" example(github)
+// @Success 302
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /auth/users/oauth/{provider} [get]
+func (h *UserHandler) OAuthLogin(c *gin.Context) {
+	provider, ok := h.OAuth[c.Param("provider")]
+	if !ok {
+		response.ErrorFromAppError(c, appErrors.NewNotFoundError("OAuth provider"))
+		return
+	}
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewInternalError("Failed to start OAuth login"))
+		return
+	}
+	encoded := hex.EncodeToString(state)
+	c.SetCookie(oauthStateCookie, encoded, oauthStateMaxAge, oauthStatePath, "", h.cookie().Secure, true)
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(encoded))
+}
+
+// @Summary OAuth Callback
+// @Tags Authentication
+// @Description Where the provider sends the user back. Logs in the account with the email the provider verified and sets the token cookie, then redirects to OAUTH_SUCCESS_URL when configured.
+// @Produce json
+// @Param provider path string true "Provider name" example(github)
+// @Param code query string true "Authorization code"
+// @Param state query string true "State from the login redirect"
+// @Success 200 {object} dto.UserResponse
+// @Success 302
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /auth/users/oauth/{provider}/callback [get]
+func (h *UserHandler) OAuthCallback(c *gin.Context) {
+	provider, ok := h.OAuth[c.Param("provider")]
+	if !ok {
+		response.ErrorFromAppError(c, appErrors.NewNotFoundError("OAuth provider"))
+		return
+	}
+
+	// The state is single use, whatever the outcome
+	state, err := c.Cookie(oauthStateCookie)
+	c.SetCookie(oauthStateCookie, "", -1, oauthStatePath, "", h.cookie().Secure, true)
+	code := c.Query("code")
+	if err != nil || state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		response.ErrorFromAppError(c, appErrors.ErrOAuthFailed)
+		return
+	}
+
+	identity, err := provider.Identity(c.Request.Context(), code)
+	if err != nil {
+		utils.LogError("OAuth login with %s failed: %v", provider.Name(), err)
+		response.ErrorFromAppError(c, appErrors.ErrOAuthFailed)
+		return
+	}
+	user, err := h.Usecase.OAuthLogin(identity)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	h.Usecase.RecordLogin(user.Email, c.ClientIP(), c.Request.UserAgent())
+
+	h.setTokenCookie(c, user.Token)
+	if h.OAuthSuccessURL != "" {
+		c.Redirect(http.StatusFound, h.OAuthSuccessURL)
+		return
+	}
+	response.Success(c, http.StatusOK, user)
+}
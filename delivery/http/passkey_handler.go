@@ -0,0 +1,125 @@
+package http
+
+import (
+	"net/http"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/authctx"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Begin Passkey Registration
+// @Tags Passkeys
+// @Description Options for navigator.credentials.create() to add a passkey to the authenticated user's account, with the ceremony_id to finish it with. The challenge expires after five minutes.
+// @Produce json
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/users/webauthn/register/begin [post]
+func (h *UserHandler) BeginPasskeyRegistration(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	creation, err := h.Usecase.BeginPasskeyRegistration(userID, email)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Passkey registration started", creation)
+}
+
+// @Summary Finish Passkey Registration
+// @Tags Passkeys
+// @Description Store the passkey the browser created for the challenge of the begin step named by ceremony_id
+// @Accept json
+// @Produce json
+// @Param request body dto.PasskeyRegisterFinishRequest true "Created credential"
+// @Success 201 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/users/webauthn/register/finish [post]
+func (h *UserHandler) FinishPasskeyRegistration(c *gin.Context) {
+	var req dto.PasskeyRegisterFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Credential) == 0 {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	passkey, err := h.Usecase.FinishPasskeyRegistration(userID, email, req.CeremonyID, req.Name, req.Credential)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	response.GeneralCreated(c, "Passkey registered", passkey)
+}
+
+// @Summary Begin Passkey Login
+// @Tags Passkeys
+// @Description Challenge for navigator.credentials.get() to log in with one of the account's passkeys, with the ceremony_id to finish it with. The challenge expires after five minutes.
+// @Accept json
+// @Produce json
+// @Param request body dto.PasskeyLoginRequest true "Email"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /auth/users/webauthn/login/begin [post]
+func (h *UserHandler) BeginPasskeyLogin(c *gin.Context) {
+	var req dto.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	if req.Email == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
+		return
+	}
+	assertion, err := h.Usecase.BeginPasskeyLogin(req.Email)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Passkey login started", assertion)
+}
+
+// @Summary Finish Passkey Login
+// @Tags Passkeys
+// @Description Log in with the passkey assertion the browser signed for the challenge of the begin step named by ceremony_id. Sets the token cookie like a password login.
+// @Accept json
+// @Produce json
+// @Param request body dto.PasskeyLoginFinishRequest true "Signed assertion"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /auth/users/webauthn/login/finish [post]
+func (h *UserHandler) FinishPasskeyLogin(c *gin.Context) {
+	var req dto.PasskeyLoginFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Credential) == 0 {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	if req.Email == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
+		return
+	}
+	user, err := h.Usecase.FinishPasskeyLogin(req.Email, req.CeremonyID, req.Credential)
+	if err != nil {
+		if err == appErrors.ErrPasskeyInvalid {
+			h.Usecase.RecordFailedLogin(req.Email, c.ClientIP(), c.Request.UserAgent())
+		}
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	h.Usecase.RecordLogin(req.Email, c.ClientIP(), c.Request.UserAgent())
+
+	h.setTokenCookie(c, user.Token)
+	response.Success(c, http.StatusOK, user)
+}
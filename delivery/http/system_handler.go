@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/buildyow/byow-user-service/docs"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
+	"github.com/buildyow/byow-user-service/infrastructure/validation"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/buildyow/byow-user-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceName is reported by the info endpoint
+const ServiceName = "byow-user-service"
+
+// Version is the build version, set with
+// -ldflags "-X github.com/buildyow/byow-user-service/delivery/http.Version=..."
+// and falling back to the API version of the swagger docs
+var Version string
+
+// startedAt is when the process started serving, for the reported uptime
+var startedAt = time.Now()
+
+// @Summary Service Info
+// @Description Name, version, Go version and uptime of the running build
+// @Tags System
+// @Produce json
+// @Success 200 {object} dto.ServiceInfoResponseSwagger
+// @Router /info [get]
+func ServiceInfo(c *gin.Context) {
+	version := Version
+	if version == "" {
+		version = docs.SwaggerInfo.Version
+	}
+	response.FetchSuccess(c, "Service info", dto.ServiceInfoResponse{
+		Name:          ServiceName,
+		Version:       version,
+		GoVersion:     runtime.Version(),
+		StartedAt:     startedAt.UTC().Format(time.RFC3339),
+		UptimeSeconds: time.Since(startedAt).Seconds(),
+	})
+}
+
+// @Summary Health Check
+// @Description Liveness of the service. With HEALTH_CHECK_EMAIL=true it also reports whether the SMTP server accepts connections, as "email": "up" or "down". No mail is sent, and an SMTP outage doesn't fail the check.
+// @Tags System
+// @Produce json
+// @Success 200 {object} object
+// @Router /health [get]
+func Health(emailProbe func(ctx context.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := gin.H{
+			"status":  "OK",
+			"message": "BYOW User Service is healthy",
+			"version": "1.0.0",
+		}
+		if emailProbe != nil {
+			body["email"] = "up"
+			if err := emailProbe(c.Request.Context()); err != nil {
+				utils.LogWarn("Email health check failed: %v", err)
+				body["email"] = "down"
+			}
+		}
+		c.JSON(http.StatusOK, body)
+	}
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying this service's tokens, in the standard JWKS format rather than the response envelope. Empty while tokens are signed with HS256.
+// @Tags System
+// @Produce json
+// @Success 200 {object} jwt.JWKSet
+// @Router /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, jwt.PublicJWKS())
+}
+
+// @Summary Server Time
+// @Description Current server time in UTC, lets clients correct clock skew before JWT exp/iat checks fail
+// @Tags System
+// @Produce json
+// @Success 200 {object} dto.ServerTimeResponse
+// @Router /time [get]
+func ServerTime(c *gin.Context) {
+	now := time.Now().UTC()
+	response.FetchSuccess(c, "Server time", dto.ServerTimeResponse{
+		UTC:   now.Format(time.RFC3339),
+		Epoch: now.Unix(),
+	})
+}
+
+// @Summary OpenAPI Spec
+// @Description The generated Swagger 2.0 document as JSON, for client generators
+// @Tags System
+// @Produce json
+// @Success 200 {object} object
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /openapi.json [get]
+func OpenAPISpec(c *gin.Context) {
+	doc := docs.SwaggerInfo.ReadDoc()
+	if doc == "" {
+		response.ErrorFromAppError(c, appErrors.ErrFetchFailed)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(doc))
+}
+
+// @Summary Upload Config
+// @Description Accepted image types, maximum size and dimensions enforced on avatar and logo uploads, a max dimension of 0 means no limit
+// @Tags System
+// @Produce json
+// @Success 200 {object} dto.UploadConfigResponseSwagger
+// @Router /api/uploads/config [get]
+func UploadConfig(c *gin.Context) {
+	config := validation.ImageUpload
+	response.FetchSuccess(c, "Upload config", dto.UploadConfigResponse{
+		MaxSizeBytes: config.MaxSize,
+		AllowedTypes: config.AllowedTypes,
+		MaxWidth:     config.MaxWidth,
+		MaxHeight:    config.MaxHeight,
+	})
+}
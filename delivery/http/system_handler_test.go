@@ -0,0 +1,225 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func TestServerTime(t *testing.T) {
+	setupGinTestMode()
+
+	router := gin.New()
+	router.GET("/time", ServerTime)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/time", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var body struct {
+		Response struct {
+			Data struct {
+				UTC   string `json:"utc"`
+				Epoch int64  `json:"epoch"`
+			} `json:"data"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	serverTime, err := time.Parse(time.RFC3339, body.Response.Data.UTC)
+	if err != nil {
+		t.Fatalf("Expected RFC3339 time, got %q", body.Response.Data.UTC)
+	}
+	if _, offset := serverTime.Zone(); offset != 0 {
+		t.Errorf("Expected UTC time, got offset %d", offset)
+	}
+	if skew := time.Since(serverTime); skew < -time.Second || skew > 2*time.Second {
+		t.Errorf("Expected server time close to now, skew was %v", skew)
+	}
+	if body.Response.Data.Epoch != serverTime.Unix() {
+		t.Errorf("Expected epoch %d to match %s", body.Response.Data.Epoch, body.Response.Data.UTC)
+	}
+}
+
+func TestHealth_Email(t *testing.T) {
+	setupGinTestMode()
+
+	tests := []struct {
+		name  string
+		probe func(ctx context.Context) error
+		email string
+	}{
+		{"check disabled", nil, ""},
+		{"smtp reachable", func(ctx context.Context) error { return nil }, "up"},
+		{"smtp unreachable", func(ctx context.Context) error { return errors.New("connection refused") }, "down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/health", Health(tt.probe))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/health", nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if body["status"] != "OK" {
+				t.Errorf("Expected the service to stay healthy, got %v", body["status"])
+			}
+			email, reported := body["email"]
+			if tt.email == "" && reported {
+				t.Errorf("Expected no email status while the check is off, got %v", email)
+			}
+			if tt.email != "" && email != tt.email {
+				t.Errorf("Expected email %q, got %v", tt.email, email)
+			}
+		})
+	}
+}
+
+func TestServiceInfo(t *testing.T) {
+	setupGinTestMode()
+
+	router := gin.New()
+	router.GET("/info", ServiceInfo)
+
+	fetch := func() dto.ServiceInfoResponse {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/info", nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var body struct {
+			Response struct {
+				Data dto.ServiceInfoResponse `json:"data"`
+			} `json:"response"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return body.Response.Data
+	}
+
+	first := fetch()
+	if first.Name != ServiceName || first.Version == "" || !strings.HasPrefix(first.GoVersion, "go") {
+		t.Errorf("Expected name, version and Go version, got %+v", first)
+	}
+	if _, err := time.Parse(time.RFC3339, first.StartedAt); err != nil {
+		t.Errorf("Expected RFC3339 start time, got %q", first.StartedAt)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	second := fetch()
+	if second.UptimeSeconds <= first.UptimeSeconds {
+		t.Errorf("Expected uptime to increase, got %v then %v", first.UptimeSeconds, second.UptimeSeconds)
+	}
+	if second.StartedAt != first.StartedAt {
+		t.Errorf("Expected a fixed start time, got %q then %q", first.StartedAt, second.StartedAt)
+	}
+
+	// A version set at build time wins over the docs version
+	Version = "1.2.3"
+	defer func() { Version = "" }()
+	if info := fetch(); info.Version != "1.2.3" {
+		t.Errorf("Expected the build version, got %q", info.Version)
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	setupGinTestMode()
+
+	router := gin.New()
+	router.GET("/openapi.json", OpenAPISpec)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("Expected JSON content type, got %q", contentType)
+	}
+
+	var spec struct {
+		Swagger string `json:"swagger"`
+		Info    struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if spec.Info.Title != "Build Your Own Website User Service API" || spec.Info.Version != "1.0" {
+		t.Errorf("Unexpected spec info %+v", spec.Info)
+	}
+	if spec.Swagger != "2.0" || len(spec.Paths) == 0 {
+		t.Errorf("Expected a Swagger 2.0 document with paths, got version %q and %d paths", spec.Swagger, len(spec.Paths))
+	}
+}
+
+func TestUploadConfig(t *testing.T) {
+	setupGinTestMode()
+
+	original := validation.ImageUpload
+	defer func() { validation.ImageUpload = original }()
+	validation.ImageUpload = validation.UploadConfig{
+		MaxSize:      2 << 20,
+		AllowedTypes: []string{"image/png", "image/webp"},
+		MaxWidth:     4096,
+		MaxHeight:    2048,
+	}
+
+	router := gin.New()
+	router.GET("/api/uploads/config", UploadConfig)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/uploads/config", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var body struct {
+		Response struct {
+			Data dto.UploadConfigResponse `json:"data"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	expected := validation.ImageUpload
+	data := body.Response.Data
+	if data.MaxSizeBytes != expected.MaxSize || data.MaxWidth != expected.MaxWidth || data.MaxHeight != expected.MaxHeight {
+		t.Errorf("Expected limits %+v, got %+v", expected, data)
+	}
+	if strings.Join(data.AllowedTypes, ",") != strings.Join(expected.AllowedTypes, ",") {
+		t.Errorf("Expected types %v, got %v", expected.AllowedTypes, data.AllowedTypes)
+	}
+}
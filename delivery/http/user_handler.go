@@ -1,26 +1,111 @@
 package http
 
 import (
+	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/authctx"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
+	"github.com/buildyow/byow-user-service/infrastructure/oauth"
+	"github.com/buildyow/byow-user-service/infrastructure/validation"
 	"github.com/buildyow/byow-user-service/lib"
 	"github.com/buildyow/byow-user-service/response"
 	"github.com/buildyow/byow-user-service/usecase"
+	"github.com/buildyow/byow-user-service/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
 	Usecase *usecase.UserUsecase
+	// Upload stores an avatar file and returns its URL, lib.CloudinaryUpload
+	// when nil
+	Upload func(file multipart.File) (string, error)
+	// OAuth are the social identity providers users can log in with, by name
+	OAuth oauth.Registry
+	// OAuthSuccessURL is where the browser lands after an OAuth login, the
+	// callback answers with the user as JSON when empty
+	OAuthSuccessURL string
+	// Cookie sets the attributes of the token cookie, a secure host-only
+	// cookie expiring with the token when its Name is empty
+	Cookie jwt.CookieConfig
+}
+
+// cookie is the token cookie configuration in effect
+func (h *UserHandler) cookie() jwt.CookieConfig {
+	if h.Cookie.Name == "" {
+		return jwt.DefaultCookieConfig(h.Usecase.JWTExpire)
+	}
+	return h.Cookie
 }
 
 func NewUserHandler(uc *usecase.UserUsecase) *UserHandler {
 	return &UserHandler{Usecase: uc}
 }
 
+// avatarURL resolves the avatar of a register or update form: an uploaded
+// avatar file, or an avatar_url already hosted in the service's Cloudinary
+// cloud. Sending both is ambiguous and rejected. "" means no avatar was sent.
+func (h *UserHandler) avatarURL(c *gin.Context) (string, error) {
+	rawURL := strings.TrimSpace(c.PostForm("avatar_url"))
+	file, _, err := c.Request.FormFile("avatar")
+	if err != nil {
+		file = nil
+	} else {
+		defer file.Close()
+	}
+
+	if file != nil && rawURL != "" {
+		return "", appErrors.NewBadRequestError("provide either an avatar file or an avatar_url, not both")
+	}
+	if rawURL != "" {
+		if !lib.IsCloudinaryImageURL(rawURL, os.Getenv("CLOUDINARY_CLOUD_NAME")) {
+			return "", appErrors.NewValidationError("avatar_url must be an image uploaded to the service's Cloudinary account")
+		}
+		return rawURL, nil
+	}
+	if file == nil {
+		return "", nil
+	}
+	upload := h.Upload
+	if upload == nil {
+		upload = lib.CloudinaryUpload
+	}
+	return upload(file)
+}
+
+func toUserResponse(user *entity.User) dto.UserResponse {
+	userResponse := dto.UserResponse{
+		Fullname:    user.Fullname,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		AvatarUrl:   user.AvatarUrl,
+		OnBoarded:   user.OnBoarded,
+		Verified:    user.Verified,
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+	}
+	if !user.PasswordChangedAt.IsZero() {
+		userResponse.PasswordChangedAt = user.PasswordChangedAt.UTC().Format(time.RFC3339)
+	}
+	if user.Preferences != (entity.UserPreferences{}) {
+		userResponse.Preferences = &dto.UserPreferences{
+			Language: user.Preferences.Language,
+			Theme:    user.Preferences.Theme,
+			Timezone: user.Preferences.Timezone,
+		}
+	}
+	return userResponse
+}
+
 // @Summary Register user
 // @Description Register a new user with avatar. All fields are validated for security and format requirements.
 // @Tags Authentication
@@ -31,9 +116,13 @@ func NewUserHandler(uc *usecase.UserUsecase) *UserHandler {
 // @Param password formData string true "Strong password (8+ chars, mixed case, numbers, symbols)" example("SecurePass123!")
 // @Param phone_number formData string true "Valid phone number (E.164 format)" example("628112123123")
 // @Param avatar formData file false "Avatar image file (max 10MB, JPEG/PNG/GIF only)"
+// @Param avatar_url formData string false "Avatar already uploaded to the service's Cloudinary account, instead of a file"
+// @Param captcha_token formData string false "Token of the CAPTCHA widget, required when CAPTCHA_PROVIDER is set"
 // @Success 201 {object} dto.UserResponseSwagger
 // @Failure 400 {object} dto.ValidationErrorResponse "Validation errors"
 // @Failure 409 {object} dto.ErrorResponse "Email or phone already exists"
+// @Failure 429 {object} dto.ErrorResponse "Too many requests"
+// @Failure 503 {object} dto.ErrorResponse "Captcha provider unreachable"
 // @Router /auth/users/register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
@@ -50,25 +139,21 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 	// Parse multipart form
 	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
-		response.ErrorFromAppError(c, appErrors.ErrFailedParseMultipart)
+		response.ErrorFromAppError(c, validation.MultipartParseError(c.Request.Context(), err))
 		return
 	}
 
 	// Upload File
-	file, _, err := c.Request.FormFile("avatar")
-	if err == nil {
-		avatarURL, err := lib.CloudinaryUpload(file)
-		if err != nil {
-			response.Error(c, http.StatusBadRequest, err.Error())
-			return
-		}
-		req.AvatarUrl = avatarURL
+	req.AvatarUrl, err = h.avatarURL(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
 	}
 
 	// Call to usecase or saving to DB
 	user, err := h.Usecase.Register(req)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, err.Error())
+		response.ErrorFromAppError(c, err)
 		return
 	}
 	response.Success(c, http.StatusOK, dto.UserResponse{
@@ -91,6 +176,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 // @Failure 400 {object} dto.ValidationErrorResponse "Validation errors or invalid JSON format"
 // @Failure 401 {object} dto.ErrorResponse "Invalid credentials or unverified account"
 // @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 429 {object} dto.ErrorResponse "Too many requests"
 // @Router /auth/users/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	// Get validated data from middleware context
@@ -104,7 +190,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 		response.Error(c, http.StatusInternalServerError, "Password validation failed")
 		return
 	}
-	
+
 	email, ok := emailIface.(string)
 	if !ok {
 		response.Error(c, http.StatusInternalServerError, "Invalid email type")
@@ -115,15 +201,19 @@ func (h *UserHandler) Login(c *gin.Context) {
 		response.Error(c, http.StatusInternalServerError, "Invalid password type")
 		return
 	}
-	
+
 	user, err := h.Usecase.Login(email, password)
 	if err != nil {
+		if err == appErrors.ErrInvalidCredentials {
+			h.Usecase.RecordFailedLogin(email, c.ClientIP(), c.Request.UserAgent())
+		}
 		response.ErrorFromAppError(c, err)
 		return
 	}
+	h.Usecase.RecordLogin(email, c.ClientIP(), c.Request.UserAgent())
 
 	// Set cookie
-	c.SetCookie("token", user.Token, 3600, "/", "", true, true)
+	h.setTokenCookie(c, user.Token)
 
 	response.Success(c, http.StatusOK, dto.UserResponse{
 		Fullname:    user.Fullname,
@@ -144,14 +234,82 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /api/users/logout [post]
 func (h *UserHandler) Logout(c *gin.Context) {
-	c.SetCookie("token", "", -1, "/", "", true, true)
-	response.Success(c, http.StatusOK, constants.LOGOUT_SUCCESSFUL)
+	h.cookie().ClearToken(c)
+	expiresAt, _ := c.Get("token_expires_at")
+	expiresAtTime, _ := expiresAt.(time.Time)
+	email, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	jti, err := authctx.JTI(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if err := h.Usecase.Logout(jti, email, expiresAtTime); err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, constants.LOGOUT_SUCCESSFUL, nil)
+}
+
+// @Summary Logout from all devices
+// @Tags Users
+// @Description Invalidate every token issued to the authenticated user, including the one making the request
+// @Produce json
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/users/logout-all [post]
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if err := h.Usecase.LogoutAll(userID, email); err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	h.cookie().ClearToken(c)
+	response.GeneralOK(c, constants.LOGOUT_SUCCESSFUL, nil)
+}
+
+// @Summary Delete Account
+// @Tags Users
+// @Description Schedule the authenticated user's account for permanent deletion after the grace period (ACCOUNT_DELETION_GRACE_DAYS) and sign out everywhere. Logging in before the deadline cancels it.
+// @Produce json
+// @Success 200 {object} dto.AccountDeletionResponseSwagger
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/users/me [delete]
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	resp, err := h.Usecase.ScheduleDeletion(userID, email)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	h.cookie().ClearToken(c)
+	response.GeneralOK(c, "Account scheduled for deletion", resp)
+}
+
+// otpChannel is where the request wants its OTP sent, email by default
+func otpChannel(c *gin.Context) string {
+	return c.DefaultQuery("channel", constants.OTP_CHANNEL_EMAIL)
 }
 
 // @Summary Send OTP Verification
 // @Tags Verification
 // @Produce plain
 // @Param email query string true "Email address"
+// @Param channel query string false "Where to send the code, email (default) or sms to the phone on the account" Enums(email, sms)
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /verification/users/send-otp [get]
@@ -161,7 +319,7 @@ func (h *UserHandler) SendOTPVerification(c *gin.Context) {
 		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
 		return
 	}
-	err := h.Usecase.SendOTP(constants.VERIFICATION, email)
+	err := h.Usecase.SendOTPVia(otpChannel(c), constants.VERIFICATION, email, c.GetHeader("Accept-Language"))
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
@@ -180,7 +338,7 @@ func (h *UserHandler) SendOTPVerification(c *gin.Context) {
 func (h *UserHandler) VerifyOTP(c *gin.Context) {
 	var req dto.VerifyOTPRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, err.Error())
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
 		return
 	}
 	email := req.Email
@@ -199,49 +357,434 @@ func (h *UserHandler) VerifyOTP(c *gin.Context) {
 	response.OTPVerifiedSuccess(c)
 }
 
+// @Summary Check OTP
+// @Tags Verification
+// @Description Check an OTP without consuming it. Wrong codes count towards the attempt limit.
+// @Accept json
+// @Produce plain
+// @Param otp body dto.VerifyOTPRequest true "Email & OTP"
+// @Success 200 {object} dto.CheckOTPResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /verification/users/check-otp [post]
+func (h *UserHandler) CheckOTP(c *gin.Context) {
+	var req dto.VerifyOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.Email == "" || req.OTP == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailOtpRequired)
+		return
+	}
+
+	valid, err := h.Usecase.PeekOTP(req.Email, req.OTP)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, dto.CheckOTPResponse{Valid: valid})
+}
+
 // @Summary Check Logged Account
 // @Tags Users
-// @Description Check if user is logged in and return user info
-// @Produce plain
+// @Description Check if user is logged in and return their profile, read from the database
+// @Produce json
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} dto.UserResponseSwagger
+// @Success 304 "Not modified"
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
 // @Router /api/users/me [get]
 func (h *UserHandler) UserMe(c *gin.Context) {
-	email, _ := c.Get("email")
-	userID, _ := c.Get("user_id")
-	phone, _ := c.Get("phone")
-	response.Success(c, http.StatusOK, gin.H{
-		"message": constants.VALID_TOKEN,
-		"user": map[string]interface{}{
-			"user_id": userID,
-			"email":   email,
-			"phone":   phone,
-		},
-	})
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	user, err := h.Usecase.CurrentUser(userID, email)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	userResponse := toUserResponse(user)
+	if response.NotModified(c, userResponse) {
+		return
+	}
+	response.GeneralOK(c, constants.VALID_TOKEN, userResponse)
+}
+
+// @Summary Recent Failed Logins
+// @Tags Users
+// @Description Recent failed sign-in attempts on the authenticated user's account, newest first
+// @Produce json
+// @Param limit query int false "Number of attempts (max 50)"
+// @Success 200 {object} dto.FailedLoginListResponseSwagger
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/users/security/failed-logins [get]
+func (h *UserHandler) FailedLogins(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.Query("limit"), 10, 64)
+
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	attempts, err := h.Usecase.FailedLogins(userID, limit)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Failed logins", attempts)
+}
+
+// @Summary Login History
+// @Tags Users
+// @Description Recent successful sign-ins on the authenticated user's account, newest first, with a readable device label alongside the raw user agent
+// @Produce json
+// @Param limit query int false "Number of sign-ins (max 50)"
+// @Success 200 {object} dto.LoginHistoryListResponseSwagger
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/users/security/logins [get]
+func (h *UserHandler) LoginHistory(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.Query("limit"), 10, 64)
+
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	logins, err := h.Usecase.LoginHistory(userID, limit)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Login history", logins)
+}
+
+// @Summary List Sessions
+// @Tags Users
+// @Description Devices signed in to the authenticated user's account, newest first. The session making the request is marked current.
+// @Produce json
+// @Success 200 {object} dto.SessionListResponseSwagger
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/users/sessions [get]
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID, err := authctx.UserID(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	jti, err := authctx.JTI(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	sessions, err := h.Usecase.ListSessions(userID, jti)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Sessions", sessions)
+}
+
+// @Summary Revoke Session
+// @Tags Users
+// @Description Sign a device out of the authenticated user's account, its token is refused from then on
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/users/sessions/{id} [delete]
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if err := h.Usecase.RevokeSession(userID, email, c.Param("id")); err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralOK(c, "Session revoked successfully", nil)
+}
+
+// @Summary Security Summary
+// @Tags Users
+// @Description Security posture of the authenticated user's account: email and phone verification, last password change and login, OTP lockout, recent failed logins and active sessions
+// @Produce json
+// @Success 200 {object} dto.SecuritySummaryResponseSwagger
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/users/security/summary [get]
+func (h *UserHandler) SecuritySummary(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	summary, err := h.Usecase.SecuritySummary(userID, email)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Security summary", summary)
+}
+
+// @Summary Get Notification Preferences
+// @Tags Users
+// @Description Which notice emails the user receives, password and email change notices are always sent
+// @Produce json
+// @Success 200 {object} dto.NotificationPrefsResponseSwagger
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/users/notifications [get]
+func (h *UserHandler) NotificationPrefs(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	prefs, err := h.Usecase.NotificationPrefs(userID, email)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Notification preferences", prefs)
+}
+
+// @Summary Update Notification Preferences
+// @Tags Users
+// @Description Turn notice emails on or off, omitted notices keep their setting. Password and email change notices can't be turned off.
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateNotificationPrefsRequest true "Notices to change"
+// @Success 200 {object} dto.NotificationPrefsResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/users/notifications [put]
+func (h *UserHandler) UpdateNotificationPrefs(c *gin.Context) {
+	var req dto.UpdateNotificationPrefsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	prefs, err := h.Usecase.UpdateNotificationPrefs(userID, email, req)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.UpdateSuccess(c, "Notification preferences", prefs)
+}
+
+// @Summary Email Language
+// @Tags Users
+// @Description Language the authenticated user's emails are written in, with the supported languages
+// @Produce json
+// @Success 200 {object} dto.LanguageResponseSwagger
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/users/language [get]
+func (h *UserHandler) Language(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	lang, err := h.Usecase.Language(userID, email)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Language", lang)
+}
+
+// @Summary Update Email Language
+// @Tags Users
+// @Description Set the language OTP and notice emails are written in when the request has no Accept-Language header
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateLanguageRequest true "Supported language"
+// @Success 200 {object} dto.LanguageResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/users/language [put]
+func (h *UserHandler) UpdateLanguage(c *gin.Context) {
+	var req dto.UpdateLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	lang, err := h.Usecase.UpdateLanguage(userID, email, req)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	response.UpdateSuccess(c, "Language", lang)
+}
+
+// @Summary Permissions
+// @Tags Users
+// @Description Role of the authenticated user and the permissions it grants, read from the database so role changes apply before the token is refreshed
+// @Produce json
+// @Success 200 {object} dto.PermissionsResponseSwagger
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/users/permissions [get]
+func (h *UserHandler) Permissions(c *gin.Context) {
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	permissions, err := h.Usecase.Permissions(userID, email)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Permissions", permissions)
+}
+
+// currentUserError writes err for a handler acting on the token's account.
+// ErrInvalidToken means the account is gone or the email now belongs to
+// someone else, so the token is revoked as well rather than left to expire.
+func (h *UserHandler) currentUserError(c *gin.Context, err error) {
+	if errors.Is(err, appErrors.ErrInvalidToken) {
+		expiresAt, _ := c.Get("token_expires_at")
+		expiresAtTime, _ := expiresAt.(time.Time)
+		// Best effort, Logout logs its own failures
+		jti, _ := authctx.JTI(c)
+		email, _ := authctx.Email(c)
+		_ = h.Usecase.Logout(jti, email, expiresAtTime)
+	}
+	response.ErrorFromAppError(c, err)
+}
+
+// refreshToken re-issues the token cookie with claims read from the database,
+// call it after any change to a field carried in the token. The token the
+// request was made with is revoked and its session ended, the new one
+// replaces it.
+func (h *UserHandler) refreshToken(c *gin.Context, email string) error {
+	h.cookie().ClearToken(c) // REMOVE OLD TOKEN
+	newLogged, err := h.Usecase.LoginWithoutPassword(email)
+	if err != nil {
+		return err
+	}
+	h.setTokenCookie(c, newLogged.Token) // SET NEW TOKEN
+
+	expiresAt, _ := c.Get("token_expires_at")
+	expiresAtTime, _ := expiresAt.(time.Time)
+	// Best effort, Logout logs its own failures
+	jti, _ := authctx.JTI(c)
+	oldEmail, _ := authctx.Email(c)
+	_ = h.Usecase.Logout(jti, oldEmail, expiresAtTime)
+	return nil
+}
+
+// setTokenCookie stores the token in a cookie that expires with it and
+// records the session it starts
+func (h *UserHandler) setTokenCookie(c *gin.Context, token string) {
+	if h.Usecase.Sessions != nil {
+		h.Usecase.StartSession(token, c.ClientIP(), c.Request.UserAgent())
+	}
+	h.cookie().SetToken(c, token)
 }
 
 // @Summary Onboarded User
 // @Tags Users
-// @Description Onboard user to the system
+// @Description Onboard user to the system. Deprecated, use POST /api/users/onboard.
 // @Produce plain
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Deprecated
 // @Router /api/users/onboard [get]
 func (h *UserHandler) OnBoard(c *gin.Context) {
-	emailIface, _ := c.Get("email")
-	email, ok := emailIface.(string)
-	if !ok {
-		response.Error(c, http.StatusBadRequest, emailIface)
+	c.Header("Deprecation", "true")
+	c.Header("Link", `</api/users/onboard>; rel="successor-version"`)
+	email, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if email == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
 		return
 	}
-	err := h.Usecase.OnBoard(email)
+	err = h.Usecase.OnBoard(email)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, err.Error())
+		h.currentUserError(c, err)
 		return
 	}
 	response.Success(c, http.StatusOK, constants.ONBOARD_SUCCESSFUL)
 }
 
+// @Summary Complete Onboarding
+// @Tags Users
+// @Description Save optional profile data and mark the user onboarded, returning the updated profile
+// @Accept json
+// @Produce json
+// @Param request body dto.OnboardRequest false "Optional profile data"
+// @Success 200 {object} dto.UserResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/users/onboard [post]
+func (h *UserHandler) CompleteOnboarding(c *gin.Context) {
+	var req dto.OnboardRequest
+	// The body is optional, an empty one just marks the user onboarded
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	userID, email, err := authctx.User(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	user, err := h.Usecase.CompleteOnboarding(userID, email, req)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	response.GeneralOK(c, constants.ONBOARD_SUCCESSFUL, toUserResponse(user))
+}
+
+// @Summary Check Email Availability
+// @Tags Authentication
+// @Description Report for each email whether it is still free to register. Emails are trimmed and lowercased, the result is keyed by that form.
+// @Accept json
+// @Produce json
+// @Param request body dto.EmailAvailabilityBatchRequest true "Emails (max 50)"
+// @Success 200 {object} dto.EmailAvailabilityBatchResponseSwagger
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Router /auth/users/availability-batch [post]
+func (h *UserHandler) EmailAvailabilityBatch(c *gin.Context) {
+	var req dto.EmailAvailabilityBatchRequest
+	if !bindBatch(c, &req, usecase.MaxEmailAvailabilityBatch) {
+		return
+	}
+
+	availability, err := h.Usecase.CheckEmailAvailability(req.Emails)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.FetchSuccess(c, "Email availability", availability)
+}
+
 // @Summary Change Password With OTP
 // @Tags Authentication
 // @Description Change user password using OTP verification
@@ -276,6 +819,7 @@ func (h *UserHandler) ChangePasswordWithOTP(c *gin.Context) {
 // @Tags Authentication
 // @Produce plain
 // @Param email query string true "Email address"
+// @Param channel query string false "Where to send the code, email (default) or sms to the phone on the account" Enums(email, sms)
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /auth/users/forgot-password/send-otp [get]
@@ -285,7 +829,7 @@ func (h *UserHandler) SendOTPForgotPassword(c *gin.Context) {
 		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
 		return
 	}
-	err := h.Usecase.SendOTP(constants.FORGOT_PASSWORD, email)
+	err := h.Usecase.SendOTPVia(otpChannel(c), constants.FORGOT_PASSWORD, email, c.GetHeader("Accept-Language"))
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
@@ -293,45 +837,131 @@ func (h *UserHandler) SendOTPForgotPassword(c *gin.Context) {
 	response.OTPSentSuccess(c)
 }
 
+// @Summary Send Password Reset Link
+// @Tags Authentication
+// @Description Email a single-use magic link for resetting the password. Always answers the same way whether or not the account exists.
+// @Accept json
+// @Produce plain
+// @Param request body dto.SendPasswordResetLinkRequest true "Email"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "Too many requests"
+// @Router /auth/users/forgot-password/send-link [post]
+func (h *UserHandler) SendPasswordResetLink(c *gin.Context) {
+	var req dto.SendPasswordResetLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	if err := h.Usecase.RequestPasswordResetLink(req.Email); err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralMessage(c, http.StatusOK, "If the account exists, a password reset link has been sent")
+}
+
+// @Summary Forgot Password Precheck
+// @Tags Authentication
+// @Description Start the forgot-password flow. Always answers the same way whether or not the account exists.
+// @Accept json
+// @Produce json
+// @Param request body dto.SendPasswordResetLinkRequest true "Email"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "Too many requests"
+// @Router /auth/users/precheck [post]
+func (h *UserHandler) PrecheckPasswordReset(c *gin.Context) {
+	var req dto.SendPasswordResetLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	if err := h.Usecase.PrecheckPasswordReset(req.Email); err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.GeneralMessage(c, http.StatusOK, "If the account exists, you'll receive instructions shortly")
+}
+
+// @Summary Reset Password With Token
+// @Tags Authentication
+// @Description Set a new password using the token from a password reset link. The token works once.
+// @Accept json
+// @Produce plain
+// @Param request body dto.ResetPasswordWithTokenRequest true "Email, Token & New Password"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "Too many requests"
+// @Router /auth/users/reset-password [post]
+func (h *UserHandler) ResetPasswordWithToken(c *gin.Context) {
+	var req dto.ResetPasswordWithTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	if req.Email == "" || req.Token == "" || req.NewPassword == "" {
+		response.ErrorFromAppError(c, appErrors.ErrAllFieldsRequired)
+		return
+	}
+	err := h.Usecase.ResetPasswordWithToken(req)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	response.PasswordChangeSuccess(c)
+}
+
 // @Summary Update User
 // @Description Update user information
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param full_name formData string true "Full name" example(John Doe)
-// @Param email formData string true "Email" example(john@example.com)
+// @Param email formData string false "Email, must match the authenticated user if given" example(john@example.com)
 // @Param avatar formData file false "Avatar file"
+// @Param avatar_url formData string false "Avatar already uploaded to the service's Cloudinary account, instead of a file"
 // @Success 201 {object} dto.UserResponseSwagger
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
 // @Router /api/users/update [post]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	var req dto.RegisterRequest
 	// Bind form values to struct
 	req.Fullname = c.PostForm("full_name")
-	req.Email = c.PostForm("email")
 	req.Password = c.PostForm("password")
 	req.PhoneNumber = c.PostForm("phone_number")
 
-	err := h.Usecase.UpdateUserValidation(c.PostForm("email"))
+	// The account to update always comes from the token, a form email is only
+	// accepted when it matches
+	email, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if formEmail := c.PostForm("email"); formEmail != "" && formEmail != email {
+		utils.LogWarn("Rejected profile update for %s from %s", formEmail, email)
+		response.ErrorFromAppError(c, appErrors.ErrUnauthorized)
+		return
+	}
+	req.Email = email
+
+	err = h.Usecase.UpdateUserValidation(email)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	// Parse multipart form
 	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
-		response.ErrorFromAppError(c, appErrors.ErrFailedParseMultipart)
+		response.ErrorFromAppError(c, validation.MultipartParseError(c.Request.Context(), err))
 		return
 	}
 
 	// Upload File
-	file, _, err := c.Request.FormFile("avatar")
-	if err == nil {
-		avatarURL, err := lib.CloudinaryUpload(file)
-		if err != nil {
-			response.Error(c, http.StatusBadRequest, err.Error())
-			return
-		}
-		req.AvatarUrl = avatarURL
+	req.AvatarUrl, err = h.avatarURL(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
 	}
 
 	// Call to usecase or saving to DB
@@ -340,80 +970,99 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		response.ErrorFromAppError(c, err)
 		return
 	}
-	
-	userResponse := dto.UserResponse{
-		Fullname:    user.Fullname,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		AvatarUrl:   user.AvatarUrl,
-		OnBoarded:   user.OnBoarded,
-		Verified:    user.Verified,
-		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-	}
-	response.UpdateSuccess(c, "User", userResponse)
+
+	response.UpdateSuccess(c, "User", toUserResponse(user))
 }
 
 // @Summary Change Email With OTP
 // @Tags Users
-// @Description Change user email using OTP verification
+// @Description Change user email using the OTP sent to the current address and the code sent to the new one
 // @Produce plain
-// @Param otp body dto.ChangeEmailRequest true "OTP & New Email"
+// @Param otp body dto.ChangeEmailRequest true "OTPs & New Email"
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /api/users/change-email [post]
 func (h *UserHandler) ChangeEmail(c *gin.Context) {
 	var req dto.ChangeEmailRequest
-	oldEmail, _ := c.Get("email")
+	oldEmail, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
 		return
 	}
-	if req.OTP == "" || req.NewEmail == "" {
+	if req.OTP == "" || req.NewEmail == "" || req.NewEmailOTP == "" {
 		response.ErrorFromAppError(c, appErrors.ErrEmailOtpRequired)
 		return
 	}
-	oldEmailStr, ok := oldEmail.(string)
-	if !ok {
-		response.Error(c, http.StatusInternalServerError, "Invalid email context")
-		return
-	}
-	err := h.Usecase.UpdateUserByEmail(req, oldEmailStr)
+	err = h.Usecase.UpdateUserByEmail(req, oldEmail)
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
 	}
-	c.SetCookie("token", "", -1, "/", "", true, true) // REMOVE OLD TOKEN
-	newLogged, err := h.Usecase.LoginWithoutPassword(req.NewEmail)
-	if err != nil {
+	if err := h.refreshToken(c, req.NewEmail); err != nil {
 		response.ErrorFromAppError(c, err)
 		return
 	}
-	c.SetCookie("token", newLogged.Token, 3600, "/", "", true, true) // SET NEW TOKEN
 	response.EmailChangeSuccess(c)
 }
 
 // @Summary Send OTP Change Email
 // @Tags Users
 // @Produce plain
+// @Param channel query string false "Where to send the code, email (default) or sms to the phone on the account" Enums(email, sms)
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /api/users/change-email/send-otp [get]
 func (h *UserHandler) SendOTPEmailChange(c *gin.Context) {
-	oldEmail, _ := c.Get("email")
+	oldEmail, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
 	if oldEmail == "" {
 		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
 		return
 	}
-	oldEmailStr, ok := oldEmail.(string)
-	if !ok {
-		response.Error(c, http.StatusInternalServerError, "Invalid email context")
+	err = h.Usecase.SendOTPVia(otpChannel(c), constants.EMAIL_CHANGED, oldEmail, c.GetHeader("Accept-Language"))
+	if err != nil {
+		response.ErrorFromAppError(c, err)
 		return
 	}
-	err := h.Usecase.SendOTP(constants.EMAIL_CHANGED, oldEmailStr)
+	response.OTPSentSuccess(c)
+}
+
+// @Summary Send OTP To New Email
+// @Tags Users
+// @Description Send a confirmation code to the address the user is changing to, required by change-email
+// @Accept json
+// @Produce plain
+// @Param request body dto.SendNewEmailOTPRequest true "New Email"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/users/change-email/send-new-otp [post]
+func (h *UserHandler) SendOTPNewEmail(c *gin.Context) {
+	email, err := authctx.Email(c)
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
 	}
+	var req dto.SendNewEmailOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+	if req.NewEmail == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
+		return
+	}
+	if err := h.Usecase.SendOTPNewEmail(email, req.NewEmail); err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
 	response.OTPSentSuccess(c)
 }
 
@@ -426,12 +1075,20 @@ func (h *UserHandler) SendOTPEmailChange(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /api/users/change-phone [post]
 func (h *UserHandler) ChangePhone(c *gin.Context) {
-	oldPhone, _ := c.Get("phone")
-	email, _ := c.Get("email")
+	oldPhone, err := authctx.Phone(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
 	if oldPhone == "" {
 		response.ErrorFromAppError(c, appErrors.ErrPhoneRequired)
 		return
 	}
+	email, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
 	var req dto.ChangePhoneRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
@@ -441,49 +1098,41 @@ func (h *UserHandler) ChangePhone(c *gin.Context) {
 		response.ErrorFromAppError(c, appErrors.ErrEmailOtpRequired)
 		return
 	}
-	oldPhoneStr, ok := oldPhone.(string)
-	if !ok {
-		response.Error(c, http.StatusInternalServerError, "Invalid phone context")
+	// Reject a malformed number before the OTP is consumed
+	if !validation.ValidatePhoneNumber(req.NewPhone) {
+		response.ErrorFromAppError(c, appErrors.NewValidationError("Invalid phone number format"))
 		return
 	}
-	err := h.Usecase.UpdateUserByPhone(req, oldPhoneStr)
+	err = h.Usecase.UpdateUserByPhone(req, oldPhone)
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
 	}
-	c.SetCookie("token", "", -1, "/", "", true, true) // REMOVE OLD TOKEN
-	emailStr, ok := email.(string)
-	if !ok {
-		response.Error(c, http.StatusInternalServerError, "Invalid email context")
-		return
-	}
-	newLogged, err := h.Usecase.LoginWithoutPassword(emailStr)
-	if err != nil {
+	if err := h.refreshToken(c, email); err != nil {
 		response.ErrorFromAppError(c, err)
 		return
 	}
-	c.SetCookie("token", newLogged.Token, 3600, "/", "", true, true) // SET NEW TOKEN
 	response.PhoneChangeSuccess(c)
 }
 
 // @Summary Send OTP Change Email
 // @Tags Users
 // @Produce plain
+// @Param channel query string false "Where to send the code, email (default) or sms to the phone on the account" Enums(email, sms)
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Router /api/users/change-phone/send-otp [get]
 func (h *UserHandler) SendOTPPhoneChange(c *gin.Context) {
-	oldEmail, _ := c.Get("email")
-	if oldEmail == "" {
-		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
+	oldEmail, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
 		return
 	}
-	oldEmailStr, ok := oldEmail.(string)
-	if !ok {
-		response.Error(c, http.StatusInternalServerError, "Invalid email context")
+	if oldEmail == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
 		return
 	}
-	err := h.Usecase.SendOTP(constants.PHONE_CHANGED, oldEmailStr)
+	err = h.Usecase.SendOTPVia(otpChannel(c), constants.PHONE_CHANGED, oldEmail, c.GetHeader("Accept-Language"))
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
@@ -498,9 +1147,14 @@ func (h *UserHandler) SendOTPPhoneChange(c *gin.Context) {
 // @Param otp body dto.ChangePasswordWithOldPasswordRequest true "Email, Old Password & New Password"
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
 // @Router /api/users/change-password-old [post]
 func (h *UserHandler) ChangePasswordWithOldPassword(c *gin.Context) {
-	email, _ := c.Get("email")
+	email, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
 	if email == "" {
 		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
 		return
@@ -516,15 +1170,74 @@ func (h *UserHandler) ChangePasswordWithOldPassword(c *gin.Context) {
 		return
 	}
 
-	emailStr, ok := email.(string)
-	if !ok {
-		response.Error(c, http.StatusInternalServerError, "Invalid email context")
+	err = h.Usecase.ChangePasswordWithOldPassword(email, req)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
+	response.PasswordChangeSuccess(c)
+}
+
+// @Summary Send OTP for Step-Up Password Change
+// @Tags Users
+// @Description Send a forgot-password OTP to the authenticated user's email, or phone with channel=sms
+// @Produce plain
+// @Param channel query string false "Where to send the code, email (default) or sms to the phone on the account" Enums(email, sms)
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /api/users/change-password-stepup/send-otp [post]
+func (h *UserHandler) SendOTPChangePasswordStepUp(c *gin.Context) {
+	email, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if email == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
 		return
 	}
-	err := h.Usecase.ChangePasswordWithOldPassword(emailStr, req)
+	err = h.Usecase.SendOTPVia(otpChannel(c), constants.FORGOT_PASSWORD, email, c.GetHeader("Accept-Language"))
 	if err != nil {
 		response.ErrorFromAppError(c, err)
 		return
 	}
+	response.OTPSentSuccess(c)
+}
+
+// @Summary Change Password With Step-Up OTP
+// @Tags Users
+// @Description Change the authenticated user's password without the old one, using an OTP sent to their email
+// @Produce plain
+// @Param otp body dto.ChangePasswordStepUpRequest true "OTP & New Password"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /api/users/change-password-stepup [post]
+func (h *UserHandler) ChangePasswordStepUp(c *gin.Context) {
+	email, err := authctx.Email(c)
+	if err != nil {
+		response.ErrorFromAppError(c, err)
+		return
+	}
+	if email == "" {
+		response.ErrorFromAppError(c, appErrors.ErrEmailRequired)
+		return
+	}
+	var req dto.ChangePasswordStepUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorFromAppError(c, appErrors.NewBadRequestError("Invalid JSON format"))
+		return
+	}
+
+	if req.OTP == "" || req.NewPassword == "" {
+		response.ErrorFromAppError(c, appErrors.ErrAllFieldsRequired)
+		return
+	}
+
+	err = h.Usecase.ChangePasswordStepUp(email, req)
+	if err != nil {
+		h.currentUserError(c, err)
+		return
+	}
 	response.PasswordChangeSuccess(c)
 }
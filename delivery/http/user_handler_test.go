@@ -2,20 +2,31 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
 	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
+	"github.com/buildyow/byow-user-service/infrastructure/oauth"
 	"github.com/buildyow/byow-user-service/usecase"
+	"github.com/buildyow/byow-user-service/utils"
 	"github.com/gin-gonic/gin"
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Mock usecase for testing
@@ -116,6 +127,170 @@ func (m *mockUserUsecase) UpdateUserByPhone(req dto.ChangePhoneRequest, oldPhone
 	return m.updatePhoneError
 }
 
+// stubUserRepository keeps users in memory keyed by email
+type stubUserRepository struct {
+	users map[string]*entity.User
+}
+
+func (r *stubUserRepository) Create(user *entity.User) error {
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *stubUserRepository) FindByEmail(email string) (*entity.User, error) {
+	if user, ok := r.users[email]; ok {
+		return user, nil
+	}
+	return nil, appErrors.ErrUserNotFound
+}
+
+func (r *stubUserRepository) FindExistingEmails(emails []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for _, email := range emails {
+		if _, ok := r.users[email]; ok {
+			existing[email] = true
+		}
+	}
+	return existing, nil
+}
+
+func (r *stubUserRepository) FindDeletionDue(before time.Time, limit int64) ([]*entity.User, error) {
+	return []*entity.User{}, nil
+}
+
+func (r *stubUserRepository) UpdateOTP(user *entity.User) error {
+	return r.Update(user)
+}
+
+func (r *stubUserRepository) UpdateRole(userID, role string) (int, error) {
+	stored, err := r.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.Role = role
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (r *stubUserRepository) RevokeTokens(userID string) (int, error) {
+	stored, err := r.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (r *stubUserRepository) Suspend(userID string, at time.Time, reason string) (int, error) {
+	stored, err := r.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.SuspendedAt = at
+	stored.SuspendedReason = reason
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (r *stubUserRepository) Reactivate(userID string) error {
+	stored, err := r.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	stored.SuspendedAt = time.Time{}
+	stored.SuspendedReason = ""
+	return nil
+}
+
+func (r *stubUserRepository) ScheduleDeletion(userID string, at time.Time) (int, error) {
+	stored, err := r.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.DeletionScheduledAt = at
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (r *stubUserRepository) CancelDeletion(userID string) error {
+	stored, err := r.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	stored.DeletionScheduledAt = time.Time{}
+	return nil
+}
+
+func (r *stubUserRepository) AddPasskeyCeremony(userID string, ceremony entity.PasskeyCeremony, keep int) error {
+	return nil
+}
+
+func (r *stubUserRepository) TakePasskeyCeremony(userID, ceremonyID string) (*entity.PasskeyCeremony, error) {
+	return nil, nil
+}
+
+func (r *stubUserRepository) FindAll(filter repository.UserFilter, limit int64, offset int64) ([]*entity.User, int64, error) {
+	users := []*entity.User{}
+	for _, user := range r.users {
+		if filter.Keyword == "" || strings.Contains(user.Email, filter.Keyword) {
+			users = append(users, user)
+		}
+	}
+	return users, int64(len(users)), nil
+}
+
+func (r *stubUserRepository) FindByID(id string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, appErrors.ErrUserNotFound
+}
+
+func (r *stubUserRepository) FindByPhone(phone string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.PhoneNumber == phone {
+			return user, nil
+		}
+	}
+	return nil, appErrors.ErrUserNotFound
+}
+
+func (r *stubUserRepository) Update(user *entity.User) error {
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *stubUserRepository) UpdateEmail(user *entity.User, oldEmail string) error {
+	delete(r.users, oldEmail)
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *stubUserRepository) UpdatePhone(user *entity.User, oldPhone string) error {
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *stubUserRepository) Delete(email string) error {
+	delete(r.users, email)
+	return nil
+}
+
+func (r *stubUserRepository) ForEach(ctx context.Context, fn func(user *entity.User) error) error {
+	for _, user := range r.users {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *stubUserRepository) ForEachActiveOTP(ctx context.Context, fn func(user *entity.User) error) error {
+	return nil
+}
+
 func setupUserHandler() *UserHandler {
 	return NewUserHandler(&usecase.UserUsecase{})
 }
@@ -305,12 +480,12 @@ func TestUserHandler_Login_MissingValidationData(t *testing.T) {
 	setupGinTestMode()
 
 	handler := setupUserHandler()
-	
+
 	// Test structure without executing (would panic due to missing dependencies)
 	if handler == nil {
 		t.Fatal("Expected non-nil handler")
 	}
-	
+
 	t.Log("Login handler missing validation data test completed")
 }
 
@@ -318,15 +493,60 @@ func TestUserHandler_Login_InvalidDataTypes(t *testing.T) {
 	setupGinTestMode()
 
 	handler := setupUserHandler()
-	
+
 	// Test structure without executing (would panic due to missing dependencies)
 	if handler == nil {
 		t.Fatal("Expected non-nil handler")
 	}
-	
+
 	t.Log("Login handler invalid data types test completed")
 }
 
+func TestUserHandler_Logout_RevokesToken(t *testing.T) {
+	setupGinTestMode()
+
+	revoked := map[string]time.Time{}
+	expiresAt := time.Now().Add(30 * time.Minute)
+	handler := NewUserHandler(&usecase.UserUsecase{
+		Repo: &stubUserRepository{users: map[string]*entity.User{}},
+		RevokeToken: func(jti, email string, expiresAt time.Time) error {
+			revoked[jti] = expiresAt
+			return nil
+		},
+	})
+
+	router := gin.New()
+	router.POST("/api/users/logout", func(c *gin.Context) {
+		c.Set("email", "john@example.com")
+		c.Set("jti", "access-jti")
+		c.Set("token_expires_at", expiresAt)
+		c.Next()
+	}, handler.Logout)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/users/logout", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"message":"`+constants.LOGOUT_SUCCESSFUL+`"`) {
+		t.Errorf("Expected the standard success envelope, got %s", w.Body.String())
+	}
+	if got, ok := revoked["access-jti"]; !ok || !got.Equal(expiresAt) {
+		t.Errorf("Expected the access token to be blacklisted until it expires, got %v", revoked)
+	}
+	cleared := false
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "token" {
+			cleared = cookie.MaxAge < 0 && cookie.Value == "" && cookie.Path == "/" && cookie.Secure && cookie.HttpOnly
+		}
+	}
+	if !cleared {
+		t.Errorf("Expected the token cookie to be expired, got %v", w.Header().Values("Set-Cookie"))
+	}
+}
+
 func TestUserHandler_Logout_Success(t *testing.T) {
 	setupGinTestMode()
 
@@ -361,6 +581,593 @@ func TestUserHandler_Logout_Success(t *testing.T) {
 	}
 }
 
+func performUpdateUser(handler *UserHandler, tokenEmail string, fields map[string]string) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+
+	router := gin.New()
+	router.POST("/api/users/update", func(c *gin.Context) {
+		c.Set("email", tokenEmail)
+		c.Next()
+	}, handler.UpdateUser)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/users/update", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUserHandler_UpdateUser_UsesTokenEmail(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"owner@example.com":  {Email: "owner@example.com", Fullname: "Owner"},
+		"victim@example.com": {Email: "victim@example.com", Fullname: "Victim"},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+
+	// A form email belonging to someone else is rejected
+	w := performUpdateUser(handler, "owner@example.com", map[string]string{
+		"full_name": "Hijacked",
+		"email":     "victim@example.com",
+	})
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "UNAUTHORIZED") {
+		t.Errorf("Expected 401 UNAUTHORIZED for mismatched email, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.users["victim@example.com"].Fullname != "Victim" {
+		t.Error("Expected the other account to be left untouched")
+	}
+
+	// Without a form email the token decides which account is updated
+	w = performUpdateUser(handler, "owner@example.com", map[string]string{
+		"full_name": "Owner Renamed",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.users["owner@example.com"].Fullname != "Owner Renamed" {
+		t.Errorf("Expected token owner to be updated, got %s", repo.users["owner@example.com"].Fullname)
+	}
+	if repo.users["victim@example.com"].Fullname != "Victim" {
+		t.Error("Expected the other account to be left untouched")
+	}
+}
+
+// avatarForm builds a multipart body with fields and, when withFile is set,
+// an avatar file
+func avatarForm(fields map[string]string, withFile bool) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	if withFile {
+		part, _ := writer.CreateFormFile("avatar", "avatar.png")
+		part.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func TestUserHandler_AvatarFileOrURL(t *testing.T) {
+	setupGinTestMode()
+	t.Setenv("CLOUDINARY_CLOUD_NAME", "demo")
+	hostedURL := "https://res.cloudinary.com/demo/image/upload/v1700000000/avatars/john.png"
+	uploadedURL := "https://res.cloudinary.com/demo/image/upload/v1700000001/avatars/new.png"
+
+	repo := &stubUserRepository{users: map[string]*entity.User{}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+	uploads := 0
+	handler.Upload = func(file multipart.File) (string, error) {
+		uploads++
+		return uploadedURL, nil
+	}
+
+	router := gin.New()
+	router.POST("/auth/users/register", handler.Register)
+	router.POST("/api/users/update", func(c *gin.Context) {
+		c.Set("email", c.GetHeader("X-Email"))
+		c.Next()
+	}, handler.UpdateUser)
+	send := func(path, email, avatarURL string, withFile bool) *httptest.ResponseRecorder {
+		fields := map[string]string{
+			"full_name":    "John Doe",
+			"email":        email,
+			"password":     "Password123!",
+			"phone_number": "+62811" + strconv.Itoa(len(repo.users)),
+		}
+		if avatarURL != "" {
+			fields["avatar_url"] = avatarURL
+		}
+		body, contentType := avatarForm(fields, withFile)
+		req, _ := http.NewRequest("POST", path, body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Email", email)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("/auth/users/register", "both@example.com", hostedURL, true); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "not both") {
+		t.Errorf("Expected registering with both to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, exists := repo.users["both@example.com"]; exists {
+		t.Error("Expected no account for a conflicting registration")
+	}
+	repo.users["existing@example.com"] = &entity.User{Email: "existing@example.com"}
+	if w := send("/api/users/update", "existing@example.com", hostedURL, true); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "not both") {
+		t.Errorf("Expected updating with both to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.users["existing@example.com"].AvatarUrl != "" {
+		t.Error("Expected the conflicting update to leave the avatar alone")
+	}
+	if uploads != 0 {
+		t.Errorf("Expected nothing to be uploaded for a conflicting form, got %d uploads", uploads)
+	}
+
+	if w := send("/auth/users/register", "file@example.com", "", true); w.Code != http.StatusOK {
+		t.Fatalf("Expected a file avatar to register, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := repo.users["file@example.com"].AvatarUrl; got != uploadedURL || uploads != 1 {
+		t.Errorf("Expected the uploaded avatar, got %q after %d uploads", got, uploads)
+	}
+
+	if w := send("/auth/users/register", "url@example.com", hostedURL, false); w.Code != http.StatusOK {
+		t.Fatalf("Expected an avatar_url to register, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := repo.users["url@example.com"].AvatarUrl; got != hostedURL {
+		t.Errorf("Expected the given avatar_url, got %q", got)
+	}
+
+	otherURL := "https://res.cloudinary.com/demo/image/upload/v1700000002/avatars/other.png"
+	if w := send("/api/users/update", "url@example.com", otherURL, false); w.Code != http.StatusOK {
+		t.Fatalf("Expected an avatar_url to update, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := repo.users["url@example.com"].AvatarUrl; got != otherURL {
+		t.Errorf("Expected the avatar to be replaced, got %q", got)
+	}
+
+	if w := send("/api/users/update", "url@example.com", "https://evil.example.com/a.png", false); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a foreign avatar_url to be rejected, got %d", w.Code)
+	}
+}
+
+func TestUserHandler_PrecheckPasswordReset_Indistinguishable(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"known@example.com":      {Email: "known@example.com", Verified: true},
+		"unverified@example.com": {Email: "unverified@example.com"},
+	}}
+	var sentTo []string
+	uc := &usecase.UserUsecase{
+		Repo:     repo,
+		RunAsync: func(task func()) { task() },
+		SendEmail: func(to, subject, body string) error {
+			sentTo = append(sentTo, to)
+			return nil
+		},
+	}
+	handler := NewUserHandler(uc)
+	router := gin.New()
+	router.POST("/auth/users/precheck", handler.PrecheckPasswordReset)
+
+	precheck := func(email string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/auth/users/precheck", strings.NewReader(`{"email":"`+email+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	known := precheck("known@example.com")
+	for _, email := range []string{"unknown@example.com", "unverified@example.com"} {
+		w := precheck(email)
+		if w.Code != known.Code || w.Body.String() != known.Body.String() {
+			t.Errorf("Expected identical response for %s, got %d %s vs %d %s",
+				email, w.Code, w.Body.String(), known.Code, known.Body.String())
+		}
+	}
+	if known.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", known.Code)
+	}
+
+	if len(sentTo) != 1 || sentTo[0] != "known@example.com" {
+		t.Errorf("Expected instructions only for the verified account, got %v", sentTo)
+	}
+}
+
+func TestUserHandler_SendPasswordResetLink_Indistinguishable(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"known@example.com": {Email: "known@example.com", Verified: true},
+	}}
+	var sentTo []string
+	uc := &usecase.UserUsecase{
+		Repo:     repo,
+		RunAsync: func(task func()) { task() },
+		SendEmail: func(to, subject, body string) error {
+			sentTo = append(sentTo, to)
+			return nil
+		},
+	}
+	handler := NewUserHandler(uc)
+	router := gin.New()
+	router.POST("/auth/users/forgot-password/send-link", handler.SendPasswordResetLink)
+
+	sendLink := func(email string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/auth/users/forgot-password/send-link", strings.NewReader(`{"email":"`+email+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	known := sendLink("known@example.com")
+	unknown := sendLink("unknown@example.com")
+	if known.Code != http.StatusOK || unknown.Code != known.Code || unknown.Body.String() != known.Body.String() {
+		t.Errorf("Expected identical 200 responses, got %d %s vs %d %s",
+			known.Code, known.Body.String(), unknown.Code, unknown.Body.String())
+	}
+	if len(sentTo) != 1 || sentTo[0] != "known@example.com" {
+		t.Errorf("Expected a link only for the existing account, got %v", sentTo)
+	}
+	if w := sendLink(""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without an email, got %d", w.Code)
+	}
+}
+
+func TestUserHandler_UserMe_ConditionalRequest(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", PhoneNumber: "628112123123"},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+	router := gin.New()
+	router.GET("/api/users/me", func(c *gin.Context) {
+		c.Set("user_id", "user-123")
+		c.Set("email", "john@example.com")
+		c.Set("phone", "628112123123")
+		c.Next()
+	}, handler.UserMe)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/me", nil)
+	router.ServeHTTP(w, req)
+
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d and %q", w.Code, etag)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/users/me", nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for matching ETag, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/users/me", nil)
+	req.Header.Set("If-None-Match", `"outdated"`)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "john@example.com") {
+		t.Errorf("Expected 200 with body for non-matching ETag, got %d", w.Code)
+	}
+}
+
+func TestUserHandler_VerifyOTP_RequestErrors(t *testing.T) {
+	setupGinTestMode()
+
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: &stubUserRepository{users: map[string]*entity.User{}}})
+	router := gin.New()
+	router.POST("/verification/users/verify-otp", handler.VerifyOTP)
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode string
+	}{
+		{"empty body", "", "BAD_REQUEST"},
+		{"malformed JSON", `{"email":`, "BAD_REQUEST"},
+		{"wrong field types", `{"email":1,"otp":2}`, "BAD_REQUEST"},
+		{"missing otp", `{"email":"john@example.com"}`, "EMAIL_OTP_REQUIRED"},
+		{"missing email", `{"otp":"123456"}`, "EMAIL_OTP_REQUIRED"},
+		{"empty object", `{}`, "EMAIL_OTP_REQUIRED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/verification/users/verify-otp", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+			var body struct {
+				Status string `json:"status"`
+				Code   int    `json:"code"`
+				Error  struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Expected a JSON error envelope, got %s", w.Body.String())
+			}
+			if body.Status != "ERROR" || body.Code != http.StatusBadRequest {
+				t.Errorf("Expected the standard error envelope, got %s", w.Body.String())
+			}
+			if body.Error.Code != tt.expectedCode {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, body.Error.Code)
+			}
+		})
+	}
+}
+
+// performUserMe calls UserMe with the given token claims
+func performUserMe(handler *UserHandler, userID, email, phone string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/api/users/me", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("email", email)
+		c.Set("phone", phone)
+		c.Next()
+	}, handler.UserMe)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/me", nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUserHandler_UserMe_StandardEnvelope(t *testing.T) {
+	setupGinTestMode()
+
+	createdAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {
+			ID:          "user-123",
+			Fullname:    "John Doe",
+			Email:       "john@example.com",
+			PhoneNumber: "628112123123",
+			AvatarUrl:   "https://assets/images/img.jpg",
+			Verified:    true,
+			OnBoarded:   true,
+			Password:    "hashed-password",
+			CreatedAt:   createdAt,
+		},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+
+	w := performUserMe(handler, "user-123", "john@example.com", "628112123123")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Status   string `json:"status"`
+		Code     int    `json:"code"`
+		Response struct {
+			Message string           `json:"message"`
+			Data    dto.UserResponse `json:"data"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Status != constants.SUCCESS || body.Code != http.StatusOK {
+		t.Errorf("Expected a success envelope, got %q %d", body.Status, body.Code)
+	}
+	if body.Response.Message != constants.VALID_TOKEN {
+		t.Errorf("Expected message %q in the envelope, got %q", constants.VALID_TOKEN, body.Response.Message)
+	}
+	expected := dto.UserResponse{
+		Fullname:    "John Doe",
+		Email:       "john@example.com",
+		PhoneNumber: "628112123123",
+		AvatarUrl:   "https://assets/images/img.jpg",
+		Verified:    true,
+		OnBoarded:   true,
+		CreatedAt:   "2024-01-15T10:30:00Z",
+	}
+	if body.Response.Data != expected {
+		t.Errorf("Expected %+v, got %+v", expected, body.Response.Data)
+	}
+	if strings.Contains(w.Body.String(), `"user"`) || strings.Contains(w.Body.String(), "hashed-password") {
+		t.Errorf("Expected only the user response fields, got %s", w.Body.String())
+	}
+}
+
+func TestUserHandler_UserMe_ReflectsLatestPhone(t *testing.T) {
+	setupGinTestMode()
+	t.Setenv("DECRYPT_KEY", "12345678901234567890123456789012")
+
+	encryptedOTP, err := utils.Encrypt("123456")
+	if err != nil {
+		t.Fatalf("Failed to encrypt OTP: %v", err)
+	}
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {
+			ID:           "user-123",
+			Email:        "john@example.com",
+			PhoneNumber:  "628112123123",
+			OTP:          encryptedOTP,
+			OTPType:      constants.PHONE_CHANGED,
+			OTPExpiresAt: time.Now().Add(5 * time.Minute),
+		},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 60})
+
+	router := gin.New()
+	router.POST("/api/users/change-phone", func(c *gin.Context) {
+		c.Set("email", "john@example.com")
+		c.Set("phone", "628112123123")
+		c.Next()
+	}, handler.ChangePhone)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/users/change-phone", strings.NewReader(`{"otp":"123456","new_phone":"628119999999"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected phone change to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(strings.Join(w.Header().Values("Set-Cookie"), "\n"), "token=ey") {
+		t.Error("Expected a re-issued token cookie")
+	}
+
+	// Claims from the old token still carry the old phone
+	w = performUserMe(handler, "user-123", "john@example.com", "628112123123")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "628119999999") {
+		t.Errorf("Expected UserMe to return the new phone, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUserHandler_ChangePhone_RequestErrors(t *testing.T) {
+	setupGinTestMode()
+	t.Setenv("DECRYPT_KEY", "12345678901234567890123456789012")
+
+	encryptedOTP, err := utils.Encrypt("123456")
+	if err != nil {
+		t.Fatalf("Failed to encrypt OTP: %v", err)
+	}
+	user := &entity.User{
+		ID:           "user-123",
+		Email:        "john@example.com",
+		PhoneNumber:  "628112123123",
+		OTP:          encryptedOTP,
+		OTPType:      constants.PHONE_CHANGED,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	repo := &stubUserRepository{users: map[string]*entity.User{"john@example.com": user}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 60})
+
+	tests := []struct {
+		name           string
+		phoneClaim     interface{}
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"malformed phone claim", 628112123123, `{"otp":"123456","new_phone":"628119999999"}`, http.StatusUnauthorized, "INVALID_TOKEN_CLAIMS"},
+		{"invalid new phone", "628112123123", `{"otp":"123456","new_phone":"12-ab"}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/api/users/change-phone", func(c *gin.Context) {
+				c.Set("email", "john@example.com")
+				c.Set("phone", tt.phoneClaim)
+				c.Next()
+			}, handler.ChangePhone)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/users/change-phone", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"code":"`+tt.expectedCode+`"`) {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, w.Body.String())
+			}
+			if user.OTP != encryptedOTP || user.PhoneNumber != "628112123123" {
+				t.Error("Expected the OTP to be left unconsumed and the phone unchanged")
+			}
+		})
+	}
+}
+
+func TestUserHandler_UserMe_ReflectsLatestEmail(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", PhoneNumber: "628112123123"},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+
+	user := repo.users["john@example.com"]
+	user.Email = "john.new@example.com"
+	repo.UpdateEmail(user, "john@example.com")
+
+	w := performUserMe(handler, "user-123", "john.new@example.com", "628112123123")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "john.new@example.com") {
+		t.Errorf("Expected UserMe to return the new email, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A token for the old email no longer resolves to an account
+	w = performUserMe(handler, "user-123", "john@example.com", "628112123123")
+	if w.Code == http.StatusOK {
+		t.Errorf("Expected a stale email claim to be rejected, got %d", w.Code)
+	}
+}
+
+func TestUserHandler_UserMe_RejectsReusedEmail(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-456", Email: "john@example.com"},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+
+	w := performUserMe(handler, "user-123", "john@example.com", "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a token issued to another account, got %d", w.Code)
+	}
+}
+
+func TestUserHandler_UserMe_DeletedUser(t *testing.T) {
+	setupGinTestMode()
+
+	revoked := map[string]time.Time{}
+	expiresAt := time.Now().Add(30 * time.Minute)
+	handler := NewUserHandler(&usecase.UserUsecase{
+		Repo: &stubUserRepository{users: map[string]*entity.User{}},
+		RevokeToken: func(jti, email string, expiresAt time.Time) error {
+			revoked[jti] = expiresAt
+			return nil
+		},
+	})
+
+	router := gin.New()
+	router.GET("/api/users/me", func(c *gin.Context) {
+		c.Set("user_id", "user-123")
+		c.Set("email", "john@example.com")
+		c.Set("jti", "orphaned-jti")
+		c.Set("token_expires_at", expiresAt)
+		c.Next()
+	}, handler.UserMe)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/me", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 for a token whose user was deleted, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), appErrors.ErrInvalidToken.Code) {
+		t.Errorf("Expected %s, got %s", appErrors.ErrInvalidToken.Code, w.Body.String())
+	}
+	if got, ok := revoked["orphaned-jti"]; !ok || !got.Equal(expiresAt) {
+		t.Errorf("Expected the orphaned token to be blacklisted, got %v", revoked)
+	}
+}
+
 func TestUserHandler_CookieSettings(t *testing.T) {
 	setupGinTestMode()
 
@@ -524,12 +1331,12 @@ func TestUserHandler_ErrorHandling(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			handler := setupUserHandler()
-			
+
 			// Test structure without executing (would panic due to missing dependencies)
 			if handler == nil {
 				t.Fatal("Expected non-nil handler")
 			}
-			
+
 			t.Logf("Error handling test completed for case: %s", tc.name)
 		})
 	}
@@ -657,3 +1464,568 @@ func BenchmarkUserHandler_Logout(b *testing.B) {
 		handler.Logout(c)
 	}
 }
+
+func TestUserHandler_CompleteOnboarding(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Fullname: "John"},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+
+	router := gin.New()
+	router.POST("/api/users/onboard", func(c *gin.Context) {
+		c.Set("user_id", "user-123")
+		c.Set("email", "john@example.com")
+		c.Next()
+	}, handler.CompleteOnboarding)
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/users/onboard", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(`{"preferences":`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for malformed JSON, got %d", w.Code)
+	}
+
+	w := send(`{"full_name":"John Doe","preferences":{"theme":"dark","timezone":"Asia/Jakarta"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Response struct {
+			Data dto.UserResponse `json:"data"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	data := body.Response.Data
+	if !data.OnBoarded || data.Fullname != "John Doe" || data.Preferences == nil || data.Preferences.Theme != "dark" {
+		t.Errorf("Expected the updated profile, got %s", w.Body.String())
+	}
+
+	if w := send(""); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "ALREADY_ONBOARDED") {
+		t.Errorf("Expected 409 ALREADY_ONBOARDED on resubmission, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUserHandler_TokenCookieMatchesTokenLifetime(t *testing.T) {
+	setupGinTestMode()
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Password: string(hashed), Verified: true},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 30})
+
+	// The token cookie must expire with the token it holds
+	assertCookieMatchesToken := func(t *testing.T, w *httptest.ResponseRecorder) {
+		t.Helper()
+		var token *http.Cookie
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == "token" && cookie.Value != "" {
+				token = cookie
+			}
+		}
+		if token == nil {
+			t.Fatalf("Expected a token cookie, got %v", w.Header().Values("Set-Cookie"))
+		}
+		claims := jwtlib.MapClaims{}
+		if _, _, err := jwtlib.NewParser().ParseUnverified(token.Value, claims); err != nil {
+			t.Fatalf("Failed to parse token: %v", err)
+		}
+		exp, err := claims.GetExpirationTime()
+		if err != nil || exp == nil {
+			t.Fatalf("Expected an exp claim, got %v", err)
+		}
+		remaining := time.Until(exp.Time)
+		if diff := time.Duration(token.MaxAge)*time.Second - remaining; diff < -5*time.Second || diff > 5*time.Second {
+			t.Errorf("Expected cookie MaxAge %ds to match the token's remaining %v", token.MaxAge, remaining)
+		}
+		if token.MaxAge != 30*60 {
+			t.Errorf("Expected cookie MaxAge %d, got %d", 30*60, token.MaxAge)
+		}
+	}
+
+	t.Run("login", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/users/login", nil)
+		c.Set("validated_email", "john@example.com")
+		c.Set("validated_password", "Password123!")
+		handler.Login(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected login to succeed, got %d: %s", w.Code, w.Body.String())
+		}
+		assertCookieMatchesToken(t, w)
+	})
+
+	t.Run("re-issued token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		if err := handler.refreshToken(c, "john@example.com"); err != nil {
+			t.Fatalf("Expected token refresh to succeed, got %v", err)
+		}
+		assertCookieMatchesToken(t, w)
+	})
+}
+
+// stubSessionRepository keeps sessions in memory, keyed by id
+type stubSessionRepository struct {
+	sessions map[string]*entity.Session
+}
+
+func (s *stubSessionRepository) Create(session *entity.Session) error {
+	if s.sessions == nil {
+		s.sessions = make(map[string]*entity.Session)
+	}
+	session.ID = primitive.NewObjectID()
+	s.sessions[session.ID.Hex()] = session
+	return nil
+}
+
+func (s *stubSessionRepository) FindActiveByUser(userID string, now time.Time) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *stubSessionRepository) FindByID(id string) (*entity.Session, error) {
+	if session, ok := s.sessions[id]; ok {
+		return session, nil
+	}
+	return nil, appErrors.ErrSessionNotFound
+}
+
+func (s *stubSessionRepository) RevokeByJTI(jti string, at time.Time) error {
+	for _, session := range s.sessions {
+		if session.JTI == jti {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (s *stubSessionRepository) RevokeAllByUser(userID string, at time.Time) error {
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestUserHandler_Sessions(t *testing.T) {
+	setupGinTestMode()
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Password: string(hashed), Verified: true},
+	}}
+	sessions := &stubSessionRepository{}
+	revoked := map[string]bool{}
+	handler := NewUserHandler(&usecase.UserUsecase{
+		Repo:      repo,
+		JWTSecret: "test-secret",
+		JWTExpire: 30,
+		Sessions:  sessions,
+		RevokeToken: func(jti, email string, expiresAt time.Time) error {
+			revoked[jti] = true
+			return nil
+		},
+	})
+
+	// Logging in records the device
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/users/login", nil)
+	c.Request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/124.0.0.0 Safari/537.36")
+	c.Set("validated_email", "john@example.com")
+	c.Set("validated_password", "Password123!")
+	handler.Login(c)
+	if w.Code != http.StatusOK || len(sessions.sessions) != 1 {
+		t.Fatalf("Expected login to record a session, got %d sessions: %s", len(sessions.sessions), w.Body.String())
+	}
+	var session *entity.Session
+	for _, s := range sessions.sessions {
+		session = s
+	}
+
+	router := gin.New()
+	authenticated := func(c *gin.Context) {
+		c.Set("user_id", "user-123")
+		c.Set("email", "john@example.com")
+		c.Set("jti", session.JTI)
+		c.Next()
+	}
+	router.GET("/api/users/sessions", authenticated, handler.ListSessions)
+	router.DELETE("/api/users/sessions/:id", authenticated, handler.RevokeSession)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/users/sessions", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var listed struct {
+		Response struct {
+			Data []dto.SessionResponse `json:"data"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(listed.Response.Data) != 1 || !listed.Response.Data[0].Current || listed.Response.Data[0].Device != "Chrome on Windows" {
+		t.Fatalf("Expected the login to be listed as the current session, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/users/sessions/"+primitive.NewObjectID().Hex(), nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/users/sessions/"+session.ID.Hex(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !revoked[session.JTI] || session.RevokedAt == nil {
+		t.Errorf("Expected the session's token to be revoked, got %v", revoked)
+	}
+}
+
+func TestUserHandler_RefreshTokenEndsOldSession(t *testing.T) {
+	setupGinTestMode()
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Verified: true},
+	}}
+	sessions := &stubSessionRepository{}
+	revoked := map[string]bool{}
+	users := &usecase.UserUsecase{
+		Repo:      repo,
+		JWTSecret: "test-secret",
+		JWTExpire: 30,
+		Sessions:  sessions,
+		RevokeToken: func(jti, email string, expiresAt time.Time) error {
+			revoked[jti] = true
+			return nil
+		},
+	}
+	handler := NewUserHandler(users)
+
+	logged, _ := users.LoginWithoutPassword("john@example.com")
+	users.StartSession(logged.Token, "203.0.113.7", "")
+	var old *entity.Session
+	for _, session := range sessions.sessions {
+		old = session
+	}
+
+	// The email change re-issues the token of the request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/api/users/change-email", nil)
+	c.Set("email", "john@example.com")
+	c.Set("jti", old.JTI)
+	if err := handler.refreshToken(c, "john@example.com"); err != nil {
+		t.Fatalf("Expected token refresh to succeed, got %v", err)
+	}
+
+	if !revoked[old.JTI] || old.RevokedAt == nil {
+		t.Errorf("Expected the old token revoked and its session ended, got %v", revoked)
+	}
+	if listed, _ := users.ListSessions("user-123", ""); len(listed) != 1 || listed[0].ID == old.ID.Hex() {
+		t.Errorf("Expected only the new session to be active, got %+v", listed)
+	}
+}
+
+func TestUserHandler_LogoutAll(t *testing.T) {
+	setupGinTestMode()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Verified: true},
+	}}
+	users := &usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 60}
+	handler := NewUserHandler(users)
+
+	router := gin.New()
+	protected := router.Group("/api")
+	protected.Use(jwt.JWTMiddleware(nil), jwt.RequireTokenVersion(users.TokenVersion))
+	protected.GET("/users/me", handler.UserMe)
+	protected.POST("/users/logout-all", handler.LogoutAll)
+
+	login := func() string {
+		user, err := users.LoginWithoutPassword("john@example.com")
+		if err != nil {
+			t.Fatalf("Failed to log in: %v", err)
+		}
+		return user.Token
+	}
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	laptop, phone := login(), login()
+	w := request("POST", "/api/users/logout-all", laptop)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Set-Cookie"), "token=;") {
+		t.Errorf("Expected the token cookie to be cleared, got %q", w.Header().Get("Set-Cookie"))
+	}
+
+	for name, token := range map[string]string{"laptop": laptop, "phone": phone} {
+		if w := request("GET", "/api/users/me", token); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected the %s token to be refused, got %d", name, w.Code)
+		}
+	}
+	if w := request("GET", "/api/users/me", login()); w.Code != http.StatusOK {
+		t.Errorf("Expected a token issued afterwards to work, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUserHandler_DeleteMe(t *testing.T) {
+	setupGinTestMode()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Verified: true},
+	}}
+	users := &usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 60, RunAsync: func(task func()) {}}
+	handler := NewUserHandler(users)
+
+	router := gin.New()
+	protected := router.Group("/api")
+	protected.Use(jwt.JWTMiddleware(nil), jwt.RequireTokenVersion(users.TokenVersion))
+	protected.DELETE("/users/me", jwt.RefuseImpersonation(), handler.DeleteMe)
+
+	request := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/users/me", nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	impersonation, _ := jwt.GenerateImpersonationToken("user-123", "john@example.com", "", "user", 0, "admin-1", "test-secret", 15)
+	if w := request(impersonation); w.Code != http.StatusForbidden {
+		t.Errorf("Expected an impersonation token to be refused, got %d: %s", w.Code, w.Body.String())
+	}
+	if !repo.users["john@example.com"].DeletionScheduledAt.IsZero() {
+		t.Fatal("Expected no deletion scheduled by an impersonator")
+	}
+
+	logged, _ := users.LoginWithoutPassword("john@example.com")
+	w := request(logged.Token)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "deletion_scheduled_at") {
+		t.Fatalf("Expected the deletion date, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Set-Cookie"), "token=;") {
+		t.Errorf("Expected the token cookie to be cleared, got %q", w.Header().Get("Set-Cookie"))
+	}
+	if repo.users["john@example.com"].DeletionScheduledAt.IsZero() {
+		t.Error("Expected the deletion to be scheduled")
+	}
+	if w := request(logged.Token); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the token to stop working, got %d", w.Code)
+	}
+}
+
+func TestUserHandler_MalformedUserIDClaim(t *testing.T) {
+	setupGinTestMode()
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com"},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 123)
+		c.Set("email", "john@example.com")
+		c.Next()
+	})
+	router.GET("/api/users/security/summary", handler.SecuritySummary)
+	router.DELETE("/api/users/me", handler.DeleteMe)
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/api/users/security/summary"},
+		{"DELETE", "/api/users/me"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(route.method, route.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "INVALID_TOKEN_CLAIMS") {
+			t.Errorf("%s %s: expected 401 INVALID_TOKEN_CLAIMS, got %d: %s", route.method, route.path, w.Code, w.Body.String())
+		}
+	}
+	if !repo.users["john@example.com"].DeletionScheduledAt.IsZero() {
+		t.Error("Expected no deletion scheduled with a malformed claim")
+	}
+}
+
+// stubOAuthProvider answers the code "good-code" with a fixed identity
+type stubOAuthProvider struct {
+	identity oauth.Identity
+}
+
+func (p *stubOAuthProvider) Name() string { return "stub" }
+
+func (p *stubOAuthProvider) AuthCodeURL(state string) string {
+	return "https://provider.example.com/authorize?state=" + state
+}
+
+func (p *stubOAuthProvider) Identity(ctx context.Context, code string) (*oauth.Identity, error) {
+	if code != "good-code" {
+		return nil, errors.New("bad verification code")
+	}
+	identity := p.identity
+	return &identity, nil
+}
+
+func TestUserHandler_OAuth(t *testing.T) {
+	setupGinTestMode()
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Verified: true},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 60})
+	provider := &stubOAuthProvider{identity: oauth.Identity{Provider: "stub", Subject: "42", Email: "john@example.com"}}
+	handler.OAuth = oauth.Registry{"stub": provider}
+
+	router := gin.New()
+	router.GET("/auth/users/oauth/:provider", handler.OAuthLogin)
+	router.GET("/auth/users/oauth/:provider/callback", handler.OAuthCallback)
+
+	start := func() string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/auth/users/oauth/stub", nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusFound {
+			t.Fatalf("Expected a redirect to the provider, got %d", w.Code)
+		}
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == oauthStateCookie {
+				if !strings.HasSuffix(w.Header().Get("Location"), "state="+cookie.Value) {
+					t.Errorf("Expected the state cookie in the redirect, got %q", w.Header().Get("Location"))
+				}
+				return cookie.Value
+			}
+		}
+		t.Fatal("Expected a state cookie")
+		return ""
+	}
+	callback := func(state, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/auth/users/oauth/stub/callback?"+query, nil)
+		if state != "" {
+			req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: state})
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("unknown provider", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/auth/users/oauth/gitlab", nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("state mismatch", func(t *testing.T) {
+		state := start()
+		if w := callback(state, "code=good-code&state=forged"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a forged state, got %d", w.Code)
+		}
+		if w := callback("", "code=good-code&state="+state); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without the state cookie, got %d", w.Code)
+		}
+	})
+
+	t.Run("bad code", func(t *testing.T) {
+		state := start()
+		if w := callback(state, "code=bad-code&state="+state); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 when the provider refuses the code, got %d", w.Code)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		state := start()
+		w := callback(state, "code=good-code&state="+state)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(strings.Join(w.Header().Values("Set-Cookie"), ";"), "token=ey") {
+			t.Errorf("Expected the token cookie to be set, got %q", w.Header().Values("Set-Cookie"))
+		}
+
+		handler.OAuthSuccessURL = "https://app.example.com/dashboard"
+		defer func() { handler.OAuthSuccessURL = "" }()
+		state = start()
+		w = callback(state, "code=good-code&state="+state)
+		if w.Code != http.StatusFound || w.Header().Get("Location") != "https://app.example.com/dashboard" {
+			t.Errorf("Expected a redirect to the success page, got %d %q", w.Code, w.Header().Get("Location"))
+		}
+	})
+
+	t.Run("no account", func(t *testing.T) {
+		provider.identity.Email = "jane@example.com"
+		state := start()
+		if w := callback(state, "code=good-code&state="+state); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 without an account, got %d", w.Code)
+		}
+	})
+}
+
+func TestUserHandler_ConfiguredCookie(t *testing.T) {
+	setupGinTestMode()
+	repo := &stubUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Verified: true},
+	}}
+	handler := NewUserHandler(&usecase.UserUsecase{Repo: repo, JWTSecret: "test-secret", JWTExpire: 15})
+	handler.Cookie = jwt.CookieConfig{Name: "byow_session", Domain: "example.com", SameSite: http.SameSiteStrictMode, Secure: false, MaxAge: 900}
+
+	router := gin.New()
+	router.POST("/api/users/logout", handler.Logout)
+	router.GET("/auth/users/oauth/:provider/callback", handler.OAuthCallback)
+	handler.OAuth = oauth.Registry{"stub": &stubOAuthProvider{identity: oauth.Identity{Provider: "stub", Email: "john@example.com"}}}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/auth/users/oauth/stub/callback?code=good-code&state=s", nil)
+	req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: "s"})
+	router.ServeHTTP(w, req)
+	w2 := httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/users/logout", nil)
+	router.ServeHTTP(w2, req)
+
+	for name, recorder := range map[string]*httptest.ResponseRecorder{"login": w, "logout": w2} {
+		var token *http.Cookie
+		for _, cookie := range recorder.Result().Cookies() {
+			if cookie.Name == "byow_session" {
+				token = cookie
+			}
+		}
+		if token == nil || token.Domain != "example.com" || token.SameSite != http.SameSiteStrictMode || token.Secure || !token.HttpOnly {
+			t.Errorf("Expected the %s cookie to use the configured attributes, got %v", name, recorder.Header().Values("Set-Cookie"))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/utils"
+)
+
+// vCardEscaper escapes the characters RFC 6350 reserves in property values
+var vCardEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	",", `\,`,
+	";", `\;`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// vCardLineLength is the longest line RFC 6350 allows, in octets
+const vCardLineLength = 75
+
+// companyVCard renders company as a vCard 4.0 document. The company has no
+// website on record, so URL is never set.
+func companyVCard(company *dto.PublicCompanyResponse) string {
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"KIND:org",
+		"FN:" + vCardEscaper.Replace(company.CompanyName),
+		"ORG:" + vCardEscaper.Replace(company.CompanyName),
+	}
+	if company.CompanyEmail != "" {
+		lines = append(lines, "EMAIL;TYPE=work:"+vCardEscaper.Replace(company.CompanyEmail))
+	}
+	if company.CompanyPhone != "" {
+		lines = append(lines, "TEL;TYPE=work,voice:"+vCardEscaper.Replace(company.CompanyPhone))
+	}
+	if company.CompanyAddress != "" {
+		// The address is free text, so it all goes in the street component
+		lines = append(lines, "ADR;TYPE=work:;;"+vCardEscaper.Replace(company.CompanyAddress)+";;;;")
+	}
+	lines = append(lines, "END:VCARD")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldVCardLine(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// foldVCardLine splits line into CRLF + space continuations of at most
+// vCardLineLength octets, never inside a multi-byte character
+func foldVCardLine(line string) string {
+	var b strings.Builder
+	limit := vCardLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation counts towards its length
+		limit = vCardLineLength - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+// vCardFilename turns a company name into a safe download filename
+func vCardFilename(companyName string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range utils.FoldText(companyName) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" {
+		return "company"
+	}
+	return name
+}
@@ -15,54 +15,53 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/companies/all": {
+        "/.well-known/jwks.json": {
             "get": {
+                "description": "Public keys for verifying this service's tokens, in the standard JWKS format rather than the response envelope. Empty while tokens are signed with HS256.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Companies"
+                    "System"
                 ],
-                "summary": "Find All Companies",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Keyword",
-                        "name": "keyword",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Offset",
-                        "name": "offset",
-                        "in": "query"
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jwt.JWKSet"
+                        }
                     }
+                }
+            }
+        },
+        "/api/admin/api-keys": {
+            "get": {
+                "description": "Every API key, revoked ones included, newest first. Requires the admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
                 ],
+                "summary": "List API Keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CompanyListResponseSwagger"
+                            "$ref": "#/definitions/dto.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/companies/create": {
+            },
             "post": {
-                "description": "Register a new company",
+                "description": "Issue an API key another BYOW service calls internal endpoints with. The key is only shown in this response. Requires the admin role.",
                 "consumes": [
                     "application/json"
                 ],
@@ -70,54 +69,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Companies"
+                    "Admin"
                 ],
-                "summary": "Create Company",
+                "summary": "Create API Key",
                 "parameters": [
                     {
-                        "type": "string",
-                        "example": "Cemerlang Jaya",
-                        "description": "Company Name",
-                        "name": "company_name",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"john@company.com\"",
-                        "description": "Company Email",
-                        "name": "company_email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "628112123123",
-                        "description": "Company Phone",
-                        "name": "company_phone",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"123 Cemerlang St, Tech City\"",
-                        "description": "Company Address",
-                        "name": "company_address",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Company Logo",
-                        "name": "company_logo",
-                        "in": "formData"
+                        "description": "Key name \u0026 scopes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateAPIKeyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
+                            "$ref": "#/definitions/dto.CreatedAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -125,28 +95,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/companies/{id}": {
-            "get": {
-                "description": "Get company details by ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/admin/api-keys/{id}": {
+            "delete": {
+                "description": "Stop an API key from authenticating. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Companies"
+                    "Admin"
                 ],
-                "summary": "Get Company By ID",
+                "summary": "Revoke API Key",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
-                        "description": "Company ID",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -156,11 +128,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
+                            "$ref": "#/definitions/dto.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -168,32 +146,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/change-email": {
-            "post": {
-                "description": "Change user email using OTP verification",
+        "/api/admin/companies": {
+            "get": {
+                "description": "List companies across all users. Requires the admin role.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Change Email With OTP",
+                "summary": "Admin List Companies",
                 "parameters": [
                     {
-                        "description": "OTP \u0026 New Email",
-                        "name": "otp",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.ChangeEmailRequest"
-                        }
+                        "type": "string",
+                        "description": "Keyword",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by verification status",
+                        "name": "verified",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by owner",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.CompanyListResponseSwagger"
                         }
                     },
                     "400": {
@@ -201,24 +200,41 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/change-email/send-otp": {
-            "get": {
+        "/api/admin/companies/purge": {
+            "post": {
+                "description": "Permanently remove companies soft-deleted more than the given number of days ago, along with their logos. Requires the admin role.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
+                ],
+                "summary": "Admin Purge Deleted Companies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 30,
+                        "description": "Minimum days since deletion",
+                        "name": "days",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Send OTP Change Email",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.PurgeCompaniesResponse"
                         }
                     },
                     "400": {
@@ -226,28 +242,37 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/change-password-old": {
+        "/api/admin/companies/verify-batch": {
             "post": {
-                "description": "Change user password using old password",
+                "description": "Set the verification status of many companies at once. Malformed IDs are skipped. Requires the admin role.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Change Password With Old Password",
+                "summary": "Admin Batch Verify Companies",
                 "parameters": [
                     {
-                        "description": "Email, Old Password \u0026 New Password",
-                        "name": "otp",
+                        "description": "Company IDs \u0026 target status",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.ChangePasswordWithOldPasswordRequest"
+                            "$ref": "#/definitions/dto.VerifyCompaniesRequest"
                         }
                     }
                 ],
@@ -255,7 +280,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.VerifyCompaniesResponse"
                         }
                     },
                     "400": {
@@ -263,28 +288,51 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/change-phone": {
+        "/api/admin/companies/{id}/unverify": {
             "post": {
-                "description": "Change user phone using OTP verification",
+                "description": "Revoke a company's verification with a reason, which is audited and emailed to the owner. Requires the admin role.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Change Phone With OTP Email",
+                "summary": "Admin Unverify Company",
                 "parameters": [
                     {
-                        "description": "OTP \u0026 New Email",
-                        "name": "otp",
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.ChangePhoneRequest"
+                            "$ref": "#/definitions/dto.UnverifyCompanyRequest"
                         }
                     }
                 ],
@@ -292,7 +340,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
                         }
                     },
                     "400": {
@@ -300,28 +348,59 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/change-phone/send-otp": {
-            "get": {
+        "/api/admin/db/indexes/rebuild": {
+            "post": {
+                "description": "Create any missing database indexes without a redeploy. Requires the admin role.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Send OTP Change Email",
+                "summary": "Rebuild Indexes",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.RebuildIndexesResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -329,27 +408,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/logout": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/admin/flags": {
+            "get": {
+                "description": "Current feature flag values. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Logout user",
+                "summary": "Feature Flags",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/dto.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -357,21 +434,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/me": {
-            "get": {
-                "description": "Check if user is logged in and return user info",
+        "/api/admin/security/reencrypt-otps": {
+            "post": {
+                "description": "After rotating DECRYPT_KEY, re-encrypt unexpired OTPs from DECRYPT_KEY_PREVIOUS to the current key, or invalidate them all with mode \"invalidate\". Requires the admin role.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
+                ],
+                "summary": "Re-encrypt OTPs",
+                "parameters": [
+                    {
+                        "description": "Mode, reencrypt by default",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReencryptOTPsRequest"
+                        }
+                    }
                 ],
-                "summary": "Check Logged Account",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                            "$ref": "#/definitions/dto.ReencryptOTPsResponse"
                         }
                     },
                     "400": {
@@ -379,25 +469,63 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/onboard": {
+        "/api/admin/users": {
             "get": {
-                "description": "Onboard user to the system",
+                "description": "List users, searching their email, phone number and name. Requires the admin role.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
+                ],
+                "summary": "List Users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email, phone or name",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by suspension status",
+                        "name": "suspended",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Onboarded User",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.AdminUserListResponseSwagger"
                         }
                     },
                     "400": {
@@ -405,56 +533,41 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/update": {
-            "post": {
-                "description": "Update user information",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/admin/users/export": {
+            "get": {
+                "description": "Stream every user as newline-delimited JSON. Passwords and OTP data are never included. Requires the admin role.",
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "Users"
-                ],
-                "summary": "Update User",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "example": "John Doe",
-                        "description": "Full name",
-                        "name": "full_name",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "john@example.com",
-                        "description": "Email",
-                        "name": "email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Avatar file",
-                        "name": "avatar",
-                        "in": "formData"
-                    }
+                    "Admin"
                 ],
+                "summary": "Export Users",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                            "$ref": "#/definitions/dto.UserExportRecord"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -462,24 +575,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/users/change-password-otp": {
+        "/api/admin/users/merge": {
             "post": {
-                "description": "Change user password using OTP verification",
+                "description": "Move an unverified duplicate account's companies to a verified account and delete the duplicate. Requires the admin role.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Change Password With OTP",
+                "summary": "Merge Accounts",
                 "parameters": [
                     {
-                        "description": "Email, OTP \u0026 New Password",
-                        "name": "otp",
+                        "description": "Account to keep \u0026 account to merge",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.ChangePasswordRequest"
+                            "$ref": "#/definitions/dto.MergeAccountsRequest"
                         }
                     }
                 ],
@@ -487,7 +603,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.MergeAccountsResponse"
                         }
                     },
                     "400": {
@@ -495,25 +611,38 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/users/forgot-password/send-otp": {
+        "/api/admin/users/{id}": {
             "get": {
+                "description": "A user's account details. Credentials and OTP data are never included. Requires the admin role.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Send OTP Forgot Password",
+                "summary": "Get User",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Email address",
-                        "name": "email",
-                        "in": "query",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -521,11 +650,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.AdminUserResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -533,51 +668,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/users/login": {
+        "/api/admin/users/{id}/impersonate": {
             "post": {
-                "description": "User login with email and password. Credentials are validated for format and security.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Issue a short-lived token to act as a user, for reproducing what they see. The token names the admin too, and every request made with it is audited. Send it as an Authorization: Bearer header from a client without your own token cookie. Admins and suspended users can't be impersonated. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Login user",
+                "summary": "Impersonate User",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                            "$ref": "#/definitions/dto.ImpersonationResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation errors or invalid JSON format",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/dto.ValidationErrorResponse"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Invalid credentials or unverified account",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -585,74 +715,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/users/register": {
+        "/api/admin/users/{id}/reactivate": {
             "post": {
-                "description": "Register a new user with avatar. All fields are validated for security and format requirements.",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "Lift a user's suspension so they can log in again. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "Register user",
+                "summary": "Reactivate User",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "\"John Doe\"",
-                        "description": "Full name (2-100 chars, letters/spaces/hyphens only)",
-                        "name": "full_name",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"john@example.com\"",
-                        "description": "Valid email address",
-                        "name": "email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"SecurePass123!\"",
-                        "description": "Strong password (8+ chars, mixed case, numbers, symbols)",
-                        "name": "password",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"628112123123\"",
-                        "description": "Valid phone number (E.164 format)",
-                        "name": "phone_number",
-                        "in": "formData",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Avatar image file (max 10MB, JPEG/PNG/GIF only)",
-                        "name": "avatar",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                            "$ref": "#/definitions/dto.AdminUserResponse"
                         }
                     },
-                    "400": {
-                        "description": "Validation errors",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/dto.ValidationErrorResponse"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Email or phone already exists",
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -660,21 +762,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/verification/users/send-otp": {
-            "get": {
+        "/api/admin/users/{id}/reset-otp-attempts": {
+            "post": {
+                "description": "Clear a locked out user's wrong OTP attempts so they can enter their code again. Requires the admin role.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Verification"
+                    "Admin"
                 ],
-                "summary": "Send OTP Verification",
+                "summary": "Reset OTP Attempts",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Email address",
-                        "name": "email",
-                        "in": "query",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -682,11 +785,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.ResetOTPAttemptsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -694,26 +803,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/verification/users/verify-otp": {
-            "post": {
+        "/api/admin/users/{id}/role": {
+            "put": {
+                "description": "Give a user the user or admin role. Their existing tokens stop working right away, so the new role applies on their next login. Admins can't change their own role. Requires the admin role.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Verification"
+                    "Admin"
                 ],
-                "summary": "Verify OTP",
+                "summary": "Set User Role",
                 "parameters": [
                     {
-                        "description": "Email \u0026 OTP",
-                        "name": "otp",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.VerifyOTPRequest"
+                            "$ref": "#/definitions/dto.SetRoleRequest"
                         }
                     }
                 ],
@@ -721,7 +838,212 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SuccessResponse"
+                            "$ref": "#/definitions/dto.SetRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/suspend": {
+            "post": {
+                "description": "Stop a user logging in. Their existing tokens stop working right away. Admins can't suspend themselves. Requires the admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Suspend User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuspendUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/all": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Find All Companies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Keyword",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List only soft-deleted companies",
+                        "name": "deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyListResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/availability": {
+            "get": {
+                "description": "Check whether a company email and/or phone is still free. Only the given fields are checked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Check Company Availability",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "info@company.com",
+                        "description": "Company email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "628112123123",
+                        "description": "Company phone",
+                        "name": "phone",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyAvailabilityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/by-name": {
+            "get": {
+                "description": "Find one of the authenticated user's companies by exact name, ignoring case",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Find Company By Name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "Cemerlang Jaya",
+                        "description": "Company name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
                         }
                     },
                     "400": {
@@ -729,69 +1051,3710 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/create": {
+            "post": {
+                "description": "Register a new company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Create Company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "Cemerlang Jaya",
+                        "description": "Company Name",
+                        "name": "company_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"john@company.com\"",
+                        "description": "Company Email",
+                        "name": "company_email",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "628112123123",
+                        "description": "Company Phone",
+                        "name": "company_phone",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"123 Cemerlang St, Tech City\"",
+                        "description": "Company Address",
+                        "name": "company_address",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Company Logo",
+                        "name": "company_logo",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List the company in the public directory once verified",
+                        "name": "public_listing",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Show email and phone in the public directory",
+                        "name": "public_contact",
+                        "in": "formData"
                     }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/events": {
+            "get": {
+                "description": "Server-Sent Events stream of changes to the authenticated user's companies (company.created, company.updated, company.deleted, company.restored)",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Company Change Events",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyEvent"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/ownership-batch": {
+            "post": {
+                "description": "Report for each company ID whether it is one of the authenticated user's active companies. Malformed IDs are reported as not owned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Check Company Ownership",
+                "parameters": [
+                    {
+                        "description": "Company IDs (max MAX_BATCH_SIZE, 100 by default)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OwnershipBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OwnershipBatchResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/recent": {
+            "get": {
+                "description": "The authenticated user's companies, most recently updated first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Recently Updated Companies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of companies (max 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanySummaryListResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/{id}": {
+            "get": {
+                "description": "Get company details by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Get Company By ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft-delete a company owned by the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Delete Company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/{id}/details": {
+            "get": {
+                "description": "Get a company with its owner's public profile. Visible to the owner and, for companies in the public directory, to everyone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Get Company With Owner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyWithOwnerResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/{id}/restore": {
+            "post": {
+                "description": "Restore a soft-deleted company owned by the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Restore Company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/companies/{id}/set-primary": {
+            "post": {
+                "description": "Make a company owned by the authenticated user their primary company, replacing the previous one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Set Primary Company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CompanyRequestSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/uploads/config": {
+            "get": {
+                "description": "Accepted image types, maximum size and dimensions enforced on avatar and logo uploads, a max dimension of 0 means no limit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Upload Config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadConfigResponseSwagger"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-email": {
+            "post": {
+                "description": "Change user email using the OTP sent to the current address and the code sent to the new one",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change Email With OTP",
+                "parameters": [
+                    {
+                        "description": "OTPs \u0026 New Email",
+                        "name": "otp",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangeEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-email/send-new-otp": {
+            "post": {
+                "description": "Send a confirmation code to the address the user is changing to, required by change-email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Send OTP To New Email",
+                "parameters": [
+                    {
+                        "description": "New Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendNewEmailOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-email/send-otp": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Send OTP Change Email",
+                "parameters": [
+                    {
+                        "enum": [
+                            "email",
+                            "sms"
+                        ],
+                        "type": "string",
+                        "description": "Where to send the code, email (default) or sms to the phone on the account",
+                        "name": "channel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-password-old": {
+            "post": {
+                "description": "Change user password using old password",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change Password With Old Password",
+                "parameters": [
+                    {
+                        "description": "Email, Old Password \u0026 New Password",
+                        "name": "otp",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangePasswordWithOldPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-password-stepup": {
+            "post": {
+                "description": "Change the authenticated user's password without the old one, using an OTP sent to their email",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change Password With Step-Up OTP",
+                "parameters": [
+                    {
+                        "description": "OTP \u0026 New Password",
+                        "name": "otp",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangePasswordStepUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-password-stepup/send-otp": {
+            "post": {
+                "description": "Send a forgot-password OTP to the authenticated user's email, or phone with channel=sms",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Send OTP for Step-Up Password Change",
+                "parameters": [
+                    {
+                        "enum": [
+                            "email",
+                            "sms"
+                        ],
+                        "type": "string",
+                        "description": "Where to send the code, email (default) or sms to the phone on the account",
+                        "name": "channel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-phone": {
+            "post": {
+                "description": "Change user phone using OTP verification",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change Phone With OTP Email",
+                "parameters": [
+                    {
+                        "description": "OTP \u0026 New Email",
+                        "name": "otp",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangePhoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/change-phone/send-otp": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Send OTP Change Email",
+                "parameters": [
+                    {
+                        "enum": [
+                            "email",
+                            "sms"
+                        ],
+                        "type": "string",
+                        "description": "Where to send the code, email (default) or sms to the phone on the account",
+                        "name": "channel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/language": {
+            "get": {
+                "description": "Language the authenticated user's emails are written in, with the supported languages",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Email Language",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LanguageResponseSwagger"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set the language OTP and notice emails are written in when the request has no Accept-Language header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update Email Language",
+                "parameters": [
+                    {
+                        "description": "Supported language",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateLanguageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LanguageResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/logout": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Logout user",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/logout-all": {
+            "post": {
+                "description": "Invalidate every token issued to the authenticated user, including the one making the request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Logout from all devices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me": {
+            "get": {
+                "description": "Check if user is logged in and return their profile, read from the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Check Logged Account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Schedule the authenticated user's account for permanent deletion after the grace period (ACCOUNT_DELETION_GRACE_DAYS) and sign out everywhere. Logging in before the deadline cancels it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete Account",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountDeletionResponseSwagger"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/notifications": {
+            "get": {
+                "description": "Which notice emails the user receives, password and email change notices are always sent",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get Notification Preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPrefsResponseSwagger"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Turn notice emails on or off, omitted notices keep their setting. Password and email change notices can't be turned off.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update Notification Preferences",
+                "parameters": [
+                    {
+                        "description": "Notices to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateNotificationPrefsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NotificationPrefsResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/onboard": {
+            "get": {
+                "description": "Onboard user to the system. Deprecated, use POST /api/users/onboard.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Onboarded User",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Save optional profile data and mark the user onboarded, returning the updated profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Complete Onboarding",
+                "parameters": [
+                    {
+                        "description": "Optional profile data",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OnboardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/permissions": {
+            "get": {
+                "description": "Role of the authenticated user and the permissions it grants, read from the database so role changes apply before the token is refreshed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PermissionsResponseSwagger"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/security/failed-logins": {
+            "get": {
+                "description": "Recent failed sign-in attempts on the authenticated user's account, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Recent Failed Logins",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of attempts (max 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FailedLoginListResponseSwagger"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/security/logins": {
+            "get": {
+                "description": "Recent successful sign-ins on the authenticated user's account, newest first, with a readable device label alongside the raw user agent",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Login History",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of sign-ins (max 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginHistoryListResponseSwagger"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/security/summary": {
+            "get": {
+                "description": "Security posture of the authenticated user's account: email and phone verification, last password change and login, OTP lockout, recent failed logins and active sessions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Security Summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SecuritySummaryResponseSwagger"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/sessions": {
+            "get": {
+                "description": "Devices signed in to the authenticated user's account, newest first. The session making the request is marked current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List Sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SessionListResponseSwagger"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/sessions/{id}": {
+            "delete": {
+                "description": "Sign a device out of the authenticated user's account, its token is refused from then on",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Revoke Session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/update": {
+            "post": {
+                "description": "Update user information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "John Doe",
+                        "description": "Full name",
+                        "name": "full_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "john@example.com",
+                        "description": "Email, must match the authenticated user if given",
+                        "name": "email",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar file",
+                        "name": "avatar",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Avatar already uploaded to the service's Cloudinary account, instead of a file",
+                        "name": "avatar_url",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/webauthn/register/begin": {
+            "post": {
+                "description": "Options for navigator.credentials.create() to add a passkey to the authenticated user's account, with the ceremony_id to finish it with. The challenge expires after five minutes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "Begin Passkey Registration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/webauthn/register/finish": {
+            "post": {
+                "description": "Store the passkey the browser created for the challenge of the begin step named by ceremony_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "Finish Passkey Registration",
+                "parameters": [
+                    {
+                        "description": "Created credential",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasskeyRegisterFinishRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/availability-batch": {
+            "post": {
+                "description": "Report for each email whether it is still free to register. Emails are trimmed and lowercased, the result is keyed by that form.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Check Email Availability",
+                "parameters": [
+                    {
+                        "description": "Emails (max 50)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailAvailabilityBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailAvailabilityBatchResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/change-password-otp": {
+            "post": {
+                "description": "Change user password using OTP verification",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Change Password With OTP",
+                "parameters": [
+                    {
+                        "description": "Email, OTP \u0026 New Password",
+                        "name": "otp",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/forgot-password/send-link": {
+            "post": {
+                "description": "Email a single-use magic link for resetting the password. Always answers the same way whether or not the account exists.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send Password Reset Link",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendPasswordResetLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/forgot-password/send-otp": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Send OTP Forgot Password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "email",
+                            "sms"
+                        ],
+                        "type": "string",
+                        "description": "Where to send the code, email (default) or sms to the phone on the account",
+                        "name": "channel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/login": {
+            "post": {
+                "description": "User login with email and password. Credentials are validated for format and security.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation errors or invalid JSON format",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials or unverified account",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/oauth/{provider}": {
+            "get": {
+                "description": "Redirect to the provider's sign-in page, e.g. /auth/users/oauth/github. Only configured providers are available.",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "OAuth Login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "github",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/oauth/{provider}/callback": {
+            "get": {
+                "description": "Where the provider sends the user back. Logs in the account with the email the provider verified and sets the token cookie, then redirects to OAUTH_SUCCESS_URL when configured.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "OAuth Callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "github",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State from the login redirect",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "Found"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/precheck": {
+            "post": {
+                "description": "Start the forgot-password flow. Always answers the same way whether or not the account exists.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Forgot Password Precheck",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendPasswordResetLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/register": {
+            "post": {
+                "description": "Register a new user with avatar. All fields are validated for security and format requirements.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Register user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"John Doe\"",
+                        "description": "Full name (2-100 chars, letters/spaces/hyphens only)",
+                        "name": "full_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"john@example.com\"",
+                        "description": "Valid email address",
+                        "name": "email",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"SecurePass123!\"",
+                        "description": "Strong password (8+ chars, mixed case, numbers, symbols)",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"628112123123\"",
+                        "description": "Valid phone number (E.164 format)",
+                        "name": "phone_number",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar image file (max 10MB, JPEG/PNG/GIF only)",
+                        "name": "avatar",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Avatar already uploaded to the service's Cloudinary account, instead of a file",
+                        "name": "avatar_url",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of the CAPTCHA widget, required when CAPTCHA_PROVIDER is set",
+                        "name": "captcha_token",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponseSwagger"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidationErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email or phone already exists",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Captcha provider unreachable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/reset-password": {
+            "post": {
+                "description": "Set a new password using the token from a password reset link. The token works once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Reset Password With Token",
+                "parameters": [
+                    {
+                        "description": "Email, Token \u0026 New Password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetPasswordWithTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/webauthn/login/begin": {
+            "post": {
+                "description": "Challenge for navigator.credentials.get() to log in with one of the account's passkeys, with the ceremony_id to finish it with. The challenge expires after five minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "Begin Passkey Login",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasskeyLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/users/webauthn/login/finish": {
+            "post": {
+                "description": "Log in with the passkey assertion the browser signed for the challenge of the begin step named by ceremony_id. Sets the token cookie like a password login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "Finish Passkey Login",
+                "parameters": [
+                    {
+                        "description": "Signed assertion",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasskeyLoginFinishRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/public": {
+            "get": {
+                "description": "List verified companies that opted into the public directory. No authentication required.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Public Company Directory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Keyword",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PublicCompanyListResponseSwagger"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/public/{id}": {
+            "get": {
+                "description": "Get a verified company from the public directory. No authentication required, responses are cacheable by CDNs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Public Company By ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PublicCompanyResponseSwagger"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/public/{id}/vcard": {
+            "get": {
+                "description": "Download a verified company from the public directory as a vCard. Email and phone are only included when the owner opted in. No authentication required.",
+                "produces": [
+                    "text/vcard"
+                ],
+                "tags": [
+                    "Companies"
+                ],
+                "summary": "Public Company vCard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"60d5ec49f1c2b14c88f3c5e5\"",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "vCard document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Liveness of the service. With HEALTH_CHECK_EMAIL=true it also reports whether the SMTP server accepts connections, as \"email\": \"up\" or \"down\". No mail is sent, and an SMTP outage doesn't fail the check.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Health Check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        },
+        "/info": {
+            "get": {
+                "description": "Name, version, Go version and uptime of the running build",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Service Info",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ServiceInfoResponseSwagger"
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/users/{id}": {
+            "get": {
+                "description": "Look a user up by id. For other BYOW services, authenticated with an API key holding the internal:users:read scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Internal"
+                ],
+                "summary": "Get User (internal)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InternalUserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "description": "The generated Swagger 2.0 document as JSON, for client generators",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "OpenAPI Spec",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/time": {
+            "get": {
+                "description": "Current server time in UTC, lets clients correct clock skew before JWT exp/iat checks fail",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Server Time",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ServerTimeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verification/users/check-otp": {
+            "post": {
+                "description": "Check an OTP without consuming it. Wrong codes count towards the attempt limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Check OTP",
+                "parameters": [
+                    {
+                        "description": "Email \u0026 OTP",
+                        "name": "otp",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CheckOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verification/users/send-otp": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Send OTP Verification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "email",
+                            "sms"
+                        ],
+                        "type": "string",
+                        "description": "Where to send the code, email (default) or sms to the phone on the account",
+                        "name": "channel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verification/users/verify-otp": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Verify OTP",
+                "parameters": [
+                    {
+                        "description": "Email \u0026 OTP",
+                        "name": "otp",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AccountDeletionResponse": {
+            "type": "object",
+            "properties": {
+                "deletion_scheduled_at": {
+                    "type": "string",
+                    "example": "2023-11-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.AccountDeletionResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.AccountDeletionResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.AdminUserListResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AdminUserResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.AdminUserResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "example": "https://assets/images/img.jpg"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "full_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3b"
+                },
+                "last_login_at": {
+                    "type": "string",
+                    "example": "2024-02-01T09:00:00Z"
+                },
+                "on_boarded": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "phone_number": {
+                    "type": "string",
+                    "example": "628112123123"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "suspended": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "suspended_at": {
+                    "type": "string",
+                    "example": "2024-03-01T12:00:00Z"
+                },
+                "suspended_reason": {
+                    "type": "string",
+                    "example": "Spam reports"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.ChangeEmailRequest": {
+            "type": "object",
+            "properties": {
+                "new_email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "new_email_otp": {
+                    "type": "string",
+                    "example": "111111"
+                },
+                "otp": {
+                    "type": "string",
+                    "example": "000000"
+                }
+            }
+        },
+        "dto.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "otp": {
+                    "type": "string",
+                    "example": "000000"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "newpassword"
+                }
+            }
+        },
+        "dto.ChangePasswordStepUpRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "newpassword"
+                },
+                "otp": {
+                    "type": "string",
+                    "example": "000000"
+                }
+            }
+        },
+        "dto.ChangePasswordWithOldPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "newpassword"
+                },
+                "old_password": {
+                    "type": "string",
+                    "example": "oldpassword"
+                }
+            }
+        },
+        "dto.ChangePhoneRequest": {
+            "type": "object",
+            "properties": {
+                "new_phone": {
+                    "type": "string",
+                    "example": "628112123123"
+                },
+                "otp": {
+                    "type": "string",
+                    "example": "000000"
+                }
+            }
+        },
+        "dto.CheckOTPResponse": {
+            "type": "object",
+            "properties": {
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.CompanyAvailabilityResponse": {
+            "type": "object",
+            "properties": {
+                "email_available": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "phone_available": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.CompanyEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "company.created"
+                }
+            }
+        },
+        "dto.CompanyListResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CompanyResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.CompanyOwnerResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "example": "https://assets/images/img.jpg"
+                },
+                "full_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.CompanyRequestSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.CompanyResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.CompanyResponse": {
+            "type": "object",
+            "properties": {
+                "company_address": {
+                    "type": "string",
+                    "example": "123 BuildYow St, Tech City"
+                },
+                "company_email": {
+                    "type": "string",
+                    "example": "info@buildyow.com"
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "company_logo": {
+                    "type": "string",
+                    "example": "https://assets/images/company_logo.jpg"
+                },
+                "company_name": {
+                    "type": "string",
+                    "example": "BuildYow"
+                },
+                "company_phone": {
+                    "type": "string",
+                    "example": "628112123123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "is_primary": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "public_contact": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "public_listing": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-10-02T08:30:00Z"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.CompanySummaryListResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CompanySummaryResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.CompanySummaryResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "company_logo": {
+                    "type": "string",
+                    "example": "https://assets/images/company_logo.jpg"
+                },
+                "company_name": {
+                    "type": "string",
+                    "example": "BuildYow"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.CompanyWithOwnerResponse": {
+            "type": "object",
+            "properties": {
+                "company_address": {
+                    "type": "string",
+                    "example": "123 BuildYow St, Tech City"
+                },
+                "company_email": {
+                    "type": "string",
+                    "example": "info@buildyow.com"
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "company_logo": {
+                    "type": "string",
+                    "example": "https://assets/images/company_logo.jpg"
+                },
+                "company_name": {
+                    "type": "string",
+                    "example": "BuildYow"
+                },
+                "company_phone": {
+                    "type": "string",
+                    "example": "628112123123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "is_primary": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "owner": {
+                    "$ref": "#/definitions/dto.CompanyOwnerResponse"
+                },
+                "public_contact": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "public_listing": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-10-02T08:30:00Z"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.CompanyWithOwnerResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.CompanyWithOwnerResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "internal:users:read"
+                    ]
+                }
+            }
+        },
+        "dto.CreatedAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3b"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "byow_3f9a1c0d5e7b4a2c9f8e1d6b3a5c7e9f0a2b4c6d8e1f3a5b"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-01-16T08:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "byow_3f9a1c"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "2024-02-01T09:00:00Z"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "internal:users:read"
+                    ]
+                }
+            }
+        },
+        "dto.EmailAvailabilityBatchRequest": {
+            "type": "object",
+            "properties": {
+                "emails": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "jane@example.com",
+                        "john@example.com"
+                    ]
+                }
+            }
+        },
+        "dto.EmailAvailabilityBatchResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.ErrorDetail": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "VALIDATION_ERROR"
+                },
+                "details": {},
+                "message": {
+                    "type": "string",
+                    "example": "Validation failed"
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.ErrorResponseData"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDetail"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ERROR"
+                }
+            }
+        },
+        "dto.ErrorResponseData": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "INTERNAL_SERVER_ERROR"
+                }
+            }
+        },
+        "dto.FailedLoginListResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FailedLoginResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.FailedLoginResponse": {
+            "type": "object",
+            "properties": {
+                "attempted_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                }
+            }
+        },
+        "dto.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-15T10:45:00Z"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "token"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3b"
+                }
+            }
+        },
+        "dto.InternalUserResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "example": "https://assets/images/img.jpg"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "full_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3b"
+                },
+                "phone_number": {
+                    "type": "string",
+                    "example": "628112123123"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.LanguageResponse": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string",
+                    "example": "id"
+                },
+                "supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "en",
+                        "id"
+                    ]
+                }
+            }
+        },
+        "dto.LanguageResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.LanguageResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.LoginHistoryListResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LoginHistoryResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.LoginHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "type": "string",
+                    "example": "Chrome on Windows"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "logged_in_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "arm.adrian02@gmail.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "masukaja123"
+                }
+            }
+        },
+        "dto.MergeAccountsRequest": {
+            "type": "object",
+            "properties": {
+                "keep_email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "merge_email": {
+                    "type": "string",
+                    "example": "John@Example.com"
+                }
+            }
+        },
+        "dto.MergeAccountsResponse": {
+            "type": "object",
+            "properties": {
+                "companies_reassigned": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dto.NotificationPrefsResponse": {
+            "type": "object",
+            "properties": {
+                "email_changed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "password_changed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "verification_revoked": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "welcome": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.NotificationPrefsResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.NotificationPrefsResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.OnboardRequest": {
+            "type": "object",
+            "properties": {
+                "full_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "preferences": {
+                    "$ref": "#/definitions/dto.UserPreferences"
+                }
+            }
+        },
+        "dto.OwnershipBatchRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "60c72b2f9b1e8c001c8e4d3a",
+                        "60c72b2f9b1e8c001c8e4d3b"
+                    ]
+                }
+            }
+        },
+        "dto.OwnershipBatchResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.PasskeyLoginFinishRequest": {
+            "type": "object",
+            "properties": {
+                "ceremony_id": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "credential": {
+                    "type": "object"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                }
+            }
+        },
+        "dto.PasskeyLoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                }
+            }
+        },
+        "dto.PasskeyRegisterFinishRequest": {
+            "type": "object",
+            "properties": {
+                "ceremony_id": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "credential": {
+                    "type": "object"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Work laptop"
+                }
+            }
+        },
+        "dto.PermissionsResponse": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "companies:create",
+                        "admin:users:read"
+                    ]
+                },
+                "role": {
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "dto.PermissionsResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.PermissionsResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.PublicCompanyListResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PublicCompanyResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.PublicCompanyResponse": {
+            "type": "object",
+            "properties": {
+                "company_address": {
+                    "type": "string",
+                    "example": "123 BuildYow St, Tech City"
+                },
+                "company_email": {
+                    "type": "string",
+                    "example": "info@buildyow.com"
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                },
+                "company_logo": {
+                    "type": "string",
+                    "example": "https://assets/images/company_logo.jpg"
+                },
+                "company_name": {
+                    "type": "string",
+                    "example": "BuildYow"
+                },
+                "company_phone": {
+                    "type": "string",
+                    "example": "628112123123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.PublicCompanyResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.PublicCompanyResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.PurgeCompaniesResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.RebuildIndexesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "indexes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "email_unique",
+                        "company_email_unique"
+                    ]
+                }
+            }
+        },
+        "dto.ReencryptOTPsRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "example": "reencrypt"
+                }
+            }
+        },
+        "dto.ReencryptOTPsResponse": {
+            "type": "object",
+            "properties": {
+                "invalidated": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "reencrypt"
+                },
+                "reencrypted": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.ResetOTPAttemptsResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "previous_attempts": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "dto.ResetPasswordWithTokenRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "new_password": {
+                    "type": "string",
+                    "example": "newpassword"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "3f2a..."
+                }
+            }
+        },
+        "dto.SecuritySummaryResponse": {
+            "type": "object",
+            "properties": {
+                "active_sessions": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_login_at": {
+                    "type": "string",
+                    "example": "2023-10-02T08:15:00Z"
+                },
+                "otp_locked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "password_changed_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "password_stale": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "phone_verified": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "recent_failed_logins": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.ChangeEmailRequest": {
+        },
+        "dto.SecuritySummaryResponseSwagger": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/dto.SecuritySummaryResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "SUCCESS"
+                }
+            }
+        },
+        "dto.SendNewEmailOTPRequest": {
             "type": "object",
             "properties": {
                 "new_email": {
                     "type": "string",
                     "example": "john.doe@example.com"
-                },
-                "otp": {
-                    "type": "string",
-                    "example": "000000"
                 }
             }
         },
-        "dto.ChangePasswordRequest": {
+        "dto.SendPasswordResetLinkRequest": {
             "type": "object",
             "properties": {
                 "email": {
                     "type": "string",
                     "example": "john@example.com"
-                },
-                "otp": {
-                    "type": "string",
-                    "example": "000000"
-                },
-                "password": {
-                    "type": "string",
-                    "example": "newpassword"
                 }
             }
         },
-        "dto.ChangePasswordWithOldPasswordRequest": {
+        "dto.ServerTimeResponse": {
             "type": "object",
             "properties": {
-                "new_password": {
-                    "type": "string",
-                    "example": "newpassword"
+                "epoch": {
+                    "type": "integer",
+                    "example": 1696161600
                 },
-                "old_password": {
+                "utc": {
                     "type": "string",
-                    "example": "oldpassword"
+                    "example": "2023-10-01T12:00:00Z"
                 }
             }
         },
-        "dto.ChangePhoneRequest": {
+        "dto.ServiceInfoResponse": {
             "type": "object",
             "properties": {
-                "new_phone": {
+                "go_version": {
                     "type": "string",
-                    "example": "628112123123"
+                    "example": "go1.24.0"
                 },
-                "otp": {
+                "name": {
                     "type": "string",
-                    "example": "000000"
+                    "example": "byow-user-service"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2023-10-01T12:00:00Z"
+                },
+                "uptime_seconds": {
+                    "type": "number",
+                    "example": 3600.5
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0"
                 }
             }
         },
-        "dto.CompanyListResponseSwagger": {
+        "dto.ServiceInfoResponseSwagger": {
             "type": "object",
             "properties": {
                 "code": {
@@ -799,10 +4762,7 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.CompanyResponse"
-                    }
+                    "$ref": "#/definitions/dto.ServiceInfoResponse"
                 },
                 "status": {
                     "type": "string",
@@ -810,7 +4770,7 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.CompanyRequestSwagger": {
+        "dto.SessionListResponseSwagger": {
             "type": "object",
             "properties": {
                 "code": {
@@ -818,7 +4778,10 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {
-                    "$ref": "#/definitions/dto.CompanyResponse"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SessionResponse"
+                    }
                 },
                 "status": {
                     "type": "string",
@@ -826,116 +4789,234 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.CompanyResponse": {
+        "dto.SessionResponse": {
             "type": "object",
             "properties": {
-                "company_address": {
-                    "type": "string",
-                    "example": "123 BuildYow St, Tech City"
-                },
-                "company_email": {
-                    "type": "string",
-                    "example": "info@buildyow.com"
+                "current": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "company_id": {
+                "device": {
                     "type": "string",
-                    "example": "60c72b2f9b1e8c001c8e4d3a"
+                    "example": "Chrome on Windows"
                 },
-                "company_logo": {
+                "expires_at": {
                     "type": "string",
-                    "example": "https://assets/images/company_logo.jpg"
+                    "example": "2023-10-02T12:00:00Z"
                 },
-                "company_name": {
+                "id": {
                     "type": "string",
-                    "example": "BuildYow"
+                    "example": "64f1c2a9e4b0a1b2c3d4e5f6"
                 },
-                "company_phone": {
+                "ip": {
                     "type": "string",
-                    "example": "628112123123"
+                    "example": "203.0.113.7"
                 },
-                "created_at": {
+                "issued_at": {
                     "type": "string",
                     "example": "2023-10-01T12:00:00Z"
                 },
-                "user_id": {
+                "user_agent": {
                     "type": "string",
-                    "example": "60c72b2f9b1e8c001c8e4d3a"
-                },
-                "verified": {
-                    "type": "boolean",
-                    "example": false
+                    "example": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
                 }
             }
         },
-        "dto.ErrorDetail": {
+        "dto.SetRoleRequest": {
             "type": "object",
             "properties": {
-                "code": {
+                "role": {
                     "type": "string",
-                    "example": "VALIDATION_ERROR"
+                    "example": "admin"
+                }
+            }
+        },
+        "dto.SetRoleResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
                 },
-                "details": {},
-                "message": {
+                "previous_role": {
                     "type": "string",
-                    "example": "Validation failed"
+                    "example": "admin"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "token_version": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "dto.ErrorResponse": {
+        "dto.SuccessResponse": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
-                    "example": 400
-                },
-                "data": {
-                    "$ref": "#/definitions/dto.ErrorResponseData"
-                },
-                "error": {
-                    "$ref": "#/definitions/dto.ErrorDetail"
+                    "example": 200
                 },
+                "data": {},
                 "status": {
                     "type": "string",
-                    "example": "ERROR"
+                    "example": "SUCCESS"
                 }
             }
         },
-        "dto.ErrorResponseData": {
+        "dto.SuspendUserRequest": {
             "type": "object",
             "properties": {
-                "message": {
+                "reason": {
                     "type": "string",
-                    "example": "INTERNAL_SERVER_ERROR"
+                    "example": "Spam reports"
                 }
             }
         },
-        "dto.LoginRequest": {
+        "dto.UnverifyCompanyRequest": {
             "type": "object",
             "properties": {
-                "email": {
+                "reason": {
                     "type": "string",
-                    "example": "arm.adrian02@gmail.com"
-                },
-                "password": {
+                    "example": "Registration documents were forged"
+                }
+            }
+        },
+        "dto.UpdateLanguageRequest": {
+            "type": "object",
+            "properties": {
+                "language": {
                     "type": "string",
-                    "example": "masukaja123"
+                    "example": "id"
                 }
             }
         },
-        "dto.SuccessResponse": {
+        "dto.UpdateNotificationPrefsRequest": {
+            "type": "object",
+            "properties": {
+                "email_changed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "password_changed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "verification_revoked": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "welcome": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.UploadConfigResponse": {
+            "type": "object",
+            "properties": {
+                "allowed_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "image/jpeg",
+                        "image/png",
+                        "image/gif"
+                    ]
+                },
+                "max_height": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "max_size_bytes": {
+                    "type": "integer",
+                    "example": 10485760
+                },
+                "max_width": {
+                    "description": "Zero when the dimension isn't limited",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "dto.UploadConfigResponseSwagger": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
                     "example": 200
                 },
-                "data": {},
+                "data": {
+                    "$ref": "#/definitions/dto.UploadConfigResponse"
+                },
                 "status": {
                     "type": "string",
                     "example": "SUCCESS"
                 }
             }
         },
+        "dto.UserExportRecord": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "on_boarded": {
+                    "type": "boolean"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.UserPreferences": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "id"
+                    ],
+                    "example": "id"
+                },
+                "theme": {
+                    "type": "string",
+                    "enum": [
+                        "light",
+                        "dark",
+                        "system"
+                    ],
+                    "example": "dark"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                }
+            }
+        },
         "dto.UserResponse": {
             "type": "object",
             "properties": {
@@ -959,10 +5040,23 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": false
                 },
+                "password_changed_at": {
+                    "description": "Only on profile responses, omitted for accounts that predate it",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
                 "phone_number": {
                     "type": "string",
                     "example": "628112123123"
                 },
+                "preferences": {
+                    "description": "Omitted until the user picks at least one preference",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.UserPreferences"
+                        }
+                    ]
+                },
                 "token": {
                     "type": "string",
                     "example": "token"
@@ -1037,6 +5131,43 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.VerifyCompaniesRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "60c72b2f9b1e8c001c8e4d3a",
+                        "60c72b2f9b1e8c001c8e4d3b"
+                    ]
+                },
+                "verified": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.VerifyCompaniesResponse": {
+            "type": "object",
+            "properties": {
+                "modified": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "skipped_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "not-an-id"
+                    ]
+                }
+            }
+        },
         "dto.VerifyOTPRequest": {
             "type": "object",
             "properties": {
@@ -1049,6 +5180,53 @@ const docTemplate = `{
                     "example": "000000"
                 }
             }
+        },
+        "jwt.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string",
+                    "example": "RS256"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string",
+                    "example": "AQAB"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "RSA"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwt.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwt.JWK"
+                    }
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey lets another BYOW service call internal endpoints without a user
+// token. Only the SHA-256 of the key is stored, the key itself is shown once
+// when it is created.
+type APIKey struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"`
+	// Prefix is the start of the key, enough to tell keys apart in a list
+	Prefix     string     `bson:"prefix" json:"prefix"`
+	Hash       string     `bson:"hash" json:"-"`
+	Scopes     []string   `bson:"scopes" json:"scopes"`
+	CreatedBy  string     `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key was given scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Retention classes decide how long an audit entry is kept
+const (
+	RetentionStandard = "standard"
+	RetentionCritical = "critical"
+)
+
+type AuditLog struct {
+	ID             primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	ActorID        string                 `bson:"actor_id" json:"actor_id"`
+	Action         string                 `bson:"action" json:"action"`
+	TargetID       string                 `bson:"target_id,omitempty" json:"target_id,omitempty"`
+	Metadata       map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	RetentionClass string                 `bson:"retention_class" json:"retention_class"`
+	CreatedAt      time.Time              `bson:"created_at" json:"created_at"`
+}
@@ -10,10 +10,26 @@ type Company struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	UserID         string             `bson:"user_id"`
 	CompanyName    string             `bson:"company_name"`
-	CompanyEmail   string             `bson:"company_email"`
-	CompanyPhone   string             `bson:"company_phone"`
+	NameNormalized string             `bson:"name_normalized"` // folded CompanyName searches run against, set by the repository
+	CompanyEmail   string             `bson:"company_email,omitempty"`
+	CompanyPhone   string             `bson:"company_phone,omitempty"`
 	CompanyAddress string             `bson:"company_address"`
 	CompanyLogo    string             `bson:"company_logo"`
 	Verified       bool               `bson:"verified"`
+	PublicListing  bool               `bson:"public_listing"` // include in the public directory once verified
+	PublicContact  bool               `bson:"public_contact"` // expose email and phone in the public directory
+	IsPrimary      bool               `bson:"is_primary"`     // the owner's default company, at most one per user
 	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at,omitempty"` // stamped by the repository on every write
+	DeletedAt      *time.Time         `bson:"deleted_at,omitempty"`
+}
+
+// LastUpdated is when the company was last written. Companies created before
+// updated_at was stamped on create have none until their first edit, their
+// creation time stands in.
+func (c *Company) LastUpdated() time.Time {
+	if c.UpdatedAt.IsZero() {
+		return c.CreatedAt
+	}
+	return c.UpdatedAt
 }
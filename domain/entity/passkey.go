@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Passkey ceremonies, a challenge issued for one can't answer the other
+const (
+	PasskeyRegistration = "registration"
+	PasskeyLogin        = "login"
+)
+
+// Passkey is a WebAuthn credential a user registered to log in with. Only
+// the public key is known to the service.
+type Passkey struct {
+	CredentialID    []byte    `bson:"credential_id"`
+	PublicKey       []byte    `bson:"public_key"`
+	AttestationType string    `bson:"attestation_type,omitempty"`
+	Transports      []string  `bson:"transports,omitempty"`
+	AAGUID          []byte    `bson:"aaguid,omitempty"`
+	SignCount       uint32    `bson:"sign_count"`
+	BackupEligible  bool      `bson:"backup_eligible"`
+	BackupState     bool      `bson:"backup_state"`
+	Name            string    `bson:"name,omitempty"`
+	CreatedAt       time.Time `bson:"created_at"`
+	LastUsedAt      time.Time `bson:"last_used_at,omitempty"`
+}
+
+// PasskeyCeremony is a passkey registration or login waiting for the
+// browser's answer. ID is handed to the client with the challenge and
+// names the ceremony being answered. Session holds the WebAuthn session
+// data, challenge included, as JSON. Ceremonies are stored with the user
+// but only read and written through the repository's ceremony methods.
+type PasskeyCeremony struct {
+	ID        string    `bson:"id"`
+	Kind      string    `bson:"kind"`
+	Session   []byte    `bson:"session"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is one signed-in token, recorded at login so the user can see
+// where they're signed in and sign a device out
+type Session struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	JTI       string             `bson:"jti" json:"-"`
+	IP        string             `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IssuedAt  time.Time          `bson:"issued_at" json:"issued_at"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the session's token is still accepted at now
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
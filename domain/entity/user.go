@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+)
 
 type User struct {
 	ID           string    `bson:"_id,omitempty"`
@@ -13,6 +17,79 @@ type User struct {
 	OTP          string    `bson:"otp,omitempty"`
 	OTPType      string    `bson:"otp_type,omitempty"`
 	OTPExpiresAt time.Time `bson:"otp_expires_at,omitempty"`
+	OTPAttempts  int       `bson:"otp_attempts,omitempty"`
 	Verified     bool      `bson:"verified"`
+	Role         string    `bson:"role,omitempty"`
+	TokenVersion int       `bson:"token_version,omitempty"` // bumped to invalidate every token issued so far
 	CreatedAt    time.Time `bson:"created_at"`
+
+	// Number the current OTP was texted to, empty when it was emailed
+	OTPPhone string `bson:"otp_phone,omitempty"`
+	// Set once a code texted to PhoneNumber is confirmed, cleared when the
+	// number changes
+	PhoneVerified bool `bson:"phone_verified"`
+
+	// Display preferences chosen while onboarding
+	Preferences UserPreferences `bson:"preferences,omitempty"`
+	// Never omitted, so unmuting a notice is written back
+	Notifications NotificationPrefs `bson:"notifications"`
+
+	// Folded Fullname that name searches run against, set by the repository
+	NameNormalized string `bson:"name_normalized"`
+
+	// Zero until the first password login or password change
+	LastLoginAt       time.Time `bson:"last_login_at,omitempty"`
+	PasswordChangedAt time.Time `bson:"password_changed_at,omitempty"`
+
+	// Set while an admin has suspended the account, it can't log in until
+	// it is reactivated
+	SuspendedAt     time.Time `bson:"suspended_at,omitempty"`
+	SuspendedReason string    `bson:"suspended_reason,omitempty"`
+
+	// Set while the user's own request to delete the account is pending,
+	// the account is removed once this passes unless they log in first
+	DeletionScheduledAt time.Time `bson:"deletion_scheduled_at,omitempty"`
+
+	// Only the SHA-256 of the magic-link reset token is stored, never the token
+	PasswordResetTokenHash string    `bson:"password_reset_token_hash,omitempty"`
+	PasswordResetExpiresAt time.Time `bson:"password_reset_expires_at,omitempty"`
+
+	// Address an email change is waiting to move to, with the encrypted
+	// code sent there to prove the user controls it
+	PendingEmail          string    `bson:"pending_email,omitempty"`
+	PendingEmailOTP       string    `bson:"pending_email_otp,omitempty"`
+	PendingEmailExpiresAt time.Time `bson:"pending_email_expires_at,omitempty"`
+
+	// Passkeys the user can log in with instead of a password
+	Passkeys []Passkey `bson:"passkeys,omitempty"`
+}
+
+// Suspended reports whether an admin suspended the account
+func (u *User) Suspended() bool {
+	return !u.SuspendedAt.IsZero()
+}
+
+// UserPreferences holds the optional display settings of a user
+type UserPreferences struct {
+	Language string `bson:"language,omitempty"`
+	Theme    string `bson:"theme,omitempty"`
+	Timezone string `bson:"timezone,omitempty"`
+}
+
+// NotificationPrefs records which optional notices the user muted, so every
+// notice is sent to accounts that never changed them
+type NotificationPrefs struct {
+	MuteWelcome             bool `bson:"mute_welcome"`
+	MuteVerificationRevoked bool `bson:"mute_verification_revoked"`
+}
+
+// Allows reports whether the notice may be emailed, security notices always are
+func (p NotificationPrefs) Allows(notice string) bool {
+	switch notice {
+	case constants.NOTICE_WELCOME:
+		return !p.MuteWelcome
+	case constants.NOTICE_VERIFICATION_REVOKED:
+		return !p.MuteVerificationRevoked
+	}
+	return true
 }
@@ -45,6 +45,14 @@ func NewUnauthorizedError(message string) *AppError {
 	}
 }
 
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:    "FORBIDDEN",
+		Message: message,
+		Status:  http.StatusForbidden,
+	}
+}
+
 func NewConflictError(message string) *AppError {
 	return &AppError{
 		Code:    "CONFLICT",
@@ -72,35 +80,55 @@ func NewBadRequestError(message string) *AppError {
 // Specific business logic errors - synced with constants.go format
 var (
 	// User authentication errors
-	ErrUserNotFound           = &AppError{Code: "NOT_FOUND", Message: "User not found", Status: http.StatusNotFound}
-	ErrInvalidCredentials     = &AppError{Code: "INVALID_CREDENTIALS", Message: "Invalid email or password", Status: http.StatusUnauthorized}
-	ErrUserNotVerified        = &AppError{Code: "USER_NOT_VERIFIED", Message: "User account not verified", Status: http.StatusUnauthorized}
-	ErrInvalidOldPassword     = &AppError{Code: "INVALID_OLD_PASSWORD", Message: "Invalid old password", Status: http.StatusBadRequest}
-	
+	ErrUserNotFound       = &AppError{Code: "NOT_FOUND", Message: "User not found", Status: http.StatusNotFound}
+	ErrInvalidCredentials = &AppError{Code: "INVALID_CREDENTIALS", Message: "Invalid email or password", Status: http.StatusUnauthorized}
+	ErrUserNotVerified    = &AppError{Code: "USER_NOT_VERIFIED", Message: "User account not verified", Status: http.StatusUnauthorized}
+	ErrInvalidOldPassword = &AppError{Code: "INVALID_OLD_PASSWORD", Message: "Invalid old password", Status: http.StatusBadRequest}
+
+	// Authorization errors
+	ErrUnauthorized = &AppError{Code: "UNAUTHORIZED", Message: "You can only modify your own account", Status: http.StatusUnauthorized}
+	ErrForbidden    = &AppError{Code: "FORBIDDEN", Message: "You do not have access to this resource", Status: http.StatusForbidden}
+
 	// Registration errors
-	ErrEmailAlreadyExists           = &AppError{Code: "EMAIL_ALREADY_REGISTERED", Message: "Email already registered", Status: http.StatusConflict}
-	ErrPhoneAlreadyExists           = &AppError{Code: "PHONE_ALREADY_REGISTERED", Message: "Phone already registered", Status: http.StatusConflict}
+	ErrEmailAlreadyExists            = &AppError{Code: "EMAIL_ALREADY_REGISTERED", Message: "Email already registered", Status: http.StatusConflict}
+	ErrPhoneAlreadyExists            = &AppError{Code: "PHONE_ALREADY_REGISTERED", Message: "Phone already registered", Status: http.StatusConflict}
 	ErrEmailOrPhoneAlreadyRegistered = &AppError{Code: "EMAIL_OR_PHONE_ALREADY_REGISTERED", Message: "Email or phone already registered", Status: http.StatusConflict}
-	
+
 	// OTP errors
-	ErrInvalidOTP             = &AppError{Code: "OTP_INVALID", Message: "Invalid OTP", Status: http.StatusBadRequest}
-	ErrExpiredOTP             = &AppError{Code: "OTP_EXPIRED", Message: "OTP expired", Status: http.StatusBadRequest}
-	
+	ErrInvalidOTP         = &AppError{Code: "OTP_INVALID", Message: "Invalid OTP", Status: http.StatusBadRequest}
+	ErrExpiredOTP         = &AppError{Code: "OTP_EXPIRED", Message: "OTP expired", Status: http.StatusBadRequest}
+	ErrTooManyOTPAttempts = &AppError{Code: "OTP_TOO_MANY_ATTEMPTS", Message: "Too many OTP attempts, request a new OTP", Status: http.StatusTooManyRequests}
+
 	// Token errors
-	ErrInvalidToken           = &AppError{Code: "INVALID_TOKEN", Message: "Invalid or expired token", Status: http.StatusUnauthorized}
-	ErrInvalidTokenClaims     = &AppError{Code: "INVALID_TOKEN_CLAIMS", Message: "Invalid token claims", Status: http.StatusUnauthorized}
-	
+	ErrInvalidToken         = &AppError{Code: "INVALID_TOKEN", Message: "Invalid or expired token", Status: http.StatusUnauthorized}
+	ErrInvalidTokenClaims   = &AppError{Code: "INVALID_TOKEN_CLAIMS", Message: "Invalid token claims", Status: http.StatusUnauthorized}
+	ErrSessionIdle          = &AppError{Code: "SESSION_IDLE", Message: "Session expired after inactivity, please log in again", Status: http.StatusUnauthorized}
+	ErrSessionNotFound      = &AppError{Code: "SESSION_NOT_FOUND", Message: "Session not found", Status: http.StatusNotFound}
+	ErrPasskeyInvalid       = &AppError{Code: "PASSKEY_INVALID", Message: "Passkey verification failed, please try again", Status: http.StatusUnauthorized}
+	ErrOAuthFailed          = &AppError{Code: "OAUTH_FAILED", Message: "Sign-in with the provider failed, please try again", Status: http.StatusUnauthorized}
+	ErrAccountSuspended     = &AppError{Code: "ACCOUNT_SUSPENDED", Message: "This account has been suspended", Status: http.StatusForbidden}
+	ErrInvalidAPIKey        = &AppError{Code: "INVALID_API_KEY", Message: "Invalid or revoked API key", Status: http.StatusUnauthorized}
+	ErrAPIKeyNotFound       = &AppError{Code: "API_KEY_NOT_FOUND", Message: "API key not found", Status: http.StatusNotFound}
+	ErrOAuthAccountNotFound = &AppError{Code: "OAUTH_ACCOUNT_NOT_FOUND", Message: "No account uses this email, please register first", Status: http.StatusNotFound}
+
 	// Validation errors
 	ErrEmailRequired          = &AppError{Code: "EMAIL_REQUIRED", Message: "Email is required", Status: http.StatusBadRequest}
 	ErrPhoneRequired          = &AppError{Code: "PHONE_REQUIRED", Message: "Phone number is required", Status: http.StatusBadRequest}
+	ErrPhoneNotTextable       = &AppError{Code: "PHONE_NOT_TEXTABLE", Message: "The phone number can't receive SMS, add the country code or use email", Status: http.StatusBadRequest}
 	ErrAllFieldsRequired      = &AppError{Code: "ALL_FIELD_REQUIRED", Message: "All fields are required", Status: http.StatusBadRequest}
 	ErrEmailOtpRequired       = &AppError{Code: "EMAIL_OTP_REQUIRED", Message: "Email and OTP are required", Status: http.StatusBadRequest}
-	
+	ErrNewEmailNotConfirmed   = &AppError{Code: "NEW_EMAIL_NOT_CONFIRMED", Message: "Request a confirmation code for the new email first", Status: http.StatusBadRequest}
+	ErrEmailDomainUnreachable = &AppError{Code: "EMAIL_DOMAIN_UNREACHABLE", Message: "Email domain can't receive mail, check the address for typos", Status: http.StatusBadRequest}
+	ErrCaptchaRequired        = &AppError{Code: "CAPTCHA_REQUIRED", Message: "Captcha is required", Status: http.StatusBadRequest}
+	ErrCaptchaInvalid         = &AppError{Code: "CAPTCHA_INVALID", Message: "Captcha verification failed, please try again", Status: http.StatusBadRequest}
+	ErrCaptchaUnavailable     = &AppError{Code: "CAPTCHA_UNAVAILABLE", Message: "Captcha can't be verified right now, please try again later", Status: http.StatusServiceUnavailable}
+
 	// File upload errors
-	ErrInvalidFileFormat      = &AppError{Code: "INVALID_FILE_FORMAT", Message: "Invalid file format", Status: http.StatusBadRequest}
-	ErrFileSizeExceeded       = &AppError{Code: "FILE_SIZE_EXCEEDED", Message: "File size exceeds limit", Status: http.StatusBadRequest}
-	ErrFailedParseMultipart   = &AppError{Code: "FAILED_PARSE_MULTIPART", Message: "Failed to parse multipart form", Status: http.StatusBadRequest}
-	
+	ErrInvalidFileFormat    = &AppError{Code: "INVALID_FILE_FORMAT", Message: "Invalid file format", Status: http.StatusBadRequest}
+	ErrFileSizeExceeded     = &AppError{Code: "FILE_SIZE_EXCEEDED", Message: "File size exceeds limit", Status: http.StatusBadRequest}
+	ErrFailedParseMultipart = &AppError{Code: "FAILED_PARSE_MULTIPART", Message: "Failed to parse multipart form", Status: http.StatusBadRequest}
+	ErrUploadIncomplete     = &AppError{Code: "UPLOAD_INCOMPLETE", Message: "Upload incomplete, please try again", Status: http.StatusBadRequest}
+
 	// General errors
 	ErrFetchFailed            = &AppError{Code: "FETCH_FAILED", Message: "Failed to fetch data", Status: http.StatusInternalServerError}
 	ErrInvalidId              = &AppError{Code: "INVALID_ID", Message: "Invalid ID format", Status: http.StatusBadRequest}
@@ -109,6 +137,13 @@ var (
 	ErrDatabaseOperation      = &AppError{Code: "DATABASE_ERROR", Message: "Database operation failed", Status: http.StatusInternalServerError}
 	ErrEmailDeliveryFailed    = &AppError{Code: "EMAIL_DELIVERY_FAILED", Message: "Email delivery failed", Status: http.StatusInternalServerError}
 	ErrCloudinaryUploadFailed = &AppError{Code: "CLOUDINARY_UPLOAD_FAILED", Message: "File upload failed", Status: http.StatusInternalServerError}
+	ErrMaintenanceMode        = &AppError{Code: "MAINTENANCE_MODE", Message: "Service is under maintenance, please try again later", Status: http.StatusServiceUnavailable}
+	ErrFeatureDisabled        = &AppError{Code: "FEATURE_DISABLED", Message: "This feature is currently disabled", Status: http.StatusNotFound}
+	ErrRateLimited            = &AppError{Code: "RATE_LIMITED", Message: "Too many requests, please try again later", Status: http.StatusTooManyRequests}
+	ErrOperationInProgress    = &AppError{Code: "OPERATION_IN_PROGRESS", Message: "Operation already in progress, try again later", Status: http.StatusConflict}
+	ErrRequestRejected        = &AppError{Code: "REQUEST_REJECTED", Message: "Request rejected", Status: http.StatusBadRequest}
+	ErrRequestTooLarge        = &AppError{Code: "REQUEST_TOO_LARGE", Message: "Request body is too large", Status: http.StatusRequestEntityTooLarge}
+	ErrAlreadyOnboarded       = &AppError{Code: "ALREADY_ONBOARDED", Message: "User has already completed onboarding", Status: http.StatusConflict}
 )
 
 // Helper function to check if error is of specific type
@@ -122,11 +157,11 @@ func WrapError(err error, message string) *AppError {
 	if appErr, ok := IsAppError(err); ok {
 		return appErr
 	}
-	
+
 	return &AppError{
 		Code:    "INTERNAL_ERROR",
 		Message: message,
 		Status:  http.StatusInternalServerError,
 		Details: err.Error(),
 	}
-}
\ No newline at end of file
+}
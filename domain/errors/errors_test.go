@@ -46,7 +46,7 @@ func TestAppError_Error(t *testing.T) {
 func TestNewValidationError(t *testing.T) {
 	message := "validation failed"
 	err := NewValidationError(message)
-	
+
 	if err.Code != "VALIDATION_ERROR" {
 		t.Errorf("Expected code 'VALIDATION_ERROR', got %v", err.Code)
 	}
@@ -61,7 +61,7 @@ func TestNewValidationError(t *testing.T) {
 func TestNewNotFoundError(t *testing.T) {
 	resource := "user"
 	err := NewNotFoundError(resource)
-	
+
 	if err.Code != "NOT_FOUND" {
 		t.Errorf("Expected code 'NOT_FOUND', got %v", err.Code)
 	}
@@ -76,7 +76,7 @@ func TestNewNotFoundError(t *testing.T) {
 func TestNewUnauthorizedError(t *testing.T) {
 	message := "unauthorized access"
 	err := NewUnauthorizedError(message)
-	
+
 	if err.Code != "UNAUTHORIZED" {
 		t.Errorf("Expected code 'UNAUTHORIZED', got %v", err.Code)
 	}
@@ -88,10 +88,25 @@ func TestNewUnauthorizedError(t *testing.T) {
 	}
 }
 
+func TestNewForbiddenError(t *testing.T) {
+	message := "forbidden"
+	err := NewForbiddenError(message)
+
+	if err.Code != "FORBIDDEN" {
+		t.Errorf("Expected code 'FORBIDDEN', got %v", err.Code)
+	}
+	if err.Message != message {
+		t.Errorf("Expected message '%v', got %v", message, err.Message)
+	}
+	if err.Status != http.StatusForbidden {
+		t.Errorf("Expected status %v, got %v", http.StatusForbidden, err.Status)
+	}
+}
+
 func TestNewConflictError(t *testing.T) {
 	message := "resource conflict"
 	err := NewConflictError(message)
-	
+
 	if err.Code != "CONFLICT" {
 		t.Errorf("Expected code 'CONFLICT', got %v", err.Code)
 	}
@@ -106,7 +121,7 @@ func TestNewConflictError(t *testing.T) {
 func TestNewInternalError(t *testing.T) {
 	message := "internal server error"
 	err := NewInternalError(message)
-	
+
 	if err.Code != "INTERNAL_ERROR" {
 		t.Errorf("Expected code 'INTERNAL_ERROR', got %v", err.Code)
 	}
@@ -121,7 +136,7 @@ func TestNewInternalError(t *testing.T) {
 func TestNewBadRequestError(t *testing.T) {
 	message := "bad request"
 	err := NewBadRequestError(message)
-	
+
 	if err.Code != "BAD_REQUEST" {
 		t.Errorf("Expected code 'BAD_REQUEST', got %v", err.Code)
 	}
@@ -193,7 +208,7 @@ func TestWrapError(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := WrapError(tt.err, tt.message)
-			
+
 			if tt.isApp {
 				// Should return original AppError
 				if result.Code != "TEST" {
@@ -229,20 +244,38 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrInvalidCredentials", ErrInvalidCredentials, "INVALID_CREDENTIALS", http.StatusUnauthorized},
 		{"ErrUserNotVerified", ErrUserNotVerified, "USER_NOT_VERIFIED", http.StatusUnauthorized},
 		{"ErrInvalidOldPassword", ErrInvalidOldPassword, "INVALID_OLD_PASSWORD", http.StatusBadRequest},
+		{"ErrUnauthorized", ErrUnauthorized, "UNAUTHORIZED", http.StatusUnauthorized},
+		{"ErrForbidden", ErrForbidden, "FORBIDDEN", http.StatusForbidden},
 		{"ErrEmailAlreadyExists", ErrEmailAlreadyExists, "EMAIL_ALREADY_REGISTERED", http.StatusConflict},
 		{"ErrPhoneAlreadyExists", ErrPhoneAlreadyExists, "PHONE_ALREADY_REGISTERED", http.StatusConflict},
 		{"ErrEmailOrPhoneAlreadyRegistered", ErrEmailOrPhoneAlreadyRegistered, "EMAIL_OR_PHONE_ALREADY_REGISTERED", http.StatusConflict},
 		{"ErrInvalidOTP", ErrInvalidOTP, "OTP_INVALID", http.StatusBadRequest},
 		{"ErrExpiredOTP", ErrExpiredOTP, "OTP_EXPIRED", http.StatusBadRequest},
+		{"ErrTooManyOTPAttempts", ErrTooManyOTPAttempts, "OTP_TOO_MANY_ATTEMPTS", http.StatusTooManyRequests},
 		{"ErrInvalidToken", ErrInvalidToken, "INVALID_TOKEN", http.StatusUnauthorized},
 		{"ErrInvalidTokenClaims", ErrInvalidTokenClaims, "INVALID_TOKEN_CLAIMS", http.StatusUnauthorized},
+		{"ErrSessionIdle", ErrSessionIdle, "SESSION_IDLE", http.StatusUnauthorized},
+		{"ErrSessionNotFound", ErrSessionNotFound, "SESSION_NOT_FOUND", http.StatusNotFound},
+		{"ErrPasskeyInvalid", ErrPasskeyInvalid, "PASSKEY_INVALID", http.StatusUnauthorized},
+		{"ErrOAuthFailed", ErrOAuthFailed, "OAUTH_FAILED", http.StatusUnauthorized},
+		{"ErrOAuthAccountNotFound", ErrOAuthAccountNotFound, "OAUTH_ACCOUNT_NOT_FOUND", http.StatusNotFound},
+		{"ErrAccountSuspended", ErrAccountSuspended, "ACCOUNT_SUSPENDED", http.StatusForbidden},
+		{"ErrInvalidAPIKey", ErrInvalidAPIKey, "INVALID_API_KEY", http.StatusUnauthorized},
+		{"ErrAPIKeyNotFound", ErrAPIKeyNotFound, "API_KEY_NOT_FOUND", http.StatusNotFound},
 		{"ErrEmailRequired", ErrEmailRequired, "EMAIL_REQUIRED", http.StatusBadRequest},
 		{"ErrPhoneRequired", ErrPhoneRequired, "PHONE_REQUIRED", http.StatusBadRequest},
+		{"ErrPhoneNotTextable", ErrPhoneNotTextable, "PHONE_NOT_TEXTABLE", http.StatusBadRequest},
 		{"ErrAllFieldsRequired", ErrAllFieldsRequired, "ALL_FIELD_REQUIRED", http.StatusBadRequest},
 		{"ErrEmailOtpRequired", ErrEmailOtpRequired, "EMAIL_OTP_REQUIRED", http.StatusBadRequest},
+		{"ErrNewEmailNotConfirmed", ErrNewEmailNotConfirmed, "NEW_EMAIL_NOT_CONFIRMED", http.StatusBadRequest},
+		{"ErrEmailDomainUnreachable", ErrEmailDomainUnreachable, "EMAIL_DOMAIN_UNREACHABLE", http.StatusBadRequest},
+		{"ErrCaptchaRequired", ErrCaptchaRequired, "CAPTCHA_REQUIRED", http.StatusBadRequest},
+		{"ErrCaptchaInvalid", ErrCaptchaInvalid, "CAPTCHA_INVALID", http.StatusBadRequest},
+		{"ErrCaptchaUnavailable", ErrCaptchaUnavailable, "CAPTCHA_UNAVAILABLE", http.StatusServiceUnavailable},
 		{"ErrInvalidFileFormat", ErrInvalidFileFormat, "INVALID_FILE_FORMAT", http.StatusBadRequest},
 		{"ErrFileSizeExceeded", ErrFileSizeExceeded, "FILE_SIZE_EXCEEDED", http.StatusBadRequest},
 		{"ErrFailedParseMultipart", ErrFailedParseMultipart, "FAILED_PARSE_MULTIPART", http.StatusBadRequest},
+		{"ErrUploadIncomplete", ErrUploadIncomplete, "UPLOAD_INCOMPLETE", http.StatusBadRequest},
 		{"ErrFetchFailed", ErrFetchFailed, "FETCH_FAILED", http.StatusInternalServerError},
 		{"ErrInvalidId", ErrInvalidId, "INVALID_ID", http.StatusBadRequest},
 		{"ErrEncryptionFailed", ErrEncryptionFailed, "ENCRYPTION_FAILED", http.StatusInternalServerError},
@@ -250,6 +283,13 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrDatabaseOperation", ErrDatabaseOperation, "DATABASE_ERROR", http.StatusInternalServerError},
 		{"ErrEmailDeliveryFailed", ErrEmailDeliveryFailed, "EMAIL_DELIVERY_FAILED", http.StatusInternalServerError},
 		{"ErrCloudinaryUploadFailed", ErrCloudinaryUploadFailed, "CLOUDINARY_UPLOAD_FAILED", http.StatusInternalServerError},
+		{"ErrMaintenanceMode", ErrMaintenanceMode, "MAINTENANCE_MODE", http.StatusServiceUnavailable},
+		{"ErrFeatureDisabled", ErrFeatureDisabled, "FEATURE_DISABLED", http.StatusNotFound},
+		{"ErrOperationInProgress", ErrOperationInProgress, "OPERATION_IN_PROGRESS", http.StatusConflict},
+		{"ErrRequestRejected", ErrRequestRejected, "REQUEST_REJECTED", http.StatusBadRequest},
+		{"ErrRequestTooLarge", ErrRequestTooLarge, "REQUEST_TOO_LARGE", http.StatusRequestEntityTooLarge},
+		{"ErrAlreadyOnboarded", ErrAlreadyOnboarded, "ALREADY_ONBOARDED", http.StatusConflict},
+		{"ErrRateLimited", ErrRateLimited, "RATE_LIMITED", http.StatusTooManyRequests},
 	}
 
 	for _, tt := range tests {
@@ -265,4 +305,4 @@ func TestPredefinedErrors(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
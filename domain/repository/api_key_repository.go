@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type APIKeyRepository interface {
+	Create(key *entity.APIKey) error
+	// FindByHash returns the key not yet revoked with the given hash,
+	// ErrInvalidAPIKey when there is none
+	FindByHash(hash string) (*entity.APIKey, error)
+	// List returns every key, revoked ones included, newest first
+	List() ([]*entity.APIKey, error)
+	// Revoke marks the key revoked at the given time, ErrAPIKeyNotFound for
+	// unknown, malformed or already revoked ids
+	Revoke(id string, at time.Time) error
+	// TouchLastUsed records that the key was used at the given time
+	TouchLastUsed(id primitive.ObjectID, at time.Time) error
+}
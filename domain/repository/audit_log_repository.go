@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+)
+
+type AuditLogRepository interface {
+	Create(log *entity.AuditLog) error
+	// DeleteOlderThan removes at most limit entries of the retention class
+	// created before cutoff and returns how many were removed
+	DeleteOlderThan(retentionClass string, cutoff time.Time, limit int64) (int64, error)
+	// FindByTarget returns at most limit entries of the action recorded
+	// against targetID, newest first
+	FindByTarget(targetID string, action string, limit int64) ([]*entity.AuditLog, error)
+}
@@ -1,16 +1,56 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/buildyow/byow-user-service/domain/entity"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// CompanyFilter narrows the companies returned by FindAll
+type CompanyFilter struct {
+	UserID   string
+	Keyword  string
+	Deleted  bool  // only soft-deleted companies when true, only active ones otherwise
+	Public   bool  // only verified companies that opted into the public listing
+	Verified *bool // only companies with this verification status when set
+}
+
+// UnknownTotal is the total FindAll reports when the page was fetched but
+// counting all matches failed
+const UnknownTotal int64 = -1
+
 type CompanyRepository interface {
-	FindAll(userID string, keyword string, limit int64, offset int64) ([]*entity.Company, int64, error)
+	// FindAll returns a page of companies and the total number of matches,
+	// UnknownTotal when only the count failed
+	FindAll(filter CompanyFilter, limit int64, offset int64) ([]*entity.Company, int64, error)
 	Create(user *entity.Company) error
 	FindByID(id primitive.ObjectID) (*entity.Company, error)
+	FindDeletedByID(id primitive.ObjectID) (*entity.Company, error)
+	// FindByNameForUser finds the user's active company whose name matches exactly, ignoring case
+	FindByNameForUser(userID string, name string) (*entity.Company, error)
+	// FindRecent returns the user's active companies, most recently updated first,
+	// never-edited companies ordered by creation time
+	FindRecent(userID string, limit int64) ([]*entity.Company, error)
+	// FindOwnedIDs returns which of ids are active companies of userID
+	FindOwnedIDs(userID string, ids []primitive.ObjectID) ([]primitive.ObjectID, error)
+	// FindByEmail and FindByPhone search every company, soft-deleted included
 	FindByEmail(email string) (*entity.Company, error)
 	FindByPhone(phone string) (*entity.Company, error)
 	Update(user *entity.Company) error
 	Delete(id primitive.ObjectID) error
+	Restore(id primitive.ObjectID) error
+	// ReassignOwner moves every company of fromUserID to toUserID and returns how many moved
+	ReassignOwner(fromUserID string, toUserID string) (int64, error)
+	// SetVerifiedMany sets the verification status of the given active companies and returns how many changed
+	SetVerifiedMany(ids []primitive.ObjectID, verified bool) (int64, error)
+	// SetPrimary marks the user's active company id as primary and clears the
+	// flag on all of the user's other companies
+	SetPrimary(userID string, id primitive.ObjectID) error
+	// PurgeDeletedOlderThan permanently removes companies soft-deleted before
+	// cutoff and returns the ones removed
+	PurgeDeletedOlderThan(cutoff time.Time) ([]*entity.Company, error)
+	// DeleteByOwner permanently removes every company of userID, soft-deleted
+	// ones included, and returns the ones removed
+	DeleteByOwner(userID string) ([]*entity.Company, error)
 }
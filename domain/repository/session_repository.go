@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+)
+
+type SessionRepository interface {
+	Create(session *entity.Session) error
+	// FindActiveByUser returns the user's sessions that are neither revoked
+	// nor expired at now, newest first
+	FindActiveByUser(userID string, now time.Time) ([]*entity.Session, error)
+	// FindByID returns ErrSessionNotFound for unknown or malformed ids
+	FindByID(id string) (*entity.Session, error)
+	// RevokeByJTI marks the session of the token revoked at the given time,
+	// a token without a session is ignored
+	RevokeByJTI(jti string, at time.Time) error
+	// RevokeAllByUser marks every session of the user not yet revoked
+	RevokeAllByUser(userID string, at time.Time) error
+}
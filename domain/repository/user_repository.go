@@ -1,12 +1,67 @@
 package repository
 
-import "github.com/buildyow/byow-user-service/domain/entity"
+import (
+	"context"
+	"time"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+)
+
+// UserFilter narrows the users returned by FindAll
+type UserFilter struct {
+	Keyword   string // partial match on the email, phone number or name
+	Suspended *bool  // only users with this suspension status when set
+}
 
 type UserRepository interface {
+	// FindAll returns a page of users, oldest first, and the total number of
+	// matches. Password, OTP and reset token fields are left empty.
+	FindAll(filter UserFilter, limit int64, offset int64) ([]*entity.User, int64, error)
 	Create(user *entity.User) error
 	FindByEmail(email string) (*entity.User, error)
+	FindByID(id string) (*entity.User, error)
 	FindByPhone(phone string) (*entity.User, error)
+	// FindExistingEmails returns which of emails belong to a user, matched
+	// exactly, with one query for the whole batch
+	FindExistingEmails(emails []string) (map[string]bool, error)
+	// Update writes user over the stored one, except for the fields with an
+	// update method of their own: the role, token version, suspension and
+	// scheduled deletion. A stale copy of the user can't undo those.
 	Update(user *entity.User) error
+	// UpdateOTP writes only the OTP fields of user, the rest of the stored
+	// user is left as is
+	UpdateOTP(user *entity.User) error
 	UpdateEmail(user *entity.User, oldEmail string) error
+	// UpdateRole stores the user's role and bumps their token version in one
+	// update, returning the new version
+	UpdateRole(userID, role string) (int, error)
+	// RevokeTokens bumps the user's token version and returns the new one
+	RevokeTokens(userID string) (int, error)
+	// Suspend marks the user suspended and bumps their token version in one
+	// update, returning the new version. Reactivate lifts the suspension.
+	Suspend(userID string, at time.Time, reason string) (int, error)
+	Reactivate(userID string) error
+	// ScheduleDeletion stores when the user is deleted and bumps their token
+	// version in one update, returning the new version. CancelDeletion
+	// clears it.
+	ScheduleDeletion(userID string, at time.Time) (int, error)
+	CancelDeletion(userID string) error
+	// AddPasskeyCeremony stores a pending passkey ceremony for the user,
+	// keeping only the newest keep of them
+	AddPasskeyCeremony(userID string, ceremony entity.PasskeyCeremony, keep int) error
+	// TakePasskeyCeremony removes the user's pending ceremony with the id
+	// and returns it, nil when there is none. Only one caller ever gets it.
+	TakePasskeyCeremony(userID, ceremonyID string) (*entity.PasskeyCeremony, error)
 	UpdatePhone(user *entity.User, oldPhone string) error
+	Delete(email string) error
+	// FindDeletionDue returns up to limit users whose scheduled deletion is
+	// due at before, earliest first
+	FindDeletionDue(before time.Time, limit int64) ([]*entity.User, error)
+	// ForEach streams every user to fn without loading them all, stopping at
+	// the first error. Password, OTP and reset token fields are left empty.
+	ForEach(ctx context.Context, fn func(user *entity.User) error) error
+	// ForEachActiveOTP streams the users holding an unexpired OTP to fn,
+	// OTP fields included, stopping at the first error. Password and reset
+	// token fields are left empty, save changes with UpdateOTP.
+	ForEachActiveOTP(ctx context.Context, fn func(user *entity.User) error) error
 }
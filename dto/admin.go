@@ -0,0 +1,155 @@
+package dto
+
+type MergeAccountsRequest struct {
+	KeepEmail  string `json:"keep_email" example:"john@example.com"`
+	MergeEmail string `json:"merge_email" example:"John@Example.com"`
+}
+
+type MergeAccountsResponse struct {
+	CompaniesReassigned int64 `json:"companies_reassigned" example:"2"`
+}
+
+type RebuildIndexesResponse struct {
+	Indexes []string `json:"indexes" example:"email_unique,company_email_unique"`
+	Count   int      `json:"count" example:"2"`
+}
+
+type VerifyCompaniesRequest struct {
+	IDs      []string `json:"ids" example:"60c72b2f9b1e8c001c8e4d3a,60c72b2f9b1e8c001c8e4d3b"`
+	Verified bool     `json:"verified" example:"true"`
+}
+
+// BatchSize is the number of company IDs in the request
+func (r VerifyCompaniesRequest) BatchSize() int { return len(r.IDs) }
+
+type VerifyCompaniesResponse struct {
+	Modified   int64    `json:"modified" example:"2"`
+	SkippedIDs []string `json:"skipped_ids" example:"not-an-id"`
+}
+
+type PurgeCompaniesResponse struct {
+	Purged int64 `json:"purged" example:"3"`
+}
+
+type UnverifyCompanyRequest struct {
+	Reason string `json:"reason" example:"Registration documents were forged"`
+}
+
+// ResetOTPAttemptsResponse reports how many wrong OTP attempts were cleared
+type ResetOTPAttemptsResponse struct {
+	Email            string `json:"email" example:"john@example.com"`
+	PreviousAttempts int    `json:"previous_attempts" example:"5"`
+}
+
+// SetRoleRequest names the role to give a user, "user" or "admin"
+type SetRoleRequest struct {
+	Role string `json:"role" example:"admin"`
+}
+
+// SetRoleResponse reports a role change, the user's earlier tokens stop
+// working once TokenVersion moved on
+type SetRoleResponse struct {
+	Email        string `json:"email" example:"john@example.com"`
+	Role         string `json:"role" example:"user"`
+	PreviousRole string `json:"previous_role" example:"admin"`
+	TokenVersion int    `json:"token_version" example:"2"`
+}
+
+// ReencryptOTPsRequest picks what happens to OTPs after a DECRYPT_KEY
+// rotation, "reencrypt" (the default) or "invalidate"
+type ReencryptOTPsRequest struct {
+	Mode string `json:"mode" example:"reencrypt"`
+}
+
+// ReencryptOTPsResponse counts the active OTPs by outcome. Skipped OTPs were
+// already under the current key, Invalidated includes OTPs neither key opens.
+type ReencryptOTPsResponse struct {
+	Mode        string `json:"mode" example:"reencrypt"`
+	Reencrypted int    `json:"reencrypted" example:"12"`
+	Invalidated int    `json:"invalidated" example:"1"`
+	Skipped     int    `json:"skipped" example:"3"`
+}
+
+// UserExportRecord is one line of the admin user export, credentials and OTP data are never included
+type UserExportRecord struct {
+	ID          string `json:"id"`
+	Fullname    string `json:"full_name"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	AvatarUrl   string `json:"avatar_url"`
+	OnBoarded   bool   `json:"on_boarded"`
+	Verified    bool   `json:"verified"`
+	Role        string `json:"role,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// AdminUserResponse is a user as admins see them, credentials and OTP data
+// are never included
+type AdminUserResponse struct {
+	ID              string `json:"id" example:"60c72b2f9b1e8c001c8e4d3b"`
+	Fullname        string `json:"full_name" example:"John Doe"`
+	Email           string `json:"email" example:"john@example.com"`
+	PhoneNumber     string `json:"phone_number" example:"628112123123"`
+	AvatarUrl       string `json:"avatar_url" example:"https://assets/images/img.jpg"`
+	Verified        bool   `json:"verified" example:"true"`
+	OnBoarded       bool   `json:"on_boarded" example:"true"`
+	Role            string `json:"role" example:"user"`
+	CreatedAt       string `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	LastLoginAt     string `json:"last_login_at,omitempty" example:"2024-02-01T09:00:00Z"`
+	Suspended       bool   `json:"suspended" example:"false"`
+	SuspendedAt     string `json:"suspended_at,omitempty" example:"2024-03-01T12:00:00Z"`
+	SuspendedReason string `json:"suspended_reason,omitempty" example:"Spam reports"`
+}
+
+type AdminUserListResponseSwagger struct {
+	Status string              `json:"status" example:"SUCCESS"`
+	Code   int                 `json:"code" example:"200"`
+	Data   []AdminUserResponse `json:"data"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" example:"Spam reports"`
+}
+
+// ImpersonationResponse carries a token to act as the user with, sent as
+// an Authorization: Bearer header
+type ImpersonationResponse struct {
+	UserID    string `json:"user_id" example:"60c72b2f9b1e8c001c8e4d3b"`
+	Email     string `json:"email" example:"john@example.com"`
+	Token     string `json:"token" example:"token"`
+	ExpiresAt string `json:"expires_at" example:"2024-01-15T10:45:00Z"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" example:"billing-service"`
+	Scopes []string `json:"scopes" example:"internal:users:read"`
+}
+
+type APIKeyResponse struct {
+	ID         string   `json:"id" example:"60c72b2f9b1e8c001c8e4d3a"`
+	Name       string   `json:"name" example:"billing-service"`
+	Prefix     string   `json:"prefix" example:"byow_3f9a1c"`
+	Scopes     []string `json:"scopes" example:"internal:users:read"`
+	CreatedBy  string   `json:"created_by" example:"60c72b2f9b1e8c001c8e4d3b"`
+	CreatedAt  string   `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	LastUsedAt string   `json:"last_used_at,omitempty" example:"2024-01-16T08:00:00Z"`
+	RevokedAt  string   `json:"revoked_at,omitempty" example:"2024-02-01T09:00:00Z"`
+}
+
+// CreatedAPIKeyResponse carries the key itself, it is only ever shown here
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"byow_3f9a1c0d5e7b4a2c9f8e1d6b3a5c7e9f0a2b4c6d8e1f3a5b"`
+}
+
+// InternalUserResponse is what other services get for a user, without
+// anything only the user should see
+type InternalUserResponse struct {
+	ID          string `json:"id" example:"60c72b2f9b1e8c001c8e4d3b"`
+	Fullname    string `json:"full_name" example:"John Doe"`
+	Email       string `json:"email" example:"john@example.com"`
+	PhoneNumber string `json:"phone_number" example:"628112123123"`
+	AvatarUrl   string `json:"avatar_url" example:"https://assets/images/img.jpg"`
+	Verified    bool   `json:"verified" example:"true"`
+	Role        string `json:"role" example:"user"`
+}
@@ -11,7 +11,83 @@ type CompanyResponse struct {
 	CompanyAddress string             `json:"company_address" example:"123 BuildYow St, Tech City"`
 	CompanyLogo    string             `json:"company_logo" example:"https://assets/images/company_logo.jpg"`
 	Verified       bool               `json:"verified" example:"false"`
+	PublicListing  bool               `json:"public_listing" example:"false"`
+	PublicContact  bool               `json:"public_contact" example:"false"`
+	IsPrimary      bool               `json:"is_primary" example:"false"`
 	CreatedAt      string             `json:"created_at" example:"2023-10-01T12:00:00Z"`
+	UpdatedAt      string             `json:"updated_at" example:"2023-10-02T08:30:00Z"`
+}
+
+type OwnershipBatchRequest struct {
+	IDs []string `json:"ids" example:"60c72b2f9b1e8c001c8e4d3a,60c72b2f9b1e8c001c8e4d3b"`
+}
+
+// BatchSize is the number of company IDs in the request
+func (r OwnershipBatchRequest) BatchSize() int { return len(r.IDs) }
+
+type OwnershipBatchResponseSwagger struct {
+	Status string          `json:"status" example:"SUCCESS"`
+	Code   int             `json:"code" example:"200"`
+	Data   map[string]bool `json:"data"`
+}
+
+// CompanyOwnerResponse is the public profile of a company's owner, contact
+// details are never included
+type CompanyOwnerResponse struct {
+	Fullname  string `json:"full_name" example:"John Doe"`
+	AvatarUrl string `json:"avatar_url" example:"https://assets/images/img.jpg"`
+	Verified  bool   `json:"verified" example:"true"`
+}
+
+// CompanyWithOwnerResponse is a company together with its owner, Owner is
+// null when the owner account no longer exists
+type CompanyWithOwnerResponse struct {
+	CompanyResponse
+	Owner *CompanyOwnerResponse `json:"owner"`
+}
+
+type CompanyWithOwnerResponseSwagger struct {
+	Status string                   `json:"status" example:"SUCCESS"`
+	Code   int                      `json:"code" example:"200"`
+	Data   CompanyWithOwnerResponse `json:"data"`
+}
+
+// PublicCompanyResponse is the reduced view served by the public directory
+type PublicCompanyResponse struct {
+	CompanyID      primitive.ObjectID `json:"company_id" example:"60c72b2f9b1e8c001c8e4d3a"`
+	CompanyName    string             `json:"company_name" example:"BuildYow"`
+	CompanyEmail   string             `json:"company_email,omitempty" example:"info@buildyow.com"`
+	CompanyPhone   string             `json:"company_phone,omitempty" example:"628112123123"`
+	CompanyAddress string             `json:"company_address" example:"123 BuildYow St, Tech City"`
+	CompanyLogo    string             `json:"company_logo" example:"https://assets/images/company_logo.jpg"`
+	CreatedAt      string             `json:"created_at" example:"2023-10-01T12:00:00Z"`
+}
+
+type PublicCompanyResponseSwagger struct {
+	Status string                `json:"status" example:"SUCCESS"`
+	Code   int                   `json:"code" example:"200"`
+	Data   PublicCompanyResponse `json:"data"`
+}
+
+type PublicCompanyListResponseSwagger struct {
+	Status string                  `json:"status" example:"SUCCESS"`
+	Code   int                     `json:"code" example:"200"`
+	Data   []PublicCompanyResponse `json:"data"`
+}
+
+// CompanySummaryResponse is the compact view used by dashboard widgets
+type CompanySummaryResponse struct {
+	CompanyID   primitive.ObjectID `json:"company_id" example:"60c72b2f9b1e8c001c8e4d3a"`
+	CompanyName string             `json:"company_name" example:"BuildYow"`
+	CompanyLogo string             `json:"company_logo" example:"https://assets/images/company_logo.jpg"`
+	Verified    bool               `json:"verified" example:"false"`
+	UpdatedAt   string             `json:"updated_at" example:"2023-10-01T12:00:00Z"`
+}
+
+type CompanySummaryListResponseSwagger struct {
+	Status string                   `json:"status" example:"SUCCESS"`
+	Code   int                      `json:"code" example:"200"`
+	Data   []CompanySummaryResponse `json:"data"`
 }
 
 type CompanyListResponseSwagger struct {
@@ -27,6 +103,8 @@ type CompanyRequest struct {
 	CompanyAddress string `json:"company_address" example:"123 BuildYow St, Tech City"`
 	CompanyLogo    string `json:"company_logo" example:"https://assets/images/company_logo.jpg"`
 	Verified       bool   `json:"verified" example:"false"`
+	PublicListing  bool   `json:"public_listing" example:"false"`
+	PublicContact  bool   `json:"public_contact" example:"false"`
 }
 
 type CompanyRequestSwagger struct {
@@ -34,3 +112,17 @@ type CompanyRequestSwagger struct {
 	Code   int             `json:"code" example:"200"`
 	Data   CompanyResponse `json:"data"`
 }
+
+// CompanyEvent is streamed to the owner when one of their companies changes
+type CompanyEvent struct {
+	Type      string             `json:"type" example:"company.created"`
+	CompanyID primitive.ObjectID `json:"company_id" example:"60c72b2f9b1e8c001c8e4d3a"`
+	At        string             `json:"at" example:"2023-10-01T12:00:00Z"`
+}
+
+// CompanyAvailabilityResponse reports whether each checked value is still
+// free, values that weren't checked are omitted
+type CompanyAvailabilityResponse struct {
+	EmailAvailable *bool `json:"email_available,omitempty" example:"true"`
+	PhoneAvailable *bool `json:"phone_available,omitempty" example:"false"`
+}
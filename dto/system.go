@@ -0,0 +1,36 @@
+package dto
+
+type ServerTimeResponse struct {
+	UTC   string `json:"utc" example:"2023-10-01T12:00:00Z"`
+	Epoch int64  `json:"epoch" example:"1696161600"`
+}
+
+// ServiceInfoResponse identifies the running build
+type ServiceInfoResponse struct {
+	Name          string  `json:"name" example:"byow-user-service"`
+	Version       string  `json:"version" example:"1.0"`
+	GoVersion     string  `json:"go_version" example:"go1.24.0"`
+	StartedAt     string  `json:"started_at" example:"2023-10-01T12:00:00Z"`
+	UptimeSeconds float64 `json:"uptime_seconds" example:"3600.5"`
+}
+
+type ServiceInfoResponseSwagger struct {
+	Status string              `json:"status" example:"SUCCESS"`
+	Code   int                 `json:"code" example:"200"`
+	Data   ServiceInfoResponse `json:"data"`
+}
+
+// UploadConfigResponse lists the limits enforced on uploaded images
+type UploadConfigResponse struct {
+	MaxSizeBytes int64    `json:"max_size_bytes" example:"10485760"`
+	AllowedTypes []string `json:"allowed_types" example:"image/jpeg,image/png,image/gif"`
+	// Zero when the dimension isn't limited
+	MaxWidth  int `json:"max_width" example:"0"`
+	MaxHeight int `json:"max_height" example:"0"`
+}
+
+type UploadConfigResponseSwagger struct {
+	Status string               `json:"status" example:"SUCCESS"`
+	Code   int                  `json:"code" example:"200"`
+	Data   UploadConfigResponse `json:"data"`
+}
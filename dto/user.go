@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"encoding/json"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
 type LoginRequest struct {
 	Email    string `json:"email" example:"arm.adrian02@gmail.com"`
 	Password string `json:"password" example:"masukaja123"`
@@ -22,6 +28,22 @@ type UserResponse struct {
 	OnBoarded   bool   `json:"on_boarded" example:"false"`
 	Token       string `json:"token,omitempty" example:"token"`
 	CreatedAt   string `json:"created_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	// Only on profile responses, omitted for accounts that predate it
+	PasswordChangedAt string `json:"password_changed_at,omitempty" example:"2024-01-15T10:30:00Z"`
+	// Omitted until the user picks at least one preference
+	Preferences *UserPreferences `json:"preferences,omitempty"`
+}
+
+type UserPreferences struct {
+	Language string `json:"language,omitempty" example:"id" enums:"en,id"`
+	Theme    string `json:"theme,omitempty" example:"dark" enums:"light,dark,system"`
+	Timezone string `json:"timezone,omitempty" example:"Asia/Jakarta"`
+}
+
+// OnboardRequest completes onboarding, every field is optional
+type OnboardRequest struct {
+	Fullname    string           `json:"full_name,omitempty" example:"John Doe"`
+	Preferences *UserPreferences `json:"preferences,omitempty"`
 }
 
 type UserResponseSwagger struct {
@@ -35,6 +57,10 @@ type VerifyOTPRequest struct {
 	OTP   string `json:"otp" example:"000000"`
 }
 
+type CheckOTPResponse struct {
+	Valid bool `json:"valid" example:"true"`
+}
+
 type ChangePasswordRequest struct {
 	Email    string `json:"email" example:"john@example.com"`
 	OTP      string `json:"otp" example:"000000"`
@@ -46,12 +72,223 @@ type ChangePasswordWithOldPasswordRequest struct {
 	NewPassword string `json:"new_password" example:"newpassword"`
 }
 
+type ChangePasswordStepUpRequest struct {
+	OTP         string `json:"otp" example:"000000"`
+	NewPassword string `json:"new_password" example:"newpassword"`
+}
+
+type SendPasswordResetLinkRequest struct {
+	Email string `json:"email" example:"john@example.com"`
+}
+
+type ResetPasswordWithTokenRequest struct {
+	Email       string `json:"email" example:"john@example.com"`
+	Token       string `json:"token" example:"3f2a..."`
+	NewPassword string `json:"new_password" example:"newpassword"`
+}
+
+// ChangeEmailRequest needs both the OTP sent to the current address and the
+// code sent to the new one
 type ChangeEmailRequest struct {
+	NewEmail    string `json:"new_email" example:"john.doe@example.com"`
+	OTP         string `json:"otp" example:"000000"`
+	NewEmailOTP string `json:"new_email_otp" example:"111111"`
+}
+
+type SendNewEmailOTPRequest struct {
 	NewEmail string `json:"new_email" example:"john.doe@example.com"`
-	OTP      string `json:"otp" example:"000000"`
 }
 
 type ChangePhoneRequest struct {
 	NewPhone string `json:"new_phone" example:"628112123123"`
 	OTP      string `json:"otp" example:"000000"`
 }
+
+// FailedLoginResponse is one failed sign-in attempt on the caller's account
+type FailedLoginResponse struct {
+	AttemptedAt string `json:"attempted_at" example:"2023-10-01T12:00:00Z"`
+	IP          string `json:"ip" example:"203.0.113.7"`
+	UserAgent   string `json:"user_agent" example:"Mozilla/5.0"`
+}
+
+// LoginHistoryResponse is one successful sign-in on the caller's account.
+// Device is a readable label derived from UserAgent, which is kept as sent.
+type LoginHistoryResponse struct {
+	LoggedInAt string `json:"logged_in_at" example:"2023-10-01T12:00:00Z"`
+	IP         string `json:"ip" example:"203.0.113.7"`
+	Device     string `json:"device" example:"Chrome on Windows"`
+	UserAgent  string `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"`
+}
+
+// SessionResponse is a device signed in to the caller's account. Current
+// marks the session of the token that made the request.
+type SessionResponse struct {
+	ID        string `json:"id" example:"64f1c2a9e4b0a1b2c3d4e5f6"`
+	Device    string `json:"device" example:"Chrome on Windows"`
+	IP        string `json:"ip" example:"203.0.113.7"`
+	UserAgent string `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"`
+	IssuedAt  string `json:"issued_at" example:"2023-10-01T12:00:00Z"`
+	ExpiresAt string `json:"expires_at" example:"2023-10-02T12:00:00Z"`
+	Current   bool   `json:"current" example:"true"`
+}
+
+// PasskeyLoginRequest starts a passkey login for the account of Email
+type PasskeyLoginRequest struct {
+	Email string `json:"email" example:"john@example.com"`
+}
+
+// PasskeyLoginFinishRequest carries the browser's answer to the login
+// challenge, the PublicKeyCredential as serialized by the browser, and the
+// ceremony_id the begin step returned
+type PasskeyLoginFinishRequest struct {
+	Email      string          `json:"email" example:"john@example.com"`
+	CeremonyID string          `json:"ceremony_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+}
+
+// PasskeyRegisterFinishRequest carries the browser's new credential, the
+// ceremony_id the begin step returned and an optional name to tell the
+// user's passkeys apart
+type PasskeyRegisterFinishRequest struct {
+	Name       string          `json:"name" example:"Work laptop"`
+	CeremonyID string          `json:"ceremony_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+}
+
+// PasskeyCreationOptions are the options for navigator.credentials.create()
+// and the id of the ceremony to finish with the created credential
+type PasskeyCreationOptions struct {
+	CeremonyID string `json:"ceremony_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	*protocol.CredentialCreation
+}
+
+// PasskeyAssertionOptions are the options for navigator.credentials.get()
+// and the id of the ceremony to finish with the signed assertion
+type PasskeyAssertionOptions struct {
+	CeremonyID string `json:"ceremony_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	*protocol.CredentialAssertion
+}
+
+// PasskeyResponse is a registered passkey, ID is the base64url credential ID
+type PasskeyResponse struct {
+	ID         string `json:"id" example:"AQIDBAUGBwgJCgsMDQ4PEA"`
+	Name       string `json:"name,omitempty" example:"Work laptop"`
+	CreatedAt  string `json:"created_at" example:"2023-10-01T12:00:00Z"`
+	LastUsedAt string `json:"last_used_at,omitempty" example:"2023-10-02T08:30:00Z"`
+}
+
+// NotificationPrefsResponse lists which notice emails the user receives.
+// PasswordChanged and EmailChanged are security notices and always true.
+type NotificationPrefsResponse struct {
+	Welcome             bool `json:"welcome" example:"true"`
+	VerificationRevoked bool `json:"verification_revoked" example:"true"`
+	PasswordChanged     bool `json:"password_changed" example:"true"`
+	EmailChanged        bool `json:"email_changed" example:"true"`
+}
+
+type NotificationPrefsResponseSwagger struct {
+	Status string                    `json:"status" example:"SUCCESS"`
+	Code   int                       `json:"code" example:"200"`
+	Data   NotificationPrefsResponse `json:"data"`
+}
+
+// UpdateNotificationPrefsRequest changes only the notices present in the body
+type UpdateNotificationPrefsRequest struct {
+	Welcome             *bool `json:"welcome,omitempty" example:"false"`
+	VerificationRevoked *bool `json:"verification_revoked,omitempty" example:"true"`
+	PasswordChanged     *bool `json:"password_changed,omitempty" example:"true"`
+	EmailChanged        *bool `json:"email_changed,omitempty" example:"true"`
+}
+
+// LanguageResponse is the language the user's emails are written in. It
+// reports the default language until the user picks a supported one.
+type LanguageResponse struct {
+	Language  string   `json:"language" example:"id"`
+	Supported []string `json:"supported" example:"en,id"`
+}
+
+type LanguageResponseSwagger struct {
+	Status string           `json:"status" example:"SUCCESS"`
+	Code   int              `json:"code" example:"200"`
+	Data   LanguageResponse `json:"data"`
+}
+
+type UpdateLanguageRequest struct {
+	Language string `json:"language" example:"id"`
+}
+
+// PermissionsResponse is the user's role and everything it allows
+type PermissionsResponse struct {
+	Role        string   `json:"role" example:"admin"`
+	Permissions []string `json:"permissions" example:"companies:create,admin:users:read"`
+}
+
+type PermissionsResponseSwagger struct {
+	Status string              `json:"status" example:"SUCCESS"`
+	Code   int                 `json:"code" example:"200"`
+	Data   PermissionsResponse `json:"data"`
+}
+
+// SecuritySummaryResponse is the account's security posture. Timestamps are
+// omitted until the event first happens, PasswordStale is only set when a
+// maximum password age is configured and RecentFailedLogins is capped at 50.
+// ActiveSessions counts the devices signed in, zero without session tracking.
+type SecuritySummaryResponse struct {
+	EmailVerified      bool   `json:"email_verified" example:"true"`
+	PhoneVerified      bool   `json:"phone_verified" example:"false"`
+	PasswordChangedAt  string `json:"password_changed_at,omitempty" example:"2023-10-01T12:00:00Z"`
+	PasswordStale      bool   `json:"password_stale" example:"false"`
+	LastLoginAt        string `json:"last_login_at,omitempty" example:"2023-10-02T08:15:00Z"`
+	OTPLocked          bool   `json:"otp_locked" example:"false"`
+	RecentFailedLogins int    `json:"recent_failed_logins" example:"2"`
+	ActiveSessions     int    `json:"active_sessions" example:"1"`
+}
+
+type SecuritySummaryResponseSwagger struct {
+	Status string                  `json:"status" example:"SUCCESS"`
+	Code   int                     `json:"code" example:"200"`
+	Data   SecuritySummaryResponse `json:"data"`
+}
+
+type FailedLoginListResponseSwagger struct {
+	Status string                `json:"status" example:"SUCCESS"`
+	Code   int                   `json:"code" example:"200"`
+	Data   []FailedLoginResponse `json:"data"`
+}
+
+type EmailAvailabilityBatchRequest struct {
+	Emails []string `json:"emails" example:"jane@example.com,john@example.com"`
+}
+
+// BatchSize is the number of emails in the request
+func (r EmailAvailabilityBatchRequest) BatchSize() int { return len(r.Emails) }
+
+type EmailAvailabilityBatchResponseSwagger struct {
+	Status string          `json:"status" example:"SUCCESS"`
+	Code   int             `json:"code" example:"200"`
+	Data   map[string]bool `json:"data"`
+}
+
+type LoginHistoryListResponseSwagger struct {
+	Status string                 `json:"status" example:"SUCCESS"`
+	Code   int                    `json:"code" example:"200"`
+	Data   []LoginHistoryResponse `json:"data"`
+}
+
+type SessionListResponseSwagger struct {
+	Status string            `json:"status" example:"SUCCESS"`
+	Code   int               `json:"code" example:"200"`
+	Data   []SessionResponse `json:"data"`
+}
+
+// AccountDeletionResponse is when the account will be removed for good,
+// logging in before then cancels the deletion
+type AccountDeletionResponse struct {
+	DeletionScheduledAt string `json:"deletion_scheduled_at" example:"2023-11-01T12:00:00Z"`
+}
+
+type AccountDeletionResponseSwagger struct {
+	Status string                  `json:"status" example:"SUCCESS"`
+	Code   int                     `json:"code" example:"200"`
+	Data   AccountDeletionResponse `json:"data"`
+}
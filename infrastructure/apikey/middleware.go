@@ -0,0 +1,41 @@
+// Package apikey authenticates service-to-service calls made with an API key
+package apikey
+
+import (
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the API key of a calling service
+const Header = "X-API-Key"
+
+// APIKeyMiddleware refuses requests without a valid key in the X-API-Key header,
+// and keys that weren't given scope. authenticate looks the key up, e.g.
+// APIKeyUsecase.Authenticate. The key's id and name are stored on the
+// context as api_key_id and api_key_name.
+func APIKeyMiddleware(authenticate func(key string) (*entity.APIKey, error), scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plain := c.GetHeader(Header)
+		if plain == "" {
+			response.ErrorFromAppError(c, appErrors.ErrInvalidAPIKey)
+			c.Abort()
+			return
+		}
+		key, err := authenticate(plain)
+		if err != nil {
+			response.ErrorFromAppError(c, err)
+			c.Abort()
+			return
+		}
+		if !key.HasScope(scope) {
+			response.ErrorFromAppError(c, appErrors.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Set("api_key_id", key.ID.Hex())
+		c.Set("api_key_name", key.Name)
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package apikey
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := map[string]*entity.APIKey{
+		"byow_reader": {Name: "billing", Scopes: []string{"internal:users:read"}},
+		"byow_other":  {Name: "search", Scopes: []string{"internal:other"}},
+	}
+	authenticate := func(key string) (*entity.APIKey, error) {
+		if found, ok := keys[key]; ok {
+			return found, nil
+		}
+		return nil, appErrors.ErrInvalidAPIKey
+	}
+
+	router := gin.New()
+	router.GET("/internal/users/:id", APIKeyMiddleware(authenticate, "internal:users:read"), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("api_key_name"))
+	})
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"no key", "", http.StatusUnauthorized},
+		{"unknown key", "byow_unknown", http.StatusUnauthorized},
+		{"missing scope", "byow_other", http.StatusForbidden},
+		{"scoped key", "byow_reader", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/internal/users/user-123", nil)
+			if tt.key != "" {
+				req.Header.Set(Header, tt.key)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusOK && w.Body.String() != "billing" {
+				t.Errorf("Expected the key name on the context, got %q", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Package authctx reads the token claims JWTMiddleware stores on the request context
+package authctx
+
+import (
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// String returns the claim stored under key, "" when it is absent and
+// ErrInvalidTokenClaims when it holds anything other than a string
+func String(c *gin.Context, key string) (string, error) {
+	value, exists := c.Get(key)
+	if !exists {
+		return "", nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", appErrors.ErrInvalidTokenClaims
+	}
+	return str, nil
+}
+
+// Email returns the email claim of the authenticated user
+func Email(c *gin.Context) (string, error) {
+	return String(c, "email")
+}
+
+// Phone returns the phone claim of the authenticated user
+func Phone(c *gin.Context) (string, error) {
+	return String(c, "phone")
+}
+
+// UserID returns the user id claim of the authenticated user
+func UserID(c *gin.Context) (string, error) {
+	return String(c, "user_id")
+}
+
+// Role returns the role claim of the authenticated user
+func Role(c *gin.Context) (string, error) {
+	return String(c, "role")
+}
+
+// JTI returns the id of the token the request was made with
+func JTI(c *gin.Context) (string, error) {
+	return String(c, "jti")
+}
+
+// User returns the user id and email claims of the authenticated user, the
+// pair most usecases look the user up by
+func User(c *gin.Context) (userID, email string, err error) {
+	if userID, err = UserID(c); err != nil {
+		return "", "", err
+	}
+	if email, err = Email(c); err != nil {
+		return "", "", err
+	}
+	return userID, email, nil
+}
@@ -0,0 +1,44 @@
+package authctx
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func TestString(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	if value, err := String(c, "email"); value != "" || err != nil {
+		t.Errorf("Expected an absent claim to be empty without error, got %q, %v", value, err)
+	}
+
+	c.Set("email", "user@example.com")
+	if value, err := Email(c); value != "user@example.com" || err != nil {
+		t.Errorf("Expected the stored email, got %q, %v", value, err)
+	}
+
+	c.Set("phone", 628123456789)
+	if _, err := Phone(c); err != appErrors.ErrInvalidTokenClaims {
+		t.Errorf("Expected ErrInvalidTokenClaims for a non-string claim, got %v", err)
+	}
+}
+
+func TestUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("user_id", "user-123")
+	c.Set("email", "user@example.com")
+
+	if userID, email, err := User(c); userID != "user-123" || email != "user@example.com" || err != nil {
+		t.Errorf("Expected the stored claims, got %q, %q, %v", userID, email, err)
+	}
+
+	c.Set("user_id", 123)
+	if _, _, err := User(c); err != appErrors.ErrInvalidTokenClaims {
+		t.Errorf("Expected ErrInvalidTokenClaims for a non-string user id, got %v", err)
+	}
+}
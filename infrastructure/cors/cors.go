@@ -12,12 +12,12 @@ import (
 func SetupCors() gin.HandlerFunc {
 	// Get allowed origins from environment variable
 	allowedOrigins := getAllowedOrigins()
-	
+
 	return cors.New(cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    append([]string{"Content-Length"}, getExposedHeaders()...),
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
@@ -26,29 +26,51 @@ func SetupCors() gin.HandlerFunc {
 // getAllowedOrigins returns the list of allowed origins from environment variable
 func getAllowedOrigins() []string {
 	allowedOriginsEnv := os.Getenv("ALLOWED_ORIGINS")
-	
+
 	// Default origins for development
 	defaultOrigins := []string{"http://localhost:3000", "http://localhost:3001"}
-	
+
 	if allowedOriginsEnv == "" {
 		return defaultOrigins
 	}
-	
+
 	// Parse comma-separated origins from environment variable
-	origins := strings.Split(allowedOriginsEnv, ",")
-	var cleanOrigins []string
-	
-	for _, origin := range origins {
-		cleanOrigin := strings.TrimSpace(origin)
-		if cleanOrigin != "" {
-			cleanOrigins = append(cleanOrigins, cleanOrigin)
-		}
-	}
-	
+	cleanOrigins := splitList(allowedOriginsEnv)
+
 	// If no valid origins found, return defaults
 	if len(cleanOrigins) == 0 {
 		return defaultOrigins
 	}
-	
+
 	return cleanOrigins
 }
+
+// defaultExposedHeaders are the custom response headers browsers may read
+// cross-origin when CORS_EXPOSE_HEADERS is unset
+var defaultExposedHeaders = []string{
+	"X-Request-ID",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+}
+
+// getExposedHeaders returns the response headers listed in CORS_EXPOSE_HEADERS,
+// Content-Length is always exposed on top of these
+func getExposedHeaders() []string {
+	headers := splitList(os.Getenv("CORS_EXPOSE_HEADERS"))
+	if len(headers) == 0 {
+		return defaultExposedHeaders
+	}
+	return headers
+}
+
+// splitList parses a comma-separated list, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
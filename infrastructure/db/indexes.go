@@ -13,13 +13,20 @@ import (
 
 // CreateIndexes creates necessary database indexes for optimal performance
 func CreateIndexes(db *mongo.Database, logger *zap.Logger) error {
-	if db == nil {
-		return fmt.Errorf("database is nil")
-	}
-	
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	_, err := EnsureIndexes(ctx, db, logger)
+	return err
+}
+
+// EnsureIndexes creates the indexes within ctx and returns the names of all
+// indexes it created or found already in place
+func EnsureIndexes(ctx context.Context, db *mongo.Database, logger *zap.Logger) ([]string, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is nil")
+	}
+
 	// Create User indexes
 	userCollection := db.Collection("users_collections")
 	userIndexes := []mongo.IndexModel{
@@ -50,6 +57,18 @@ func CreateIndexes(db *mongo.Database, logger *zap.Logger) error {
 			Options: options.Index().
 				SetName("is_onboarded_index"),
 		},
+		{
+			Keys: bson.D{{Key: "name_normalized", Value: 1}},
+			Options: options.Index().
+				SetName("name_normalized_index"),
+		},
+		// Only accounts waiting for deletion carry the field
+		{
+			Keys: bson.D{{Key: "deletion_scheduled_at", Value: 1}},
+			Options: options.Index().
+				SetSparse(true).
+				SetName("deletion_scheduled_at_index"),
+		},
 		// Compound index for common queries
 		{
 			Keys: bson.D{
@@ -65,7 +84,7 @@ func CreateIndexes(db *mongo.Database, logger *zap.Logger) error {
 	userIndexNames, err := userCollection.Indexes().CreateMany(ctx, userIndexes)
 	if err != nil {
 		logger.Error("Failed to create user indexes", zap.Error(err))
-		return err
+		return nil, err
 	}
 
 	// Create Company indexes
@@ -77,16 +96,23 @@ func CreateIndexes(db *mongo.Database, logger *zap.Logger) error {
 				SetName("company_name_index"),
 		},
 		{
-			Keys: bson.D{{Key: "email", Value: 1}},
+			Keys: bson.D{{Key: "name_normalized", Value: 1}},
+			Options: options.Index().
+				SetName("company_name_normalized_index"),
+		},
+		{
+			Keys: bson.D{{Key: "company_email", Value: 1}},
 			Options: options.Index().
 				SetUnique(true).
 				SetSparse(true).
 				SetName("company_email_unique"),
 		},
 		{
-			Keys: bson.D{{Key: "phone", Value: 1}},
+			Keys: bson.D{{Key: "company_phone", Value: 1}},
 			Options: options.Index().
-				SetName("company_phone_index"),
+				SetUnique(true).
+				SetSparse(true).
+				SetName("company_phone_unique"),
 		},
 		{
 			Keys: bson.D{{Key: "created_at", Value: 1}},
@@ -127,15 +153,98 @@ func CreateIndexes(db *mongo.Database, logger *zap.Logger) error {
 	companyIndexNames, err := companyCollection.Indexes().CreateMany(ctx, companyIndexes)
 	if err != nil {
 		logger.Error("Failed to create company indexes", zap.Error(err))
-		return err
+		return nil, err
+	}
+
+	// Create Audit log indexes, the retention purge scans by class and age
+	auditCollection := db.Collection("audit_logs_collections")
+	auditIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "retention_class", Value: 1},
+				{Key: "created_at", Value: 1},
+			},
+			Options: options.Index().
+				SetName("audit_retention_created_at_compound"),
+		},
+		// Per-user security history, e.g. recent failed logins
+		{
+			Keys: bson.D{
+				{Key: "target_id", Value: 1},
+				{Key: "action", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().
+				SetName("audit_target_action_created_at_compound"),
+		},
+	}
+
+	auditIndexNames, err := auditCollection.Indexes().CreateMany(ctx, auditIndexes)
+	if err != nil {
+		logger.Error("Failed to create audit log indexes", zap.Error(err))
+		return nil, err
+	}
+
+	// Create Session indexes, sessions are dropped once their token expires
+	sessionCollection := db.Collection("sessions_collections")
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "jti", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetName("session_jti_unique"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "issued_at", Value: -1},
+			},
+			Options: options.Index().
+				SetName("session_user_issued_at_compound"),
+		},
+		{
+			Keys: bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().
+				SetExpireAfterSeconds(0).
+				SetName("session_expires_at_ttl"),
+		},
+	}
+
+	sessionIndexNames, err := sessionCollection.Indexes().CreateMany(ctx, sessionIndexes)
+	if err != nil {
+		logger.Error("Failed to create session indexes", zap.Error(err))
+		return nil, err
+	}
+
+	// Create API key indexes, keys are looked up by their hash
+	apiKeyCollection := db.Collection("api_keys_collections")
+	apiKeyIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetName("api_key_hash_unique"),
+		},
+	}
+
+	apiKeyIndexNames, err := apiKeyCollection.Indexes().CreateMany(ctx, apiKeyIndexes)
+	if err != nil {
+		logger.Error("Failed to create API key indexes", zap.Error(err))
+		return nil, err
 	}
 
 	allIndexNames := append(userIndexNames, companyIndexNames...)
+	allIndexNames = append(allIndexNames, auditIndexNames...)
+	allIndexNames = append(allIndexNames, sessionIndexNames...)
+	allIndexNames = append(allIndexNames, apiKeyIndexNames...)
 	logger.Info("Database indexes created successfully",
 		zap.Strings("user_indexes", userIndexNames),
 		zap.Strings("company_indexes", companyIndexNames),
+		zap.Strings("audit_indexes", auditIndexNames),
+		zap.Strings("session_indexes", sessionIndexNames),
+		zap.Strings("api_key_indexes", apiKeyIndexNames),
 		zap.Int("total_indexes", len(allIndexNames)))
-	return nil
+	return allIndexNames, nil
 }
 
 // DropIndexes drops all custom indexes (useful for testing or migration)
@@ -143,7 +252,7 @@ func DropIndexes(db *mongo.Database, logger *zap.Logger) error {
 	if db == nil {
 		return fmt.Errorf("database is nil")
 	}
-	
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -182,7 +291,7 @@ func CheckIndexes(db *mongo.Database, logger *zap.Logger) error {
 	if db == nil {
 		return fmt.Errorf("database is nil")
 	}
-	
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -219,14 +328,17 @@ func CheckIndexes(db *mongo.Database, logger *zap.Logger) error {
 		"created_at_index",
 		"is_verified_index",
 		"is_onboarded_index",
+		"name_normalized_index",
+		"deletion_scheduled_at_index",
 		"email_verified_compound",
 	}
 
 	// Required company indexes
 	requiredCompanyIndexes := []string{
 		"company_name_index",
+		"company_name_normalized_index",
 		"company_email_unique",
-		"company_phone_index",
+		"company_phone_unique",
 		"company_created_at_index",
 		"company_updated_at_index",
 		"company_user_id_index",
@@ -282,7 +394,7 @@ func RebuildCompanyIndexes(db *mongo.Database, logger *zap.Logger) error {
 	if db == nil {
 		return fmt.Errorf("database is nil")
 	}
-	
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -294,9 +406,10 @@ func RebuildCompanyIndexes(db *mongo.Database, logger *zap.Logger) error {
 		logger.Warn("Could not drop existing company_email_unique index", zap.Error(err))
 	}
 
-	// Create new sparse unique index for company email
+	// Create new sparse unique index for company email. Companies without an
+	// email omit the field entirely, so the sparse index skips them.
 	emailIndex := mongo.IndexModel{
-		Keys: bson.D{{Key: "email", Value: 1}},
+		Keys: bson.D{{Key: "company_email", Value: 1}},
 		Options: options.Index().
 			SetUnique(true).
 			SetSparse(true).
@@ -310,5 +423,30 @@ func RebuildCompanyIndexes(db *mongo.Database, logger *zap.Logger) error {
 	}
 
 	logger.Info("Company email index rebuilt successfully", zap.String("index", indexName))
+
+	// The phone index used to be a plain index on the wrong field, drop it
+	// along with any previous unique one before recreating
+	for _, name := range []string{"company_phone_index", "company_phone_unique"} {
+		if _, err := companyCollection.Indexes().DropOne(ctx, name); err != nil {
+			logger.Warn("Could not drop existing company phone index", zap.String("index", name), zap.Error(err))
+		}
+	}
+
+	// Like email, companies without a phone omit the field
+	phoneIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "company_phone", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetSparse(true).
+			SetName("company_phone_unique"),
+	}
+
+	indexName, err = companyCollection.Indexes().CreateOne(ctx, phoneIndex)
+	if err != nil {
+		logger.Error("Failed to create company phone sparse index", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Company phone index rebuilt successfully", zap.String("index", indexName))
 	return nil
 }
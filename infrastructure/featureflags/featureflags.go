@@ -0,0 +1,107 @@
+package featureflags
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Flag names accepted by Enabled
+const (
+	WelcomeEmail          = "welcome_email"
+	RequireVerification   = "require_verification"
+	PublicDirectory       = "public_directory"
+	MaintenanceMode       = "maintenance_mode"
+	PasswordChangedNotice = "password_changed_notice"
+	ReverifyOnEmailChange = "reverify_on_email_change"
+	BotFilter             = "bot_filter"
+	EmailMXCheck          = "email_mx_check"
+)
+
+// Flags holds the feature toggles loaded at startup
+type Flags struct {
+	WelcomeEmail          bool   `json:"welcome_email"`
+	RequireVerification   bool   `json:"require_verification"`
+	PublicDirectory       bool   `json:"public_directory"`
+	MaintenanceMode       bool   `json:"maintenance_mode"`
+	MaintenanceMessage    string `json:"maintenance_message"`
+	PasswordChangedNotice bool   `json:"password_changed_notice"`
+	ReverifyOnEmailChange bool   `json:"reverify_on_email_change"`
+	BotFilter             bool   `json:"bot_filter"`
+	// BotDenyPatterns is a comma-separated list of User-Agent substrings
+	// rejected by the bot filter, matched case-insensitively
+	BotDenyPatterns string `json:"bot_deny_patterns"`
+	// EmailMXCheck rejects registrations whose email domain has no MX record
+	EmailMXCheck bool `json:"email_mx_check"`
+}
+
+// Default returns the flags used when nothing is configured, matching the
+// service behaviour before the toggles existed
+func Default() *Flags {
+	return &Flags{
+		WelcomeEmail:        false,
+		RequireVerification: true,
+		PublicDirectory:     true,
+		MaintenanceMode:     false,
+	}
+}
+
+// Load reads the FEATURE_* environment variables on top of the defaults
+func Load() *Flags {
+	return LoadFrom(os.Getenv)
+}
+
+// LoadFrom builds the flags from the given lookup, unset or unparsable values keep their default
+func LoadFrom(getenv func(string) string) *Flags {
+	flags := Default()
+	flags.WelcomeEmail = parseBool(getenv("FEATURE_WELCOME_EMAIL"), flags.WelcomeEmail)
+	flags.RequireVerification = parseBool(getenv("FEATURE_REQUIRE_VERIFICATION"), flags.RequireVerification)
+	flags.PublicDirectory = parseBool(getenv("FEATURE_PUBLIC_DIRECTORY"), flags.PublicDirectory)
+	flags.MaintenanceMode = parseBool(getenv("FEATURE_MAINTENANCE_MODE"), flags.MaintenanceMode)
+	flags.MaintenanceMessage = strings.TrimSpace(getenv("FEATURE_MAINTENANCE_MESSAGE"))
+	flags.PasswordChangedNotice = parseBool(getenv("NOTIFY_ON_PASSWORD_CHANGE"), flags.PasswordChangedNotice)
+	flags.ReverifyOnEmailChange = parseBool(getenv("FEATURE_REVERIFY_ON_EMAIL_CHANGE"), flags.ReverifyOnEmailChange)
+	flags.BotFilter = parseBool(getenv("FEATURE_BOT_FILTER"), flags.BotFilter)
+	flags.BotDenyPatterns = strings.TrimSpace(getenv("BOT_DENY_PATTERNS"))
+	flags.EmailMXCheck = parseBool(getenv("FEATURE_EMAIL_MX_CHECK"), flags.EmailMXCheck)
+	return flags
+}
+
+// Enabled reports whether the named boolean flag is on. A nil Flags behaves
+// like Default so callers that were not given flags keep working.
+func (f *Flags) Enabled(name string) bool {
+	if f == nil {
+		f = Default()
+	}
+	switch name {
+	case WelcomeEmail:
+		return f.WelcomeEmail
+	case RequireVerification:
+		return f.RequireVerification
+	case PublicDirectory:
+		return f.PublicDirectory
+	case MaintenanceMode:
+		return f.MaintenanceMode
+	case PasswordChangedNotice:
+		return f.PasswordChangedNotice
+	case ReverifyOnEmailChange:
+		return f.ReverifyOnEmailChange
+	case BotFilter:
+		return f.BotFilter
+	case EmailMXCheck:
+		return f.EmailMXCheck
+	}
+	return false
+}
+
+func parseBool(value string, fallback bool) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,215 @@
+package featureflags
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func envMap(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestLoadFrom_Defaults(t *testing.T) {
+	flags := LoadFrom(envMap(nil))
+
+	if *flags != *Default() {
+		t.Errorf("Expected defaults when nothing is set, got %+v", flags)
+	}
+	if !flags.RequireVerification || !flags.PublicDirectory {
+		t.Error("Expected verification and public directory to be on by default")
+	}
+	if flags.WelcomeEmail || flags.MaintenanceMode {
+		t.Error("Expected welcome email and maintenance mode to be off by default")
+	}
+}
+
+func TestLoadFrom_ParsesValues(t *testing.T) {
+	flags := LoadFrom(envMap(map[string]string{
+		"FEATURE_WELCOME_EMAIL":            "true",
+		"FEATURE_REQUIRE_VERIFICATION":     "0",
+		"FEATURE_PUBLIC_DIRECTORY":         " FALSE ",
+		"FEATURE_MAINTENANCE_MODE":         "1",
+		"FEATURE_MAINTENANCE_MESSAGE":      "  Back at 10:00 UTC  ",
+		"NOTIFY_ON_PASSWORD_CHANGE":        "true",
+		"FEATURE_REVERIFY_ON_EMAIL_CHANGE": "true",
+		"FEATURE_BOT_FILTER":               "true",
+		"BOT_DENY_PATTERNS":                " curl, python-requests ",
+		"FEATURE_EMAIL_MX_CHECK":           "true",
+	}))
+
+	if !flags.WelcomeEmail {
+		t.Error("Expected welcome email to be enabled")
+	}
+	if flags.RequireVerification {
+		t.Error("Expected verification requirement to be disabled")
+	}
+	if flags.PublicDirectory {
+		t.Error("Expected public directory to be disabled")
+	}
+	if !flags.MaintenanceMode {
+		t.Error("Expected maintenance mode to be enabled")
+	}
+	if !flags.PasswordChangedNotice {
+		t.Error("Expected password changed notice to be enabled")
+	}
+	if !flags.ReverifyOnEmailChange {
+		t.Error("Expected reverify on email change to be enabled")
+	}
+	if !flags.BotFilter {
+		t.Error("Expected bot filter to be enabled")
+	}
+	if !flags.EmailMXCheck {
+		t.Error("Expected email MX check to be enabled")
+	}
+	if flags.BotDenyPatterns != "curl, python-requests" {
+		t.Errorf("Expected bot deny patterns to be trimmed, got %q", flags.BotDenyPatterns)
+	}
+	if flags.MaintenanceMessage != "Back at 10:00 UTC" {
+		t.Errorf("Expected trimmed maintenance message, got %q", flags.MaintenanceMessage)
+	}
+}
+
+func TestLoadFrom_InvalidValueKeepsDefault(t *testing.T) {
+	flags := LoadFrom(envMap(map[string]string{
+		"FEATURE_REQUIRE_VERIFICATION": "sometimes",
+		"FEATURE_WELCOME_EMAIL":        "yes please",
+	}))
+
+	if !flags.RequireVerification {
+		t.Error("Expected invalid value to keep the default of true")
+	}
+	if flags.WelcomeEmail {
+		t.Error("Expected invalid value to keep the default of false")
+	}
+}
+
+func TestLoad_ReadsEnvironment(t *testing.T) {
+	original := os.Getenv("FEATURE_PUBLIC_DIRECTORY")
+	os.Setenv("FEATURE_PUBLIC_DIRECTORY", "false")
+	defer os.Setenv("FEATURE_PUBLIC_DIRECTORY", original)
+
+	if Load().PublicDirectory {
+		t.Error("Expected FEATURE_PUBLIC_DIRECTORY=false to disable the public directory")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	flags := &Flags{WelcomeEmail: true, MaintenanceMode: true}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{WelcomeEmail, true},
+		{RequireVerification, false},
+		{PublicDirectory, false},
+		{MaintenanceMode, true},
+		{PasswordChangedNotice, false},
+		{ReverifyOnEmailChange, false},
+		{"unknown_flag", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := flags.Enabled(tt.name); got != tt.expected {
+				t.Errorf("Enabled(%q) = %v, expected %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnabled_NilUsesDefaults(t *testing.T) {
+	var flags *Flags
+
+	if !flags.Enabled(RequireVerification) || !flags.Enabled(PublicDirectory) {
+		t.Error("Expected nil flags to fall back to the defaults")
+	}
+	if flags.Enabled(MaintenanceMode) {
+		t.Error("Expected nil flags to leave maintenance mode off")
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		flags        *Flags
+		expectedCode int
+		expectedBody string
+	}{
+		{"disabled", &Flags{}, http.StatusOK, "ok"},
+		{"enabled", &Flags{MaintenanceMode: true}, http.StatusServiceUnavailable, "MAINTENANCE_MODE"},
+		{"custom message", &Flags{MaintenanceMode: true, MaintenanceMessage: "Back soon"}, http.StatusServiceUnavailable, "Back soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Maintenance(tt.flags))
+			router.GET("/test", func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRejectBots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	enabled := &Flags{BotFilter: true, BotDenyPatterns: "curl, python-requests"}
+	tests := []struct {
+		name         string
+		flags        *Flags
+		userAgent    string
+		expectedCode int
+		expectedBody string
+	}{
+		{"disabled allows empty user agent", &Flags{}, "", http.StatusOK, "ok"},
+		{"empty user agent", enabled, "", http.StatusBadRequest, "REQUEST_REJECTED"},
+		{"blank user agent", enabled, "   ", http.StatusBadRequest, "REQUEST_REJECTED"},
+		{"denied user agent", enabled, "curl/8.4.0", http.StatusBadRequest, "REQUEST_REJECTED"},
+		{"denied case-insensitively", enabled, "Python-Requests/2.31", http.StatusBadRequest, "REQUEST_REJECTED"},
+		{"browser user agent", enabled, "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) Safari/605.1.15", http.StatusOK, "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RejectBots(tt.flags))
+			router.POST("/login", func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/login", nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package featureflags
+
+import (
+	"strings"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Maintenance rejects requests with 503 while maintenance mode is on
+func Maintenance(flags *Flags) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.Enabled(MaintenanceMode) {
+			c.Next()
+			return
+		}
+
+		err := *appErrors.ErrMaintenanceMode
+		if flags.MaintenanceMessage != "" {
+			err.Message = flags.MaintenanceMessage
+		}
+		response.ErrorFromAppError(c, &err)
+		c.Abort()
+	}
+}
+
+// RejectBots turns away requests without a User-Agent or whose User-Agent
+// contains one of the deny patterns, while the bot filter flag is on. It only
+// stops trivial scripts, anything can send a browser User-Agent.
+func RejectBots(flags *Flags) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.Enabled(BotFilter) {
+			c.Next()
+			return
+		}
+
+		userAgent := strings.ToLower(strings.TrimSpace(c.Request.UserAgent()))
+		if userAgent == "" || deniedUserAgent(userAgent, flags.BotDenyPatterns) {
+			response.ErrorFromAppError(c, appErrors.ErrRequestRejected)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func deniedUserAgent(userAgent, patterns string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern != "" && strings.Contains(userAgent, pattern) {
+			return true
+		}
+	}
+	return false
+}
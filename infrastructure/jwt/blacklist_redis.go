@@ -0,0 +1,90 @@
+package jwt
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Blacklist revokes tokens by JTI until they expire. BlacklistService keeps
+// them in MongoDB, RedisBlacklist in Redis.
+type Blacklist interface {
+	BlacklistToken(jti, userEmail string, expiresAt time.Time) error
+	IsTokenBlacklisted(jti string) bool
+}
+
+// redisBlacklistPrefix namespaces revoked JTIs in a shared Redis
+const redisBlacklistPrefix = "token_blacklist:"
+
+// RedisBlacklist keeps revoked JTIs in Redis, so a logout is seen by every
+// instance of the service at once. Each key expires with its token, there is
+// nothing to clean up.
+type RedisBlacklist struct {
+	client *redis.Client
+	logger *zap.Logger
+}
+
+// NewRedisBlacklist connects to the Redis at redisURL, e.g.
+// redis://:password@localhost:6379/0, and checks it answers
+func NewRedisBlacklist(redisURL string, logger *zap.Logger) (*RedisBlacklist, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisBlacklist{client: client, logger: logger}, nil
+}
+
+// BlacklistToken revokes jti until expiresAt. An already expired token is
+// refused by the JWT check anyway and isn't stored.
+func (rb *RedisBlacklist) BlacklistToken(jti, userEmail string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rb.client.Set(ctx, redisBlacklistPrefix+jti, userEmail, ttl).Err(); err != nil {
+		rb.logger.Error("Failed to blacklist token in redis",
+			zap.String("jti", jti),
+			zap.String("user_email", userEmail),
+			zap.Error(err))
+		return err
+	}
+
+	rb.logger.Info("Token blacklisted successfully",
+		zap.String("jti", jti),
+		zap.String("user_email", userEmail))
+	return nil
+}
+
+// IsTokenBlacklisted checks if a token is blacklisted. Like BlacklistService
+// it lets the token through when the lookup fails.
+func (rb *RedisBlacklist) IsTokenBlacklisted(jti string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	count, err := rb.client.Exists(ctx, redisBlacklistPrefix+jti).Result()
+	if err != nil {
+		rb.logger.Warn("Error checking token blacklist",
+			zap.String("jti", jti),
+			zap.Error(err))
+		return false
+	}
+	return count > 0
+}
+
+// Close releases the Redis connections
+func (rb *RedisBlacklist) Close() error {
+	return rb.client.Close()
+}
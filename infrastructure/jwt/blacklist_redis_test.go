@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func newTestRedisBlacklist(t *testing.T) (*RedisBlacklist, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	blacklist, err := NewRedisBlacklist("redis://"+server.Addr(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to connect to redis: %v", err)
+	}
+	t.Cleanup(func() { blacklist.Close() })
+	return blacklist, server
+}
+
+func TestRedisBlacklist(t *testing.T) {
+	blacklist, server := newTestRedisBlacklist(t)
+
+	if blacklist.IsTokenBlacklisted("jti-1") {
+		t.Error("Expected an unknown token not to be blacklisted")
+	}
+	if err := blacklist.BlacklistToken("jti-1", "test@example.com", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !blacklist.IsTokenBlacklisted("jti-1") {
+		t.Error("Expected the token to be blacklisted")
+	}
+	if ttl := server.TTL(redisBlacklistPrefix + "jti-1"); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("Expected the key to expire with the token, got a TTL of %v", ttl)
+	}
+
+	server.FastForward(time.Hour)
+	if blacklist.IsTokenBlacklisted("jti-1") {
+		t.Error("Expected the entry to go once the token expired")
+	}
+
+	if err := blacklist.BlacklistToken("jti-2", "test@example.com", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Expected an expired token to be ignored, got %v", err)
+	}
+	if server.Exists(redisBlacklistPrefix + "jti-2") {
+		t.Error("Expected nothing to be stored for an expired token")
+	}
+}
+
+func TestRedisBlacklist_Unavailable(t *testing.T) {
+	if _, err := NewRedisBlacklist("redis://127.0.0.1:1", zap.NewNop()); err == nil {
+		t.Error("Expected an unreachable redis to fail")
+	}
+	if _, err := NewRedisBlacklist("not a url", zap.NewNop()); err == nil {
+		t.Error("Expected an invalid URL to fail")
+	}
+
+	blacklist, server := newTestRedisBlacklist(t)
+	server.Close()
+	if blacklist.IsTokenBlacklisted("jti-1") {
+		t.Error("Expected a failed lookup to let the token through")
+	}
+}
+
+func TestJWTMiddleware_RedisBlacklist(t *testing.T) {
+	setupMiddlewareTest()
+	blacklist, _ := newTestRedisBlacklist(t)
+
+	token, err := createTestJWTToken("user123", "test@example.com", "", "jti-logout", "test-secret-key-for-middleware-testing", time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create test token: %v", err)
+	}
+	router := gin.New()
+	router.GET("/protected", JWTMiddleware(blacklist), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func() int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := get(); code != http.StatusOK {
+		t.Fatalf("Expected the token to pass before logout, got %d", code)
+	}
+	blacklist.BlacklistToken("jti-logout", "test@example.com", time.Now().Add(time.Hour))
+	if code := get(); code != http.StatusUnauthorized {
+		t.Errorf("Expected the token to be refused after logout, got %d", code)
+	}
+}
@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCookieName is the cookie the token travels in unless COOKIE_NAME
+// says otherwise
+const DefaultCookieName = "token"
+
+// CookieName is the cookie JWTMiddleware reads the token from, set it to the
+// Name of the CookieConfig the handlers use
+var CookieName = DefaultCookieName
+
+// CookieConfig holds the attributes of the token cookie. It is always
+// HttpOnly with path /, and expires with the token it holds.
+type CookieConfig struct {
+	Name   string
+	Domain string
+	// SameSite is left out of the cookie when http.SameSiteDefaultMode
+	SameSite http.SameSite
+	// Secure only sends the cookie over HTTPS, turn it off for local
+	// development over plain HTTP
+	Secure bool
+	// MaxAge is the cookie lifetime in seconds
+	MaxAge int
+}
+
+// DefaultCookieConfig is a secure host-only cookie living jwtExpire minutes
+func DefaultCookieConfig(jwtExpire int) CookieConfig {
+	return CookieConfig{Name: DefaultCookieName, Secure: true, MaxAge: jwtExpire * 60}
+}
+
+// LoadCookieConfig reads COOKIE_NAME, COOKIE_DOMAIN, COOKIE_SAMESITE (lax,
+// strict or none) and COOKIE_SECURE over DefaultCookieConfig. SameSite=None
+// is refused without Secure, browsers drop such cookies.
+func LoadCookieConfig(jwtExpire int) (CookieConfig, error) {
+	config := DefaultCookieConfig(jwtExpire)
+	if name := strings.TrimSpace(os.Getenv("COOKIE_NAME")); name != "" {
+		config.Name = name
+	}
+	config.Domain = strings.TrimSpace(os.Getenv("COOKIE_DOMAIN"))
+
+	switch sameSite := strings.ToLower(strings.TrimSpace(os.Getenv("COOKIE_SAMESITE"))); sameSite {
+	case "":
+	case "lax":
+		config.SameSite = http.SameSiteLaxMode
+	case "strict":
+		config.SameSite = http.SameSiteStrictMode
+	case "none":
+		config.SameSite = http.SameSiteNoneMode
+	default:
+		return config, fmt.Errorf("unsupported COOKIE_SAMESITE %q, expected lax, strict or none", sameSite)
+	}
+
+	if value := os.Getenv("COOKIE_SECURE"); value != "" {
+		secure, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid COOKIE_SECURE %q: %w", value, err)
+		}
+		config.Secure = secure
+	}
+	if config.SameSite == http.SameSiteNoneMode && !config.Secure {
+		return config, fmt.Errorf("COOKIE_SAMESITE=none needs COOKIE_SECURE")
+	}
+	return config, nil
+}
+
+// SetToken stores token in the cookie
+func (config CookieConfig) SetToken(c *gin.Context, token string) {
+	config.write(c, token, config.MaxAge)
+}
+
+// ClearToken expires the cookie. The attributes must match the ones it was
+// set with, or the browser keeps it.
+func (config CookieConfig) ClearToken(c *gin.Context) {
+	config.write(c, "", -1)
+}
+
+func (config CookieConfig) write(c *gin.Context, value string, maxAge int) {
+	if config.SameSite != http.SameSiteDefaultMode {
+		c.SetSameSite(config.SameSite)
+	}
+	c.SetCookie(config.Name, value, maxAge, "/", config.Domain, config.Secure, true)
+}
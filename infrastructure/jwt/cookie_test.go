@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoadCookieConfig(t *testing.T) {
+	for _, key := range []string{"COOKIE_NAME", "COOKIE_DOMAIN", "COOKIE_SAMESITE", "COOKIE_SECURE"} {
+		t.Setenv(key, "")
+	}
+	config, err := LoadCookieConfig(60)
+	if err != nil || config != DefaultCookieConfig(60) {
+		t.Errorf("Expected the defaults without configuration, got %+v %v", config, err)
+	}
+	if config.Name != "token" || !config.Secure || config.MaxAge != 3600 {
+		t.Errorf("Expected a secure token cookie living an hour, got %+v", config)
+	}
+
+	t.Setenv("COOKIE_NAME", "byow_session")
+	t.Setenv("COOKIE_DOMAIN", ".example.com")
+	t.Setenv("COOKIE_SAMESITE", "Strict")
+	t.Setenv("COOKIE_SECURE", "false")
+	config, err = LoadCookieConfig(15)
+	want := CookieConfig{Name: "byow_session", Domain: ".example.com", SameSite: http.SameSiteStrictMode, Secure: false, MaxAge: 900}
+	if err != nil || config != want {
+		t.Errorf("Expected %+v, got %+v %v", want, config, err)
+	}
+
+	t.Setenv("COOKIE_SAMESITE", "none")
+	if _, err := LoadCookieConfig(15); err == nil {
+		t.Error("Expected SameSite=None without Secure to be refused")
+	}
+	t.Setenv("COOKIE_SECURE", "true")
+	if config, err := LoadCookieConfig(15); err != nil || config.SameSite != http.SameSiteNoneMode {
+		t.Errorf("Expected SameSite=None with Secure, got %+v %v", config, err)
+	}
+	t.Setenv("COOKIE_SAMESITE", "sometimes")
+	if _, err := LoadCookieConfig(15); err == nil {
+		t.Error("Expected an unknown SameSite to be refused")
+	}
+	t.Setenv("COOKIE_SAMESITE", "")
+	t.Setenv("COOKIE_SECURE", "maybe")
+	if _, err := LoadCookieConfig(15); err == nil {
+		t.Error("Expected an invalid COOKIE_SECURE to be refused")
+	}
+}
+
+func TestCookieConfig_SetAndClear(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := CookieConfig{Name: "byow_session", Domain: "example.com", SameSite: http.SameSiteLaxMode, Secure: true, MaxAge: 900}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	config.SetToken(c, "abc")
+	config.ClearToken(c)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("Expected two cookies, got %v", w.Header().Values("Set-Cookie"))
+	}
+	for i, maxAge := range []int{900, -1} {
+		cookie := cookies[i]
+		if cookie.Name != "byow_session" || cookie.Domain != "example.com" || cookie.Path != "/" ||
+			cookie.SameSite != http.SameSiteLaxMode || !cookie.Secure || !cookie.HttpOnly || cookie.MaxAge != maxAge {
+			t.Errorf("Unexpected cookie %d: %+v", i, cookie)
+		}
+	}
+	if cookies[0].Value != "abc" || cookies[1].Value != "" {
+		t.Errorf("Expected the token then an empty value, got %q and %q", cookies[0].Value, cookies[1].Value)
+	}
+}
+
+func TestJWTMiddleware_CookieName(t *testing.T) {
+	setupMiddlewareTest()
+	CookieName = "byow_session"
+	t.Cleanup(func() { CookieName = DefaultCookieName })
+
+	token, _ := GenerateToken("user123", "test@example.com", "", os.Getenv("JWT_SECRET"), 60)
+	if c, _ := runMiddleware(token); !c.IsAborted() {
+		t.Error("Expected the default cookie to be ignored once renamed")
+	}
+
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.AddCookie(&http.Cookie{Name: "byow_session", Value: token})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	JWTMiddleware(nil)(c)
+	if c.IsAborted() || c.GetString("email") != "test@example.com" {
+		t.Errorf("Expected the token to be read from the renamed cookie, got %d", w.Code)
+	}
+}
@@ -3,16 +3,62 @@ package jwt
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ParseExpire reads the token lifetime in minutes from JWT_EXPIRE. It must
+// be a positive number, zero would issue tokens that are already expired and
+// set the token cookie as a session cookie.
+func ParseExpire(value string) (int, error) {
+	minutes, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || minutes <= 0 {
+		return 0, fmt.Errorf("JWT_EXPIRE must be a positive number of minutes, got %q", value)
+	}
+	return minutes, nil
+}
+
 func GenerateToken(user_id string, email string, phone string, secret string, minutes int) (string, error) {
+	return GenerateTokenWithRole(user_id, email, phone, "", secret, minutes)
+}
+
+// GenerateTokenWithRole is GenerateToken with a role claim, checked by RequirePermission.
+// secret is only used while tokens are signed with HS256, see Configure.
+func GenerateTokenWithRole(user_id string, email string, phone string, role string, secret string, minutes int) (string, error) {
+	return GenerateTokenWithVersion(user_id, email, phone, role, 0, secret, minutes)
+}
+
+// GenerateTokenWithVersion is GenerateTokenWithRole stamped with the user's
+// token version, RequireTokenVersion refuses it once the version moves on
+func GenerateTokenWithVersion(user_id string, email string, phone string, role string, tokenVersion int, secret string, minutes int) (string, error) {
+	claims, err := userClaims(user_id, email, phone, role, tokenVersion, minutes)
+	if err != nil {
+		return "", err
+	}
+	return signToken(claims, secret)
+}
+
+// GenerateImpersonationToken is GenerateTokenWithVersion for a user an admin
+// acts as. The admin is named in the RFC 8693 act claim, which JWTMiddleware
+// stores as impersonator_id.
+func GenerateImpersonationToken(user_id string, email string, phone string, role string, tokenVersion int, actorID string, secret string, minutes int) (string, error) {
+	claims, err := userClaims(user_id, email, phone, role, tokenVersion, minutes)
+	if err != nil {
+		return "", err
+	}
+	claims["act"] = map[string]interface{}{"sub": actorID}
+	return signToken(claims, secret)
+}
+
+func userClaims(user_id string, email string, phone string, role string, tokenVersion int, minutes int) (jwt.MapClaims, error) {
 	// Generate unique JTI (JWT ID) for token revocation
 	jti, err := generateJTI()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	now := time.Now()
@@ -26,8 +72,43 @@ func GenerateToken(user_id string, email string, phone string, secret string, mi
 		"iss":     "byow-user-service",
 		"aud":     "byow-platform",
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	if role != "" {
+		claims["role"] = role
+	}
+	if tokenVersion > 0 {
+		claims["token_version"] = tokenVersion
+	}
+	return claims, nil
+}
+
+// TokenInfo identifies a token and its lifetime
+type TokenInfo struct {
+	UserID    string
+	JTI       string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// ReadTokenInfo reads the claims a session is recorded with. The signature
+// isn't checked, only pass it tokens this service has just issued.
+func ReadTokenInfo(tokenStr string) (TokenInfo, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenStr, claims); err != nil {
+		return TokenInfo{}, err
+	}
+	info := TokenInfo{}
+	info.UserID, _ = claims["user_id"].(string)
+	info.JTI, _ = claims["jti"].(string)
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		info.IssuedAt = iat.Time
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		info.ExpiresAt = exp.Time
+	}
+	if info.JTI == "" || info.ExpiresAt.IsZero() {
+		return TokenInfo{}, jwt.ErrTokenInvalidClaims
+	}
+	return info, nil
 }
 
 // generateJTI creates a unique JWT ID for token revocation
@@ -8,6 +8,17 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+func TestParseExpire(t *testing.T) {
+	if minutes, err := ParseExpire(" 60 "); minutes != 60 || err != nil {
+		t.Errorf("Expected 60 minutes, got %d, %v", minutes, err)
+	}
+	for _, value := range []string{"", "0", "-5", "1h"} {
+		if _, err := ParseExpire(value); err == nil {
+			t.Errorf("Expected %q to be refused", value)
+		}
+	}
+}
+
 func TestGenerateToken(t *testing.T) {
 	userID := "user123"
 	email := "test@example.com"
@@ -106,13 +117,42 @@ func TestGenerateTokenClaims(t *testing.T) {
 
 	expiresAt := time.Unix(int64(exp), 0)
 	expectedExpiry := issuedAt.Add(time.Duration(minutes) * time.Minute)
-	
+
 	// Allow for small time differences (within 5 seconds)
 	if expiresAt.Sub(expectedExpiry).Abs() > 5*time.Second {
 		t.Errorf("Expected expiry %v, got %v", expectedExpiry, expiresAt)
 	}
 }
 
+func TestGenerateTokenWithRole(t *testing.T) {
+	secret := "test-secret-key"
+	parseClaims := func(token string) jwt.MapClaims {
+		parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to parse token: %v", err)
+		}
+		return parsedToken.Claims.(jwt.MapClaims)
+	}
+
+	token, err := GenerateTokenWithRole("user123", "admin@example.com", "+1234567890", "admin", secret, 30)
+	if err != nil {
+		t.Fatalf("GenerateTokenWithRole() error = %v", err)
+	}
+	if role := parseClaims(token)["role"]; role != "admin" {
+		t.Errorf("Expected role admin, got %v", role)
+	}
+
+	token, err = GenerateToken("user123", "test@example.com", "+1234567890", secret, 30)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, ok := parseClaims(token)["role"]; ok {
+		t.Error("Expected no role claim for a regular user")
+	}
+}
+
 func TestGenerateTokenWithDifferentExpiry(t *testing.T) {
 	userID := "user123"
 	email := "test@example.com"
@@ -191,7 +231,7 @@ func TestGenerateTokenWithEmptyInputs(t *testing.T) {
 		{
 			name:    "empty user ID",
 			userID:  "",
-			email:   "test@example.com", 
+			email:   "test@example.com",
 			phone:   "+1234567890",
 			secret:  "secret",
 			minutes: 30,
@@ -344,17 +384,17 @@ func TestGenerateJTIError(t *testing.T) {
 	// This test is mainly for coverage completion
 	// Under normal circumstances, generateJTI should not fail
 	// But we can test that it generates proper hex strings
-	
+
 	for i := 0; i < 100; i++ {
 		jti, err := generateJTI()
 		if err != nil {
 			t.Fatalf("generateJTI() unexpected error = %v", err)
 		}
-		
+
 		if len(jti) != 32 {
 			t.Errorf("Expected JTI length 32, got %d", len(jti))
 		}
-		
+
 		// Verify it's valid hex
 		for _, char := range jti {
 			if !((char >= '0' && char <= '9') || (char >= 'a' && char <= 'f')) {
@@ -362,4 +402,4 @@ func TestGenerateJTIError(t *testing.T) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,292 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms accepted by JWT_ALG
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// signingKey is an asymmetric private key tokens are signed with from
+// notBefore on
+type signingKey struct {
+	method    jwt.SigningMethod
+	key       crypto.Signer
+	kid       string
+	notBefore time.Time
+}
+
+// keyring holds every asymmetric key tokens are verified with, the newest
+// one past its notBefore signs. It is empty while tokens are signed with
+// HS256 and the shared secret.
+var keyring []*signingKey
+
+// Configure selects the token signing algorithm. HS256, the default when alg
+// is empty, signs with the secret passed to GenerateToken. RS256 and ES256
+// sign with the PEM encoded RSA or P-256 private key at privateKeyFiles and
+// verify with its public key, which PublicJWKS publishes for other services.
+//
+// privateKeyFiles may list several comma separated keys to rotate them, each
+// optionally followed by @ and the RFC 3339 time it starts signing, e.g.
+// "old.pem,new.pem@2025-04-01T00:00:00Z". The latest key to have started
+// signs, all of them verify, so tokens of a replaced key keep working until
+// the key is dropped from the list.
+func Configure(alg, privateKeyFiles string) error {
+	switch alg {
+	case "", AlgHS256:
+		UseRSAKey(nil)
+		return nil
+	case AlgRS256, AlgES256:
+	default:
+		return fmt.Errorf("unsupported JWT_ALG %q, expected %s, %s or %s", alg, AlgHS256, AlgRS256, AlgES256)
+	}
+	if strings.TrimSpace(privateKeyFiles) == "" {
+		return fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s", alg)
+	}
+
+	var keys []*signingKey
+	for _, entry := range strings.Split(privateKeyFiles, ",") {
+		path, notBefore, err := parseKeyEntry(strings.TrimSpace(entry))
+		if err != nil {
+			return err
+		}
+		pemBytes, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read JWT private key: %w", err)
+		}
+		var key *signingKey
+		if alg == AlgRS256 {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return fmt.Errorf("failed to parse JWT private key %s: %w", path, err)
+			}
+			key = newRSAKey(private)
+		} else {
+			private, err := jwt.ParseECPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return fmt.Errorf("failed to parse JWT private key %s: %w", path, err)
+			}
+			if key, err = newECKey(private); err != nil {
+				return err
+			}
+		}
+		key.notBefore = notBefore
+		keys = append(keys, key)
+	}
+	keyring = keys
+	return nil
+}
+
+// parseKeyEntry splits "path@time" into the key file and when it starts
+// signing, zero for a plain path
+func parseKeyEntry(entry string) (string, time.Time, error) {
+	at := strings.LastIndex(entry, "@")
+	if at < 0 {
+		return entry, time.Time{}, nil
+	}
+	notBefore, err := time.Parse(time.RFC3339, entry[at+1:])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid start time in JWT key %q, expected RFC 3339: %w", entry, err)
+	}
+	return entry[:at], notBefore, nil
+}
+
+// UseRSAKey switches token signing to RS256 with key alone, nil goes back
+// to HS256
+func UseRSAKey(key *rsa.PrivateKey) {
+	if key == nil {
+		keyring = nil
+		return
+	}
+	keyring = []*signingKey{newRSAKey(key)}
+}
+
+// UseECKey switches token signing to ES256 with key alone, which must be on
+// the P-256 curve. nil goes back to HS256.
+func UseECKey(key *ecdsa.PrivateKey) error {
+	if key == nil {
+		keyring = nil
+		return nil
+	}
+	signing, err := newECKey(key)
+	if err != nil {
+		return err
+	}
+	keyring = []*signingKey{signing}
+	return nil
+}
+
+func newRSAKey(key *rsa.PrivateKey) *signingKey {
+	return &signingKey{method: jwt.SigningMethodRS256, key: key, kid: thumbprint(&key.PublicKey)}
+}
+
+func newECKey(key *ecdsa.PrivateKey) (*signingKey, error) {
+	if key.Curve != elliptic.P256() {
+		return nil, errors.New("ES256 needs a P-256 key")
+	}
+	return &signingKey{method: jwt.SigningMethodES256, key: key, kid: thumbprint(&key.PublicKey)}, nil
+}
+
+// currentKey is the key signing at now: the one with the latest notBefore
+// that has passed, the later in the list on a tie. Before any key has
+// started, the earliest scheduled one signs so tokens can still be issued.
+func currentKey(now time.Time) *signingKey {
+	var current, earliest *signingKey
+	for _, key := range keyring {
+		if !key.notBefore.After(now) && (current == nil || !key.notBefore.Before(current.notBefore)) {
+			current = key
+		}
+		if earliest == nil || key.notBefore.Before(earliest.notBefore) {
+			earliest = key
+		}
+	}
+	if current == nil {
+		return earliest
+	}
+	return current
+}
+
+// secretKeyID identifies an HS256 secret in the kid header without
+// revealing it
+func secretKeyID(secret string) string {
+	sum := sha256.Sum256([]byte("byow-jwt-secret:" + secret))
+	return "hs-" + base64.RawURLEncoding.EncodeToString(sum[:9])
+}
+
+// signToken signs claims with the configured algorithm
+func signToken(claims jwt.Claims, secret string) (string, error) {
+	if key := currentKey(time.Now()); key != nil {
+		token := jwt.NewWithClaims(key.method, claims)
+		token.Header["kid"] = key.kid
+		return token.SignedString(key.key)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = secretKeyID(secret)
+	return token.SignedString([]byte(secret))
+}
+
+// verificationKey is the jwt.Keyfunc of JWTMiddleware. The key is picked by
+// the token's kid, tokens without one are checked against the signing key.
+// Only the configured algorithm is accepted, so an HS256 token can't be
+// signed with the public key.
+//
+// HS256 tokens verify with JWT_SECRET or, while it is being rotated, any of
+// the comma separated JWT_PREVIOUS_SECRETS.
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if len(keyring) > 0 {
+		key := currentKey(time.Now())
+		if kid != "" {
+			key = nil
+			for _, candidate := range keyring {
+				if candidate.kid == kid {
+					key = candidate
+				}
+			}
+		}
+		if key == nil || token.Method.Alg() != key.method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return key.key.Public(), nil
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	secret := os.Getenv("JWT_SECRET")
+	if kid == "" || kid == secretKeyID(secret) {
+		return []byte(secret), nil
+	}
+	for _, previous := range strings.Split(os.Getenv("JWT_PREVIOUS_SECRETS"), ",") {
+		if previous = strings.TrimSpace(previous); previous != "" && kid == secretKeyID(previous) {
+			return []byte(previous), nil
+		}
+	}
+	return nil, jwt.ErrSignatureInvalid
+}
+
+// JWK is a public key in JSON Web Key format, N and E are set for RSA keys,
+// Crv, X and Y for EC keys
+type JWK struct {
+	Kty string `json:"kty" example:"RSA"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"RS256"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty" example:"AQAB"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns the public keys tokens are verified with, including
+// keys scheduled to sign later so other services can fetch them ahead of
+// the rotation. It has no keys while tokens are signed with HS256 since the
+// shared secret is never published.
+func PublicJWKS() JWKSet {
+	keys := make([]JWK, 0, len(keyring))
+	for _, key := range keyring {
+		jwk := publicJWK(key.key.Public())
+		jwk.Use = "sig"
+		jwk.Alg = key.method.Alg()
+		jwk.Kid = key.kid
+		keys = append(keys, jwk)
+	}
+	return JWKSet{Keys: keys}
+}
+
+// publicJWK returns the key parameters of an RSA or P-256 public key
+func publicJWK(key crypto.PublicKey) JWK {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		return JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}
+	}
+	return JWK{}
+}
+
+// thumbprint is the RFC 7638 thumbprint of key, used as its key ID. The
+// members are hashed in lexicographic order.
+func thumbprint(key crypto.PublicKey) string {
+	jwk := publicJWK(key)
+	var members string
+	if jwk.Kty == "EC" {
+		members = `{"crv":"` + jwk.Crv + `","kty":"EC","x":"` + jwk.X + `","y":"` + jwk.Y + `"}`
+	} else {
+		members = `{"e":"` + jwk.E + `","kty":"RSA","n":"` + jwk.N + `"}`
+	}
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,334 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useTestRSAKey signs tokens with a fresh RS256 key for the rest of the test
+func useTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	UseRSAKey(key)
+	t.Cleanup(func() { UseRSAKey(nil) })
+	return key
+}
+
+// runMiddleware passes token through JWTMiddleware and returns the context
+func runMiddleware(token string) (*gin.Context, *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.AddCookie(&http.Cookie{Name: "token", Value: token})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	JWTMiddleware(nil)(c)
+	return c, w
+}
+
+func TestRS256_RoundTrip(t *testing.T) {
+	setupMiddlewareTest()
+	key := useTestRSAKey(t)
+
+	token, err := GenerateTokenWithRole("user123", "test@example.com", "+1234567890", "admin", "", 60)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	if err != nil || !parsed.Valid {
+		t.Fatalf("Expected the token to verify with the public key, got %v", err)
+	}
+	if parsed.Method.Alg() != AlgRS256 {
+		t.Errorf("Expected RS256, got %s", parsed.Method.Alg())
+	}
+	if kid, _ := parsed.Header["kid"].(string); kid == "" || kid != PublicJWKS().Keys[0].Kid {
+		t.Errorf("Expected the kid header to match the JWKS, got %q", kid)
+	}
+
+	c, w := runMiddleware(token)
+	if c.IsAborted() {
+		t.Fatalf("Expected the middleware to accept the RS256 token, got %d", w.Code)
+	}
+	if c.GetString("email") != "test@example.com" || c.GetString("role") != "admin" {
+		t.Errorf("Expected claims on the context, got email=%q role=%q", c.GetString("email"), c.GetString("role"))
+	}
+}
+
+func TestRS256_RejectsOtherTokens(t *testing.T) {
+	setupMiddlewareTest()
+	useTestRSAKey(t)
+
+	// An HS256 token signed with the old shared secret
+	hsToken, err := createTestJWTToken("user123", "test@example.com", "", "jti-1", os.Getenv("JWT_SECRET"), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create HS256 token: %v", err)
+	}
+	if c, _ := runMiddleware(hsToken); !c.IsAborted() {
+		t.Error("Expected an HS256 token to be rejected while RS256 is configured")
+	}
+
+	// A token signed with another RSA key
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"email": "test@example.com"}).SignedString(other)
+	if c, _ := runMiddleware(forged); !c.IsAborted() {
+		t.Error("Expected a token signed with another key to be rejected")
+	}
+}
+
+func TestPublicJWKS(t *testing.T) {
+	if keys := PublicJWKS().Keys; len(keys) != 0 {
+		t.Errorf("Expected no keys with HS256, got %v", keys)
+	}
+
+	key := useTestRSAKey(t)
+	keys := PublicJWKS().Keys
+	if len(keys) != 1 {
+		t.Fatalf("Expected one key, got %d", len(keys))
+	}
+	jwk := keys[0]
+	if jwk.Kty != "RSA" || jwk.Alg != AlgRS256 || jwk.Use != "sig" || jwk.E != "AQAB" {
+		t.Errorf("Unexpected key parameters %+v", jwk)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil || new(big.Int).SetBytes(n).Cmp(key.N) != 0 {
+		t.Error("Expected the modulus to be the public key's")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { UseRSAKey(nil) })
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, pemBytes, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	if err := Configure(AlgRS256, path); err != nil {
+		t.Fatalf("Expected RS256 to be configured, got %v", err)
+	}
+	if len(keyring) != 1 || !key.PublicKey.Equal(keyring[0].key.Public()) {
+		t.Error("Expected the key from the file to be used")
+	}
+	if err := Configure("", ""); err != nil || len(keyring) != 0 {
+		t.Errorf("Expected HS256 by default, got err=%v", err)
+	}
+
+	if err := Configure(AlgRS256, ""); err == nil {
+		t.Error("Expected RS256 without a key file to fail")
+	}
+	if err := Configure(AlgRS256, filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("Expected a missing key file to fail")
+	}
+	if err := Configure(AlgES256, ""); err == nil {
+		t.Error("Expected ES256 without a key file to fail")
+	}
+	if err := Configure(AlgES256, path); err == nil {
+		t.Error("Expected an RSA key file to fail for ES256")
+	}
+	if err := Configure("PS256", path); err == nil {
+		t.Error("Expected an unsupported algorithm to fail")
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(ecKey)
+	ecPath := filepath.Join(t.TempDir(), "jwt-ec.pem")
+	if err := os.WriteFile(ecPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	if err := Configure(AlgES256, ecPath); err != nil {
+		t.Fatalf("Expected ES256 to be configured, got %v", err)
+	}
+	if len(keyring) != 1 || !ecKey.PublicKey.Equal(keyring[0].key.Public()) {
+		t.Error("Expected the EC key from the file to be used")
+	}
+}
+
+func TestES256_RoundTrip(t *testing.T) {
+	setupMiddlewareTest()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := UseECKey(key); err != nil {
+		t.Fatalf("Expected the P-256 key to be accepted, got %v", err)
+	}
+	t.Cleanup(func() { UseRSAKey(nil) })
+
+	token, err := GenerateTokenWithRole("user123", "test@example.com", "+1234567890", "admin", "", 60)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	if err != nil || !parsed.Valid || parsed.Method.Alg() != AlgES256 {
+		t.Fatalf("Expected an ES256 token verifying with the public key, got %v", err)
+	}
+	if c, w := runMiddleware(token); c.IsAborted() {
+		t.Fatalf("Expected the middleware to accept the ES256 token, got %d", w.Code)
+	}
+
+	// The JWKS carries the curve point, enough to verify without this service
+	jwk := PublicJWKS().Keys[0]
+	if jwk.Kty != "EC" || jwk.Crv != "P-256" || jwk.Alg != AlgES256 || jwk.N != "" || jwk.Kid != parsed.Header["kid"] {
+		t.Errorf("Unexpected key parameters %+v", jwk)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, _ := base64.RawURLEncoding.DecodeString(jwk.Y)
+	published := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if _, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return published, nil }); err != nil {
+		t.Errorf("Expected the token to verify with the published key, got %v", err)
+	}
+
+	// An RS256 token isn't accepted in place of an ES256 one
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsToken, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"email": "test@example.com"}).SignedString(other)
+	if c, _ := runMiddleware(rsToken); !c.IsAborted() {
+		t.Error("Expected an RS256 token to be rejected while ES256 is configured")
+	}
+
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err := UseECKey(p384); err == nil {
+		t.Error("Expected a P-384 key to be refused for ES256")
+	}
+}
+
+// writeRSAKey stores a fresh RSA key as PEM and returns it with its path
+func writeRSAKey(t *testing.T, dir, name string) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, pemBytes, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return key, path
+}
+
+func signedWith(t *testing.T, token string, key *rsa.PrivateKey) bool {
+	_, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+	return err == nil
+}
+
+func TestKeyRotation(t *testing.T) {
+	setupMiddlewareTest()
+	t.Cleanup(func() { UseRSAKey(nil) })
+	dir := t.TempDir()
+	_, oldPath := writeRSAKey(t, dir, "old.pem")
+	newKey, newPath := writeRSAKey(t, dir, "new.pem")
+	nextKey, nextPath := writeRSAKey(t, dir, "next.pem")
+
+	// Only the old key so far
+	if err := Configure(AlgRS256, oldPath); err != nil {
+		t.Fatalf("Expected RS256 to be configured, got %v", err)
+	}
+	oldToken, _ := GenerateToken("user123", "test@example.com", "", "", 60)
+
+	// The new key takes over, the old one still verifies, and the next one is
+	// published ahead of its start
+	later := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if err := Configure(AlgRS256, oldPath+", "+newPath+","+nextPath+"@"+later); err != nil {
+		t.Fatalf("Expected the key list to be configured, got %v", err)
+	}
+	newToken, _ := GenerateToken("user123", "test@example.com", "", "", 60)
+	if !signedWith(t, newToken, newKey) {
+		t.Error("Expected the latest started key to sign")
+	}
+	if signedWith(t, newToken, nextKey) {
+		t.Error("Expected the scheduled key not to sign before its start")
+	}
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if c, w := runMiddleware(token); c.IsAborted() {
+			t.Errorf("Expected the %s key's token to verify, got %d", name, w.Code)
+		}
+	}
+	if keys := PublicJWKS().Keys; len(keys) != 3 {
+		t.Errorf("Expected all three keys published, got %d", len(keys))
+	}
+
+	// Once the old key is dropped its tokens are refused
+	if err := Configure(AlgRS256, newPath+","+nextPath+"@"+later); err != nil {
+		t.Fatalf("Expected the key list to be configured, got %v", err)
+	}
+	if c, _ := runMiddleware(oldToken); !c.IsAborted() {
+		t.Error("Expected a token of a dropped key to be refused")
+	}
+
+	// A token naming an unknown kid is refused even if another key signed it
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"email": "test@example.com"})
+	forged.Header["kid"] = "unknown"
+	forgedToken, _ := forged.SignedString(newKey)
+	if c, _ := runMiddleware(forgedToken); !c.IsAborted() {
+		t.Error("Expected an unknown kid to be refused")
+	}
+
+	if err := Configure(AlgRS256, newPath+"@tomorrow"); err == nil {
+		t.Error("Expected an invalid start time to fail")
+	}
+}
+
+func TestCurrentKey(t *testing.T) {
+	t.Cleanup(func() { UseRSAKey(nil) })
+	now := time.Now()
+	a := &signingKey{kid: "a"}
+	b := &signingKey{kid: "b", notBefore: now.Add(-time.Hour)}
+	c := &signingKey{kid: "c", notBefore: now.Add(time.Hour)}
+
+	keyring = []*signingKey{b, a, c}
+	if key := currentKey(now); key != b {
+		t.Errorf("Expected the latest started key, got %s", key.kid)
+	}
+	if key := currentKey(now.Add(2 * time.Hour)); key != c {
+		t.Errorf("Expected the scheduled key once started, got %s", key.kid)
+	}
+	keyring = []*signingKey{c}
+	if key := currentKey(now); key != c {
+		t.Errorf("Expected the only key to sign before its start, got %v", key)
+	}
+}
+
+func TestPreviousSecrets(t *testing.T) {
+	setupMiddlewareTest()
+	t.Setenv("JWT_SECRET", "old-secret")
+	oldToken, _ := GenerateToken("user123", "test@example.com", "", "old-secret", 60)
+
+	// JWT_SECRET rotated, the old one kept for the tokens still out there
+	t.Setenv("JWT_SECRET", "new-secret")
+	if c, _ := runMiddleware(oldToken); !c.IsAborted() {
+		t.Error("Expected a token of the replaced secret to be refused without JWT_PREVIOUS_SECRETS")
+	}
+	t.Setenv("JWT_PREVIOUS_SECRETS", "older-secret, old-secret")
+	if c, w := runMiddleware(oldToken); c.IsAborted() {
+		t.Errorf("Expected a token of a previous secret to verify, got %d", w.Code)
+	}
+	newToken, _ := GenerateToken("user123", "test@example.com", "", "new-secret", 60)
+	if c, w := runMiddleware(newToken); c.IsAborted() {
+		t.Errorf("Expected a token of the current secret to verify, got %d", w.Code)
+	}
+
+	// Tokens issued before the kid header existed verify with JWT_SECRET
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"email": "test@example.com"}).SignedString([]byte("new-secret"))
+	if c, w := runMiddleware(legacy); c.IsAborted() {
+		t.Errorf("Expected a token without kid to verify with JWT_SECRET, got %d", w.Code)
+	}
+}
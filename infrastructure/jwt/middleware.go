@@ -1,34 +1,43 @@
 package jwt
 
 import (
-	"os"
+	"strings"
 
+	"github.com/buildyow/byow-user-service/constants"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/response"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTMiddleware(blacklistService *BlacklistService) gin.HandlerFunc {
+// requestToken returns the token of the request: the token cookie, or for
+// clients without cookies such as mobile apps an Authorization: Bearer
+// header. The cookie wins when both are sent.
+func requestToken(c *gin.Context) string {
+	if cookie, err := c.Request.Cookie(CookieName); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	scheme, token, ok := strings.Cut(strings.TrimSpace(c.GetHeader("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// JWTMiddleware verifies the token cookie or bearer token and puts its claims
+// on the context. blacklist may be nil, revoked tokens are then only refused
+// at expiry.
+func JWTMiddleware(blacklist Blacklist) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get Token From Cookie
-		cookie, err := c.Request.Cookie("token")
-		if err != nil {
+		tokenStr := requestToken(c)
+		if tokenStr == "" {
 			response.ErrorFromAppError(c, appErrors.ErrInvalidToken)
 			c.Abort()
 			return
 		}
 
-		tokenStr := cookie.Value
-
 		// Parse & Verification
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			// Method Sign
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		token, err := jwt.Parse(tokenStr, verificationKey)
 		if err != nil || !token.Valid {
 			response.ErrorFromAppError(c, appErrors.ErrInvalidToken)
 			c.Abort()
@@ -38,9 +47,9 @@ func JWTMiddleware(blacklistService *BlacklistService) gin.HandlerFunc {
 		// Get Claims
 		if claims, ok := token.Claims.(jwt.MapClaims); ok {
 			// Check if token is blacklisted (if blacklist service is available)
-			if blacklistService != nil {
+			if blacklist != nil {
 				if jti, ok := claims["jti"].(string); ok {
-					if blacklistService.IsTokenBlacklisted(jti) {
+					if blacklist.IsTokenBlacklisted(jti) {
 						response.ErrorFromAppError(c, appErrors.ErrInvalidToken)
 						c.Abort()
 						return
@@ -64,8 +73,82 @@ func JWTMiddleware(blacklistService *BlacklistService) gin.HandlerFunc {
 				// Set JTI to Context for potential blacklisting
 				c.Set("jti", jti)
 			}
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				// Set Expiry to Context so logout can blacklist until then
+				c.Set("token_expires_at", exp.Time)
+			}
+			if role, ok := claims["role"].(string); ok {
+				// Set Role to Context for RequirePermission
+				c.Set("role", role)
+			}
+			if version, ok := claims["token_version"].(float64); ok {
+				// Set Token Version to Context for RequireTokenVersion
+				c.Set("token_version", int(version))
+			}
+			if act, ok := claims["act"].(map[string]interface{}); ok {
+				if actorID, ok := act["sub"].(string); ok {
+					// Set the impersonating admin to Context for AuditImpersonation
+					c.Set("impersonator_id", actorID)
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission only lets through requests whose token carries a role
+// granted permission. It must run after JWTMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !constants.HasPermission(c.GetString("role"), permission) {
+			response.ErrorFromAppError(c, appErrors.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuditImpersonation hands every request made with an impersonation token
+// to record once it has been served, with the admin, the user acted as, the
+// route and the response status. It must run after JWTMiddleware.
+func AuditImpersonation(record func(impersonatorID, userID, method, route string, status int)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if impersonatorID := c.GetString("impersonator_id"); impersonatorID != "" {
+			record(impersonatorID, c.GetString("user_id"), c.Request.Method, c.FullPath(), c.Writer.Status())
 		}
+	}
+}
 
+// RefuseImpersonation refuses requests made with an impersonation token, for
+// routes that change how the account signs in or whether it exists. An admin
+// acting as the user could otherwise e.g. register their own passkey and keep
+// access after the impersonation expired. It must run after JWTMiddleware.
+func RefuseImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("impersonator_id") != "" {
+			response.ErrorFromAppError(c, appErrors.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireTokenVersion refuses tokens issued before the user's token version
+// last moved on, e.g. when their role changed, so claims never outlive the
+// account they describe. current looks up the user's version, a failed lookup
+// refuses the token too. It must run after JWTMiddleware.
+func RequireTokenVersion(current func(userID, email string) (int, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version, err := current(c.GetString("user_id"), c.GetString("email"))
+		if err != nil || c.GetInt("token_version") < version {
+			response.ErrorFromAppError(c, appErrors.ErrInvalidToken)
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"sync"
+	"time"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/gin-gonic/gin"
+)
+
+// session is the activity of one token, keyed by its JTI
+type session struct {
+	LastSeen  time.Time
+	ExpiresAt time.Time
+}
+
+// SessionTracker remembers when each token was last used so sessions can
+// time out on inactivity, independently of the token's own expiry. It is
+// kept in memory, a restart gives every live token a fresh idle window.
+type SessionTracker struct {
+	maxIdle  time.Duration
+	sessions map[string]session
+	mutex    sync.Mutex
+}
+
+// NewSessionTracker creates a tracker that expires sessions idle for longer
+// than maxIdle
+func NewSessionTracker(maxIdle time.Duration) *SessionTracker {
+	return &SessionTracker{
+		maxIdle:  maxIdle,
+		sessions: make(map[string]session),
+	}
+}
+
+// Touch records activity on the token jti at now and reports whether its
+// session is still active. An idle session stays idle, later requests with
+// the same token keep being refused until it expires.
+func (st *SessionTracker) Touch(jti string, expiresAt, now time.Time) bool {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if s, exists := st.sessions[jti]; exists && now.Sub(s.LastSeen) > st.maxIdle {
+		return false
+	}
+	st.sessions[jti] = session{LastSeen: now, ExpiresAt: expiresAt}
+	return true
+}
+
+// CleanupExpiredSessions forgets sessions whose token has expired, the JWT
+// check rejects those on its own
+func (st *SessionTracker) CleanupExpiredSessions() {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	now := time.Now()
+	for jti, s := range st.sessions {
+		if now.After(s.ExpiresAt) {
+			delete(st.sessions, jti)
+		}
+	}
+}
+
+// StartCleanupWorker starts a background worker to forget expired sessions
+func (st *SessionTracker) StartCleanupWorker() {
+	ticker := time.NewTicker(1 * time.Hour)
+	go func() {
+		for range ticker.C {
+			st.CleanupExpiredSessions()
+		}
+	}()
+}
+
+// IdleTimeout refuses tokens whose session has been idle for longer than the
+// tracker allows and revokes them until they expire. It must run after
+// JWTMiddleware, tokens without a JTI aren't tracked. revoke may be nil.
+func IdleTimeout(sessions *SessionTracker, revoke func(jti, email string, expiresAt time.Time) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jti := c.GetString("jti")
+		if jti == "" {
+			c.Next()
+			return
+		}
+
+		expiresAt := c.GetTime("token_expires_at")
+		if !sessions.Touch(jti, expiresAt, time.Now()) {
+			if revoke != nil && !expiresAt.IsZero() {
+				// Best effort, the tracker keeps refusing the token anyway
+				_ = revoke(jti, c.GetString("email"), expiresAt)
+			}
+			response.ErrorFromAppError(c, appErrors.ErrSessionIdle)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
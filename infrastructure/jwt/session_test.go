@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSessionTracker_Touch(t *testing.T) {
+	tracker := NewSessionTracker(30 * time.Minute)
+	start := time.Now()
+	expiresAt := start.Add(24 * time.Hour)
+
+	if !tracker.Touch("jti-1", expiresAt, start) {
+		t.Fatal("Expected a new session to be active")
+	}
+	// Each request moves the idle window
+	if !tracker.Touch("jti-1", expiresAt, start.Add(20*time.Minute)) {
+		t.Error("Expected a session used within the window to stay active")
+	}
+	if !tracker.Touch("jti-1", expiresAt, start.Add(40*time.Minute)) {
+		t.Error("Expected the window to restart from the last request")
+	}
+
+	if tracker.Touch("jti-1", expiresAt, start.Add(2*time.Hour)) {
+		t.Error("Expected a session idle beyond the limit to be refused")
+	}
+	if tracker.Touch("jti-1", expiresAt, start.Add(2*time.Hour+time.Second)) {
+		t.Error("Expected an idle session to stay refused")
+	}
+
+	if !tracker.Touch("jti-2", expiresAt, start.Add(2*time.Hour)) {
+		t.Error("Expected other sessions to be unaffected")
+	}
+}
+
+func TestSessionTracker_CleanupExpiredSessions(t *testing.T) {
+	tracker := NewSessionTracker(30 * time.Minute)
+	now := time.Now()
+	tracker.Touch("expired", now.Add(-time.Minute), now.Add(-time.Hour))
+	tracker.Touch("valid", now.Add(time.Hour), now)
+
+	tracker.CleanupExpiredSessions()
+
+	if _, exists := tracker.sessions["expired"]; exists {
+		t.Error("Expected the expired session to be removed")
+	}
+	if _, exists := tracker.sessions["valid"]; !exists {
+		t.Error("Expected the valid session to remain")
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := NewSessionTracker(30 * time.Minute)
+	expiresAt := time.Now().Add(time.Hour)
+
+	var revoked []string
+	revoke := func(jti, email string, until time.Time) error {
+		if email != "test@example.com" || !until.Equal(expiresAt) {
+			t.Errorf("Unexpected revoke of %s for %s until %v", jti, email, until)
+		}
+		revoked = append(revoked, jti)
+		return nil
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("jti", c.GetHeader("X-JTI"))
+		c.Set("email", "test@example.com")
+		c.Set("token_expires_at", expiresAt)
+	}, IdleTimeout(tracker, revoke))
+	router.GET("/protected", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	request := func(jti string) int {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("X-JTI", jti)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request("jti-active"); code != http.StatusOK {
+		t.Fatalf("Expected an active session to continue, got %d", code)
+	}
+	if code := request("jti-active"); code != http.StatusOK {
+		t.Errorf("Expected a repeated request to continue, got %d", code)
+	}
+
+	// Simulate a session last used an hour ago
+	tracker.sessions["jti-idle"] = session{LastSeen: time.Now().Add(-time.Hour), ExpiresAt: expiresAt}
+	if code := request("jti-idle"); code != http.StatusUnauthorized {
+		t.Errorf("Expected an idle session to be rejected, got %d", code)
+	}
+	if len(revoked) != 1 || revoked[0] != "jti-idle" {
+		t.Errorf("Expected the idle token to be revoked, got %v", revoked)
+	}
+
+	// Tokens without a JTI can't be tracked
+	if code := request(""); code != http.StatusOK {
+		t.Errorf("Expected a token without JTI to pass, got %d", code)
+	}
+}
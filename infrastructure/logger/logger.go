@@ -3,13 +3,23 @@ package logger
 import (
 	"bytes"
 	"io"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 func LogRequestBody(logger *zap.Logger) gin.HandlerFunc {
+	return LogRequestBodySampled(logger, 1)
+}
+
+// LogRequestBodySampled logs request payloads once the response status is
+// known. Error responses (4xx/5xx) are always logged, successful ones only
+// for the given fraction of requests.
+func LogRequestBodySampled(logger *zap.Logger, sampleRate float64) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.Body == nil || c.Request.Method == http.MethodGet {
 			c.Next()
@@ -27,20 +37,47 @@ func LogRequestBody(logger *zap.Logger) gin.HandlerFunc {
 		// Restore body to the request
 		c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
+		c.Next()
+
 		// Optional: skip log endpoints that don't need request body logging
 		skipPaths := map[string]bool{
 			"/auth/users/login":           true,
 			"/auth/users/change-password": true,
 			"/auth/users/register":        true,
 		}
-		if !skipPaths[c.FullPath()] {
-			logger.Info("Request Payload",
-				zap.String("method", c.Request.Method),
-				zap.String("path", c.FullPath()),
-				zap.ByteString("body", bodyBytes),
-			)
+		if skipPaths[c.FullPath()] {
+			return
 		}
 
-		c.Next()
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && !sampled(sampleRate) {
+			return
+		}
+		logger.Info("Request Payload",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+			zap.Int("status", status),
+			zap.ByteString("body", bodyBytes),
+		)
+	}
+}
+
+// ParseSampleRate reads a LOG_SAMPLE_RATE value, a fraction between 0 and 1.
+// Empty or invalid values log everything.
+func ParseSampleRate(value string) float64 {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
 	}
+	return rand.Float64() < rate
 }
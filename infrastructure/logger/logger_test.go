@@ -20,19 +20,19 @@ func setupLoggerTestRouter() *gin.Engine {
 func createTestLogger() (*zap.Logger, *bytes.Buffer) {
 	// Create a buffer to capture log output
 	buffer := &bytes.Buffer{}
-	
+
 	// Create a custom core that writes to our buffer
 	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
 	core := zapcore.NewCore(encoder, zapcore.AddSync(buffer), zapcore.InfoLevel)
 	logger := zap.New(core)
-	
+
 	return logger, buffer
 }
 
 func TestLogRequestBody_GET_Request(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -56,7 +56,7 @@ func TestLogRequestBody_GET_Request(t *testing.T) {
 func TestLogRequestBody_POST_WithBody(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.POST("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -90,7 +90,7 @@ func TestLogRequestBody_POST_WithBody(t *testing.T) {
 func TestLogRequestBody_POST_SkipPath(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.POST("/auth/users/login", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -116,7 +116,7 @@ func TestLogRequestBody_POST_SkipPath(t *testing.T) {
 func TestLogRequestBody_POST_RegisterSkipPath(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.POST("/auth/users/register", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -138,7 +138,7 @@ func TestLogRequestBody_POST_RegisterSkipPath(t *testing.T) {
 func TestLogRequestBody_POST_ChangePasswordSkipPath(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.POST("/auth/users/change-password", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -160,7 +160,7 @@ func TestLogRequestBody_POST_ChangePasswordSkipPath(t *testing.T) {
 func TestLogRequestBody_PUT_WithBody(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.PUT("/api/update", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "updated"})
@@ -186,7 +186,7 @@ func TestLogRequestBody_PUT_WithBody(t *testing.T) {
 func TestLogRequestBody_EmptyBody(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.POST("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -207,7 +207,7 @@ func TestLogRequestBody_EmptyBody(t *testing.T) {
 func TestLogRequestBody_NilBody(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.POST("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -240,7 +240,7 @@ func TestLogRequestBody_AllSkipPaths(t *testing.T) {
 		t.Run(path, func(t *testing.T) {
 			logger, buffer := createTestLogger()
 			router := setupLoggerTestRouter()
-			
+
 			router.Use(LogRequestBody(logger))
 			router.POST(path, func(c *gin.Context) {
 				c.JSON(200, gin.H{"status": "ok"})
@@ -263,7 +263,7 @@ func TestLogRequestBody_AllSkipPaths(t *testing.T) {
 func TestLogRequestBody_NonSkipPath(t *testing.T) {
 	logger, buffer := createTestLogger()
 	router := setupLoggerTestRouter()
-	
+
 	router.Use(LogRequestBody(logger))
 	router.POST("/api/public/endpoint", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
@@ -284,4 +284,79 @@ func TestLogRequestBody_NonSkipPath(t *testing.T) {
 	if !strings.Contains(logOutput, "/api/public/endpoint") {
 		t.Error("Expected path in log output")
 	}
-}
\ No newline at end of file
+}
+func TestLogRequestBodySampled_AlwaysLogsErrors(t *testing.T) {
+	logger, buffer := createTestLogger()
+	router := setupLoggerTestRouter()
+
+	router.Use(LogRequestBodySampled(logger, 0))
+	router.POST("/ok", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	router.POST("/bad", func(c *gin.Context) {
+		c.JSON(400, gin.H{"status": "bad"})
+	})
+	router.POST("/fail", func(c *gin.Context) {
+		c.JSON(500, gin.H{"status": "fail"})
+	})
+
+	for _, path := range []string{"/ok", "/bad", "/fail"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, strings.NewReader(`{"path":"`+path+`"}`))
+		router.ServeHTTP(w, req)
+	}
+
+	logOutput := buffer.String()
+	if strings.Contains(logOutput, `"path":"/ok"`) {
+		t.Error("Expected successful request to be sampled out at rate 0")
+	}
+	if !strings.Contains(logOutput, `"path":"/bad"`) || !strings.Contains(logOutput, `"status":400`) {
+		t.Error("Expected 4xx request to be logged with its status")
+	}
+	if !strings.Contains(logOutput, `"path":"/fail"`) || !strings.Contains(logOutput, `"status":500`) {
+		t.Error("Expected 5xx request to be logged with its status")
+	}
+}
+
+func TestLogRequestBodySampled_SamplesSuccess(t *testing.T) {
+	logger, buffer := createTestLogger()
+	router := setupLoggerTestRouter()
+
+	router.Use(LogRequestBodySampled(logger, 0.5))
+	router.POST("/ok", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	const requests = 400
+	for i := 0; i < requests; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/ok", strings.NewReader(`{"key":"value"}`))
+		router.ServeHTTP(w, req)
+	}
+
+	logged := strings.Count(buffer.String(), "Request Payload")
+	if logged == 0 || logged == requests {
+		t.Errorf("Expected roughly half of %d requests to be logged, got %d", requests, logged)
+	}
+}
+
+func TestParseSampleRate(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+	}{
+		{"", 1},
+		{"0.1", 0.1},
+		{" 0 ", 0},
+		{"1", 1},
+		{"1.5", 1},
+		{"-0.2", 1},
+		{"ten percent", 1},
+	}
+
+	for _, tt := range tests {
+		if got := ParseSampleRate(tt.value); got != tt.expected {
+			t.Errorf("ParseSampleRate(%q) = %v, expected %v", tt.value, got, tt.expected)
+		}
+	}
+}
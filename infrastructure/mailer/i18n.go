@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"golang.org/x/text/language"
+)
+
+// Languages emails are written in
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// DefaultLanguage is used when neither the request nor the user names a
+// supported language
+const DefaultLanguage = English
+
+// SupportedLanguages are the languages a user can pick for their emails
+var SupportedLanguages = []string{English, Indonesian}
+
+// IsSupportedLanguage reports whether lang is one of SupportedLanguages
+func IsSupportedLanguage(lang string) bool {
+	for _, supported := range SupportedLanguages {
+		if lang == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveLanguage picks the language of an email: the first supported
+// language of the Accept-Language header, else the user's stored preference,
+// else DefaultLanguage. Region subtags are ignored, en-US is written in en.
+func ResolveLanguage(acceptLanguage, preferred string) string {
+	if acceptLanguage != "" {
+		tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+		for _, tag := range tags {
+			if lang := baseLanguage(tag); lang != "" {
+				return lang
+			}
+		}
+	}
+	if preferred != "" {
+		if tag, err := language.Parse(preferred); err == nil {
+			if lang := baseLanguage(tag); lang != "" {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+// baseLanguage returns the supported language of tag, or "" when unsupported
+func baseLanguage(tag language.Tag) string {
+	base, confidence := tag.Base()
+	if confidence != language.Exact || !IsSupportedLanguage(base.String()) {
+		return ""
+	}
+	return base.String()
+}
@@ -1,21 +1,99 @@
 package mailer
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
-	"gopkg.in/gomail.v2"
 )
 
 func SendOTP(email, otp, host, user, pass string, port int, otpType string) error {
-	m := gomail.NewMessage()
-	m.SetHeader("From", user)
-	m.SetHeader("To", email)
-	m.SetHeader("Subject", "Your OTP Code")
-	m.SetBody("text/plain", fmt.Sprintf("Your OTP for %s is: %s expired in %d minutes", otpType, otp, getOTPLifetime(otpType)))
-
-	d := gomail.NewDialer(host, port, user, pass)
-	return d.DialAndSend(m)
+	subject, body := OTPMessage(DefaultLanguage, otp, otpType)
+	return Send(email, subject, body, host, user, pass, port)
+}
+
+// OTPMessage builds the subject and body of an OTP email in lang
+func OTPMessage(lang, otp, otpType string) (string, string) {
+	if lang == Indonesian {
+		return "Kode OTP Anda", fmt.Sprintf("OTP Anda untuk %s adalah: %s berlaku selama %d menit", otpType, otp, getOTPLifetime(otpType))
+	}
+	return "Your OTP Code", fmt.Sprintf("Your OTP for %s is: %s expired in %d minutes", otpType, otp, getOTPLifetime(otpType))
+}
+
+// NewEmailConfirmationMessage builds the email carrying the code that
+// confirms a new address during an email change
+func NewEmailConfirmationMessage(otp string) (string, string) {
+	body := fmt.Sprintf("Your code to confirm this address for your account is: %s expired in %d minutes\n\nIf you didn't ask to change your email, ignore this message.", otp, getOTPLifetime(constants.EMAIL_CHANGED))
+	return "Confirm your new email address", body
+}
+
+// Send delivers a plain-text email with the default timeouts
+func Send(email, subject, body, host, user, pass string, port int) error {
+	return SendContext(context.Background(), Options{}, email, subject, body, host, user, pass, port)
+}
+
+// PasswordChangedNotice builds the email telling a user their password was
+// changed, in lang
+func PasswordChangedNotice(lang string, changedAt time.Time, supportURL string) (string, string) {
+	if lang == Indonesian {
+		body := fmt.Sprintf("Kata sandi akun Anda diubah pada %s.\n\nJika ini Anda, tidak perlu melakukan apa pun.", changedAt.UTC().Format(time.RFC1123))
+		if supportURL != "" {
+			body += fmt.Sprintf(" Jika bukan Anda, segera atur ulang kata sandi Anda dan hubungi dukungan: %s", supportURL)
+		} else {
+			body += " Jika bukan Anda, segera atur ulang kata sandi Anda dan hubungi dukungan."
+		}
+		return "Kata sandi Anda telah diubah", body
+	}
+	body := fmt.Sprintf("The password for your account was changed on %s.\n\nIf this was you, no action is needed.", changedAt.UTC().Format(time.RFC1123))
+	if supportURL != "" {
+		body += fmt.Sprintf(" If it wasn't you, reset your password right away and contact support: %s", supportURL)
+	} else {
+		body += " If it wasn't you, reset your password right away and contact support."
+	}
+	return "Your password was changed", body
+}
+
+// AccountDeletionNotice builds the email confirming a user asked for their
+// account to be deleted at deleteAt, in lang
+func AccountDeletionNotice(lang string, deleteAt time.Time, supportURL string) (string, string) {
+	if lang == Indonesian {
+		body := fmt.Sprintf("Akun Anda akan dihapus secara permanen pada %s.\n\nUntuk membatalkan, cukup masuk kembali sebelum waktu tersebut.", deleteAt.UTC().Format(time.RFC1123))
+		if supportURL != "" {
+			body += fmt.Sprintf(" Jika Anda tidak meminta ini, masuk untuk membatalkannya dan hubungi dukungan: %s", supportURL)
+		} else {
+			body += " Jika Anda tidak meminta ini, masuk untuk membatalkannya dan hubungi dukungan."
+		}
+		return "Akun Anda dijadwalkan untuk dihapus", body
+	}
+	body := fmt.Sprintf("Your account will be permanently deleted on %s.\n\nTo cancel, just log in again before then.", deleteAt.UTC().Format(time.RFC1123))
+	if supportURL != "" {
+		body += fmt.Sprintf(" If you didn't ask for this, log in to cancel it and contact support: %s", supportURL)
+	} else {
+		body += " If you didn't ask for this, log in to cancel it and contact support."
+	}
+	return "Your account is scheduled for deletion", body
+}
+
+// WelcomeMessage builds the email greeting a newly registered user, in lang
+func WelcomeMessage(lang, name string) (string, string) {
+	if lang == Indonesian {
+		body := fmt.Sprintf("Halo %s,\n\nSelamat datang di BYOW! Akun Anda sudah dibuat. Verifikasi email Anda dengan kode OTP yang kami kirim untuk mulai membangun situs Anda.", name)
+		return "Selamat datang di BYOW", body
+	}
+	body := fmt.Sprintf("Hi %s,\n\nWelcome to BYOW! Your account is ready. Verify your email with the OTP we sent you to start building your site.", name)
+	return "Welcome to BYOW", body
+}
+
+// VerificationRevokedNotice builds the email telling an owner their company
+// lost its verification, in lang
+func VerificationRevokedNotice(lang, companyName, reason string) (string, string) {
+	if lang == Indonesian {
+		body := fmt.Sprintf("Verifikasi perusahaan Anda %s dicabut oleh moderator kami.\n\nAlasan: %s", companyName, reason)
+		return "Verifikasi perusahaan Anda dicabut", body
+	}
+	body := fmt.Sprintf("The verification of your company %s was revoked by our moderators.\n\nReason: %s", companyName, reason)
+	return "Your company verification was revoked", body
 }
 
 func getOTPLifetime(otpType string) int {
@@ -1,10 +1,17 @@
 package mailer
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 )
 
 func TestGetOTPLifetime(t *testing.T) {
@@ -150,12 +157,12 @@ func TestSendOTP_MessageContent(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Use invalid SMTP settings so it fails to send but doesn't panic
 			err := SendOTP(tc.email, tc.otp, "invalid-host", "user", "pass", 587, tc.otpType)
-			
+
 			// We expect an error due to invalid SMTP, but no panic
 			if err == nil {
 				t.Error("Expected error with invalid SMTP settings")
 			}
-			
+
 			// Test completed without panic
 			t.Logf("Test case %s completed with expected error: %v", tc.name, err)
 		})
@@ -165,7 +172,7 @@ func TestSendOTP_MessageContent(t *testing.T) {
 func TestSendOTP_DifferentOTPTypes(t *testing.T) {
 	// Test that different OTP types are handled and result in appropriate lifetimes
 	// being referenced in the message (though we can't verify the message content directly)
-	
+
 	otpTypes := []string{
 		constants.VERIFICATION,
 		constants.FORGOT_PASSWORD,
@@ -178,7 +185,7 @@ func TestSendOTP_DifferentOTPTypes(t *testing.T) {
 		t.Run(otpType, func(t *testing.T) {
 			// Verify that getOTPLifetime works correctly for this type
 			lifetime := getOTPLifetime(otpType)
-			
+
 			expectedLifetime := 1 // default
 			switch otpType {
 			case constants.FORGOT_PASSWORD, constants.EMAIL_CHANGED, constants.PHONE_CHANGED:
@@ -186,7 +193,7 @@ func TestSendOTP_DifferentOTPTypes(t *testing.T) {
 			case constants.VERIFICATION:
 				expectedLifetime = 5
 			}
-			
+
 			if lifetime != expectedLifetime {
 				t.Errorf("Expected lifetime %d for %s, got %d", expectedLifetime, otpType, lifetime)
 			}
@@ -214,12 +221,12 @@ func TestSendOTP_InvalidEmailFormats(t *testing.T) {
 	for _, email := range invalidEmails {
 		t.Run(email, func(t *testing.T) {
 			err := SendOTP(email, "123456", "invalid-host", "user", "pass", 587, constants.VERIFICATION)
-			
+
 			// Should return error (either due to invalid email or invalid SMTP)
 			if err == nil {
 				t.Errorf("Expected error with invalid email: %s", email)
 			}
-			
+
 			t.Logf("Invalid email %s resulted in expected error: %v", email, err)
 		})
 	}
@@ -228,9 +235,9 @@ func TestSendOTP_InvalidEmailFormats(t *testing.T) {
 func TestSendOTP_LongOTP(t *testing.T) {
 	// Test with very long OTP
 	longOTP := strings.Repeat("1234567890", 10) // 100 characters
-	
+
 	err := SendOTP("test@example.com", longOTP, "invalid-host", "user", "pass", 587, constants.VERIFICATION)
-	
+
 	// Should still attempt to send (and fail due to invalid SMTP)
 	if err == nil {
 		t.Error("Expected error with invalid SMTP settings")
@@ -250,7 +257,7 @@ func TestSendOTP_SpecialCharactersInOTP(t *testing.T) {
 	for _, otp := range specialOTPs {
 		t.Run(otp, func(t *testing.T) {
 			err := SendOTP("test@example.com", otp, "invalid-host", "user", "pass", 587, constants.VERIFICATION)
-			
+
 			// Should attempt to send regardless of OTP content
 			if err == nil {
 				t.Error("Expected error with invalid SMTP settings")
@@ -262,11 +269,11 @@ func TestSendOTP_SpecialCharactersInOTP(t *testing.T) {
 func TestSendOTP_CommonPorts(t *testing.T) {
 	// Test with common SMTP ports
 	commonPorts := []int{25, 587, 465, 2525}
-	
+
 	for _, port := range commonPorts {
 		t.Run(string(rune(port)), func(t *testing.T) {
 			err := SendOTP("test@example.com", "123456", "invalid-host", "user", "pass", port, constants.VERIFICATION)
-			
+
 			// Should fail due to invalid host, not port
 			if err == nil {
 				t.Error("Expected error with invalid SMTP host")
@@ -284,10 +291,229 @@ func BenchmarkGetOTPLifetime(b *testing.B) {
 		constants.PHONE_CHANGED,
 		"unknown",
 	}
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		otpType := otpTypes[i%len(otpTypes)]
 		getOTPLifetime(otpType)
 	}
-}
\ No newline at end of file
+}
+func TestPasswordChangedNotice(t *testing.T) {
+	changedAt := time.Date(2024, 3, 5, 14, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+	subject, body := PasswordChangedNotice(English, changedAt, "https://support.example.com")
+	if subject == "" {
+		t.Error("Expected a subject")
+	}
+	if !strings.Contains(body, "Tue, 05 Mar 2024 07:30:00 UTC") {
+		t.Errorf("Expected UTC timestamp in body, got %q", body)
+	}
+	if !strings.Contains(body, "https://support.example.com") {
+		t.Errorf("Expected support link in body, got %q", body)
+	}
+
+	_, body = PasswordChangedNotice(English, changedAt, "")
+	if strings.Contains(body, "http") {
+		t.Errorf("Expected no link without a support URL, got %q", body)
+	}
+}
+
+// stalledSMTPServer accepts connections but never sends the SMTP greeting
+func stalledSMTPServer(t *testing.T) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	var conns []net.Conn
+	var mu sync.Mutex
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestSendContext_SendTimeout(t *testing.T) {
+	host, port := stalledSMTPServer(t)
+
+	start := time.Now()
+	err := SendContext(context.Background(), Options{SendTimeout: 200 * time.Millisecond},
+		"test@example.com", "Subject", "Body", host, "user@example.com", "pass", port)
+	elapsed := time.Since(start)
+
+	if err != appErrors.ErrEmailDeliveryFailed {
+		t.Errorf("Expected ErrEmailDeliveryFailed, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected the send timeout to fire quickly, took %v", elapsed)
+	}
+}
+
+func TestSendContext_Canceled(t *testing.T) {
+	host, port := stalledSMTPServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := SendContext(ctx, Options{SendTimeout: time.Minute},
+		"test@example.com", "Subject", "Body", host, "user@example.com", "pass", port)
+	elapsed := time.Since(start)
+
+	if err != appErrors.ErrEmailDeliveryFailed {
+		t.Errorf("Expected ErrEmailDeliveryFailed, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected cancellation to abort the send, took %v", elapsed)
+	}
+}
+
+// greetingSMTPServer greets each connection and answers EHLO and QUIT
+func greetingSMTPServer(t *testing.T) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				fmt.Fprint(conn, "220 localhost ESMTP ready\r\n")
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch command := strings.ToUpper(line); {
+					case strings.HasPrefix(command, "EHLO"):
+						fmt.Fprint(conn, "250 localhost\r\n")
+					case strings.HasPrefix(command, "QUIT"):
+						fmt.Fprint(conn, "221 Bye\r\n")
+						return
+					default:
+						fmt.Fprint(conn, "502 Not implemented\r\n")
+					}
+				}
+			}()
+		}
+	}()
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestProbe(t *testing.T) {
+	host, port := greetingSMTPServer(t)
+	if err := Probe(context.Background(), host, port, time.Second); err != nil {
+		t.Errorf("Expected a greeting server to be up, got %v", err)
+	}
+
+	// Nothing listens on a port once its listener is closed
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	if err := Probe(context.Background(), "127.0.0.1", closedPort, time.Second); err == nil {
+		t.Error("Expected an unreachable server to be down")
+	}
+
+	host, port = stalledSMTPServer(t)
+	start := time.Now()
+	if err := Probe(context.Background(), host, port, 200*time.Millisecond); err == nil {
+		t.Error("Expected a server that never greets to be down")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the probe timeout to fire quickly, took %v", elapsed)
+	}
+}
+
+func TestVerificationRevokedNotice(t *testing.T) {
+	subject, body := VerificationRevokedNotice(English, "BuildYow", "Forged documents")
+	if subject == "" {
+		t.Error("Expected a subject")
+	}
+	if !strings.Contains(body, "BuildYow") || !strings.Contains(body, "Forged documents") {
+		t.Errorf("Expected the company name and reason in body, got %q", body)
+	}
+}
+
+func TestWelcomeMessage(t *testing.T) {
+	subject, body := WelcomeMessage(English, "John Doe")
+	if subject != "Welcome to BYOW" || !strings.Contains(body, "John Doe") {
+		t.Errorf("Expected a welcome for John, got %q: %q", subject, body)
+	}
+
+	subject, _ = WelcomeMessage(Indonesian, "John Doe")
+	if subject != "Selamat datang di BYOW" {
+		t.Errorf("Expected the Indonesian subject, got %q", subject)
+	}
+}
+
+func TestAccountDeletionNotice(t *testing.T) {
+	deleteAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	subject, body := AccountDeletionNotice(English, deleteAt, "https://support.example.com")
+	if subject == "" {
+		t.Error("Expected a subject")
+	}
+	if !strings.Contains(body, deleteAt.Format(time.RFC1123)) || !strings.Contains(body, "https://support.example.com") {
+		t.Errorf("Expected the deletion date and support link in body, got %q", body)
+	}
+
+	subject, _ = AccountDeletionNotice(Indonesian, deleteAt, "")
+	if subject != "Akun Anda dijadwalkan untuk dihapus" {
+		t.Errorf("Expected the Indonesian subject, got %q", subject)
+	}
+}
+
+func TestResolveLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		preferred      string
+		expected       string
+	}{
+		{"header", "id-ID,id;q=0.9", "en", Indonesian},
+		{"first supported in header", "fr-FR,en;q=0.8", "id", English},
+		{"unsupported header", "fr-FR", "id", Indonesian},
+		{"stored preference", "", "id", Indonesian},
+		{"stored region tag", "", "en-US", English},
+		{"wildcard header", "*", "id", Indonesian},
+		{"unsupported preference", "", "fr", DefaultLanguage},
+		{"nothing", "", "", DefaultLanguage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveLanguage(tt.acceptLanguage, tt.preferred); got != tt.expected {
+				t.Errorf("ResolveLanguage(%q, %q) = %q, expected %q", tt.acceptLanguage, tt.preferred, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOTPMessage_Indonesian(t *testing.T) {
+	subject, body := OTPMessage(Indonesian, "123456", constants.VERIFICATION)
+	if subject != "Kode OTP Anda" || !strings.Contains(body, "123456") || !strings.Contains(body, "5 menit") {
+		t.Errorf("Expected an Indonesian OTP email, got %q: %q", subject, body)
+	}
+}
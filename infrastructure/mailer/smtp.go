@@ -0,0 +1,204 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/utils"
+	"gopkg.in/gomail.v2"
+)
+
+// Default timeouts used when Options leaves them zero
+const (
+	DefaultDialTimeout = 10 * time.Second
+	DefaultSendTimeout = 30 * time.Second
+)
+
+// Options bounds how long a single delivery may take
+type Options struct {
+	// DialTimeout limits connecting to the SMTP server
+	DialTimeout time.Duration
+	// SendTimeout limits everything after the connection is up: greeting,
+	// TLS, auth and the message transfer
+	SendTimeout time.Duration
+}
+
+// SendContext delivers a plain-text email. It gives up when a timeout fires
+// or ctx is canceled, any failure is returned as ErrEmailDeliveryFailed.
+func SendContext(ctx context.Context, opts Options, email, subject, body, host, user, pass string, port int) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", user)
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
+
+	if err := deliver(ctx, opts, m, email, host, user, pass, port); err != nil {
+		utils.LogError("Email delivery to %s failed: %v", email, err)
+		return appErrors.ErrEmailDeliveryFailed
+	}
+	return nil
+}
+
+func deliver(ctx context.Context, opts Options, m *gomail.Message, email, host, user, pass string, port int) error {
+	if email == "" {
+		return errors.New("no recipient")
+	}
+	dialTimeout := opts.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = DefaultDialTimeout
+	}
+	sendTimeout := opts.SendTimeout
+	if sendTimeout <= 0 {
+		sendTimeout = DefaultSendTimeout
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The deadline bounds a stalled server, closing the connection unblocks
+	// any pending read or write when ctx is canceled
+	if err := conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	tlsConfig := &tls.Config{ServerName: host}
+	if port == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && port != 465 {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if user != "" {
+		if ok, mechanisms := c.Extension("AUTH"); ok {
+			if err := c.Auth(chooseAuth(mechanisms, user, pass, host)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := c.Mail(user); err != nil {
+		return err
+	}
+	if err := c.Rcpt(email); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := m.WriteTo(w); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// DefaultProbeTimeout bounds Probe when it is given no timeout, short enough
+// for a health check
+const DefaultProbeTimeout = 3 * time.Second
+
+// Probe connects to the SMTP server, waits for its greeting and hangs up
+// without sending anything. It reports whether mail could be delivered right
+// now, as far as reaching the server goes.
+func Probe(ctx context.Context, host string, port int, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	if port == 465 {
+		conn = tls.Client(conn, &tls.Config{ServerName: host})
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.Quit()
+}
+
+// chooseAuth picks the auth mechanism the same way gomail does
+func chooseAuth(mechanisms, user, pass, host string) smtp.Auth {
+	if strings.Contains(mechanisms, "CRAM-MD5") {
+		return smtp.CRAMMD5Auth(user, pass)
+	}
+	if strings.Contains(mechanisms, "LOGIN") && !strings.Contains(mechanisms, "PLAIN") {
+		return &loginAuth{username: user, password: pass, host: host}
+	}
+	return smtp.PlainAuth("", user, pass, host)
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp doesn't provide
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		advertised := false
+		for _, mechanism := range server.Auth {
+			if mechanism == "LOGIN" {
+				advertised = true
+				break
+			}
+		}
+		if !advertised {
+			return "", nil, errors.New("unencrypted connection")
+		}
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch {
+	case strings.EqualFold(string(fromServer), "Username:"):
+		return []byte(a.username), nil
+	case strings.EqualFold(string(fromServer), "Password:"):
+		return []byte(a.password), nil
+	default:
+		return nil, errors.New("unexpected server challenge")
+	}
+}
@@ -0,0 +1,102 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// GitHub signs users in with their GitHub account. It asks for the
+// user:email scope, the profile email is often hidden.
+type GitHub struct {
+	config *oauth2.Config
+	apiURL string
+}
+
+func NewGitHub(clientID, clientSecret, redirectURL string) *GitHub {
+	return &GitHub{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		},
+		apiURL: "https://api.github.com",
+	}
+}
+
+func (g *GitHub) Name() string { return "github" }
+
+func (g *GitHub) AuthCodeURL(state string) string {
+	return g.config.AuthCodeURL(state)
+}
+
+func (g *GitHub) Identity(ctx context.Context, code string) (*Identity, error) {
+	token, err := g.config.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	client := g.config.Client(ctx, token)
+
+	var profile struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := g.get(client, "/user", &profile); err != nil {
+		return nil, err
+	}
+
+	// Only the primary address counts, and only once GitHub verified it
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := g.get(client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+	identity := &Identity{
+		Provider:  g.Name(),
+		Subject:   strconv.FormatInt(profile.ID, 10),
+		Name:      profile.Name,
+		AvatarURL: profile.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = profile.Login
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			identity.Email = email.Email
+		}
+	}
+	if identity.Email == "" {
+		return nil, ErrNoVerifiedEmail
+	}
+	return identity, nil
+}
+
+// get decodes the JSON answer of a GitHub API path into out
+func (g *GitHub) get(client *http.Client, path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, g.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,107 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeGitHub serves the token exchange and the two API calls Identity makes
+func fakeGitHub(t *testing.T, emails string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "good-code" {
+			http.Error(w, `{"error":"bad_verification_code"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_test", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_test" {
+			t.Errorf("Expected the access token on API calls, got %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"id":42,"login":"octocat","name":"","avatar_url":"https://avatars.example/42"}`))
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(emails))
+	})
+	return httptest.NewServer(mux)
+}
+
+func testGitHub(server *httptest.Server) *GitHub {
+	provider := NewGitHub("client-id", "client-secret", "https://api.example/auth/users/oauth/github/callback")
+	provider.config.Endpoint = oauth2.Endpoint{
+		AuthURL:  server.URL + "/login/oauth/authorize",
+		TokenURL: server.URL + "/login/oauth/access_token",
+	}
+	provider.apiURL = server.URL
+	return provider
+}
+
+func TestGitHub_Identity(t *testing.T) {
+	server := fakeGitHub(t, `[
+		{"email":"octo@old.example","primary":false,"verified":true},
+		{"email":"octocat@example.com","primary":true,"verified":true}
+	]`)
+	defer server.Close()
+
+	identity, err := testGitHub(server).Identity(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := Identity{Provider: "github", Subject: "42", Email: "octocat@example.com", Name: "octocat", AvatarURL: "https://avatars.example/42"}
+	if *identity != want {
+		t.Errorf("Expected %+v, got %+v", want, *identity)
+	}
+
+	if _, err := testGitHub(server).Identity(context.Background(), "bad-code"); err == nil {
+		t.Error("Expected a rejected code to fail")
+	}
+}
+
+func TestGitHub_UnverifiedPrimaryEmail(t *testing.T) {
+	server := fakeGitHub(t, `[{"email":"octocat@example.com","primary":true,"verified":false}]`)
+	defer server.Close()
+
+	if _, err := testGitHub(server).Identity(context.Background(), "good-code"); err != ErrNoVerifiedEmail {
+		t.Errorf("Expected ErrNoVerifiedEmail, got %v", err)
+	}
+}
+
+func TestGitHub_AuthCodeURL(t *testing.T) {
+	authURL, err := url.Parse(NewGitHub("client-id", "secret", "https://api.example/callback").AuthCodeURL("state-123"))
+	if err != nil {
+		t.Fatalf("Expected a valid URL, got %v", err)
+	}
+	query := authURL.Query()
+	if query.Get("state") != "state-123" || query.Get("client_id") != "client-id" || !strings.Contains(query.Get("scope"), "user:email") {
+		t.Errorf("Expected state, client id and the email scope, got %s", authURL)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("OAUTH_GITHUB_CLIENT_ID", "")
+	t.Setenv("OAUTH_GITHUB_CLIENT_SECRET", "")
+	if registry := Load(); len(registry) != 0 {
+		t.Errorf("Expected no providers without credentials, got %v", registry)
+	}
+
+	t.Setenv("OAUTH_GITHUB_CLIENT_ID", "client-id")
+	t.Setenv("OAUTH_GITHUB_CLIENT_SECRET", "secret")
+	t.Setenv("OAUTH_REDIRECT_BASE_URL", "https://api.example/")
+	provider, ok := Load()["github"]
+	if !ok {
+		t.Fatal("Expected github to be enabled")
+	}
+	if got := provider.(*GitHub).config.RedirectURL; got != "https://api.example/auth/users/oauth/github/callback" {
+		t.Errorf("Expected the callback under the base URL, got %s", got)
+	}
+}
@@ -0,0 +1,61 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// ErrNoVerifiedEmail is returned when the provider can't vouch for any email
+// of the user, who then can't be matched to an account
+var ErrNoVerifiedEmail = errors.New("oauth: no verified email")
+
+// Identity is the user a provider signed in, Email is verified by it
+type Identity struct {
+	Provider  string
+	Subject   string
+	Email     string
+	Name      string
+	AvatarURL string
+}
+
+// Provider is a social identity provider signing users in with the OAuth2
+// authorization code flow
+type Provider interface {
+	// Name is the provider's key in URLs, e.g. "github"
+	Name() string
+	// AuthCodeURL is the provider's sign-in page, state comes back on the callback
+	AuthCodeURL(state string) string
+	// Identity exchanges the callback's code and fetches the signed-in user
+	Identity(ctx context.Context, code string) (*Identity, error)
+}
+
+// Registry holds the configured providers by name
+type Registry map[string]Provider
+
+// factories builds each supported provider from its client credentials and
+// callback URL. A new provider only needs an entry here.
+var factories = map[string]func(clientID, clientSecret, redirectURL string) Provider{
+	"github": func(clientID, clientSecret, redirectURL string) Provider {
+		return NewGitHub(clientID, clientSecret, redirectURL)
+	},
+}
+
+// Load enables every supported provider whose OAUTH_<NAME>_CLIENT_ID and
+// OAUTH_<NAME>_CLIENT_SECRET are set. Providers call back to
+// OAUTH_REDIRECT_BASE_URL followed by /auth/users/oauth/<name>/callback.
+func Load() Registry {
+	registry := Registry{}
+	baseURL := strings.TrimSuffix(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+	for name, build := range factories {
+		prefix := "OAUTH_" + strings.ToUpper(name)
+		clientID := os.Getenv(prefix + "_CLIENT_ID")
+		clientSecret := os.Getenv(prefix + "_CLIENT_SECRET")
+		if clientID == "" || clientSecret == "" {
+			continue
+		}
+		registry[name] = build(clientID, clientSecret, baseURL+"/auth/users/oauth/"+name+"/callback")
+	}
+	return registry
+}
@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Limiter throttles requests per client IP and per email address. When the
+// store fails it counts in memory instead, an outage of Redis doesn't lift
+// the limits nor lock everyone out.
+type Limiter struct {
+	store    Store
+	fallback *MemoryStore
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// New returns a Limiter over store, in memory only when store is nil
+func New(store Store, logger *zap.Logger) *Limiter {
+	fallback := NewMemoryStore()
+	if store == nil {
+		store = fallback
+	}
+	return &Limiter{store: store, fallback: fallback, logger: logger, now: time.Now}
+}
+
+func (l *Limiter) take(ctx context.Context, key string, limit Limit) Result {
+	now := l.now()
+	result, err := l.store.Take(ctx, key, limit, now)
+	if err != nil {
+		l.logger.Warn("Rate limit store failed, counting in memory", zap.String("key", key), zap.Error(err))
+		result, _ = l.fallback.Take(ctx, key, limit, now)
+	}
+	return result
+}
+
+// maxEmailBodySize caps how much of a JSON body is read to find the email,
+// larger bodies are left to the handler and only limited per IP
+const maxEmailBodySize = 64 << 10
+
+// requestEmail is the email a request is about: the one the login
+// validation read, the authenticated user's, or an email query, form or
+// JSON body field. A JSON body is put back for the handler to bind.
+func requestEmail(c *gin.Context) string {
+	email := c.GetString("validated_email")
+	if email == "" {
+		email = c.GetString("email")
+	}
+	if email == "" {
+		email = c.Query("email")
+	}
+	if email == "" && strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		email = c.PostForm("email")
+	}
+	if email == "" && c.ContentType() == "application/json" {
+		email = jsonEmail(c.Request)
+	}
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// jsonEmail reads the email field of a JSON request body and restores the
+// body, untouched, for the handler
+func jsonEmail(req *http.Request) string {
+	if req.Body == nil {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxEmailBodySize))
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(data), req.Body), req.Body}
+	if err != nil {
+		return ""
+	}
+	var body struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return ""
+	}
+	return body.Email
+}
+
+// readCloser reads the restored body and closes the original one
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// seconds rounds d up to whole seconds for a header
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int((d + time.Second - 1) / time.Second))
+}
+
+// Middleware lets limit requests through per client IP and, when the
+// request names one, per email address, so neither one address nor a
+// spread of addresses against one account gets past it. Routes sharing a
+// name share their buckets. A refused request gets 429 with Retry-After,
+// every response carries X-RateLimit-Reset, the seconds until the
+// emptiest bucket is full again.
+func (l *Limiter) Middleware(name string, limit Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys := []string{name + ":ip:" + c.ClientIP()}
+		if email := requestEmail(c); email != "" {
+			keys = append(keys, name+":email:"+email)
+		}
+
+		remaining := limit.Burst
+		var reset time.Duration
+		for _, key := range keys {
+			result := l.take(c.Request.Context(), key, limit)
+			if result.Reset > reset {
+				reset = result.Reset
+			}
+			if !result.Allowed {
+				retryAfter := result.RetryAfter
+				if retryAfter < time.Second {
+					retryAfter = time.Second
+				}
+				c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+				c.Header("X-RateLimit-Remaining", "0")
+				c.Header("X-RateLimit-Reset", seconds(reset))
+				c.Header("Retry-After", seconds(retryAfter))
+				response.ErrorFromAppError(c, appErrors.ErrRateLimited)
+				c.Abort()
+				return
+			}
+			if result.Remaining < remaining {
+				remaining = result.Remaining
+			}
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", seconds(reset))
+		c.Next()
+	}
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func newTestRouter(limiter *Limiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/send-otp", limiter.Middleware("otp", PerMinute(2)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func sendOTP(router *gin.Engine, ip, email string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/send-otp?email="+email, nil)
+	req.RemoteAddr = ip + ":1234"
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	router := newTestRouter(New(nil, zap.NewNop()))
+
+	for i := 0; i < 2; i++ {
+		if w := sendOTP(router, "203.0.113.7", "john@example.com"); w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d through, got %d", i+1, w.Code)
+		}
+	}
+	w := sendOTP(router, "203.0.113.7", "john@example.com")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 past the limit, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "30" || w.Header().Get("X-RateLimit-Remaining") != "0" || w.Header().Get("X-RateLimit-Reset") != "60" {
+		t.Errorf("Expected Retry-After and the rate limit headers, got %v", w.Header())
+	}
+	var body struct {
+		Status string `json:"status"`
+		Error  struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Status != "ERROR" || body.Error.Code != "RATE_LIMITED" {
+		t.Errorf("Expected the structured RATE_LIMITED error, got %s", w.Body.String())
+	}
+
+	// The same account from other addresses is still limited, the
+	// spelling of the email doesn't matter
+	if w := sendOTP(router, "198.51.100.1", "John@Example.com"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the email's bucket to be empty, got %d", w.Code)
+	}
+	if w := sendOTP(router, "198.51.100.2", "jane@example.com"); w.Code != http.StatusOK {
+		t.Errorf("Expected another client and account through, got %d", w.Code)
+	}
+}
+
+func TestMiddleware_FallsBackToMemory(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := NewRedisStore("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("Failed to connect to redis: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	router := newTestRouter(New(store, zap.NewNop()))
+
+	server.Close()
+	for i := 0; i < 2; i++ {
+		if w := sendOTP(router, "203.0.113.7", ""); w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d through without redis, got %d", i+1, w.Code)
+		}
+	}
+	if w := sendOTP(router, "203.0.113.7", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the limit to hold without redis, got %d", w.Code)
+	}
+}
+
+func TestMiddleware_JSONBodyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/verify-otp", New(nil, zap.NewNop()).Middleware("otp", PerMinute(2)), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	verify := func(ip, email string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := `{"email":"` + email + `","otp":"000000"}`
+		req, _ := http.NewRequest("POST", "/verify-otp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := verify("203.0.113.7", "john@example.com")
+	if w.Code != http.StatusOK || w.Body.String() != `{"email":"john@example.com","otp":"000000"}` {
+		t.Fatalf("Expected the body to reach the handler untouched, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-RateLimit-Reset") != "30" {
+		t.Errorf("Expected the bucket to be full again in 30 seconds, got %q", w.Header().Get("X-RateLimit-Reset"))
+	}
+	verify("198.51.100.1", "john@example.com")
+
+	// Guessing from another address is still limited by the email in the body
+	if w := verify("198.51.100.2", "john@example.com"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the email's bucket to be empty, got %d", w.Code)
+	}
+}
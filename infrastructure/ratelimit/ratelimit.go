@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket holding Burst requests that refills completely
+// over Per, e.g. 10 per minute lets 10 through at once and one more every
+// six seconds after that
+type Limit struct {
+	Burst int
+	Per   time.Duration
+}
+
+// PerMinute is a Limit of n requests a minute
+func PerMinute(n int) Limit {
+	return Limit{Burst: n, Per: time.Minute}
+}
+
+// Result is the outcome of taking a token, RetryAfter is how long until the
+// next one when the request was refused and Reset how long until the bucket
+// is full again
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// Store keeps the buckets, MemoryStore in the process and RedisStore shared
+// by every instance
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// refill returns the tokens of a bucket last seen with tokens at updated
+func refill(tokens float64, updated, now time.Time, limit Limit) float64 {
+	elapsed := now.Sub(updated)
+	if elapsed <= 0 {
+		return tokens
+	}
+	return math.Min(float64(limit.Burst), tokens+float64(limit.Burst)*float64(elapsed)/float64(limit.Per))
+}
+
+// untilFull is how long a bucket holding tokens takes to refill completely
+func untilFull(tokens float64, limit Limit) time.Duration {
+	return time.Duration((float64(limit.Burst) - tokens) * float64(limit.Per) / float64(limit.Burst))
+}
+
+// take spends a token of a bucket holding tokens
+func take(tokens float64, limit Limit) (float64, Result) {
+	if tokens >= 1 {
+		tokens--
+		return tokens, Result{Allowed: true, Remaining: int(tokens), Reset: untilFull(tokens, limit)}
+	}
+	wait := time.Duration((1 - tokens) * float64(limit.Per) / float64(limit.Burst))
+	return tokens, Result{RetryAfter: wait, Reset: untilFull(tokens, limit)}
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore keeps buckets in the process. Each instance counts on its
+// own, so behind a load balancer the effective limit is multiplied.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Full buckets are the same as no bucket, dropping them keeps the map
+	// from growing with every address ever seen
+	if now.Sub(s.lastSweep) >= time.Minute {
+		for k, b := range s.buckets {
+			if now.Sub(b.updated) >= limit.Per {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	tokens, result := take(refill(b.tokens, b.updated, now, limit), limit)
+	b.tokens, b.updated = tokens, now
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// exerciseStore checks the bucket arithmetic every store must agree on
+func exerciseStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	limit := Limit{Burst: 3, Per: time.Minute}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "login:ip:203.0.113.7", limit, now)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !result.Allowed || result.Remaining != i {
+			t.Fatalf("Expected the burst to pass with %d left, got %+v", i, result)
+		}
+	}
+	result, _ := store.Take(ctx, "login:ip:203.0.113.7", limit, now)
+	if result.Allowed || result.RetryAfter != 20*time.Second {
+		t.Errorf("Expected the fourth request to wait for the next token in 20s, got %+v", result)
+	}
+
+	// Another key has its own bucket
+	if result, _ := store.Take(ctx, "login:ip:198.51.100.1", limit, now); !result.Allowed {
+		t.Error("Expected another client to be let through")
+	}
+
+	// One token comes back every 20s, and the bucket never overflows
+	if result, _ := store.Take(ctx, "login:ip:203.0.113.7", limit, now.Add(20*time.Second)); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Expected a refilled token after 20s, got %+v", result)
+	}
+	if result, _ := store.Take(ctx, "login:ip:203.0.113.7", limit, now.Add(time.Hour)); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("Expected a full bucket after an hour, got %+v", result)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	exerciseStore(t, NewMemoryStore())
+}
+
+func TestMemoryStore_DropsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.Take(context.Background(), "a", PerMinute(5), now)
+	store.Take(context.Background(), "b", PerMinute(5), now.Add(2*time.Minute))
+	if _, ok := store.buckets["a"]; ok || len(store.buckets) != 1 {
+		t.Errorf("Expected the refilled bucket to be dropped, got %d buckets", len(store.buckets))
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := NewRedisStore("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("Failed to connect to redis: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	exerciseStore(t, store)
+	if ttl := server.TTL(redisRateLimitPrefix + "login:ip:203.0.113.7"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Expected the bucket to expire once it refilled, got a TTL of %v", ttl)
+	}
+}
+
+func TestRedisStore_Unavailable(t *testing.T) {
+	if _, err := NewRedisStore("redis://127.0.0.1:1"); err == nil {
+		t.Error("Expected an unreachable redis to fail")
+	}
+	if _, err := NewRedisStore("not a url"); err == nil {
+		t.Error("Expected an invalid URL to fail")
+	}
+}
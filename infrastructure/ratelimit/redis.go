@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisRateLimitPrefix namespaces the buckets in a shared Redis
+const redisRateLimitPrefix = "rate_limit:"
+
+// takeScript refills and takes from a bucket in one step, so concurrent
+// requests on different instances can't both spend the last token. The
+// bucket expires once it would be full again.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local per = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+	tokens = burst
+	updated = now
+end
+if now > updated then
+	tokens = math.min(burst, tokens + burst * (now - updated) / per)
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", tostring(now))
+redis.call("PEXPIRE", KEYS[1], per)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps buckets in Redis, so the limit holds across every
+// instance of the service
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to the Redis at redisURL, e.g.
+// redis://:password@localhost:6379/0, and checks it answers
+func NewRedisStore(redisURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisStore{client: client}, nil
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	reply, err := takeScript.Run(ctx, s.client, []string{redisRateLimitPrefix + key},
+		limit.Burst, limit.Per.Milliseconds(), now.UnixMilli()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	allowed, _ := reply[0].(int64)
+	var tokens float64
+	if value, ok := reply[1].(string); ok {
+		tokens, _ = strconv.ParseFloat(value, 64)
+	}
+	if allowed == 1 {
+		return Result{Allowed: true, Remaining: int(tokens), Reset: untilFull(tokens, limit)}, nil
+	}
+	_, result := take(tokens, limit)
+	return result, nil
+}
+
+// Close releases the Redis connections
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,58 @@
+package sms
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Provider delivers text messages to a phone number in E.164 format
+type Provider interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// e164 is a number in E.164 format, a + and up to 15 digits
+var e164 = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+
+// NormalizeE164 returns phone in E.164 format, false when it can't be put in
+// it. Spaces, dashes, dots and brackets are dropped and a 00 prefix becomes
+// +. A number without a prefix is taken to start with its country code, as
+// in 628112123123, unless it starts with a single 0, a national number that
+// gets countryCode in place of the 0 and is refused when countryCode is
+// empty.
+func NormalizeE164(phone, countryCode string) (string, bool) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+	switch {
+	case strings.HasPrefix(digits, "+"):
+	case strings.HasPrefix(digits, "00"):
+		digits = "+" + digits[2:]
+	case strings.HasPrefix(digits, "0"):
+		if countryCode == "" {
+			return "", false
+		}
+		digits = "+" + strings.TrimPrefix(countryCode, "+") + digits[1:]
+	default:
+		digits = "+" + digits
+	}
+	if !e164.MatchString(digits) {
+		return "", false
+	}
+	return digits, true
+}
+
+// Load returns the SMS provider configured in the environment, nil when
+// none is, which leaves OTPs to email only
+func Load() Provider {
+	sid, token, from := os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_FROM_NUMBER")
+	if sid == "" || token == "" || from == "" {
+		return nil
+	}
+	return NewTwilio(sid, token, from)
+}
@@ -0,0 +1,32 @@
+package sms
+
+import "testing"
+
+func TestNormalizeE164(t *testing.T) {
+	tests := []struct {
+		phone       string
+		countryCode string
+		want        string
+		ok          bool
+	}{
+		{"+6281234567890", "", "+6281234567890", true},
+		{"628112123123", "", "+628112123123", true},
+		{"+62 811-2123-123", "", "+628112123123", true},
+		{"0062811212312", "", "+62811212312", true},
+		{"08112123123", "62", "+628112123123", true},
+		{"08112123123", "+62", "+628112123123", true},
+		{"08112123123", "", "", false},
+		{"+0811212312", "", "", false},
+		{"1234567", "", "", false},
+		{"+1234567890123456", "", "", false},
+		{"62811abc123", "", "", false},
+		{"", "62", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeE164(tt.phone, tt.countryCode)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeE164(%q, %q) = %q, %v, want %q, %v", tt.phone, tt.countryCode, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Twilio sends messages through the Twilio Messages API
+type Twilio struct {
+	accountSID string
+	authToken  string
+	from       string
+	apiURL     string
+	client     *http.Client
+}
+
+func NewTwilio(accountSID, authToken, from string) *Twilio {
+	return &Twilio{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		apiURL:     "https://api.twilio.com",
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (t *Twilio) Send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "From": {t.from}, "Body": {body}}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", t.apiURL, t.accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		// Twilio explains a refusal in the body, e.g. an unverified number
+		var failure struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("twilio: %d %s (code %d)", resp.StatusCode, failure.Message, failure.Code)
+	}
+	return nil
+}
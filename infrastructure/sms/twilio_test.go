@@ -0,0 +1,66 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func fakeTwilio(t *testing.T, status int, body string) (*Twilio, *http.Request) {
+	received := &http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		*received = *r
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	twilio := NewTwilio("AC123", "secret", "+15005550006")
+	twilio.apiURL = server.URL
+	return twilio, received
+}
+
+func TestTwilio_Send(t *testing.T) {
+	twilio, received := fakeTwilio(t, http.StatusCreated, `{"sid":"SM123","status":"queued"}`)
+
+	if err := twilio.Send(context.Background(), "+6281234567890", "Your OTP is 123456"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+		t.Errorf("Expected the account's Messages endpoint, got %s", received.URL.Path)
+	}
+	if user, pass, ok := received.BasicAuth(); !ok || user != "AC123" || pass != "secret" {
+		t.Errorf("Expected the account credentials, got %q %q", user, pass)
+	}
+	form := received.PostForm
+	if form.Get("To") != "+6281234567890" || form.Get("From") != "+15005550006" || form.Get("Body") != "Your OTP is 123456" {
+		t.Errorf("Expected the message in the form, got %v", form)
+	}
+}
+
+func TestTwilio_SendRefused(t *testing.T) {
+	twilio, _ := fakeTwilio(t, http.StatusBadRequest, `{"code":21211,"message":"The 'To' number is not a valid phone number."}`)
+
+	err := twilio.Send(context.Background(), "12", "Your OTP is 123456")
+	if err == nil || !strings.Contains(err.Error(), "21211") {
+		t.Errorf("Expected Twilio's error code in the error, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("TWILIO_ACCOUNT_SID", "AC123")
+	t.Setenv("TWILIO_AUTH_TOKEN", "")
+	t.Setenv("TWILIO_FROM_NUMBER", "+15005550006")
+	if Load() != nil {
+		t.Error("Expected no provider without an auth token")
+	}
+
+	t.Setenv("TWILIO_AUTH_TOKEN", "secret")
+	if _, ok := Load().(*Twilio); !ok {
+		t.Error("Expected Twilio once it is configured")
+	}
+}
@@ -0,0 +1,138 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/response"
+	"github.com/buildyow/byow-user-service/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CaptchaTokenField is the form field carrying the token the CAPTCHA widget
+// produced in the browser
+const CaptchaTokenField = "captcha_token"
+
+// captchaVerifyURLs are the siteverify endpoints of the supported providers,
+// both take the same form and answer with the same success flag
+var captchaVerifyURLs = map[string]string{
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+}
+
+// CaptchaVerifier checks CAPTCHA tokens with the provider that issued them
+type CaptchaVerifier struct {
+	Provider string
+	secret   string
+	// MinScore refuses reCAPTCHA v3 tokens scored below it, unchecked when
+	// zero or when the provider sends no score
+	MinScore  float64
+	verifyURL string
+	client    *http.Client
+}
+
+// NewCaptchaVerifier returns a verifier for provider, recaptcha or hcaptcha
+func NewCaptchaVerifier(provider, secret string) (*CaptchaVerifier, error) {
+	verifyURL, ok := captchaVerifyURLs[provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported captcha provider %q", provider)
+	}
+	return &CaptchaVerifier{
+		Provider:  provider,
+		secret:    secret,
+		verifyURL: verifyURL,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// LoadCaptcha returns the verifier configured by CAPTCHA_PROVIDER and
+// CAPTCHA_SECRET, nil when no provider is set
+func LoadCaptcha() (*CaptchaVerifier, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("CAPTCHA_PROVIDER")))
+	if provider == "" {
+		return nil, nil
+	}
+	secret := os.Getenv("CAPTCHA_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("CAPTCHA_SECRET is required with CAPTCHA_PROVIDER")
+	}
+	verifier, err := NewCaptchaVerifier(provider, secret)
+	if err != nil {
+		return nil, err
+	}
+	verifier.MinScore, _ = strconv.ParseFloat(os.Getenv("CAPTCHA_MIN_SCORE"), 64)
+	return verifier, nil
+}
+
+// Verify reports whether the provider accepts token. An error means the
+// provider couldn't be asked, not that the token is wrong.
+func (v *CaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%s siteverify: status %d", v.Provider, resp.StatusCode)
+	}
+	var result struct {
+		Success bool     `json:"success"`
+		Score   *float64 `json:"score"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	if result.Score != nil && *result.Score < v.MinScore {
+		return false, nil
+	}
+	return result.Success, nil
+}
+
+// RequireCaptcha refuses requests whose captcha_token form field the
+// verifier doesn't accept. It reads the parsed form, so it runs after
+// ParseMultipartForm. Nothing is checked when verifier is nil.
+func RequireCaptcha(verifier *CaptchaVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if verifier == nil {
+			c.Next()
+			return
+		}
+		token := strings.TrimSpace(c.PostForm(CaptchaTokenField))
+		if token == "" {
+			response.ErrorFromAppError(c, appErrors.ErrCaptchaRequired)
+			c.Abort()
+			return
+		}
+		ok, err := verifier.Verify(c.Request.Context(), token, c.ClientIP())
+		if err != nil {
+			utils.LogError("Failed to verify captcha with %s: %v", verifier.Provider, err)
+			response.ErrorFromAppError(c, appErrors.ErrCaptchaUnavailable)
+			c.Abort()
+			return
+		}
+		if !ok {
+			response.ErrorFromAppError(c, appErrors.ErrCaptchaInvalid)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
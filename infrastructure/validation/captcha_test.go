@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeSiteverify accepts the token "good-token", scoring it score when set
+func fakeSiteverify(t *testing.T, score string) *CaptchaVerifier {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("secret") != "secret" {
+			t.Errorf("Expected the secret in the form, got %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("response") == "provider-down" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		success := r.PostForm.Get("response") == "good-token"
+		body := `{"success":` + map[bool]string{true: "true", false: "false"}[success]
+		if score != "" {
+			body += `,"score":` + score
+		}
+		w.Write([]byte(body + "}"))
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := NewCaptchaVerifier("hcaptcha", "secret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	verifier.verifyURL = server.URL
+	return verifier
+}
+
+func postCaptcha(verifier *CaptchaVerifier, token string) *httptest.ResponseRecorder {
+	router := setupValidationTestRouter()
+	router.POST("/register", ParseMultipartForm(1<<20), RequireCaptcha(verifier), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("email", "john@example.com")
+	if token != "" {
+		writer.WriteField(CaptchaTokenField, token)
+	}
+	writer.Close()
+	req, _ := http.NewRequest("POST", "/register", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireCaptcha(t *testing.T) {
+	verifier := fakeSiteverify(t, "")
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+		wantErr  string
+	}{
+		{"accepted", "good-token", http.StatusCreated, ""},
+		{"missing", "", http.StatusBadRequest, "CAPTCHA_REQUIRED"},
+		{"refused", "bad-token", http.StatusBadRequest, "CAPTCHA_INVALID"},
+		{"provider down", "provider-down", http.StatusServiceUnavailable, "CAPTCHA_UNAVAILABLE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postCaptcha(verifier, tt.token)
+			if w.Code != tt.wantCode {
+				t.Errorf("Expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantErr != "" && !strings.Contains(w.Body.String(), tt.wantErr) {
+				t.Errorf("Expected %s, got %s", tt.wantErr, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireCaptcha_Disabled(t *testing.T) {
+	if w := postCaptcha(nil, ""); w.Code != http.StatusCreated {
+		t.Errorf("Expected registration without a captcha when none is configured, got %d", w.Code)
+	}
+}
+
+func TestRequireCaptcha_MinScore(t *testing.T) {
+	verifier := fakeSiteverify(t, "0.3")
+	if w := postCaptcha(verifier, "good-token"); w.Code != http.StatusCreated {
+		t.Errorf("Expected any score to pass without a minimum, got %d", w.Code)
+	}
+	verifier.MinScore = 0.5
+	if w := postCaptcha(verifier, "good-token"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a low score to be refused, got %d", w.Code)
+	}
+}
+
+func TestLoadCaptcha(t *testing.T) {
+	t.Setenv("CAPTCHA_PROVIDER", "")
+	if verifier, err := LoadCaptcha(); verifier != nil || err != nil {
+		t.Errorf("Expected no verifier without a provider, got %v %v", verifier, err)
+	}
+
+	t.Setenv("CAPTCHA_PROVIDER", "reCAPTCHA")
+	t.Setenv("CAPTCHA_SECRET", "secret")
+	t.Setenv("CAPTCHA_MIN_SCORE", "0.7")
+	verifier, err := LoadCaptcha()
+	if err != nil || verifier.Provider != "recaptcha" || verifier.MinScore != 0.7 {
+		t.Errorf("Expected a reCAPTCHA verifier, got %+v %v", verifier, err)
+	}
+
+	t.Setenv("CAPTCHA_PROVIDER", "turnstile")
+	if _, err := LoadCaptcha(); err == nil {
+		t.Error("Expected an unknown provider to be refused")
+	}
+	t.Setenv("CAPTCHA_PROVIDER", "hcaptcha")
+	t.Setenv("CAPTCHA_SECRET", "")
+	if _, err := LoadCaptcha(); err == nil {
+		t.Error("Expected a provider without a secret to be refused")
+	}
+}
@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CheckEmailMX turns on the MX lookup in ValidateRegistrationRequest. Off by
+// default, every uncached registration waits on DNS.
+var CheckEmailMX bool
+
+const (
+	// MXLookupTimeout bounds a single MX lookup
+	MXLookupTimeout = 3 * time.Second
+	// MXCacheTTL is how long a lookup result is reused for the same domain
+	MXCacheTTL = 10 * time.Minute
+	// mxCacheLimit bounds the cache, it is simply emptied when full
+	mxCacheLimit = 1000
+)
+
+// MXResolver looks up MX records, net.Resolver satisfies it
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+type mxCacheEntry struct {
+	ok        bool
+	expiresAt time.Time
+}
+
+var (
+	mxResolver MXResolver = net.DefaultResolver
+	mxCache               = map[string]mxCacheEntry{}
+	mxCacheMu  sync.Mutex
+)
+
+// HasMXRecord reports whether the domain of email publishes an MX record
+// that accepts mail. It is a soft check: lookups that fail for reasons other
+// than the domain not existing, such as a timeout, count as deliverable so a
+// DNS outage never blocks registration. Answers are cached for MXCacheTTL.
+func HasMXRecord(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSuffix(email[at+1:], "."))
+
+	mxCacheMu.Lock()
+	entry, cached := mxCache[domain]
+	mxCacheMu.Unlock()
+	if cached && time.Now().Before(entry.expiresAt) {
+		return entry.ok
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MXLookupTimeout)
+	defer cancel()
+	records, err := mxResolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			return true
+		}
+	}
+
+	ok := false
+	for _, record := range records {
+		// A lone "." is a null MX, the domain explicitly accepts no mail
+		if record.Host != "." && record.Host != "" {
+			ok = true
+			break
+		}
+	}
+
+	mxCacheMu.Lock()
+	if len(mxCache) >= mxCacheLimit {
+		mxCache = map[string]mxCacheEntry{}
+	}
+	mxCache[domain] = mxCacheEntry{ok: ok, expiresAt: time.Now().Add(MXCacheTTL)}
+	mxCacheMu.Unlock()
+	return ok
+}
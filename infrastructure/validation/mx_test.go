@@ -0,0 +1,160 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// stubResolver answers MX lookups from a map and counts the lookups
+type stubResolver struct {
+	records map[string][]*net.MX
+	err     error
+	lookups int
+}
+
+func (r *stubResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// useStubResolver swaps in resolver with an empty cache for the test
+func useStubResolver(t *testing.T, resolver MXResolver) {
+	original := mxResolver
+	mxResolver = resolver
+	mxCache = map[string]mxCacheEntry{}
+	t.Cleanup(func() {
+		mxResolver = original
+		mxCache = map[string]mxCacheEntry{}
+	})
+}
+
+func TestHasMXRecord(t *testing.T) {
+	resolver := &stubResolver{records: map[string][]*net.MX{
+		"example.com":   {{Host: "mx1.example.com.", Pref: 10}},
+		"nomail.com":    {{Host: ".", Pref: 0}},
+		"emptyzone.com": {},
+	}}
+	useStubResolver(t, resolver)
+
+	tests := []struct {
+		email    string
+		expected bool
+	}{
+		{"john@example.com", true},
+		{"john@EXAMPLE.com", true},
+		{"john@nomail.com", false},
+		{"john@emptyzone.com", false},
+		{"john@exmaple.com", false},
+		{"not-an-email", false},
+		{"john@", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := HasMXRecord(tt.email); got != tt.expected {
+				t.Errorf("HasMXRecord(%q) = %v, expected %v", tt.email, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasMXRecord_CachesLookups(t *testing.T) {
+	resolver := &stubResolver{records: map[string][]*net.MX{
+		"example.com": {{Host: "mx1.example.com.", Pref: 10}},
+	}}
+	useStubResolver(t, resolver)
+
+	HasMXRecord("john@example.com")
+	HasMXRecord("jane@example.com")
+	HasMXRecord("john@exmaple.com")
+	HasMXRecord("jane@exmaple.com")
+
+	if resolver.lookups != 2 {
+		t.Errorf("Expected one lookup per domain, got %d", resolver.lookups)
+	}
+}
+
+func TestHasMXRecord_LookupFailureIsSoft(t *testing.T) {
+	resolver := &stubResolver{err: &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}}
+	useStubResolver(t, resolver)
+
+	if !HasMXRecord("john@example.com") {
+		t.Error("Expected a DNS timeout not to reject the email")
+	}
+	resolver.err = errors.New("network is unreachable")
+	if !HasMXRecord("john@example.com") {
+		t.Error("Expected a network failure not to reject the email")
+	}
+	if resolver.lookups != 2 {
+		t.Errorf("Expected failed lookups not to be cached, got %d lookups", resolver.lookups)
+	}
+}
+
+func TestValidateRegistrationRequest_EmailMXCheck(t *testing.T) {
+	useStubResolver(t, &stubResolver{records: map[string][]*net.MX{
+		"example.com": {{Host: "mx1.example.com.", Pref: 10}},
+	}})
+	original := CheckEmailMX
+	defer func() { CheckEmailMX = original }()
+
+	router := setupValidationTestRouter()
+	router.POST("/register", ValidateRegistrationRequest(), func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "success"})
+	})
+	register := func(email string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Add("full_name", "John Doe")
+		form.Add("email", email)
+		form.Add("password", "Password123!")
+		form.Add("phone_number", "+1234567890")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	CheckEmailMX = false
+	if w := register("john@exmaple.com"); w.Code != http.StatusOK {
+		t.Errorf("Expected no MX check when disabled, got %d", w.Code)
+	}
+
+	CheckEmailMX = true
+	if w := register("john@example.com"); w.Code != http.StatusOK {
+		t.Errorf("Expected a domain with MX to pass, got %d", w.Code)
+	}
+
+	w := register("john@exmaple.com")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected a domain without MX to be rejected, got %d", w.Code)
+	}
+	var response struct {
+		Error struct {
+			Details []ValidationError `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	details := response.Error.Details
+	if len(details) != 1 || details[0].Field != "email" || details[0].Message != appErrors.ErrEmailDomainUnreachable.Message {
+		t.Errorf("Expected an unreachable email domain error, got %+v", details)
+	}
+}
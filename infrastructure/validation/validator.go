@@ -1,11 +1,18 @@
 package validation
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/response"
 	"github.com/gin-gonic/gin"
 )
@@ -19,6 +26,29 @@ type ValidationResponse struct {
 	Errors []ValidationError `json:"errors"`
 }
 
+// RegistrationFieldOrder is the order registration errors are reported in,
+// whatever order the checks run in
+var RegistrationFieldOrder = []string{"full_name", "email", "password", "phone_number"}
+
+// sortByFieldOrder orders errors by the position of their field in order,
+// keeping errors of the same field in the order they were added. Fields not
+// in order go last.
+func sortByFieldOrder(errors []ValidationError, order []string) {
+	rank := make(map[string]int, len(order))
+	for i, field := range order {
+		rank[field] = i
+	}
+	position := func(field string) int {
+		if i, ok := rank[field]; ok {
+			return i
+		}
+		return len(order)
+	}
+	sort.SliceStable(errors, func(i, j int) bool {
+		return position(errors[i].Field) < position(errors[j].Field)
+	})
+}
+
 // ValidateEmail validates email format
 func ValidateEmail(email string) bool {
 	emailRegex := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
@@ -72,37 +102,60 @@ func ValidatePassword(password string) (bool, string) {
 func ValidatePhoneNumber(phone string) bool {
 	// Remove all non-digit characters for validation
 	phoneDigits := regexp.MustCompile(`\D`).ReplaceAllString(phone, "")
-	
+
 	// Check if it's a valid length (8-15 digits as per E.164)
 	if len(phoneDigits) < 8 || len(phoneDigits) > 15 {
 		return false
 	}
-	
+
 	// Check if it starts with country code or local format
 	phoneRegex := regexp.MustCompile(`^(\+?[1-9]\d{1,14}|0\d{7,14})$`)
 	return phoneRegex.MatchString(phone)
 }
 
-// ValidateFullName validates full name
+// FullNamePunctuation lists the punctuation allowed in full names besides
+// spaces, e.g. "Jean-Pierre", "O'Connor" and "Dr. Smith"
+var FullNamePunctuation = []rune{'-', '\'', '.'}
+
+// MinFullNameLength and MaxFullNameLength bound the full name length in
+// characters, not bytes, so two-character CJK names pass and long accented
+// or CJK names aren't cut off early
+var MinFullNameLength = 2
+var MaxFullNameLength = 100
+
+// ValidateFullName validates full name, accepting letters from any script
 func ValidateFullName(name string) (bool, string) {
 	name = strings.TrimSpace(name)
-	if len(name) < 2 {
-		return false, "Full name must be at least 2 characters long"
+	length := utf8.RuneCountInString(name)
+	if length < MinFullNameLength {
+		return false, fmt.Sprintf("Full name must be at least %d characters long", MinFullNameLength)
 	}
-	if len(name) > 100 {
-		return false, "Full name must be less than 100 characters long"
+	if length > MaxFullNameLength {
+		return false, fmt.Sprintf("Full name must be less than %d characters long", MaxFullNameLength)
 	}
-	
-	// Check for valid characters (letters, spaces, hyphens, apostrophes)
-	nameRegex := regexp.MustCompile(`^[a-zA-Z\s\-'\.]+$`)
-	if !nameRegex.MatchString(name) {
+
+	for _, r := range name {
+		// Marks cover combining accents and vowel signs in scripts like Devanagari
+		if unicode.IsLetter(r) || unicode.IsMark(r) || r == ' ' || isFullNamePunctuation(r) {
+			continue
+		}
 		return false, "Full name can only contain letters, spaces, hyphens, apostrophes, and periods"
 	}
-	
+
 	return true, ""
 }
 
-// ValidateRegistrationRequest validates registration form data
+func isFullNamePunctuation(r rune) bool {
+	for _, p := range FullNamePunctuation {
+		if r == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRegistrationRequest validates registration form data. Errors are
+// reported in RegistrationFieldOrder so clients can rely on their order.
 func ValidateRegistrationRequest() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var errors []ValidationError
@@ -124,8 +177,12 @@ func ValidateRegistrationRequest() gin.HandlerFunc {
 		// Validate email
 		if email == "" {
 			errors = append(errors, ValidationError{Field: "email", Message: "Email is required"})
+		} else if looksLikePhoneNumber(email) {
+			errors = append(errors, ValidationError{Field: "email", Message: "Email looks like a phone number, are the email and phone number fields swapped?"})
 		} else if !ValidateEmail(email) {
 			errors = append(errors, ValidationError{Field: "email", Message: "Invalid email format"})
+		} else if CheckEmailMX && !HasMXRecord(email) {
+			errors = append(errors, ValidationError{Field: "email", Message: appErrors.ErrEmailDomainUnreachable.Message})
 		}
 
 		// Validate password
@@ -140,11 +197,14 @@ func ValidateRegistrationRequest() gin.HandlerFunc {
 		// Validate phone number
 		if phoneNumber == "" {
 			errors = append(errors, ValidationError{Field: "phone_number", Message: "Phone number is required"})
+		} else if strings.Contains(phoneNumber, "@") {
+			errors = append(errors, ValidationError{Field: "phone_number", Message: "Phone number looks like an email, are the email and phone number fields swapped?"})
 		} else if !ValidatePhoneNumber(phoneNumber) {
 			errors = append(errors, ValidationError{Field: "phone_number", Message: "Invalid phone number format"})
 		}
 
 		if len(errors) > 0 {
+			sortByFieldOrder(errors, RegistrationFieldOrder)
 			response.ValidationError(c, errors)
 			c.Abort()
 			return
@@ -154,6 +214,20 @@ func ValidateRegistrationRequest() gin.HandlerFunc {
 	}
 }
 
+// looksLikePhoneNumber reports whether value is only digits, optionally with a leading +
+func looksLikePhoneNumber(value string) bool {
+	digits := strings.TrimPrefix(value, "+")
+	if digits == "" || strings.Contains(value, "@") {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // ValidateLoginRequest validates login JSON data
 func ValidateLoginRequest() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -199,6 +273,48 @@ func ValidateLoginRequest() gin.HandlerFunc {
 	}
 }
 
+// MultipartParseError maps a ParseMultipartForm failure to an AppError. A body
+// cut short by a dropped connection is reported as an incomplete upload rather
+// than a malformed form.
+func MultipartParseError(ctx context.Context, err error) *appErrors.AppError {
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		ctx.Err() != nil {
+		return appErrors.ErrUploadIncomplete
+	}
+	return appErrors.ErrFailedParseMultipart
+}
+
+// ParseMultipartForm parses the multipart body up front so that later
+// PostForm/FormFile calls never silently read a truncated form
+func ParseMultipartForm(maxMemory int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
+			response.ErrorFromAppError(c, MultipartParseError(c.Request.Context(), err))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// UploadConfig describes the limits enforced on an uploaded file
+type UploadConfig struct {
+	MaxSize      int64
+	AllowedTypes []string
+	// MaxWidth and MaxHeight limit image dimensions in pixels, no limit when zero
+	MaxWidth  int
+	MaxHeight int
+}
+
+// ImageUpload is what image uploads are validated against, clients read it
+// from GET /api/uploads/config to keep their pickers in sync
+var ImageUpload = UploadConfig{
+	MaxSize:      10 << 20, // 10MB
+	AllowedTypes: []string{"image/jpeg", "image/png", "image/gif"},
+}
+
 // ValidateFileUpload validates file upload constraints
 func ValidateFileUpload(maxSize int64, allowedTypes []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -240,4 +356,4 @@ func ValidateFileUpload(maxSize int64, allowedTypes []string) gin.HandlerFunc {
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
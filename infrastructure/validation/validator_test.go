@@ -2,7 +2,11 @@ package validation
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +14,7 @@ import (
 	"strings"
 	"testing"
 
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -89,10 +94,10 @@ func TestValidatePhoneNumber(t *testing.T) {
 		{"1234567890", true},
 		{"+628123456789", true},
 		{"08123456789", true},
-		{"123-456-7890", false}, // Regex doesn't allow dashes in this format
-		{"(123) 456-7890", false}, // Regex doesn't allow parentheses and spaces
-		{"+1 234 567 8900", false}, // Regex doesn't allow spaces
-		{"1234567", false}, // Too short
+		{"123-456-7890", false},      // Regex doesn't allow dashes in this format
+		{"(123) 456-7890", false},    // Regex doesn't allow parentheses and spaces
+		{"+1 234 567 8900", false},   // Regex doesn't allow spaces
+		{"1234567", false},           // Too short
 		{"12345678901234567", false}, // Too long
 		{"", false},
 		{"abc1234567", false},
@@ -124,10 +129,21 @@ func TestValidateFullName(t *testing.T) {
 		{"A", false, "Full name must be at least 2 characters long"},
 		{"", false, "Full name must be at least 2 characters long"},
 		{strings.Repeat("a", 101), false, "Full name must be less than 100 characters long"},
+		{strings.Repeat("王", 100), true, ""},
+		{strings.Repeat("é", 101), false, "Full name must be less than 100 characters long"},
 		{"John123", false, "Full name can only contain letters, spaces, hyphens, apostrophes, and periods"},
 		{"John@Doe", false, "Full name can only contain letters, spaces, hyphens, apostrophes, and periods"},
 		{"  John Doe  ", true, ""}, // Should handle trimming
-		{"José María", false, "Full name can only contain letters, spaces, hyphens, apostrophes, and periods"}, // Current regex doesn't support accented characters
+		{"José María", true, ""},
+		{"Zoë Saldaña-Núñez", true, ""},
+		{"王小明", true, ""},
+		{"李明", true, ""},
+		{"佐藤 花子", true, ""},
+		{"Дмитрий Иванов", true, ""},
+		{"李", false, "Full name must be at least 2 characters long"},
+		{"José2", false, "Full name can only contain letters, spaces, hyphens, apostrophes, and periods"},
+		{"王小明３", false, "Full name can only contain letters, spaces, hyphens, apostrophes, and periods"},
+		{"John\tDoe", false, "Full name can only contain letters, spaces, hyphens, apostrophes, and periods"},
 	}
 
 	for _, tt := range tests {
@@ -143,6 +159,20 @@ func TestValidateFullName(t *testing.T) {
 	}
 }
 
+func TestValidateFullName_CustomPunctuation(t *testing.T) {
+	original := FullNamePunctuation
+	defer func() { FullNamePunctuation = original }()
+
+	if valid, _ := ValidateFullName("Anne·Marie"); valid {
+		t.Error("Expected middle dot to be rejected by default")
+	}
+
+	FullNamePunctuation = append([]rune{'·'}, original...)
+	if valid, msg := ValidateFullName("Anne·Marie"); !valid {
+		t.Errorf("Expected middle dot to be accepted once configured, got %q", msg)
+	}
+}
+
 func TestValidationError(t *testing.T) {
 	err := ValidationError{
 		Field:   "email",
@@ -207,10 +237,10 @@ func TestValidateRegistrationRequest_ValidationErrors(t *testing.T) {
 
 	// Test with invalid data
 	form := url.Values{}
-	form.Add("full_name", "A") // Too short
+	form.Add("full_name", "A")         // Too short
 	form.Add("email", "invalid-email") // Invalid format
-	form.Add("password", "short") // Too short
-	form.Add("phone_number", "123") // Too short
+	form.Add("password", "short")      // Too short
+	form.Add("phone_number", "123")    // Too short
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
@@ -259,13 +289,168 @@ func TestValidateRegistrationRequest_EmptyFields(t *testing.T) {
 
 	errorData := response["error"].(map[string]interface{})
 	details := errorData["details"].([]interface{})
-	
+
 	// Should have 4 validation errors for all required fields
 	if len(details) != 4 {
 		t.Errorf("Expected 4 validation errors, got %d", len(details))
 	}
 }
 
+func TestValidateRegistrationRequest_CanonicalFieldOrder(t *testing.T) {
+	router := setupValidationTestRouter()
+	router.POST("/register", ValidateRegistrationRequest(), func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "success"})
+	})
+
+	valid := map[string]string{
+		"full_name":    "John Doe",
+		"email":        "john@example.com",
+		"password":     "Password123!",
+		"phone_number": "+1234567890",
+	}
+	invalid := map[string]string{
+		"full_name":    "A",
+		"email":        "invalid-email",
+		"password":     "short",
+		"phone_number": "123",
+	}
+
+	// Every combination of invalid fields, as a bitmask over the canonical order
+	for mask := 1; mask < 1<<len(RegistrationFieldOrder); mask++ {
+		form := url.Values{}
+		var expected []string
+		for i, field := range RegistrationFieldOrder {
+			if mask&(1<<i) != 0 {
+				form.Add(field, invalid[field])
+				expected = append(expected, field)
+			} else {
+				form.Add(field, valid[field])
+			}
+		}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Error struct {
+				Details []ValidationError `json:"details"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		var got []string
+		for _, detail := range response.Error.Details {
+			got = append(got, detail.Field)
+		}
+		if strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("Invalid %v: expected errors in order %v, got %v", expected, expected, got)
+		}
+	}
+}
+
+func TestSortByFieldOrder(t *testing.T) {
+	errors := []ValidationError{
+		{Field: "phone_number", Message: "Invalid phone number format"},
+		{Field: "company", Message: "Unknown"},
+		{Field: "password", Message: "first"},
+		{Field: "full_name", Message: "Full name is required"},
+		{Field: "password", Message: "second"},
+	}
+
+	sortByFieldOrder(errors, RegistrationFieldOrder)
+
+	expected := []string{"full_name", "password", "password", "phone_number", "company"}
+	for i, field := range expected {
+		if errors[i].Field != field {
+			t.Fatalf("Expected field %s at %d, got %+v", field, i, errors)
+		}
+	}
+	if errors[1].Message != "first" || errors[2].Message != "second" {
+		t.Errorf("Expected errors of one field to keep their order, got %+v", errors)
+	}
+}
+
+func TestValidateRegistrationRequest_SwappedFields(t *testing.T) {
+	router := setupValidationTestRouter()
+	router.POST("/register", ValidateRegistrationRequest(), func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "success"})
+	})
+
+	tests := []struct {
+		name          string
+		email         string
+		phoneNumber   string
+		swappedFields []string
+	}{
+		{"both swapped", "628112123123", "john@example.com", []string{"email", "phone_number"}},
+		{"phone in email field", "+628112123123", "628112123123", []string{"email"}},
+		{"email in phone field", "john@example.com", "john@example.com", []string{"phone_number"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("full_name", "John Doe")
+			form.Add("email", tt.email)
+			form.Add("password", "Password123!")
+			form.Add("phone_number", tt.phoneNumber)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router.ServeHTTP(w, req)
+
+			if w.Code != 400 {
+				t.Fatalf("Expected status code 400, got %d", w.Code)
+			}
+
+			var resp struct {
+				Error struct {
+					Details []ValidationError `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if len(resp.Error.Details) != len(tt.swappedFields) {
+				t.Fatalf("Expected %d validation errors, got %v", len(tt.swappedFields), resp.Error.Details)
+			}
+			for i, field := range tt.swappedFields {
+				detail := resp.Error.Details[i]
+				if detail.Field != field || !strings.Contains(detail.Message, "swapped") {
+					t.Errorf("Expected swapped-fields hint on %s, got %+v", field, detail)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationRequest_InvalidEmailIsNotSwapHint(t *testing.T) {
+	router := setupValidationTestRouter()
+	router.POST("/register", ValidateRegistrationRequest(), func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "success"})
+	})
+
+	form := url.Values{}
+	form.Add("full_name", "John Doe")
+	form.Add("email", "john.example.com")
+	form.Add("password", "Password123!")
+	form.Add("phone_number", "628112123123")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), "Invalid email format") || strings.Contains(w.Body.String(), "swapped") {
+		t.Errorf("Expected plain invalid email error, got %s", w.Body.String())
+	}
+}
+
 func TestValidateLoginRequest_Success(t *testing.T) {
 	router := setupValidationTestRouter()
 	router.POST("/login", ValidateLoginRequest(), func(c *gin.Context) {
@@ -369,13 +554,13 @@ func TestValidateFileUpload_ValidFile(t *testing.T) {
 	// Create a multipart form with a valid file
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
-	
+
 	// Create a form file
 	fileWriter, err := writer.CreateFormFile("avatar", "test.jpg")
 	if err != nil {
 		t.Fatalf("Failed to create form file: %v", err)
 	}
-	
+
 	// Write some test data
 	fileWriter.Write([]byte("test image data"))
 	writer.Close()
@@ -400,12 +585,12 @@ func TestValidateFileUpload_FileSizeExceeds(t *testing.T) {
 	// Create a multipart form with a file that exceeds size limit
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
-	
+
 	fileWriter, err := writer.CreateFormFile("avatar", "large.jpg")
 	if err != nil {
 		t.Fatalf("Failed to create form file: %v", err)
 	}
-	
+
 	// Write data larger than the limit (10 bytes)
 	fileWriter.Write([]byte("this is definitely more than 10 bytes of data"))
 	writer.Close()
@@ -418,4 +603,85 @@ func TestValidateFileUpload_FileSizeExceeds(t *testing.T) {
 	if w.Code != 400 {
 		t.Errorf("Expected status code 400 for file size exceeded, got %d", w.Code)
 	}
-}
\ No newline at end of file
+}
+func TestParseMultipartForm_TruncatedBody(t *testing.T) {
+	router := setupValidationTestRouter()
+	handlerCalled := false
+	router.POST("/upload", ParseMultipartForm(10<<20), func(c *gin.Context) {
+		handlerCalled = true
+		c.JSON(200, gin.H{"status": "success"})
+	})
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("full_name", "John Doe")
+	fileWriter, _ := writer.CreateFormFile("avatar", "avatar.jpg")
+	fileWriter.Write(bytes.Repeat([]byte("x"), 1024))
+	writer.Close()
+
+	// Drop the tail of the body, as if the client disconnected mid-upload
+	truncated := body.Bytes()[:body.Len()/2]
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/upload", bytes.NewReader(truncated))
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "UPLOAD_INCOMPLETE") {
+		t.Errorf("Expected UPLOAD_INCOMPLETE error, got %s", w.Body.String())
+	}
+	if handlerCalled {
+		t.Error("Expected handler not to run for a truncated upload")
+	}
+}
+
+func TestParseMultipartForm_ValidBody(t *testing.T) {
+	router := setupValidationTestRouter()
+	router.POST("/upload", ParseMultipartForm(10<<20), func(c *gin.Context) {
+		c.JSON(200, gin.H{"full_name": c.PostForm("full_name")})
+	})
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("full_name", "John Doe")
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("Expected status code 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "John Doe") {
+		t.Errorf("Expected form field to be readable, got %s", w.Body.String())
+	}
+}
+
+func TestMultipartParseError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected *appErrors.AppError
+	}{
+		{"unexpected EOF", context.Background(), fmt.Errorf("multipart: NextPart: %w", io.ErrUnexpectedEOF), appErrors.ErrUploadIncomplete},
+		{"cancelled request", cancelled, errors.New("read failed"), appErrors.ErrUploadIncomplete},
+		{"malformed form", context.Background(), http.ErrNotMultipart, appErrors.ErrFailedParseMultipart},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MultipartParseError(tt.ctx, tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -3,16 +3,29 @@ package lib
 import (
 	"context"
 	"mime/multipart"
+	"net/url"
 	"os"
+	"path"
+	"strings"
 
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
-func CloudinaryUpload(file multipart.File) (string, error) {
+// allowedImageExtensions are the formats accepted back from Cloudinary
+var allowedImageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+}
+
+// uploadFile sends the file to Cloudinary and returns its secure URL, swapped out in tests
+var uploadFile = func(cloudName string, file multipart.File) (string, error) {
 	cld, err := cloudinary.NewFromParams(
-		os.Getenv("CLOUDINARY_CLOUD_NAME"),
+		cloudName,
 		os.Getenv("CLOUDINARY_API_KEY"),
 		os.Getenv("CLOUDINARY_API_SECRET"),
 	)
@@ -27,3 +40,93 @@ func CloudinaryUpload(file multipart.File) (string, error) {
 
 	return uploadResp.SecureURL, nil
 }
+
+// destroyFile removes an uploaded image from Cloudinary, swapped out in tests
+var destroyFile = func(cloudName string, publicID string) error {
+	cld, err := cloudinary.NewFromParams(
+		cloudName,
+		os.Getenv("CLOUDINARY_API_KEY"),
+		os.Getenv("CLOUDINARY_API_SECRET"),
+	)
+	if err != nil {
+		return appErrors.WrapError(err, "Failed to initialize Cloudinary")
+	}
+
+	if _, err := cld.Upload.Destroy(context.Background(), uploader.DestroyParams{PublicID: publicID}); err != nil {
+		return appErrors.WrapError(err, "Failed to delete image from Cloudinary")
+	}
+	return nil
+}
+
+func CloudinaryUpload(file multipart.File) (string, error) {
+	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
+	secureURL, err := uploadFile(cloudName, file)
+	if err != nil {
+		return "", err
+	}
+
+	// A misconfigured account can hand back a non-image resource, never store it
+	if !IsCloudinaryImageURL(secureURL, cloudName) {
+		return "", appErrors.ErrCloudinaryUploadFailed
+	}
+
+	return secureURL, nil
+}
+
+// IsCloudinaryImageURL reports whether rawURL is an https image delivery URL
+// of the given Cloudinary cloud with an allowed image extension
+func IsCloudinaryImageURL(rawURL string, cloudName string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host != "res.cloudinary.com" || cloudName == "" {
+		return false
+	}
+	if !strings.HasPrefix(u.Path, "/"+cloudName+"/image/") {
+		return false
+	}
+	return allowedImageExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// CloudinaryDelete removes an image stored by CloudinaryUpload. URLs outside
+// the configured cloud are left alone.
+func CloudinaryDelete(imageURL string) error {
+	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
+	publicID, ok := CloudinaryPublicID(imageURL, cloudName)
+	if !ok {
+		return nil
+	}
+	return destroyFile(cloudName, publicID)
+}
+
+// CloudinaryPublicID extracts the public ID from an image delivery URL like
+// https://res.cloudinary.com/<cloud>/image/upload/v1700000000/logos/acme.png
+func CloudinaryPublicID(rawURL string, cloudName string) (string, bool) {
+	if !IsCloudinaryImageURL(rawURL, cloudName) {
+		return "", false
+	}
+	u, _ := url.Parse(rawURL)
+	prefix := "/" + cloudName + "/image/upload/"
+	if !strings.HasPrefix(u.Path, prefix) {
+		return "", false
+	}
+
+	segments := strings.Split(strings.TrimPrefix(u.Path, prefix), "/")
+	// The optional version segment isn't part of the public ID
+	if len(segments) > 1 && isVersionSegment(segments[0]) {
+		segments = segments[1:]
+	}
+	publicID := strings.Join(segments, "/")
+	publicID = strings.TrimSuffix(publicID, path.Ext(publicID))
+	return publicID, publicID != ""
+}
+
+func isVersionSegment(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, r := range segment[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestAPIKeyRepo(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("find by hash skips revoked keys", func(mt *mtest.T) {
+		repo := &apiKeyMongoRepo{collection: mt.Coll}
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "name", Value: "billing"},
+				{Key: "hash", Value: "abc"},
+			}),
+		)
+
+		key, err := repo.FindByHash("abc")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if key.Name != "billing" {
+			t.Errorf("Expected the stored key, got %+v", key)
+		}
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if _, err := filter.LookupErr("revoked_at", "$exists"); err != nil {
+			t.Error("Expected revoked keys to be filtered out")
+		}
+	})
+
+	mt.Run("find by unknown hash", func(mt *mtest.T) {
+		repo := &apiKeyMongoRepo{collection: mt.Coll}
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+
+		if _, err := repo.FindByHash("missing"); err != appErrors.ErrInvalidAPIKey {
+			t.Errorf("Expected ErrInvalidAPIKey, got %v", err)
+		}
+	})
+
+	mt.Run("revoke malformed id", func(mt *mtest.T) {
+		repo := &apiKeyMongoRepo{collection: mt.Coll}
+		if err := repo.Revoke("not-an-id", time.Now()); err != appErrors.ErrAPIKeyNotFound {
+			t.Errorf("Expected ErrAPIKeyNotFound, got %v", err)
+		}
+	})
+
+	mt.Run("revoke already revoked key", func(mt *mtest.T) {
+		repo := &apiKeyMongoRepo{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		if err := repo.Revoke(primitive.NewObjectID().Hex(), time.Now()); err != appErrors.ErrAPIKeyNotFound {
+			t.Errorf("Expected ErrAPIKeyNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+	"github.com/buildyow/byow-user-service/domain/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type auditLogMongoRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogMongoRepo(db *mongo.Database) repository.AuditLogRepository {
+	return &auditLogMongoRepo{
+		collection: db.Collection("audit_logs_collections"),
+	}
+}
+
+func (r *auditLogMongoRepo) Create(log *entity.AuditLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, log)
+	return err
+}
+
+func (r *auditLogMongoRepo) DeleteOlderThan(retentionClass string, cutoff time.Time, limit int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// DeleteMany has no limit, so pick the oldest ids first to keep each
+	// batch bounded
+	filter := bson.M{
+		"retention_class": retentionClass,
+		"created_at":      bson.M{"$lt": cutoff},
+	}
+	findOptions := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.M{"created_at": 1}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc["_id"])
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *auditLogMongoRepo) FindByTarget(targetID string, action string, limit int64) ([]*entity.AuditLog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"target_id": targetID, "action": action}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []*entity.AuditLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"time"
 
-	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/domain/repository"
+	"github.com/buildyow/byow-user-service/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -23,69 +26,125 @@ func NewCompanyMongoRepo(db *mongo.Database) repository.CompanyRepository {
 	}
 }
 
-func (r *companyMongoRepo) FindAll(userID string, keyword string, limit int64, offset int64) ([]*entity.Company, int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// normalizeKeyword lowercases the search keyword, collapses whitespace and
+// drops repeated words so "  Tech  tech " searches like "tech"
+func normalizeKeyword(keyword string) string {
+	seen := map[string]bool{}
+	words := []string{}
+	for _, word := range strings.Fields(strings.ToLower(keyword)) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
 
+// buildListFilter translates a CompanyFilter into the Mongo query used by FindAll
+func buildListFilter(f repository.CompanyFilter) bson.M {
 	filter := bson.M{}
 
-	if keyword != "" {
-		// case-insensitive dan partial match
-		filter["company_name"] = bson.M{
-			"$regex":   keyword,
-			"$options": "i", // case-insensitive
+	if keyword := normalizeKeyword(f.Keyword); keyword != "" {
+		// Partial match on the folded name, so "jose" finds "José". Companies
+		// not written since name_normalized was added fall back to a
+		// case-insensitive match on the name itself.
+		filter["$or"] = bson.A{
+			bson.M{"name_normalized": bson.M{"$regex": regexp.QuoteMeta(utils.FoldText(keyword))}},
+			bson.M{
+				"name_normalized": bson.M{"$exists": false},
+				"company_name": bson.M{
+					"$regex":   regexp.QuoteMeta(keyword),
+					"$options": "i", // case-insensitive
+				},
+			},
 		}
 	}
 
-	if userID != "" {
-		filter["user_id"] = userID // exact match
+	if f.UserID != "" {
+		filter["user_id"] = f.UserID // exact match
 	}
-	findOptions := options.Find()
-	findOptions.SetLimit(limit)
-	findOptions.SetSkip(offset)
 
-	total, err := r.collection.CountDocuments(context.Background(), filter)
-	if err != nil {
-		return nil, 0, err
+	if f.Verified != nil {
+		filter["verified"] = *f.Verified
 	}
 
-	cursor, err := r.collection.Find(ctx, filter, findOptions)
-	if err != nil {
-		return nil, 0, err
+	if f.Public {
+		filter["verified"] = true
+		filter["public_listing"] = true
 	}
-	defer cursor.Close(ctx)
 
-	var companies []*entity.Company
-	for cursor.Next(ctx) {
-		var company entity.Company
-		if err := cursor.Decode(&company); err != nil {
-			return nil, 0, err
+	if f.Deleted {
+		filter["deleted_at"] = bson.M{"$ne": nil}
+	} else {
+		filter["deleted_at"] = nil // matches missing or null
+	}
+
+	return filter
+}
+
+func (r *companyMongoRepo) FindAll(f repository.CompanyFilter, limit int64, offset int64) ([]*entity.Company, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := buildListFilter(f)
+	findOptions := options.Find()
+	findOptions.SetLimit(limit)
+	findOptions.SetSkip(offset)
+
+	fetch := func() ([]*entity.Company, error) {
+		cursor, err := r.collection.Find(ctx, filter, findOptions)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		var companies []*entity.Company
+		for cursor.Next(ctx) {
+			var company entity.Company
+			if err := cursor.Decode(&company); err != nil {
+				return nil, err
+			}
+			companies = append(companies, &company)
 		}
-		companies = append(companies, &company)
+		return companies, cursor.Err()
 	}
+	count := func() (int64, error) {
+		return r.collection.CountDocuments(ctx, filter)
+	}
+	return pageWithTotal(fetch, count)
+}
 
-	if err := cursor.Err(); err != nil {
+// pageWithTotal fetches a page and then counts all matches. The page is what
+// callers need, so a failed count only loses the total, reported as
+// repository.UnknownTotal.
+func pageWithTotal(fetch func() ([]*entity.Company, error), count func() (int64, error)) ([]*entity.Company, int64, error) {
+	companies, err := fetch()
+	if err != nil {
 		return nil, 0, err
 	}
-
+	total, err := count()
+	if err != nil {
+		utils.LogWarn("Failed to count companies, returning page without total: %v", err)
+		return companies, repository.UnknownTotal, nil
+	}
 	return companies, total, nil
 }
 
 func (r *companyMongoRepo) Create(company *entity.Company) error {
 	// Build filter for duplicate check, only include non-empty fields
 	orConditions := []bson.M{}
-	
+
 	if company.CompanyEmail != "" {
 		orConditions = append(orConditions, bson.M{"company_email": company.CompanyEmail})
 	}
 	if company.CompanyPhone != "" {
 		orConditions = append(orConditions, bson.M{"company_phone": company.CompanyPhone})
 	}
-	
+
 	// Only check for duplicates if we have fields to check
 	if len(orConditions) > 0 {
 		filter := bson.M{"$or": orConditions}
-		
+
 		count, err := r.collection.CountDocuments(context.Background(), filter)
 		if err != nil {
 			return err
@@ -96,9 +155,11 @@ func (r *companyMongoRepo) Create(company *entity.Company) error {
 	}
 
 	company.CreatedAt = time.Now()
+	company.UpdatedAt = company.CreatedAt
+	company.NameNormalized = utils.FoldText(company.CompanyName)
 	result, err := r.collection.InsertOne(context.Background(), company)
 	if err != nil {
-		return err
+		return duplicateCompanyError(err)
 	}
 	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
 		company.ID = oid
@@ -106,11 +167,98 @@ func (r *companyMongoRepo) Create(company *entity.Company) error {
 	return nil
 }
 
+// duplicateCompanyError maps a unique index violation, from a concurrent
+// create slipping past the duplicate check, to the same conflict naming the
+// clashing field. Other errors are returned unchanged.
+func duplicateCompanyError(err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	field := "company_email"
+	if strings.Contains(err.Error(), "company_phone_unique") {
+		field = "company_phone"
+	}
+	conflict := *appErrors.ErrEmailOrPhoneAlreadyRegistered
+	conflict.Details = field + " already registered"
+	return &conflict
+}
+
 func (r *companyMongoRepo) FindByID(id primitive.ObjectID) (*entity.Company, error) {
+	return r.findOne(bson.M{"_id": id, "deleted_at": nil})
+}
+
+func (r *companyMongoRepo) FindDeletedByID(id primitive.ObjectID) (*entity.Company, error) {
+	return r.findOne(bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}})
+}
+
+func (r *companyMongoRepo) FindByNameForUser(userID string, name string) (*entity.Company, error) {
+	return r.findOne(bson.M{
+		"user_id": userID,
+		"company_name": bson.M{
+			"$regex":   "^" + regexp.QuoteMeta(name) + "$",
+			"$options": "i",
+		},
+		"deleted_at": nil,
+	})
+}
+
+func (r *companyMongoRepo) FindRecent(userID string, limit int64) ([]*entity.Company, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Companies created before updated_at was stamped on create may have
+	// none, fall back to created_at
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID, "deleted_at": nil}}},
+		{{Key: "$addFields", Value: bson.M{
+			"last_activity": bson.M{"$ifNull": bson.A{"$updated_at", "$created_at"}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "last_activity", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var companies []*entity.Company
+	if err := cursor.All(ctx, &companies); err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+func (r *companyMongoRepo) FindOwnedIDs(userID string, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": id}
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "user_id": userID, "deleted_at": nil},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	owned := make([]primitive.ObjectID, 0, len(docs))
+	for _, doc := range docs {
+		owned = append(owned, doc.ID)
+	}
+	return owned, nil
+}
+
+func (r *companyMongoRepo) findOne(filter bson.M) (*entity.Company, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
 	var company entity.Company
 	err := r.collection.FindOne(ctx, filter).Decode(&company)
@@ -124,33 +272,196 @@ func (r *companyMongoRepo) FindByID(id primitive.ObjectID) (*entity.Company, err
 	return &company, nil
 }
 
+// FindByEmail matches soft-deleted companies too, they still hold the email
+// in the unique index
 func (r *companyMongoRepo) FindByEmail(email string) (*entity.Company, error) {
-	var company entity.Company
-	err := r.collection.FindOne(context.Background(), bson.M{"email": email}).Decode(&company)
-	return &company, err
+	return r.findOne(bson.M{"company_email": email})
 }
 
 func (r *companyMongoRepo) FindByPhone(phone string) (*entity.Company, error) {
-	var company entity.Company
-	err := r.collection.FindOne(context.Background(), bson.M{"phone_number": phone}).Decode(&company)
-	return &company, err
+	return r.findOne(bson.M{"company_phone": phone})
 }
 
 func (r *companyMongoRepo) Update(company *entity.Company) error {
-	_, err := r.collection.UpdateOne(
+	company.UpdatedAt = time.Now()
+	company.NameNormalized = utils.FoldText(company.CompanyName)
+	updateData, err := bson.Marshal(company)
+	if err != nil {
+		return err
+	}
+
+	var updateMap bson.M
+	err = bson.Unmarshal(updateData, &updateMap)
+	if err != nil {
+		return err
+	}
+
+	delete(updateMap, "_id")
+
+	// Remove the email field instead of storing "" so the sparse unique
+	// index keeps ignoring companies without an email
+	update := bson.M{"$set": updateMap}
+	if company.CompanyEmail == "" {
+		update["$unset"] = bson.M{"company_email": ""}
+	}
+
+	_, err = r.collection.UpdateOne(
 		context.Background(),
-		bson.M{"id": company.ID},
-		bson.M{"$set": company},
+		bson.M{"_id": company.ID},
+		update,
 	)
 
 	return err
 }
 
+// Delete soft-deletes a company by stamping deleted_at, the document is kept
+// so it can be restored later
 func (r *companyMongoRepo) Delete(id primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": id}
-	_, err := r.collection.DeleteOne(ctx, filter)
-	return err
+	filter := bson.M{"_id": id, "deleted_at": nil}
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return appErrors.NewNotFoundError("Company")
+	}
+	return nil
+}
+
+// Restore clears deleted_at on a soft-deleted company
+func (r *companyMongoRepo) Restore(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{
+		"$unset": bson.M{"deleted_at": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return appErrors.NewNotFoundError("Company")
+	}
+	return nil
+}
+
+func (r *companyMongoRepo) ReassignOwner(fromUserID string, toUserID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": fromUserID},
+		// Moved companies lose their primary flag, the target owner keeps theirs
+		bson.M{"$set": bson.M{"user_id": toUserID, "is_primary": false, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *companyMongoRepo) SetVerifiedMany(ids []primitive.ObjectID, verified bool) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "verified": bson.M{"$ne": verified}, "deleted_at": nil},
+		bson.M{"$set": bson.M{"verified": verified, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// SetPrimary clears the flag on the user's other companies before setting it
+// on the target, so a failure in between leaves no primary rather than two
+func (r *companyMongoRepo) SetPrimary(userID string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "_id": bson.M{"$ne": id}, "is_primary": true},
+		bson.M{"$set": bson.M{"is_primary": false, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID, "deleted_at": nil},
+		bson.M{"$set": bson.M{"is_primary": true, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return appErrors.NewNotFoundError("Company")
+	}
+	return nil
+}
+
+// PurgeDeletedOlderThan removes each match on its own, re-checking deleted_at,
+// so a company restored meanwhile is neither removed nor reported
+func (r *companyMongoRepo) PurgeDeletedOlderThan(cutoff time.Time) ([]*entity.Company, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// $lt never matches a missing deleted_at, active companies are safe
+	filter := bson.M{"deleted_at": bson.M{"$lt": cutoff}}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*entity.Company
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+
+	purged := []*entity.Company{}
+	for _, company := range candidates {
+		result, err := r.collection.DeleteOne(ctx, bson.M{"_id": company.ID, "deleted_at": bson.M{"$lt": cutoff}})
+		if err != nil {
+			return purged, err
+		}
+		if result.DeletedCount == 1 {
+			purged = append(purged, company)
+		}
+	}
+	return purged, nil
+}
+
+// DeleteByOwner removes the companies it found, a company created after the
+// lookup is left for the next call
+func (r *companyMongoRepo) DeleteByOwner(userID string) ([]*entity.Company, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	owned := []*entity.Company{}
+	if err := cursor.All(ctx, &owned); err != nil {
+		return nil, err
+	}
+	if len(owned) == 0 {
+		return owned, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(owned))
+	for _, company := range owned {
+		ids = append(ids, company.ID)
+	}
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return nil, err
+	}
+	return owned, nil
 }
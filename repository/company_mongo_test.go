@@ -1,12 +1,21 @@
 package repository
 
 import (
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
+	"github.com/buildyow/byow-user-service/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 )
 
 func TestCompanyMongoRepoStructure(t *testing.T) {
@@ -35,7 +44,7 @@ func TestFindAllFilterConstruction(t *testing.T) {
 	}{
 		{"empty filters", "", "", 0},
 		{"user ID only", "user123", "", 1},
-		{"keyword only", "", "test", 1}, 
+		{"keyword only", "", "test", 1},
 		{"both filters", "user123", "test", 2},
 	}
 
@@ -85,10 +94,10 @@ func TestFindAllFilterConstruction(t *testing.T) {
 func TestCreateDuplicateCheckLogic(t *testing.T) {
 	// Test duplicate check logic used in Create method
 	testCases := []struct {
-		name           string
-		company        *entity.Company
-		expectedOrLen  int
-		description    string
+		name          string
+		company       *entity.Company
+		expectedOrLen int
+		description   string
 	}{
 		{
 			"no email or phone",
@@ -103,7 +112,7 @@ func TestCreateDuplicateCheckLogic(t *testing.T) {
 			"should create one OR condition",
 		},
 		{
-			"phone only", 
+			"phone only",
 			&entity.Company{CompanyPhone: "+1234567890"},
 			1,
 			"should create one OR condition",
@@ -126,7 +135,7 @@ func TestCreateDuplicateCheckLogic(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Simulate the duplicate check logic
 			orConditions := []bson.M{}
-			
+
 			if tc.company.CompanyEmail != "" {
 				orConditions = append(orConditions, bson.M{"company_email": tc.company.CompanyEmail})
 			}
@@ -222,7 +231,7 @@ func TestFindByIDFilter(t *testing.T) {
 	// Test with zero ObjectID
 	zeroID := primitive.ObjectID{}
 	zeroFilter := bson.M{"_id": zeroID}
-	
+
 	if zeroFilter["_id"] != zeroID {
 		t.Error("Expected zero ObjectID in filter")
 	}
@@ -231,20 +240,20 @@ func TestFindByIDFilter(t *testing.T) {
 func TestFindByEmailFilter(t *testing.T) {
 	// Test email filter construction
 	email := "test@company.com"
-	filter := bson.M{"email": email}
+	filter := bson.M{"company_email": email}
 
-	if filter["email"] != email {
-		t.Errorf("Expected email filter %v, got %v", email, filter["email"])
+	if filter["company_email"] != email {
+		t.Errorf("Expected email filter %v, got %v", email, filter["company_email"])
 	}
 }
 
 func TestFindByPhoneFilter(t *testing.T) {
-	// Test phone filter construction  
+	// Test phone filter construction
 	phone := "+1234567890"
-	filter := bson.M{"phone_number": phone}
+	filter := bson.M{"company_phone": phone}
 
-	if filter["phone_number"] != phone {
-		t.Errorf("Expected phone filter %v, got %v", phone, filter["phone_number"])
+	if filter["company_phone"] != phone {
+		t.Errorf("Expected phone filter %v, got %v", phone, filter["company_phone"])
 	}
 }
 
@@ -360,7 +369,7 @@ func TestCompanyBSONMarshaling(t *testing.T) {
 	// Test BSON marshaling for company entities
 	company := &entity.Company{
 		ID:           primitive.NewObjectID(),
-		UserID:       "user123", 
+		UserID:       "user123",
 		CompanyName:  "Test Company",
 		CompanyEmail: "test@company.com",
 		CompanyPhone: "+1234567890",
@@ -398,6 +407,111 @@ func TestCompanyBSONMarshaling(t *testing.T) {
 	}
 }
 
+func TestCompanyBSONMarshaling_OmitsEmptyEmail(t *testing.T) {
+	// Companies without an email must not store company_email at all,
+	// otherwise "" values collide on the sparse unique index
+	company := &entity.Company{
+		UserID:      "user123",
+		CompanyName: "Logo Only Company",
+		CompanyLogo: "https://example.com/logo.png",
+	}
+
+	data, err := bson.Marshal(company)
+	if err != nil {
+		t.Fatalf("Failed to marshal company: %v", err)
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to unmarshal company document: %v", err)
+	}
+
+	if _, exists := doc["company_email"]; exists {
+		t.Error("Expected company_email to be omitted when empty")
+	}
+
+	company.CompanyEmail = "info@company.com"
+	data, err = bson.Marshal(company)
+	if err != nil {
+		t.Fatalf("Failed to marshal company: %v", err)
+	}
+
+	doc = bson.M{}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to unmarshal company document: %v", err)
+	}
+
+	if doc["company_email"] != "info@company.com" {
+		t.Errorf("Expected company_email 'info@company.com', got %v", doc["company_email"])
+	}
+}
+
+func TestCompanyBSONMarshaling_OmitsEmptyPhone(t *testing.T) {
+	// Same as email, "" phones would collide on the sparse unique index
+	company := &entity.Company{
+		UserID:       "user123",
+		CompanyName:  "Email Only Company",
+		CompanyEmail: "info@company.com",
+	}
+
+	data, err := bson.Marshal(company)
+	if err != nil {
+		t.Fatalf("Failed to marshal company: %v", err)
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to unmarshal company document: %v", err)
+	}
+
+	if _, exists := doc["company_phone"]; exists {
+		t.Error("Expected company_phone to be omitted when empty")
+	}
+}
+
+func TestDuplicateCompanyError(t *testing.T) {
+	duplicate := func(index string) error {
+		return mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: byow.companies_collections index: " + index + " dup key",
+		}}}
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		details string
+	}{
+		{"phone", duplicate("company_phone_unique"), "company_phone already registered"},
+		{"email", duplicate("company_email_unique"), "company_email already registered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr, ok := appErrors.IsAppError(duplicateCompanyError(tt.err))
+			if !ok {
+				t.Fatalf("Expected an AppError, got %v", tt.err)
+			}
+			if appErr.Code != appErrors.ErrEmailOrPhoneAlreadyRegistered.Code || appErr.Status != http.StatusConflict {
+				t.Errorf("Expected EMAIL_OR_PHONE_ALREADY_REGISTERED conflict, got %s (%d)", appErr.Code, appErr.Status)
+			}
+			if appErr.Details != tt.details {
+				t.Errorf("Expected details %q, got %q", tt.details, appErr.Details)
+			}
+		})
+	}
+
+	// The shared error must not pick up details
+	if appErrors.ErrEmailOrPhoneAlreadyRegistered.Details != "" {
+		t.Error("Expected ErrEmailOrPhoneAlreadyRegistered to be left untouched")
+	}
+
+	other := errors.New("connection reset")
+	if err := duplicateCompanyError(other); err != other {
+		t.Errorf("Expected non-duplicate errors unchanged, got %v", err)
+	}
+}
+
 func TestRegexFilterConstruction(t *testing.T) {
 	// Test regex filter construction for case-insensitive search
 	testCases := []struct {
@@ -459,4 +573,249 @@ func BenchmarkObjectIDCreation(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = primitive.NewObjectID()
 	}
-}
\ No newline at end of file
+}
+func TestBuildListFilter_SoftDelete(t *testing.T) {
+	active := buildListFilter(repository.CompanyFilter{UserID: "user-1"})
+	if v, ok := active["deleted_at"]; !ok || v != nil {
+		t.Errorf("Expected active filter to match missing deleted_at, got %v", active["deleted_at"])
+	}
+	if active["user_id"] != "user-1" {
+		t.Errorf("Expected user_id filter, got %v", active["user_id"])
+	}
+	if _, ok := active["$or"]; ok {
+		t.Error("Expected no keyword filter without keyword")
+	}
+
+	deleted := buildListFilter(repository.CompanyFilter{Keyword: "tech", Deleted: true})
+	if cond, ok := deleted["deleted_at"].(bson.M); !ok || cond["$ne"] != nil {
+		t.Errorf("Expected deleted filter to require deleted_at, got %v", deleted["deleted_at"])
+	}
+	if folded, _ := keywordConditions(t, deleted); folded["$regex"] != "tech" {
+		t.Errorf("Expected keyword regex, got %v", folded)
+	}
+}
+
+func TestBuildListFilter_Public(t *testing.T) {
+	filter := buildListFilter(repository.CompanyFilter{Public: true})
+	if filter["verified"] != true || filter["public_listing"] != true {
+		t.Errorf("Expected public filter to require verified and public_listing, got %v", filter)
+	}
+	if _, ok := filter["user_id"]; ok {
+		t.Error("Expected no user_id filter for public listing")
+	}
+}
+
+// keywordConditions returns the regex on name_normalized and the fallback
+// regex on company_name from a keyword filter
+func keywordConditions(t *testing.T, filter bson.M) (bson.M, bson.M) {
+	t.Helper()
+	or, ok := filter["$or"].(bson.A)
+	if !ok || len(or) != 2 {
+		t.Fatalf("Expected a two-way keyword $or, got %v", filter["$or"])
+	}
+	folded, ok := or[0].(bson.M)["name_normalized"].(bson.M)
+	if !ok {
+		t.Fatalf("Expected a name_normalized regex, got %v", or[0])
+	}
+	legacy, ok := or[1].(bson.M)["company_name"].(bson.M)
+	if !ok {
+		t.Fatalf("Expected a company_name fallback regex, got %v", or[1])
+	}
+	return folded, legacy
+}
+
+// searchNames applies a keyword filter to names the way Mongo would to
+// documents carrying name_normalized
+func searchNames(t *testing.T, keyword string, names []string) []string {
+	t.Helper()
+	folded, legacy := keywordConditions(t, buildListFilter(repository.CompanyFilter{Keyword: keyword}))
+	if legacy["$options"] != "i" {
+		t.Errorf("Expected case-insensitive fallback for %q", keyword)
+	}
+	re := regexp.MustCompile(folded["$regex"].(string))
+	matches := []string{}
+	for _, name := range names {
+		if re.MatchString(utils.FoldText(name)) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+func TestBuildListFilter_NormalizesKeyword(t *testing.T) {
+	names := []string{"Tech Corp", "tech labs", "Biotech", "Food Co", "TECH tech"}
+	search := func(keyword string) []string {
+		return searchNames(t, keyword, names)
+	}
+
+	expected := search("tech")
+	if len(expected) != 4 {
+		t.Fatalf("Expected 4 matches for tech, got %v", expected)
+	}
+	for _, keyword := range []string{"  Tech  ", "TECH", "Tech tech", "\ttech\n"} {
+		if got := search(keyword); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %q to match %v, got %v", keyword, expected, got)
+		}
+	}
+
+	if got := search("food   CO"); !reflect.DeepEqual(got, []string{"Food Co"}) {
+		t.Errorf("Expected collapsed whitespace to match Food Co, got %v", got)
+	}
+}
+
+func TestBuildListFilter_KeywordEdgeCases(t *testing.T) {
+	filter := buildListFilter(repository.CompanyFilter{Keyword: "   "})
+	if _, ok := filter["$or"]; ok {
+		t.Error("Expected no keyword filter for a blank keyword")
+	}
+
+	filter = buildListFilter(repository.CompanyFilter{Keyword: "a.b (c)"})
+	folded, legacy := keywordConditions(t, filter)
+	if folded["$regex"] != `a\.b \(c\)` || legacy["$regex"] != `a\.b \(c\)` {
+		t.Errorf("Expected regex metacharacters to be escaped, got %v and %v", folded["$regex"], legacy["$regex"])
+	}
+}
+
+func TestBuildListFilter_FoldsDiacritics(t *testing.T) {
+	names := []string{"José Imports", "Jose Cuervo", "München Bau", "Munich Labs", "Food Co"}
+
+	if got := searchNames(t, "jose", names); !reflect.DeepEqual(got, []string{"José Imports", "Jose Cuervo"}) {
+		t.Errorf("Expected jose to match both spellings, got %v", got)
+	}
+	if got := searchNames(t, "munchen", names); !reflect.DeepEqual(got, []string{"München Bau"}) {
+		t.Errorf("Expected munchen to match München, got %v", got)
+	}
+	if got := searchNames(t, "MÜNCHEN", names); !reflect.DeepEqual(got, []string{"München Bau"}) {
+		t.Errorf("Expected an accented keyword to be folded too, got %v", got)
+	}
+
+	// The fallback for companies without name_normalized keeps the raw keyword
+	_, legacy := keywordConditions(t, buildListFilter(repository.CompanyFilter{Keyword: "José"}))
+	if legacy["$regex"] != "josé" {
+		t.Errorf("Expected the fallback to use the unfolded keyword, got %v", legacy["$regex"])
+	}
+}
+
+func TestPageWithTotal_CountFailureKeepsPage(t *testing.T) {
+	page := []*entity.Company{{CompanyName: "First"}, {CompanyName: "Second"}}
+	companies, total, err := pageWithTotal(
+		func() ([]*entity.Company, error) { return page, nil },
+		func() (int64, error) { return 0, errors.New("count timed out") },
+	)
+
+	if err != nil {
+		t.Fatalf("Expected no error when only the count fails, got %v", err)
+	}
+	if len(companies) != 2 {
+		t.Errorf("Expected the fetched page, got %d companies", len(companies))
+	}
+	if total != repository.UnknownTotal {
+		t.Errorf("Expected total %d, got %d", repository.UnknownTotal, total)
+	}
+}
+
+func TestPageWithTotal_FindFailure(t *testing.T) {
+	counted := false
+	companies, _, err := pageWithTotal(
+		func() ([]*entity.Company, error) { return nil, errors.New("find failed") },
+		func() (int64, error) { counted = true; return 5, nil },
+	)
+
+	if err == nil {
+		t.Fatal("Expected the find error to be returned")
+	}
+	if companies != nil {
+		t.Errorf("Expected no companies, got %v", companies)
+	}
+	if counted {
+		t.Error("Expected the count to be skipped after a failed find")
+	}
+}
+
+func TestPageWithTotal_Success(t *testing.T) {
+	companies, total, err := pageWithTotal(
+		func() ([]*entity.Company, error) { return []*entity.Company{{CompanyName: "Only"}}, nil },
+		func() (int64, error) { return 12, nil },
+	)
+
+	if err != nil || len(companies) != 1 || total != 12 {
+		t.Errorf("Expected 1 company with total 12, got %d, %d, %v", len(companies), total, err)
+	}
+}
+
+// updatedAtSet returns the updated_at an update command set, if any
+func updatedAtSet(mt *mtest.T) (time.Time, bool) {
+	event := mt.GetStartedEvent()
+	if event == nil || event.CommandName != "update" {
+		return time.Time{}, false
+	}
+	update := event.Command.Lookup("updates", "0", "u")
+	value, err := update.Document().LookupErr("$set", "updated_at")
+	if err != nil {
+		return time.Time{}, false
+	}
+	return value.Time(), true
+}
+
+func TestCompanyRepo_StampsUpdatedAt(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	matched := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+
+	mt.Run("create", func(mt *mtest.T) {
+		repo := &companyMongoRepo{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		company := &entity.Company{CompanyName: "Acme"}
+		if err := repo.Create(company); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if company.UpdatedAt.IsZero() || !company.UpdatedAt.Equal(company.CreatedAt) {
+			t.Errorf("Expected updated_at to start at created_at, got %v and %v", company.UpdatedAt, company.CreatedAt)
+		}
+		inserted := mt.GetStartedEvent().Command.Lookup("documents", "0")
+		if _, err := inserted.Document().LookupErr("updated_at"); err != nil {
+			t.Error("Expected updated_at to be stored on create")
+		}
+	})
+
+	mt.Run("update", func(mt *mtest.T) {
+		repo := &companyMongoRepo{collection: mt.Coll}
+		mt.AddMockResponses(matched)
+
+		previous := time.Now().Add(-time.Hour)
+		company := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Acme", CreatedAt: previous, UpdatedAt: previous}
+		if err := repo.Update(company); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !company.UpdatedAt.After(previous) {
+			t.Errorf("Expected updated_at to advance past %v, got %v", previous, company.UpdatedAt)
+		}
+		if stored, ok := updatedAtSet(mt); !ok || !stored.After(previous) {
+			t.Errorf("Expected the update to set a later updated_at, got %v", stored)
+		}
+	})
+
+	mutations := map[string]func(*companyMongoRepo) error{
+		"delete":  func(r *companyMongoRepo) error { return r.Delete(primitive.NewObjectID()) },
+		"restore": func(r *companyMongoRepo) error { return r.Restore(primitive.NewObjectID()) },
+		"set verified": func(r *companyMongoRepo) error {
+			_, err := r.SetVerifiedMany([]primitive.ObjectID{primitive.NewObjectID()}, true)
+			return err
+		},
+		"reassign owner": func(r *companyMongoRepo) error { _, err := r.ReassignOwner("user-1", "user-2"); return err },
+	}
+	for name, mutate := range mutations {
+		mt.Run(name, func(mt *mtest.T) {
+			repo := &companyMongoRepo{collection: mt.Coll}
+			mt.AddMockResponses(matched)
+
+			before := time.Now().Add(-time.Second)
+			if err := mutate(repo); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored, ok := updatedAtSet(mt); !ok || stored.Before(before) {
+				t.Errorf("Expected %s to stamp updated_at, got %v", name, stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxSessionsListed bounds FindActiveByUser, far more than a user has devices
+const maxSessionsListed = 100
+
+type sessionMongoRepo struct {
+	collection *mongo.Collection
+}
+
+func NewSessionMongoRepo(db *mongo.Database) repository.SessionRepository {
+	return &sessionMongoRepo{
+		collection: db.Collection("sessions_collections"),
+	}
+}
+
+func (r *sessionMongoRepo) Create(session *entity.Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, session)
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		session.ID = id
+	}
+	return nil
+}
+
+func (r *sessionMongoRepo) FindActiveByUser(userID string, now time.Time) ([]*entity.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "issued_at", Value: -1}}).
+		SetLimit(maxSessionsListed)
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*entity.Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *sessionMongoRepo) FindByID(id string) (*entity.Session, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, appErrors.ErrSessionNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var session entity.Session
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, appErrors.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionMongoRepo) RevokeByJTI(jti string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Keep the first revocation time when a token is revoked twice
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"jti": jti, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": at}},
+	)
+	return err
+}
+
+func (r *sessionMongoRepo) RevokeAllByUser(userID string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": at}},
+	)
+	return err
+}
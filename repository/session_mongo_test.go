@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestSessionRepo(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("find active skips revoked and expired", func(mt *mtest.T) {
+		repo := &sessionMongoRepo{collection: mt.Coll}
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "user_id", Value: "user-1"},
+				{Key: "jti", Value: "jti-1"},
+			}),
+		)
+
+		now := time.Now()
+		sessions, err := repo.FindActiveByUser("user-1", now)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(sessions) != 1 || sessions[0].JTI != "jti-1" {
+			t.Fatalf("Expected the stored session, got %+v", sessions)
+		}
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if _, err := filter.LookupErr("revoked_at", "$exists"); err != nil {
+			t.Error("Expected revoked sessions to be filtered out")
+		}
+		if _, err := filter.LookupErr("expires_at", "$gt"); err != nil {
+			t.Error("Expected expired sessions to be filtered out")
+		}
+	})
+
+	mt.Run("find by malformed id", func(mt *mtest.T) {
+		repo := &sessionMongoRepo{collection: mt.Coll}
+		if _, err := repo.FindByID("not-an-id"); err != appErrors.ErrSessionNotFound {
+			t.Errorf("Expected ErrSessionNotFound, got %v", err)
+		}
+	})
+
+	mt.Run("find by unknown id", func(mt *mtest.T) {
+		repo := &sessionMongoRepo{collection: mt.Coll}
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+
+		if _, err := repo.FindByID(primitive.NewObjectID().Hex()); err != appErrors.ErrSessionNotFound {
+			t.Errorf("Expected ErrSessionNotFound, got %v", err)
+		}
+	})
+
+	mt.Run("revoke keeps the first revocation", func(mt *mtest.T) {
+		repo := &sessionMongoRepo{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		if err := repo.RevokeByJTI("jti-1", time.Now()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		query := mt.GetStartedEvent().Command.Lookup("updates", "0", "q").Document()
+		if _, err := query.LookupErr("revoked_at", "$exists"); err != nil {
+			t.Error("Expected an already revoked session to be left alone")
+		}
+	})
+}
@@ -2,13 +2,18 @@ package repository
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"time"
 
-	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/domain/repository"
+	"github.com/buildyow/byow-user-service/utils"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type userMongoRepo struct {
@@ -23,6 +28,7 @@ func NewUserMongoRepo(db *mongo.Database) repository.UserRepository {
 
 func (r *userMongoRepo) Create(user *entity.User) error {
 	user.CreatedAt = time.Now()
+	user.NameNormalized = utils.FoldText(user.Fullname)
 	_, err := r.collection.InsertOne(context.Background(), user)
 	return err
 }
@@ -39,6 +45,48 @@ func (r *userMongoRepo) FindByEmail(email string) (*entity.User, error) {
 	return &user, nil
 }
 
+func (r *userMongoRepo) FindExistingEmails(emails []string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"email": bson.M{"$in": emails}},
+		options.Find().SetProjection(bson.M{"_id": 0, "email": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		Email string `bson:"email"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		existing[doc.Email] = true
+	}
+	return existing, nil
+}
+
+func (r *userMongoRepo) FindByID(id string) (*entity.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	var user entity.User
+	err = r.collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, appErrors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userMongoRepo) FindByPhone(phone string) (*entity.User, error) {
 	var user entity.User
 	err := r.collection.FindOne(context.Background(), bson.M{"phone_number": phone}).Decode(&user)
@@ -51,27 +99,108 @@ func (r *userMongoRepo) FindByPhone(phone string) (*entity.User, error) {
 	return &user, nil
 }
 
-func (r *userMongoRepo) Update(user *entity.User) error {
-	updateData, err := bson.Marshal(user)
-	if err != nil {
-		return err
+// buildUserFilter matches the keyword anywhere in the email, phone number
+// or folded name, so "jose" finds "José"
+func buildUserFilter(f repository.UserFilter) bson.M {
+	filter := bson.M{}
+	if keyword := strings.TrimSpace(f.Keyword); keyword != "" {
+		filter["$or"] = bson.A{
+			bson.M{"email": bson.M{"$regex": regexp.QuoteMeta(keyword), "$options": "i"}},
+			bson.M{"phone_number": bson.M{"$regex": regexp.QuoteMeta(keyword)}},
+			bson.M{"name_normalized": bson.M{"$regex": regexp.QuoteMeta(utils.FoldText(keyword))}},
+		}
+	}
+	if f.Suspended != nil {
+		filter["suspended_at"] = bson.M{"$exists": *f.Suspended}
 	}
+	return filter
+}
 
-	var updateMap bson.M
-	err = bson.Unmarshal(updateData, &updateMap)
+func (r *userMongoRepo) FindAll(f repository.UserFilter, limit int64, offset int64) ([]*entity.User, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := buildUserFilter(f)
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(limit).
+		SetSkip(offset).
+		SetProjection(bson.M{"password": 0, "otp": 0, "password_reset_token_hash": 0, "pending_email_otp": 0})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return err
+		return nil, 0, err
+	}
+	users := []*entity.User{}
+	err = forEachUser(ctx, cursor, func(user *entity.User) error {
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
-	delete(updateMap, "_id")
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		utils.LogWarn("Failed to count users, returning page without total: %v", err)
+		return users, repository.UnknownTotal, nil
+	}
+	return users, total, nil
+}
 
+// clearedFields lists the omitempty fields of user that were cleared, $set
+// skips them so they have to be unset explicitly
+func clearedFields(user *entity.User) bson.M {
 	unsetMap := bson.M{}
 	if user.OTP == "" {
 		unsetMap["otp"] = ""
 		unsetMap["otp_expires_at"] = ""
 		unsetMap["otp_type"] = ""
+		unsetMap["otp_phone"] = ""
+	}
+	// otp_attempts is omitempty, so a reset to zero has to be unset explicitly
+	if user.OTPAttempts == 0 {
+		unsetMap["otp_attempts"] = ""
+	}
+	if user.PasswordResetTokenHash == "" {
+		unsetMap["password_reset_token_hash"] = ""
+		unsetMap["password_reset_expires_at"] = ""
+	}
+	if user.PendingEmail == "" {
+		unsetMap["pending_email"] = ""
+		unsetMap["pending_email_otp"] = ""
+		unsetMap["pending_email_expires_at"] = ""
+	}
+	return unsetMap
+}
+
+// targetedFields are written only by their own update methods, a user read
+// before one of those ran can't put the old value back with Update
+var targetedFields = []string{"role", "token_version", "suspended_at", "suspended_reason", "deletion_scheduled_at"}
+
+// userUpdate sets every field of user and unsets the cleared ones, leaving
+// the targeted fields alone
+func userUpdate(user *entity.User) (bson.M, error) {
+	user.NameNormalized = utils.FoldText(user.Fullname)
+	updateData, err := bson.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var updateMap bson.M
+	err = bson.Unmarshal(updateData, &updateMap)
+	if err != nil {
+		return nil, err
 	}
 
+	delete(updateMap, "_id")
+
+	for _, field := range targetedFields {
+		delete(updateMap, field)
+	}
+
+	unsetMap := clearedFields(user)
+
 	update := bson.M{}
 	if len(updateMap) > 0 {
 		update["$set"] = updateMap
@@ -79,6 +208,14 @@ func (r *userMongoRepo) Update(user *entity.User) error {
 	if len(unsetMap) > 0 {
 		update["$unset"] = unsetMap
 	}
+	return update, nil
+}
+
+func (r *userMongoRepo) Update(user *entity.User) error {
+	update, err := userUpdate(user)
+	if err != nil {
+		return err
+	}
 	_, err = r.collection.UpdateOne(
 		context.Background(),
 		bson.M{"email": user.Email},
@@ -88,33 +225,175 @@ func (r *userMongoRepo) Update(user *entity.User) error {
 	return err
 }
 
-func (r *userMongoRepo) UpdateEmail(user *entity.User, oldEmail string) error {
-	updateData, err := bson.Marshal(user)
+// UpdateOTP writes only the OTP fields of user, so a user loaded without
+// its password or reset token can't wipe them
+func (r *userMongoRepo) UpdateOTP(user *entity.User) error {
+	objectID, err := primitive.ObjectIDFromHex(user.ID)
 	if err != nil {
-		return err
+		return appErrors.ErrUserNotFound
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	var updateMap bson.M
-	err = bson.Unmarshal(updateData, &updateMap)
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, otpUpdate(user))
+	return err
+}
+
+// otpUpdate sets the OTP fields of user, or unsets them all once the OTP
+// was cleared
+func otpUpdate(user *entity.User) bson.M {
+	if user.OTP == "" {
+		return bson.M{"$unset": bson.M{"otp": "", "otp_type": "", "otp_expires_at": "", "otp_attempts": "", "otp_phone": ""}}
+	}
+	return bson.M{"$set": bson.M{
+		"otp":            user.OTP,
+		"otp_type":       user.OTPType,
+		"otp_expires_at": user.OTPExpiresAt,
+		"otp_attempts":   user.OTPAttempts,
+		"otp_phone":      user.OTPPhone,
+	}}
+}
+
+// UpdateRole stores role, "user" included so a demotion is never mistaken
+// for a field left unset, and bumps the token version in the same update
+func (r *userMongoRepo) UpdateRole(userID, role string) (int, error) {
+	return r.bumpTokenVersion(userID, roleUpdate(role))
+}
+
+func roleUpdate(role string) bson.M {
+	return bson.M{"$set": bson.M{"role": role}}
+}
+
+// RevokeTokens bumps the token version, refusing every token issued so far
+func (r *userMongoRepo) RevokeTokens(userID string) (int, error) {
+	return r.bumpTokenVersion(userID, bson.M{})
+}
+
+// Suspend marks the user suspended at and bumps the token version in the
+// same update
+func (r *userMongoRepo) Suspend(userID string, at time.Time, reason string) (int, error) {
+	return r.bumpTokenVersion(userID, suspendUpdate(at, reason))
+}
+
+func suspendUpdate(at time.Time, reason string) bson.M {
+	if reason == "" {
+		return bson.M{"$set": bson.M{"suspended_at": at}, "$unset": bson.M{"suspended_reason": ""}}
+	}
+	return bson.M{"$set": bson.M{"suspended_at": at, "suspended_reason": reason}}
+}
+
+// Reactivate lifts the user's suspension
+func (r *userMongoRepo) Reactivate(userID string) error {
+	return r.updateByID(userID, bson.M{"$unset": bson.M{"suspended_at": "", "suspended_reason": ""}})
+}
+
+// ScheduleDeletion stores when the user is due to be deleted and bumps the
+// token version in the same update
+func (r *userMongoRepo) ScheduleDeletion(userID string, at time.Time) (int, error) {
+	return r.bumpTokenVersion(userID, bson.M{"$set": bson.M{"deletion_scheduled_at": at}})
+}
+
+// CancelDeletion clears the user's scheduled deletion
+func (r *userMongoRepo) CancelDeletion(userID string) error {
+	return r.updateByID(userID, bson.M{"$unset": bson.M{"deletion_scheduled_at": ""}})
+}
+
+// updateByID applies update to the user with the id
+func (r *userMongoRepo) updateByID(userID string, update bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return appErrors.ErrUserNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return appErrors.ErrUserNotFound
+	}
+	return nil
+}
 
-	delete(updateMap, "_id")
+// bumpTokenVersion applies update to the user along with a token version
+// increment and returns the new version
+func (r *userMongoRepo) bumpTokenVersion(userID string, update bson.M) (int, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, appErrors.ErrUserNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	unsetMap := bson.M{}
-	if user.OTP == "" {
-		unsetMap["otp"] = ""
-		unsetMap["otp_expires_at"] = ""
-		unsetMap["otp_type"] = ""
+	update["$inc"] = bson.M{"token_version": 1}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"token_version": 1})
+	var updated entity.User
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return 0, appErrors.ErrUserNotFound
+	}
+	if err != nil {
+		return 0, err
 	}
+	return updated.TokenVersion, nil
+}
 
-	update := bson.M{}
-	if len(updateMap) > 0 {
-		update["$set"] = updateMap
+// AddPasskeyCeremony pushes ceremony onto the user's pending ones, the
+// oldest beyond keep are dropped in the same update
+func (r *userMongoRepo) AddPasskeyCeremony(userID string, ceremony entity.PasskeyCeremony, keep int) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return appErrors.ErrUserNotFound
 	}
-	if len(unsetMap) > 0 {
-		update["$unset"] = unsetMap
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{
+		"$push": bson.M{"passkey_ceremonies": bson.M{"$each": []entity.PasskeyCeremony{ceremony}, "$slice": -keep}},
+	})
+	return err
+}
+
+// TakePasskeyCeremony pulls the ceremony out of the user and returns it as
+// it was before the pull, so two concurrent answers can't both get it
+func (r *userMongoRepo) TakePasskeyCeremony(userID, ceremonyID string) (*entity.PasskeyCeremony, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var before struct {
+		Ceremonies []entity.PasskeyCeremony `bson:"passkey_ceremonies"`
+	}
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "passkey_ceremonies.id": ceremonyID},
+		bson.M{"$pull": bson.M{"passkey_ceremonies": bson.M{"id": ceremonyID}}},
+		options.FindOneAndUpdate().SetProjection(bson.M{"passkey_ceremonies": 1}).SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, ceremony := range before.Ceremonies {
+		if ceremony.ID == ceremonyID {
+			return &ceremony, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userMongoRepo) UpdateEmail(user *entity.User, oldEmail string) error {
+	update, err := userUpdate(user)
+	if err != nil {
+		return err
 	}
 	_, err = r.collection.UpdateOne(
 		context.Background(),
@@ -126,38 +405,94 @@ func (r *userMongoRepo) UpdateEmail(user *entity.User, oldEmail string) error {
 }
 
 func (r *userMongoRepo) UpdatePhone(user *entity.User, oldPhone string) error {
-	updateData, err := bson.Marshal(user)
+	update, err := userUpdate(user)
 	if err != nil {
 		return err
 	}
+	_, err = r.collection.UpdateOne(
+		context.Background(),
+		bson.M{"phone_number": oldPhone},
+		update,
+	)
 
-	var updateMap bson.M
-	err = bson.Unmarshal(updateData, &updateMap)
+	return err
+}
+
+func (r *userMongoRepo) Delete(email string) error {
+	result, err := r.collection.DeleteOne(context.Background(), bson.M{"email": email})
 	if err != nil {
 		return err
 	}
+	if result.DeletedCount == 0 {
+		return appErrors.ErrUserNotFound
+	}
+	return nil
+}
 
-	delete(updateMap, "_id")
+func (r *userMongoRepo) FindDeletionDue(before time.Time, limit int64) ([]*entity.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	unsetMap := bson.M{}
-	if user.OTP == "" {
-		unsetMap["otp"] = ""
-		unsetMap["otp_expires_at"] = ""
-		unsetMap["otp_type"] = ""
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "deletion_scheduled_at", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"password": 0, "otp": 0, "password_reset_token_hash": 0, "pending_email_otp": 0})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"deletion_scheduled_at": bson.M{"$lte": before}}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	users := []*entity.User{}
+	err = forEachUser(ctx, cursor, func(user *entity.User) error {
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return users, nil
+}
 
-	update := bson.M{}
-	if len(updateMap) > 0 {
-		update["$set"] = updateMap
+func (r *userMongoRepo) ForEach(ctx context.Context, fn func(user *entity.User) error) error {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"password": 0, "otp": 0, "password_reset_token_hash": 0, "pending_email_otp": 0})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return err
 	}
-	if len(unsetMap) > 0 {
-		update["$unset"] = unsetMap
+	return forEachUser(ctx, cursor, fn)
+}
+
+func (r *userMongoRepo) ForEachActiveOTP(ctx context.Context, fn func(user *entity.User) error) error {
+	filter := bson.M{
+		"otp":            bson.M{"$exists": true, "$ne": ""},
+		"otp_expires_at": bson.M{"$gt": time.Now()},
 	}
-	_, err = r.collection.UpdateOne(
-		context.Background(),
-		bson.M{"phone_number": oldPhone},
-		update,
-	)
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"password": 0, "password_reset_token_hash": 0})
 
-	return err
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return err
+	}
+	return forEachUser(ctx, cursor, fn)
+}
+
+// forEachUser decodes each user of cursor and hands it to fn, closing the cursor
+func forEachUser(ctx context.Context, cursor *mongo.Cursor, fn func(user *entity.User) error) error {
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user entity.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
 }
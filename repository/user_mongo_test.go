@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/buildyow/byow-user-service/domain/entity"
+	"github.com/buildyow/byow-user-service/domain/repository"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -141,6 +142,170 @@ func TestUpdateMapConstruction(t *testing.T) {
 	}
 }
 
+func TestClearedFields(t *testing.T) {
+	cleared := clearedFields(&entity.User{Email: "test@example.com"})
+	for _, field := range []string{"otp", "otp_attempts", "password_reset_token_hash", "pending_email", "pending_email_otp", "pending_email_expires_at"} {
+		if _, ok := cleared[field]; !ok {
+			t.Errorf("Expected %s to be unset on a user without it", field)
+		}
+	}
+
+	pending := clearedFields(&entity.User{
+		Email:           "test@example.com",
+		OTP:             "encrypted",
+		OTPAttempts:     1,
+		PendingEmail:    "new@example.com",
+		PendingEmailOTP: "encrypted",
+	})
+	if len(pending) != 2 {
+		t.Errorf("Expected only the reset token fields to be unset, got %v", pending)
+	}
+}
+
+func TestOTPUpdate(t *testing.T) {
+	expiresAt := time.Now().Add(5 * time.Minute)
+	update := otpUpdate(&entity.User{Email: "test@example.com", Password: "hashed", OTP: "encrypted", OTPType: "VERIFICATION", OTPExpiresAt: expiresAt, OTPAttempts: 2})
+	set, ok := update["$set"].(bson.M)
+	if !ok || len(update) != 1 {
+		t.Fatalf("Expected only a $set, got %v", update)
+	}
+	if len(set) != 5 || set["otp"] != "encrypted" || set["otp_attempts"] != 2 || set["otp_expires_at"] != expiresAt {
+		t.Errorf("Expected only the OTP fields to be set, got %v", set)
+	}
+
+	update = otpUpdate(&entity.User{Email: "test@example.com"})
+	unset, ok := update["$unset"].(bson.M)
+	if !ok || len(update) != 1 || len(unset) != 5 {
+		t.Errorf("Expected only the OTP fields to be unset, got %v", update)
+	}
+}
+
+// applyUpdate runs the $set, $unset and $inc of each update against the
+// stored document of user, the way MongoDB would, and reads the user back
+func applyUpdate(t *testing.T, user *entity.User, updates ...bson.M) *entity.User {
+	t.Helper()
+	data, err := bson.Marshal(user)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+
+	for _, update := range updates {
+		if set, ok := update["$set"].(bson.M); ok {
+			for field, value := range set {
+				doc[field] = value
+			}
+		}
+		if unset, ok := update["$unset"].(bson.M); ok {
+			for field := range unset {
+				delete(doc, field)
+			}
+		}
+		if inc, ok := update["$inc"].(bson.M); ok {
+			for field, by := range inc {
+				var current int64
+				switch value := doc[field].(type) {
+				case int32:
+					current = int64(value)
+				case int64:
+					current = value
+				}
+				doc[field] = current + int64(by.(int))
+			}
+		}
+	}
+
+	data, err = bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var stored entity.User
+	if err := bson.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	return &stored
+}
+
+func TestUpdateRole_Demotion(t *testing.T) {
+	admin := &entity.User{Email: "john@example.com", Fullname: "John", Role: "admin", TokenVersion: 1}
+	stale := *admin
+
+	demotion := roleUpdate("user")
+	demotion["$inc"] = bson.M{"token_version": 1}
+	stored := applyUpdate(t, admin, demotion)
+	if stored.Role != "user" || stored.TokenVersion != 2 {
+		t.Fatalf("Expected the user role stored with the version bumped, got %q and %d", stored.Role, stored.TokenVersion)
+	}
+
+	// A full update from a copy read before the demotion keeps it
+	stale.LastLoginAt = time.Now()
+	update, err := userUpdate(&stale)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored = applyUpdate(t, stored, update)
+	if stored.Role != "user" {
+		t.Errorf("Expected a stale update to leave the role alone, got %q", stored.Role)
+	}
+	if stored.LastLoginAt.IsZero() {
+		t.Error("Expected the stale update's other fields to be written")
+	}
+}
+
+func TestUserUpdate_KeepsTargetedFields(t *testing.T) {
+	stale := &entity.User{Email: "john@example.com", Fullname: "John"}
+
+	// Suspended, signed out and scheduled for deletion after stale was read
+	suspension := suspendUpdate(time.Now(), "fraud")
+	suspension["$inc"] = bson.M{"token_version": 1}
+	deletion := bson.M{"$set": bson.M{"deletion_scheduled_at": time.Now().Add(time.Hour)}}
+	stored := applyUpdate(t, stale, suspension, deletion)
+
+	stale.LastLoginAt = time.Now()
+	update, err := userUpdate(stale)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored = applyUpdate(t, stored, update)
+	if !stored.Suspended() || stored.SuspendedReason != "fraud" || stored.TokenVersion != 1 || stored.DeletionScheduledAt.IsZero() {
+		t.Errorf("Expected a stale update to keep the suspension, token version and deletion, got %+v", stored)
+	}
+
+	stored = applyUpdate(t, stored, suspendUpdate(time.Now(), ""))
+	if stored.SuspendedReason != "" {
+		t.Errorf("Expected a suspension without a reason to clear the old one, got %q", stored.SuspendedReason)
+	}
+}
+
+func TestBuildUserFilter(t *testing.T) {
+	if filter := buildUserFilter(repository.UserFilter{Keyword: "  "}); len(filter) != 0 {
+		t.Errorf("Expected a blank keyword to match everyone, got %v", filter)
+	}
+
+	filter := buildUserFilter(repository.UserFilter{Keyword: "José+1"})
+	conditions, ok := filter["$or"].(bson.A)
+	if !ok || len(conditions) != 3 {
+		t.Fatalf("Expected the keyword to be matched on email, phone and name, got %v", filter)
+	}
+	email := conditions[0].(bson.M)["email"].(bson.M)
+	if email["$regex"] != `José\+1` || email["$options"] != "i" {
+		t.Errorf("Expected a quoted case-insensitive email match, got %v", email)
+	}
+	name := conditions[2].(bson.M)["name_normalized"].(bson.M)
+	if name["$regex"] != `jose\+1` {
+		t.Errorf("Expected the name match to use the folded keyword, got %v", name)
+	}
+
+	suspended := true
+	filter = buildUserFilter(repository.UserFilter{Suspended: &suspended})
+	if filter["suspended_at"].(bson.M)["$exists"] != true {
+		t.Errorf("Expected only suspended users, got %v", filter)
+	}
+}
+
 func TestUpdateMapWithOTP(t *testing.T) {
 	// Test update map when OTP is present
 	user := &entity.User{
@@ -213,4 +378,4 @@ func TestBSONFilters(t *testing.T) {
 	if phoneFilter["phone_number"] != phone {
 		t.Errorf("Expected phone filter %v, got %v", phone, phoneFilter["phone_number"])
 	}
-}
\ No newline at end of file
+}
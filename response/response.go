@@ -1,7 +1,12 @@
 package response
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
@@ -154,6 +159,38 @@ func ListSuccess(c *gin.Context, resourceName string, data interface{}, total in
 	})
 }
 
+// NotModified sets an ETag computed from data and, when the request's
+// If-None-Match already holds it, answers 304 and returns true so the
+// caller can skip writing the body
+func NotModified(c *gin.Context, data interface{}) bool {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return false
+	}
+	sum := sha256.Sum256(payload)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			c.Status(304)
+			return true
+		}
+	}
+	return false
+}
+
+// CacheHeaders lets shared caches such as a CDN keep public responses for
+// maxAge, anything private or without a max age is never stored
+func CacheHeaders(c *gin.Context, public bool, maxAge time.Duration) {
+	if public && maxAge > 0 {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+}
+
 func Error(c *gin.Context, code int, message interface{}) {
 	c.JSON(code, gin.H{
 		"status": constants.ERROR,
@@ -167,13 +204,18 @@ func Error(c *gin.Context, code int, message interface{}) {
 // ErrorFromAppError handles structured application errors
 func ErrorFromAppError(c *gin.Context, err error) {
 	if appErr, ok := appErrors.IsAppError(err); ok {
+		body := gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		}
+		// Details of server errors can carry internals, they stay in the logs
+		if appErr.Details != "" && appErr.Status < 500 {
+			body["details"] = appErr.Details
+		}
 		c.JSON(appErr.Status, gin.H{
 			"status": constants.ERROR,
 			"code":   appErr.Status,
-			"error": gin.H{
-				"code":    appErr.Code,
-				"message": appErr.Message,
-			},
+			"error":  body,
 		})
 		return
 	}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
@@ -19,7 +20,7 @@ func setupTestRouter() *gin.Engine {
 
 func TestSuccess(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		Success(c, 200, "test data")
 	})
@@ -53,7 +54,7 @@ func TestSuccess(t *testing.T) {
 
 func TestSuccessWithPagination(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		SuccessWithPagination(c, 200, []string{"item1", "item2"}, 2)
 	})
@@ -79,7 +80,7 @@ func TestSuccessWithPagination(t *testing.T) {
 
 func TestSuccessWithMessage(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		SuccessWithMessage(c, 200, "Success message")
 	})
@@ -101,7 +102,7 @@ func TestSuccessWithMessage(t *testing.T) {
 
 func TestCreated(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.POST("/test", func(c *gin.Context) {
 		Created(c, "created data")
 	})
@@ -117,7 +118,7 @@ func TestCreated(t *testing.T) {
 
 func TestCreatedWithMessage(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.POST("/test", func(c *gin.Context) {
 		CreatedWithMessage(c, "Resource created")
 	})
@@ -133,7 +134,7 @@ func TestCreatedWithMessage(t *testing.T) {
 
 func TestOK(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		OK(c, "ok data")
 	})
@@ -149,7 +150,7 @@ func TestOK(t *testing.T) {
 
 func TestOKWithMessage(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		OKWithMessage(c, "OK message")
 	})
@@ -207,7 +208,7 @@ func TestSpecificSuccessResponses(t *testing.T) {
 
 func TestGeneral(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		General(c, 200, "Test message", "test data")
 	})
@@ -234,7 +235,7 @@ func TestGeneral(t *testing.T) {
 
 func TestGeneralWithoutData(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		General(c, 200, "Test message", nil)
 	})
@@ -317,7 +318,7 @@ func TestCRUDHelpers(t *testing.T) {
 
 func TestListSuccess(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		ListSuccess(c, "Users", []string{"user1", "user2"}, 2)
 	})
@@ -344,7 +345,7 @@ func TestListSuccess(t *testing.T) {
 
 func TestError(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		Error(c, 400, "Bad request")
 	})
@@ -375,7 +376,7 @@ func TestError(t *testing.T) {
 
 func TestErrorFromAppError(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	// Test with AppError
 	router.GET("/test-app-error", func(c *gin.Context) {
 		err := appErrors.ErrUserNotFound
@@ -402,12 +403,37 @@ func TestErrorFromAppError(t *testing.T) {
 	}
 }
 
+func TestErrorFromAppError_Details(t *testing.T) {
+	router := setupTestRouter()
+	router.GET("/client-error", func(c *gin.Context) {
+		ErrorFromAppError(c, &appErrors.AppError{Code: "BAD_REQUEST", Message: "Too many", Status: http.StatusBadRequest, Details: "At most 100"})
+	})
+	router.GET("/server-error", func(c *gin.Context) {
+		ErrorFromAppError(c, appErrors.WrapError(errors.New("dial tcp 10.0.0.5:443"), "Upload failed"))
+	})
+
+	for path, expected := range map[string]interface{}{"/client-error": "At most 100", "/server-error": nil} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		errorData := response["error"].(map[string]interface{})
+		if errorData["details"] != expected {
+			t.Errorf("Expected details %v for %s, got %v", expected, path, errorData["details"])
+		}
+	}
+}
+
 func TestErrorFromStandardError(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	// Test with standard error
 	router.GET("/test-std-error", func(c *gin.Context) {
-		err := errors.New("standard error") 
+		err := errors.New("standard error")
 		ErrorFromAppError(c, err)
 	})
 
@@ -433,7 +459,7 @@ func TestErrorFromStandardError(t *testing.T) {
 
 func TestValidationError(t *testing.T) {
 	router := setupTestRouter()
-	
+
 	router.GET("/test", func(c *gin.Context) {
 		errors := []map[string]string{
 			{"field": "email", "message": "Invalid email"},
@@ -499,6 +525,80 @@ func TestSuccessResponse(t *testing.T) {
 	}
 
 	if unmarshaled.Message != response.Message {
-		t.Errorf("Expected message '%v', got %v", response.Message, unmarshaled.Message)  
+		t.Errorf("Expected message '%v', got %v", response.Message, unmarshaled.Message)
+	}
+}
+func TestNotModified(t *testing.T) {
+	router := setupTestRouter()
+	router.GET("/resource", func(c *gin.Context) {
+		data := gin.H{"name": "BuildYow"}
+		if NotModified(c, data) {
+			return
+		}
+		Success(c, 200, data)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/resource", nil)
+	router.ServeHTTP(w, req)
+
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d and %q", w.Code, etag)
+	}
+
+	// Matching If-None-Match skips the body
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/resource", nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected empty body for 304, got %s", w.Body.String())
+	}
+
+	// Stale If-None-Match gets the full body
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/resource", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, got %d", w.Code)
 	}
-}
\ No newline at end of file
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("Expected stable ETag %s, got %s", etag, w.Header().Get("ETag"))
+	}
+	if w.Body.Len() == 0 {
+		t.Error("Expected body for non-matching ETag")
+	}
+}
+
+func TestCacheHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		public   bool
+		maxAge   time.Duration
+		expected string
+	}{
+		{"public", true, 5 * time.Minute, "public, max-age=300"},
+		{"public without max age", true, 0, "no-store"},
+		{"private", false, 5 * time.Minute, "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			CacheHeaders(c, tt.public, tt.maxAge)
+			if got := w.Header().Get("Cache-Control"); got != tt.expected {
+				t.Errorf("Expected Cache-Control %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
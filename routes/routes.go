@@ -1,34 +1,67 @@
 package routes
 
 import (
+	"context"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/buildyow/byow-user-service/constants"
 	"github.com/buildyow/byow-user-service/delivery/http"
 	"github.com/buildyow/byow-user-service/docs"
+	"github.com/buildyow/byow-user-service/infrastructure/apikey"
 	"github.com/buildyow/byow-user-service/infrastructure/db"
+	"github.com/buildyow/byow-user-service/infrastructure/featureflags"
 	"github.com/buildyow/byow-user-service/infrastructure/jwt"
 	loggerZap "github.com/buildyow/byow-user-service/infrastructure/logger"
+	"github.com/buildyow/byow-user-service/infrastructure/mailer"
+	"github.com/buildyow/byow-user-service/infrastructure/oauth"
+	"github.com/buildyow/byow-user-service/infrastructure/ratelimit"
+	"github.com/buildyow/byow-user-service/infrastructure/sms"
 	"github.com/buildyow/byow-user-service/infrastructure/validation"
+	"github.com/buildyow/byow-user-service/lib"
 	"github.com/buildyow/byow-user-service/repository"
 	"github.com/buildyow/byow-user-service/usecase"
 	"go.uber.org/zap"
 
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/webauthn"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func InitRoutes(r *gin.Engine) {
+// envInt reads a positive integer from the environment, falling back when unset or invalid
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// envDuration reads a positive duration such as "30m" from the environment,
+// zero when unset or invalid
+func envDuration(key string) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return 0
+	}
+	return value
+}
+
+// InitRoutes wires every route onto r. ctx is canceled on shutdown, emails
+// and SMS still in flight are aborted with it.
+func InitRoutes(ctx context.Context, r *gin.Engine) {
 	logger, err := zap.NewProduction()
 	if err != nil {
 		panic("failed to initialize zap logger: " + err.Error())
 	}
 	defer logger.Sync()
-	r.Use(ginzap.Ginzap(logger, "", true))      // Logging request
-	r.Use(ginzap.RecoveryWithZap(logger, true)) // Logging panic recovery
-	r.Use(loggerZap.LogRequestBody(logger))     // Logging request body
+	r.Use(ginzap.Ginzap(logger, "", true))                                                                  // Logging request
+	r.Use(ginzap.RecoveryWithZap(logger, true))                                                             // Logging panic recovery
+	r.Use(loggerZap.LogRequestBodySampled(logger, loggerZap.ParseSampleRate(os.Getenv("LOG_SAMPLE_RATE")))) // Logging request body, errors always
 	// Connect DB
 	client, err := db.Connect(os.Getenv("MONGO_URI"))
 	if err != nil {
@@ -42,20 +75,100 @@ func InitRoutes(r *gin.Engine) {
 		logger.Warn("Failed to create database indexes", zap.Error(err))
 	}
 
-	// Initialize JWT blacklist service  
-	blacklistService := jwt.NewBlacklistService(database, logger)
-	blacklistService.StartCleanupWorker()
+	// Token signing algorithm
+	if err := jwt.Configure(os.Getenv("JWT_ALG"), os.Getenv("JWT_PRIVATE_KEY_FILE")); err != nil {
+		panic(err)
+	}
+
+	// Token blacklist and rate limits, in Redis when REDIS_URL is set so
+	// a logout reaches every instance at once and the limits hold across
+	// them, in MongoDB and in memory otherwise
+	var blacklist jwt.Blacklist
+	var rateLimitStore ratelimit.Store
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisBlacklist, err := jwt.NewRedisBlacklist(redisURL, logger)
+		if err != nil {
+			panic("failed to connect to redis: " + err.Error())
+		}
+		blacklist = redisBlacklist
+		redisStore, err := ratelimit.NewRedisStore(redisURL)
+		if err != nil {
+			panic("failed to connect to redis: " + err.Error())
+		}
+		rateLimitStore = redisStore
+	} else {
+		blacklistService := jwt.NewBlacklistService(database, logger)
+		blacklistService.StartCleanupWorker()
+		blacklist = blacklistService
+	}
+
+	// Feature flags
+	flags := featureflags.Load()
+	validation.CheckEmailMX = flags.Enabled(featureflags.EmailMXCheck)
+
+	// Audit log retention
+	auditUC := &usecase.AuditUsecase{
+		Repo: repository.NewAuditLogMongoRepo(database),
+		Retention: usecase.AuditRetention{
+			Standard: time.Duration(envInt("AUDIT_RETENTION_DAYS", 90)) * 24 * time.Hour,
+			Critical: time.Duration(envInt("AUDIT_CRITICAL_RETENTION_DAYS", 2555)) * 24 * time.Hour,
+		},
+	}
+	auditUC.StartPurgeWorker(24 * time.Hour)
 
 	// Usecase
 	userUC := &usecase.UserUsecase{
 		Repo:      userRepo,
 		JWTSecret: os.Getenv("JWT_SECRET"),
+		Flags:     flags,
+	}
+	userUC.JWTExpire, err = jwt.ParseExpire(os.Getenv("JWT_EXPIRE"))
+	if err != nil {
+		panic(err)
 	}
-	userUC.JWTExpire, _ = strconv.Atoi(os.Getenv("JWT_EXPIRE"))
 	userUC.EmailConfig.Host = os.Getenv("EMAIL_HOST")
 	userUC.EmailConfig.Port, _ = strconv.Atoi(os.Getenv("EMAIL_PORT"))
 	userUC.EmailConfig.User = os.Getenv("EMAIL_USER")
 	userUC.EmailConfig.Pass = os.Getenv("EMAIL_PASS")
+	userUC.PasswordResetURL = os.Getenv("PASSWORD_RESET_URL")
+	userUC.PasswordResetTTL = time.Duration(envInt("PASSWORD_RESET_TTL_MINUTES", 30)) * time.Minute
+	userUC.SupportURL = os.Getenv("SUPPORT_URL")
+	userUC.PasswordMaxAge = time.Duration(envInt("PASSWORD_MAX_AGE_DAYS", 0)) * 24 * time.Hour
+	userUC.DeletionGrace = time.Duration(envInt("ACCOUNT_DELETION_GRACE_DAYS", 30)) * 24 * time.Hour
+	userUC.EmailTimeouts.DialTimeout = time.Duration(envInt("EMAIL_DIAL_TIMEOUT_SECONDS", 10)) * time.Second
+	userUC.EmailTimeouts.SendTimeout = time.Duration(envInt("EMAIL_SEND_TIMEOUT_SECONDS", 30)) * time.Second
+	userUC.Audit = auditUC
+	userUC.RevokeToken = blacklist.BlacklistToken
+	userUC.Sessions = repository.NewSessionMongoRepo(database)
+	userUC.SMS = sms.Load()
+	userUC.SMSCountryCode = os.Getenv("SMS_DEFAULT_COUNTRY_CODE")
+	userUC.MailContext = ctx
+
+	// Passkeys, enabled once the relying party is configured. The RP ID is
+	// the site's domain, passkeys only work on it and its subdomains.
+	if rpID := os.Getenv("WEBAUTHN_RP_ID"); rpID != "" {
+		rpName := os.Getenv("WEBAUTHN_RP_NAME")
+		if rpName == "" {
+			rpName = "BYOW"
+		}
+		var origins []string
+		for _, origin := range strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+		ceremonyTimeout := webauthn.TimeoutConfig{Enforce: true, Timeout: usecase.PasskeyCeremonyTTL, TimeoutUVD: usecase.PasskeyCeremonyTTL}
+		passkeys, err := webauthn.New(&webauthn.Config{
+			RPID:          rpID,
+			RPDisplayName: rpName,
+			RPOrigins:     origins,
+			Timeouts:      webauthn.TimeoutsConfig{Login: ceremonyTimeout, Registration: ceremonyTimeout},
+		})
+		if err != nil {
+			panic("invalid passkey configuration: " + err.Error())
+		}
+		userUC.WebAuthn = passkeys
+	}
 
 	companyUC := &usecase.CompanyUsecase{
 		Repo: repository.NewCompanyMongoRepo(database),
@@ -69,63 +182,223 @@ func InitRoutes(r *gin.Engine) {
 			}
 			return ""
 		},
+		Flags:      flags,
+		Audit:      auditUC,
+		Events:     usecase.NewCompanyEventHub(),
+		Users:      userRepo,
+		DeleteLogo: lib.CloudinaryDelete,
+		SendEmail: func(to, subject, body string) error {
+			return mailer.SendContext(ctx, userUC.EmailTimeouts, to, subject, body,
+				userUC.EmailConfig.Host, userUC.EmailConfig.User, userUC.EmailConfig.Pass, userUC.EmailConfig.Port)
+		},
+	}
+
+	// Deleted accounts take their companies and avatar with them
+	userUC.PurgeOwnerData = companyUC.PurgeOwner
+	userUC.DeleteAvatar = lib.CloudinaryDelete
+	userUC.StartDeletionWorker(time.Hour)
+
+	adminUC := &usecase.AdminUsecase{
+		UserRepo:    userRepo,
+		CompanyRepo: companyUC.Repo,
+		Audit:       auditUC,
+		Flags:       flags,
+		BuildIndexes: func(ctx context.Context) ([]string, error) {
+			return db.EnsureIndexes(ctx, database, logger)
+		},
+		PreviousDecryptKey:  os.Getenv("DECRYPT_KEY_PREVIOUS"),
+		JWTSecret:           userUC.JWTSecret,
+		ImpersonationExpire: envInt("IMPERSONATION_EXPIRE_MINUTES", usecase.DefaultImpersonationExpire),
+	}
+
+	apiKeyUC := &usecase.APIKeyUsecase{
+		Repo:     repository.NewAPIKeyMongoRepo(database),
+		UserRepo: userRepo,
+		Audit:    auditUC,
 	}
 
 	// Handler
 	userHandler := http.NewUserHandler(userUC)
+	userHandler.OAuth = oauth.Load()
+	userHandler.OAuthSuccessURL = os.Getenv("OAUTH_SUCCESS_URL")
+	userHandler.Cookie, err = jwt.LoadCookieConfig(userUC.JWTExpire)
+	if err != nil {
+		panic("invalid cookie configuration: " + err.Error())
+	}
+	jwt.CookieName = userHandler.Cookie.Name
 	companyHandler := http.NewCompanyHandler(companyUC)
+	companyHandler.PublicMaxAge = time.Duration(envInt("PUBLIC_CACHE_MAX_AGE_SECONDS", 300)) * time.Second
+	companyHandler.MaxBatchSize = envInt("MAX_BATCH_SIZE", http.DefaultMaxBatchSize)
+	adminHandler := http.NewAdminHandler(adminUC)
+	apiKeyHandler := http.NewAPIKeyHandler(apiKeyUC)
+
+	// CAPTCHA on registration, off unless CAPTCHA_PROVIDER is set
+	captcha, err := validation.LoadCaptcha()
+	if err != nil {
+		panic("invalid captcha configuration: " + err.Error())
+	}
+
+	// Rate limits per client IP and email, OTP and password reset endpoints
+	// share one budget
+	limiter := ratelimit.New(rateLimitStore, logger)
+	loginLimit := limiter.Middleware("login", ratelimit.PerMinute(envInt("RATE_LIMIT_LOGIN_PER_MINUTE", 10)))
+	registerLimit := limiter.Middleware("register", ratelimit.PerMinute(envInt("RATE_LIMIT_REGISTER_PER_MINUTE", 5)))
+	otpLimit := limiter.Middleware("otp", ratelimit.PerMinute(envInt("RATE_LIMIT_OTP_PER_MINUTE", 5)))
+	// Each availability call checks a whole batch of emails, keep it scarce
+	availabilityLimit := limiter.Middleware("availability", ratelimit.PerMinute(envInt("RATE_LIMIT_AVAILABILITY_PER_MINUTE", 3)))
 
 	// Public Routes
 	auth := r.Group("/auth/users")
+	auth.Use(featureflags.Maintenance(flags), featureflags.RejectBots(flags))
 	{
-		auth.POST("/register", 
+		auth.POST("/register",
+			validation.ParseMultipartForm(10<<20), // reject truncated uploads before any field is read
+			registerLimit,
+			validation.RequireCaptcha(captcha),
 			validation.ValidateRegistrationRequest(),
-			validation.ValidateFileUpload(10<<20, []string{"image/jpeg", "image/png", "image/gif"}), // 10MB limit
+			validation.ValidateFileUpload(validation.ImageUpload.MaxSize, validation.ImageUpload.AllowedTypes),
 			userHandler.Register)
-		auth.POST("/login", 
+		auth.POST("/login",
 			validation.ValidateLoginRequest(),
+			loginLimit,
 			userHandler.Login)
-		auth.POST("/change-password-otp", userHandler.ChangePasswordWithOTP)
-		auth.GET("/forgot-password/send-otp", userHandler.SendOTPForgotPassword)
+		auth.POST("/change-password-otp", otpLimit, userHandler.ChangePasswordWithOTP)
+		auth.GET("/forgot-password/send-otp", otpLimit, userHandler.SendOTPForgotPassword)
+		auth.POST("/forgot-password/send-link", otpLimit, userHandler.SendPasswordResetLink)
+		auth.POST("/reset-password", otpLimit, userHandler.ResetPasswordWithToken)
+		auth.POST("/precheck", otpLimit, userHandler.PrecheckPasswordReset)
+		auth.POST("/availability-batch", availabilityLimit, userHandler.EmailAvailabilityBatch)
+		auth.POST("/webauthn/login/begin", loginLimit, userHandler.BeginPasskeyLogin)
+		auth.POST("/webauthn/login/finish", loginLimit, userHandler.FinishPasskeyLogin)
+		auth.GET("/oauth/:provider", userHandler.OAuthLogin)
+		auth.GET("/oauth/:provider/callback", userHandler.OAuthCallback)
 	}
 
 	verification := r.Group("/verification/users")
+	verification.Use(featureflags.Maintenance(flags))
+	{
+		verification.GET("/send-otp", otpLimit, userHandler.SendOTPVerification)
+		verification.POST("/verify-otp", otpLimit, userHandler.VerifyOTP)
+		verification.POST("/check-otp", otpLimit, userHandler.CheckOTP)
+	}
+
+	companies := r.Group("/companies")
+	companies.Use(featureflags.Maintenance(flags))
+	{
+		companies.GET("/public", companyHandler.FindPublic)
+		companies.GET("/public/:id", companyHandler.FindPublicByID)
+		companies.GET("/public/:id/vcard", companyHandler.PublicVCard)
+	}
+
+	// Internal Routes, for other BYOW services calling with an API key
+	internal := r.Group("/internal")
 	{
-		verification.GET("/send-otp", userHandler.SendOTPVerification)
-		verification.POST("/verify-otp", userHandler.VerifyOTP)
+		internal.GET("/users/:id", apikey.APIKeyMiddleware(apiKeyUC.Authenticate, constants.API_SCOPE_USERS_READ), apiKeyHandler.GetUser)
 	}
 
 	// Protected Routes
 	protected := r.Group("/api")
-	protected.Use(jwt.JWTMiddleware(blacklistService))
+	protected.Use(featureflags.Maintenance(flags), jwt.JWTMiddleware(blacklist), jwt.AuditImpersonation(auditUC.RecordImpersonatedRequest))
+	if maxIdle := envDuration("SESSION_MAX_IDLE"); maxIdle > 0 {
+		sessions := jwt.NewSessionTracker(maxIdle)
+		sessions.StartCleanupWorker()
+		protected.Use(jwt.IdleTimeout(sessions, blacklist.BlacklistToken))
+	}
+	protected.Use(jwt.RequireTokenVersion(userUC.TokenVersion))
+	// Changing how the account signs in, or deleting it, is left to the owner
+	ownerOnly := jwt.RefuseImpersonation()
 	{
 		//USER
 		protected.GET("/users/me", userHandler.UserMe)
-		protected.GET("/users/onboard", userHandler.OnBoard)
-		protected.POST("/users/update", userHandler.UpdateUser)
+		protected.GET("/users/security/failed-logins", userHandler.FailedLogins)
+		protected.GET("/users/security/logins", userHandler.LoginHistory)
+		protected.GET("/users/sessions", userHandler.ListSessions)
+		protected.DELETE("/users/sessions/:id", userHandler.RevokeSession)
+		protected.GET("/users/security/summary", userHandler.SecuritySummary)
+		protected.GET("/users/notifications", userHandler.NotificationPrefs)
+		protected.PUT("/users/notifications", userHandler.UpdateNotificationPrefs)
+		protected.GET("/users/language", userHandler.Language)
+		protected.PUT("/users/language", userHandler.UpdateLanguage)
+		protected.GET("/users/permissions", userHandler.Permissions)
+		protected.GET("/users/onboard", userHandler.OnBoard) // deprecated, kept for older clients
+		protected.POST("/users/onboard", userHandler.CompleteOnboarding)
+		protected.POST("/users/update", validation.ParseMultipartForm(10<<20), userHandler.UpdateUser)
 		protected.POST("/users/logout", userHandler.Logout)
-		protected.POST("/users/change-email", userHandler.ChangeEmail)
-		protected.GET("/users/change-email/send-otp", userHandler.SendOTPEmailChange)
-		protected.POST("/users/change-phone", userHandler.ChangePhone)
-		protected.GET("/users/change-phone/send-otp", userHandler.SendOTPPhoneChange)
-		protected.POST("/users/change-password-old", userHandler.ChangePasswordWithOldPassword)
+		protected.POST("/users/logout-all", ownerOnly, userHandler.LogoutAll)
+		protected.DELETE("/users/me", ownerOnly, userHandler.DeleteMe)
+		protected.POST("/users/webauthn/register/begin", ownerOnly, userHandler.BeginPasskeyRegistration)
+		protected.POST("/users/webauthn/register/finish", ownerOnly, userHandler.FinishPasskeyRegistration)
+		protected.POST("/users/change-email", ownerOnly, otpLimit, userHandler.ChangeEmail)
+		protected.GET("/users/change-email/send-otp", ownerOnly, otpLimit, userHandler.SendOTPEmailChange)
+		protected.POST("/users/change-email/send-new-otp", ownerOnly, otpLimit, userHandler.SendOTPNewEmail)
+		protected.POST("/users/change-phone", ownerOnly, otpLimit, userHandler.ChangePhone)
+		protected.GET("/users/change-phone/send-otp", ownerOnly, otpLimit, userHandler.SendOTPPhoneChange)
+		protected.POST("/users/change-password-old", ownerOnly, userHandler.ChangePasswordWithOldPassword)
+		protected.POST("/users/change-password-stepup/send-otp", ownerOnly, otpLimit, userHandler.SendOTPChangePasswordStepUp)
+		protected.POST("/users/change-password-stepup", ownerOnly, otpLimit, userHandler.ChangePasswordStepUp)
+
+		//UPLOADS
+		protected.GET("/uploads/config", http.UploadConfig)
 
 		//COMPANIES
 		protected.GET("/companies/all", companyHandler.FindAll)
+		protected.GET("/companies/recent", companyHandler.FindRecent)
+		protected.GET("/companies/by-name", companyHandler.FindByName)
+		protected.GET("/companies/availability", companyHandler.CheckAvailability)
+		protected.GET("/companies/events", companyHandler.Events)
 		protected.POST("/companies/create", companyHandler.Create)
+		protected.POST("/companies/ownership-batch", companyHandler.OwnershipBatch)
 		protected.GET("/companies/:id", companyHandler.FindByID)
+		protected.GET("/companies/:id/details", companyHandler.FindByIDWithOwner)
+		protected.DELETE("/companies/:id", companyHandler.Delete)
+		protected.POST("/companies/:id/restore", companyHandler.Restore)
+		protected.POST("/companies/:id/set-primary", companyHandler.SetPrimary)
+	}
+
+	// Admin Routes, closed to anyone without admin access, then each gated by
+	// the permission it needs
+	admin := protected.Group("/admin")
+	admin.Use(jwt.RequirePermission(constants.PERMISSION_ADMIN_ACCESS))
+	{
+		admin.GET("/companies", jwt.RequirePermission(constants.PERMISSION_ADMIN_COMPANIES_READ), companyHandler.AdminFindAll)
+		admin.POST("/companies/verify-batch", jwt.RequirePermission(constants.PERMISSION_ADMIN_COMPANIES_WRITE), companyHandler.AdminVerifyBatch)
+		admin.POST("/companies/purge", jwt.RequirePermission(constants.PERMISSION_ADMIN_COMPANIES_WRITE), companyHandler.AdminPurgeDeleted)
+		admin.POST("/companies/:id/unverify", jwt.RequirePermission(constants.PERMISSION_ADMIN_COMPANIES_WRITE), companyHandler.AdminUnverify)
+		admin.GET("/users", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_READ), adminHandler.ListUsers)
+		admin.POST("/users/merge", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_WRITE), adminHandler.MergeAccounts)
+		admin.GET("/users/export", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_READ), adminHandler.ExportUsers)
+		admin.GET("/users/:id", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_READ), adminHandler.GetUser)
+		admin.POST("/users/:id/suspend", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_WRITE), adminHandler.SuspendUser)
+		admin.POST("/users/:id/reactivate", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_WRITE), adminHandler.ReactivateUser)
+		admin.POST("/users/:id/impersonate", jwt.RequirePermission(constants.PERMISSION_ADMIN_IMPERSONATE), adminHandler.Impersonate)
+		admin.POST("/users/:id/reset-otp-attempts", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_WRITE), adminHandler.ResetOTPAttempts)
+		admin.PUT("/users/:id/role", jwt.RequirePermission(constants.PERMISSION_ADMIN_USERS_WRITE), adminHandler.SetRole)
+		admin.GET("/flags", jwt.RequirePermission(constants.PERMISSION_ADMIN_FLAGS_READ), adminHandler.FeatureFlags)
+		admin.POST("/api-keys", jwt.RequirePermission(constants.PERMISSION_ADMIN_API_KEYS_WRITE), apiKeyHandler.Create)
+		admin.GET("/api-keys", jwt.RequirePermission(constants.PERMISSION_ADMIN_API_KEYS_READ), apiKeyHandler.List)
+		admin.DELETE("/api-keys/:id", jwt.RequirePermission(constants.PERMISSION_ADMIN_API_KEYS_WRITE), apiKeyHandler.Revoke)
+		admin.POST("/db/indexes/rebuild", jwt.RequirePermission(constants.PERMISSION_ADMIN_SYSTEM_WRITE), adminHandler.RebuildIndexes)
+		admin.POST("/security/reencrypt-otps", jwt.RequirePermission(constants.PERMISSION_ADMIN_SYSTEM_WRITE), adminHandler.ReencryptOTPs)
 	}
 
 	// Health Check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "OK",
-			"message": "BYOW User Service is healthy",
-			"version": "1.0.0",
-		})
-	})
+	// Reaching SMTP is opt-in, some environments block it from health checks
+	var emailProbe func(ctx context.Context) error
+	if enabled, _ := strconv.ParseBool(os.Getenv("HEALTH_CHECK_EMAIL")); enabled {
+		emailProbe = func(ctx context.Context) error {
+			return mailer.Probe(ctx, userUC.EmailConfig.Host, userUC.EmailConfig.Port, mailer.DefaultProbeTimeout)
+		}
+	}
+	r.GET("/health", http.Health(emailProbe))
+
+	// Server time, for clients correcting clock skew
+	r.GET("/time", http.ServerTime)
+	// Build and uptime, to tell which build is deployed
+	r.GET("/info", http.ServiceInfo)
+	r.GET("/.well-known/jwks.json", http.JWKS)
 
 	// Swagger
 	docs.SwaggerInfo.BasePath = "/"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/openapi.json", http.OpenAPISpec) // stable spec URL for client generators
 }
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -9,21 +10,21 @@ import (
 
 func TestInitRoutes_PanicWithoutMongoDB(t *testing.T) {
 	// Test that InitRoutes panics when MongoDB URI is not available
-	
+
 	// Set gin to test mode
 	gin.SetMode(gin.TestMode)
-	
+
 	// Clear MongoDB environment variables to force panic
 	originalMongoURI := os.Getenv("MONGO_URI")
 	originalDBName := os.Getenv("DB_NAME")
 	os.Unsetenv("MONGO_URI")
 	os.Unsetenv("DB_NAME")
-	
+
 	defer func() {
 		// Restore environment variables
 		os.Setenv("MONGO_URI", originalMongoURI)
 		os.Setenv("DB_NAME", originalDBName)
-		
+
 		// Recover from expected panic
 		if r := recover(); r != nil {
 			t.Logf("Expected panic occurred: %v", r)
@@ -31,13 +32,13 @@ func TestInitRoutes_PanicWithoutMongoDB(t *testing.T) {
 			t.Error("Expected panic when MongoDB URI is not available")
 		}
 	}()
-	
+
 	// Create test router
 	r := gin.New()
-	
+
 	// This should panic due to missing MongoDB configuration
-	InitRoutes(r)
-	
+	InitRoutes(context.Background(), r)
+
 	// If we reach here, something went wrong (no panic occurred)
 	t.Error("InitRoutes should have panicked with missing MongoDB config")
 }
@@ -45,36 +46,36 @@ func TestInitRoutes_PanicWithoutMongoDB(t *testing.T) {
 func TestInitRoutes_EnvironmentVariableHandling(t *testing.T) {
 	// Test environment variable handling in InitRoutes
 	// We can't fully test InitRoutes without MongoDB, but we can test the env var logic
-	
+
 	// Test JWT_SECRET handling
 	originalJWTSecret := os.Getenv("JWT_SECRET")
 	os.Setenv("JWT_SECRET", "test-secret")
-	
+
 	secret := os.Getenv("JWT_SECRET")
 	if secret != "test-secret" {
 		t.Errorf("Expected JWT_SECRET 'test-secret', got %v", secret)
 	}
-	
+
 	// Test JWT_EXPIRE handling
 	originalJWTExpire := os.Getenv("JWT_EXPIRE")
 	os.Setenv("JWT_EXPIRE", "3600")
-	
+
 	expire := os.Getenv("JWT_EXPIRE")
 	if expire != "3600" {
 		t.Errorf("Expected JWT_EXPIRE '3600', got %v", expire)
 	}
-	
+
 	// Test EMAIL configuration
 	originalEmailHost := os.Getenv("EMAIL_HOST")
 	originalEmailPort := os.Getenv("EMAIL_PORT")
 	originalEmailUser := os.Getenv("EMAIL_USER")
 	originalEmailPass := os.Getenv("EMAIL_PASS")
-	
+
 	os.Setenv("EMAIL_HOST", "smtp.gmail.com")
 	os.Setenv("EMAIL_PORT", "587")
 	os.Setenv("EMAIL_USER", "test@gmail.com")
 	os.Setenv("EMAIL_PASS", "password")
-	
+
 	// Verify environment variables are set correctly
 	if os.Getenv("EMAIL_HOST") != "smtp.gmail.com" {
 		t.Error("EMAIL_HOST not set correctly")
@@ -82,7 +83,7 @@ func TestInitRoutes_EnvironmentVariableHandling(t *testing.T) {
 	if os.Getenv("EMAIL_PORT") != "587" {
 		t.Error("EMAIL_PORT not set correctly")
 	}
-	
+
 	// Restore environment variables
 	os.Setenv("JWT_SECRET", originalJWTSecret)
 	os.Setenv("JWT_EXPIRE", originalJWTExpire)
@@ -94,26 +95,26 @@ func TestInitRoutes_EnvironmentVariableHandling(t *testing.T) {
 
 func TestInitRoutes_GinSetup(t *testing.T) {
 	// Test basic Gin engine setup that InitRoutes expects
-	
+
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	
+
 	// Test that router is properly initialized
 	if r == nil {
 		t.Error("Expected non-nil gin engine")
 	}
-	
+
 	// Test that we can add routes to the engine
 	r.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
-	
+
 	// Verify routes were added
 	routes := r.Routes()
 	if len(routes) == 0 {
 		t.Error("Expected at least one route to be added")
 	}
-	
+
 	found := false
 	for _, route := range routes {
 		if route.Path == "/test" {
@@ -121,7 +122,7 @@ func TestInitRoutes_GinSetup(t *testing.T) {
 			break
 		}
 	}
-	
+
 	if !found {
 		t.Error("Expected /test route to be found")
 	}
@@ -129,19 +130,19 @@ func TestInitRoutes_GinSetup(t *testing.T) {
 
 func TestInitRoutes_MiddlewareSetup(t *testing.T) {
 	// Test middleware setup patterns used in InitRoutes
-	
+
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	
+
 	// Test middleware addition (similar to what InitRoutes does)
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
-	
+
 	// Add a test route to verify middleware chain
 	r.GET("/middleware-test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "middleware working"})
 	})
-	
+
 	// Verify that middleware doesn't break route setup
 	routes := r.Routes()
 	if len(routes) == 0 {
@@ -151,28 +152,28 @@ func TestInitRoutes_MiddlewareSetup(t *testing.T) {
 
 func TestInitRoutes_RouteGroupSetup(t *testing.T) {
 	// Test route group setup patterns used in InitRoutes
-	
+
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	
+
 	// Test auth group (similar to InitRoutes)
 	auth := r.Group("/auth/users")
 	auth.POST("/test-register", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "auth route"})
 	})
-	
+
 	// Test verification group
 	verification := r.Group("/verification/users")
 	verification.GET("/test-verify", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "verification route"})
 	})
-	
+
 	// Test protected group
 	protected := r.Group("/api")
 	protected.GET("/test-protected", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "protected route"})
 	})
-	
+
 	// Verify routes were added
 	routes := r.Routes()
 	expectedRoutes := []string{
@@ -180,7 +181,7 @@ func TestInitRoutes_RouteGroupSetup(t *testing.T) {
 		"/verification/users/test-verify",
 		"/api/test-protected",
 	}
-	
+
 	for _, expectedRoute := range expectedRoutes {
 		found := false
 		for _, route := range routes {
@@ -197,10 +198,10 @@ func TestInitRoutes_RouteGroupSetup(t *testing.T) {
 
 func TestInitRoutes_HealthCheckPattern(t *testing.T) {
 	// Test health check route pattern used in InitRoutes
-	
+
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	
+
 	// Add health check route (similar to InitRoutes)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -209,7 +210,7 @@ func TestInitRoutes_HealthCheckPattern(t *testing.T) {
 			"version": "1.0.0",
 		})
 	})
-	
+
 	// Verify health route was added
 	routes := r.Routes()
 	found := false
@@ -219,7 +220,7 @@ func TestInitRoutes_HealthCheckPattern(t *testing.T) {
 			break
 		}
 	}
-	
+
 	if !found {
 		t.Error("Expected /health GET route not found")
 	}
@@ -227,15 +228,15 @@ func TestInitRoutes_HealthCheckPattern(t *testing.T) {
 
 func TestInitRoutes_SwaggerSetup(t *testing.T) {
 	// Test swagger route setup pattern used in InitRoutes
-	
+
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	
+
 	// Add swagger route (similar to InitRoutes)
 	r.GET("/swagger/*any", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "swagger"})
 	})
-	
+
 	// Verify swagger route was added
 	routes := r.Routes()
 	found := false
@@ -245,7 +246,7 @@ func TestInitRoutes_SwaggerSetup(t *testing.T) {
 			break
 		}
 	}
-	
+
 	if !found {
 		t.Error("Expected /swagger/*any GET route not found")
 	}
@@ -253,60 +254,60 @@ func TestInitRoutes_SwaggerSetup(t *testing.T) {
 
 func TestInitRoutes_ImportAccessibility(t *testing.T) {
 	// Test that all imports used in InitRoutes are accessible
-	
+
 	// Test strconv import (used for Atoi)
 	// This is tested indirectly by ensuring we can use strconv functions
 	_, err := os.LookupEnv("TEST_VAR")
 	if err {
 		// LookupEnv doesn't return error, but this tests the pattern
 	}
-	
+
 	// Test that zap logger can be referenced
 	// We can't create a real logger without proper setup, but we can
 	// test that the import pattern works
-	
+
 	t.Log("Testing import accessibility for InitRoutes")
-	
+
 	// If this test compiles and runs, all imports in routes.go are accessible
 	t.Log("All imports are accessible")
 }
 
 func TestInitRoutes_DatabaseConnectionPattern(t *testing.T) {
 	// Test the database connection pattern without actually connecting
-	
+
 	// Test MONGO_URI environment variable handling
 	originalMongoURI := os.Getenv("MONGO_URI")
 	originalDBName := os.Getenv("DB_NAME")
-	
+
 	// Test with empty values
 	os.Setenv("MONGO_URI", "")
 	os.Setenv("DB_NAME", "")
-	
+
 	mongoURI := os.Getenv("MONGO_URI")
 	dbName := os.Getenv("DB_NAME")
-	
+
 	if mongoURI != "" {
 		t.Errorf("Expected empty MONGO_URI, got %v", mongoURI)
 	}
 	if dbName != "" {
 		t.Errorf("Expected empty DB_NAME, got %v", dbName)
 	}
-	
+
 	// Test with test values
 	os.Setenv("MONGO_URI", "mongodb://localhost:27017")
 	os.Setenv("DB_NAME", "testdb")
-	
+
 	mongoURI = os.Getenv("MONGO_URI")
 	dbName = os.Getenv("DB_NAME")
-	
+
 	if mongoURI != "mongodb://localhost:27017" {
 		t.Errorf("Expected test MONGO_URI, got %v", mongoURI)
 	}
 	if dbName != "testdb" {
 		t.Errorf("Expected test DB_NAME, got %v", dbName)
 	}
-	
+
 	// Restore original values
 	os.Setenv("MONGO_URI", originalMongoURI)
 	os.Setenv("DB_NAME", originalDBName)
-}
\ No newline at end of file
+}
@@ -9,7 +9,7 @@ db.companies_collections.dropIndex("company_email_unique");
 
 // Create new sparse unique index for company email
 db.companies_collections.createIndex(
-  { "email": 1 },
+  { "company_email": 1 },
   { 
     "unique": true, 
     "sparse": true, 
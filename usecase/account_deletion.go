@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/mailer"
+	"github.com/buildyow/byow-user-service/utils"
+)
+
+const (
+	// DefaultDeletionGrace is how long a user has to change their mind
+	// about deleting their account unless configured otherwise
+	DefaultDeletionGrace = 30 * 24 * time.Hour
+
+	deletionPurgeBatchSize int64 = 100
+)
+
+// ScheduleDeletion marks the account for deletion once the grace period
+// (DeletionGrace, DefaultDeletionGrace when zero) is over and signs the user
+// out everywhere. Logging in again before then cancels it. Asking again
+// while a deletion is pending keeps the original date.
+func (u *UserUsecase) ScheduleDeletion(userID, email string) (*dto.AccountDeletionResponse, error) {
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	if !user.DeletionScheduledAt.IsZero() {
+		return &dto.AccountDeletionResponse{DeletionScheduledAt: user.DeletionScheduledAt.Format(time.RFC3339)}, nil
+	}
+
+	grace := u.DeletionGrace
+	if grace <= 0 {
+		grace = DefaultDeletionGrace
+	}
+	deleteAt := time.Now().Add(grace)
+	version, err := u.Repo.ScheduleDeletion(user.ID, deleteAt)
+	if err != nil {
+		utils.LogError("Failed to schedule deletion of user %s: %v", user.ID, err)
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	user.DeletionScheduledAt = deleteAt
+	user.TokenVersion = version
+	if u.Sessions != nil {
+		if err := u.Sessions.RevokeAllByUser(user.ID, time.Now()); err != nil {
+			utils.LogError("Failed to end sessions of user %s: %v", user.ID, err)
+		}
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(user.ID, constants.AUDIT_DELETION_SCHEDULED, user.ID, map[string]interface{}{
+			"deletion_scheduled_at": user.DeletionScheduledAt,
+		})
+		if err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_DELETION_SCHEDULED, err)
+		}
+	}
+
+	// The confirmation tells the owner how to cancel if it wasn't them, it
+	// can't be muted
+	to := user.Email
+	lang := mailer.ResolveLanguage("", user.Preferences.Language)
+	u.runAsync(func() {
+		subject, body := mailer.AccountDeletionNotice(lang, deleteAt, u.SupportURL)
+		if err := u.sendEmail(to, subject, body); err != nil {
+			utils.LogError("Failed to send account deletion notice: %v", err)
+		}
+	})
+	return &dto.AccountDeletionResponse{DeletionScheduledAt: deleteAt.Format(time.RFC3339)}, nil
+}
+
+// resumeAccount cancels the pending deletion of a user logging in before
+// it is due, saving the user at once so the purge can't remove them. Once
+// due the account is only waiting for the purge, ErrUserNotFound.
+func (u *UserUsecase) resumeAccount(user *entity.User) error {
+	if user.DeletionScheduledAt.IsZero() {
+		return nil
+	}
+	if !time.Now().Before(user.DeletionScheduledAt) {
+		return appErrors.ErrUserNotFound
+	}
+	if err := u.Repo.CancelDeletion(user.ID); err != nil {
+		utils.LogError("Failed to cancel deletion of user %s: %v", user.ID, err)
+		return appErrors.ErrDatabaseOperation
+	}
+	user.DeletionScheduledAt = time.Time{}
+
+	if u.Audit != nil {
+		if err := u.Audit.Record(user.ID, constants.AUDIT_DELETION_CANCELLED, user.ID, nil); err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_DELETION_CANCELLED, err)
+		}
+	}
+	return nil
+}
+
+// PurgeScheduledDeletions permanently removes the accounts whose deletion
+// was due at now, with everything they own, and returns how many were
+// removed. The owned data goes first, so a failure anywhere leaves the
+// account in place to be logged and retried on the next run.
+func (u *UserUsecase) PurgeScheduledDeletions(now time.Time) (int64, error) {
+	users, err := u.Repo.FindDeletionDue(now, deletionPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, user := range users {
+		var removed int64
+		if u.PurgeOwnerData != nil {
+			if removed, err = u.PurgeOwnerData(user.ID); err != nil {
+				utils.LogError("Failed to delete the data of account %s: %v", user.ID, err)
+				continue
+			}
+		}
+		if err := u.Repo.Delete(user.Email); err != nil {
+			utils.LogError("Failed to delete account %s: %v", user.ID, err)
+			continue
+		}
+		purged++
+
+		// The account is already gone, a stray avatar is only wasted storage
+		if user.AvatarUrl != "" && u.DeleteAvatar != nil {
+			if err := u.DeleteAvatar(user.AvatarUrl); err != nil {
+				utils.LogError("Failed to delete avatar of account %s: %v", user.ID, err)
+			}
+		}
+
+		if u.Audit != nil {
+			err := u.Audit.Record(user.ID, constants.AUDIT_ACCOUNT_DELETED, user.ID, map[string]interface{}{
+				"deletion_scheduled_at": user.DeletionScheduledAt,
+				"owned_records_deleted": removed,
+			})
+			if err != nil {
+				utils.LogError("Failed to record %s: %v", constants.AUDIT_ACCOUNT_DELETED, err)
+			}
+		}
+	}
+	return purged, nil
+}
+
+// StartDeletionWorker runs PurgeScheduledDeletions every interval in the background
+func (u *UserUsecase) StartDeletionWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if _, err := u.PurgeScheduledDeletions(time.Now()); err != nil {
+				utils.LogError("Failed to purge scheduled account deletions: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+)
+
+func setupDeletionUsecase() (*UserUsecase, *mockAuditLogRepository) {
+	uc := setupUserUsecase()
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+	uc.RunAsync = func(task func()) { task() }
+	uc.SendEmail = func(to, subject, body string) error { return nil }
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com", Verified: true})
+	return uc, auditRepo
+}
+
+func TestScheduleDeletion(t *testing.T) {
+	uc, auditRepo := setupDeletionUsecase()
+	uc.DeletionGrace = 7 * 24 * time.Hour
+	sessions := &mockSessionRepository{}
+	uc.Sessions = sessions
+	logged, _ := uc.LoginWithoutPassword("john@example.com")
+	uc.StartSession(logged.Token, "203.0.113.7", "")
+
+	var sentTo, sentBody string
+	uc.SendEmail = func(to, subject, body string) error {
+		sentTo, sentBody = to, body
+		return nil
+	}
+
+	resp, err := uc.ScheduleDeletion("user-123", "john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if until := time.Until(user.DeletionScheduledAt); until < 6*24*time.Hour || until > 7*24*time.Hour {
+		t.Errorf("Expected the deletion a week from now, got %v", user.DeletionScheduledAt)
+	}
+	if resp.DeletionScheduledAt != user.DeletionScheduledAt.Format(time.RFC3339) {
+		t.Errorf("Expected the scheduled date back, got %+v", resp)
+	}
+	if user.TokenVersion != 1 {
+		t.Errorf("Expected every token to be revoked, got version %d", user.TokenVersion)
+	}
+	if listed, _ := uc.ListSessions("user-123", ""); len(listed) != 0 {
+		t.Errorf("Expected every session to be ended, got %+v", listed)
+	}
+	if sentTo != "john@example.com" || !strings.Contains(sentBody, user.DeletionScheduledAt.UTC().Format(time.RFC1123)) {
+		t.Errorf("Expected a confirmation with the date sent to john, got %q: %q", sentTo, sentBody)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_DELETION_SCHEDULED {
+		t.Errorf("Expected a scheduled deletion audit entry, got %v", auditRepo.logs)
+	}
+
+	// Asking again keeps the original date
+	again, err := uc.ScheduleDeletion("user-123", "john@example.com")
+	if err != nil || again.DeletionScheduledAt != resp.DeletionScheduledAt {
+		t.Errorf("Expected the pending deletion back unchanged, got %+v, %v", again, err)
+	}
+}
+
+func TestScheduleDeletion_DefaultGrace(t *testing.T) {
+	uc, _ := setupDeletionUsecase()
+
+	if _, err := uc.ScheduleDeletion("user-123", "john@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if until := time.Until(user.DeletionScheduledAt); until < DefaultDeletionGrace-time.Minute {
+		t.Errorf("Expected the default grace period, got %v", until)
+	}
+}
+
+func TestLogin_CancelsScheduledDeletion(t *testing.T) {
+	uc, auditRepo := setupDeletionUsecase()
+	uc.ScheduleDeletion("user-123", "john@example.com")
+
+	if _, err := uc.LoginWithoutPassword("john@example.com"); err != nil {
+		t.Fatalf("Expected the login to succeed, got %v", err)
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if !user.DeletionScheduledAt.IsZero() {
+		t.Errorf("Expected the deletion to be cancelled, got %v", user.DeletionScheduledAt)
+	}
+	if last := auditRepo.logs[len(auditRepo.logs)-1]; last.Action != constants.AUDIT_DELETION_CANCELLED {
+		t.Errorf("Expected a cancelled deletion audit entry, got %v", last)
+	}
+
+	if purged, _ := uc.PurgeScheduledDeletions(time.Now().Add(DefaultDeletionGrace + time.Hour)); purged != 0 {
+		t.Errorf("Expected the cancelled account to be kept, %d removed", purged)
+	}
+}
+
+func TestLogin_AfterDeletionDeadline(t *testing.T) {
+	uc, _ := setupDeletionUsecase()
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	user.DeletionScheduledAt = time.Now().Add(-time.Minute)
+
+	if _, err := uc.LoginWithoutPassword("john@example.com"); err != appErrors.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound once the deletion is due, got %v", err)
+	}
+	if user.DeletionScheduledAt.IsZero() {
+		t.Error("Expected a due deletion to stay scheduled")
+	}
+}
+
+func TestPurgeScheduledDeletions(t *testing.T) {
+	uc, auditRepo := setupDeletionUsecase()
+	uc.Repo.Create(&entity.User{ID: "user-456", Email: "jane@example.com", DeletionScheduledAt: time.Now().Add(time.Hour)})
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	user.DeletionScheduledAt = time.Now().Add(-time.Minute)
+	user.AvatarUrl = "https://res.cloudinary.com/byow/image/upload/v1/avatars/john.png"
+	var ownersPurged, avatarsDeleted []string
+	uc.PurgeOwnerData = func(userID string) (int64, error) {
+		ownersPurged = append(ownersPurged, userID)
+		return 2, nil
+	}
+	uc.DeleteAvatar = func(url string) error {
+		avatarsDeleted = append(avatarsDeleted, url)
+		return nil
+	}
+
+	purged, err := uc.PurgeScheduledDeletions(time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected only the due account to be removed, %d removed", purged)
+	}
+	if _, err := uc.Repo.FindByEmail("john@example.com"); err != appErrors.ErrUserNotFound {
+		t.Errorf("Expected john to be gone, got %v", err)
+	}
+	if _, err := uc.Repo.FindByEmail("jane@example.com"); err != nil {
+		t.Errorf("Expected jane to be kept until her deadline, got %v", err)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_ACCOUNT_DELETED || auditRepo.logs[0].TargetID != "user-123" {
+		t.Errorf("Expected a deletion audit entry for john, got %v", auditRepo.logs)
+	}
+	if len(ownersPurged) != 1 || ownersPurged[0] != "user-123" {
+		t.Errorf("Expected only john's data to be removed, got %v", ownersPurged)
+	}
+	if len(avatarsDeleted) != 1 || avatarsDeleted[0] != user.AvatarUrl {
+		t.Errorf("Expected john's avatar to be deleted, got %v", avatarsDeleted)
+	}
+}
+
+func TestPurgeScheduledDeletions_OwnedDataFailure(t *testing.T) {
+	uc, auditRepo := setupDeletionUsecase()
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	user.DeletionScheduledAt = time.Now().Add(-time.Minute)
+	uc.PurgeOwnerData = func(userID string) (int64, error) {
+		return 0, appErrors.ErrDatabaseOperation
+	}
+
+	if purged, err := uc.PurgeScheduledDeletions(time.Now()); err != nil || purged != 0 {
+		t.Errorf("Expected nothing purged, got %d, %v", purged, err)
+	}
+	if _, err := uc.Repo.FindByEmail("john@example.com"); err != nil {
+		t.Errorf("Expected john to be kept for the next run, got %v", err)
+	}
+	if len(auditRepo.logs) != 0 {
+		t.Errorf("Expected no deletion audit entry, got %v", auditRepo.logs)
+	}
+}
@@ -0,0 +1,512 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/featureflags"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
+	"github.com/buildyow/byow-user-service/utils"
+)
+
+// DefaultImpersonationExpire is how many minutes an impersonation token
+// lasts unless configured otherwise
+const DefaultImpersonationExpire = 15
+
+// AdminUsecase holds support operations that span users and companies.
+// Callers must be gated behind the admin role.
+type AdminUsecase struct {
+	UserRepo    repository.UserRepository
+	CompanyRepo repository.CompanyRepository
+	Audit       *AuditUsecase
+	Flags       *featureflags.Flags
+
+	// BuildIndexes creates the database indexes and returns their names,
+	// bounded by IndexTimeout (one minute when zero)
+	BuildIndexes func(ctx context.Context) ([]string, error)
+	IndexTimeout time.Duration
+
+	// PreviousDecryptKey is the DECRYPT_KEY before the last rotation, only
+	// needed to re-encrypt OTPs
+	PreviousDecryptKey string
+
+	// JWTSecret signs impersonation tokens, which last ImpersonationExpire
+	// minutes (DefaultImpersonationExpire when zero)
+	JWTSecret           string
+	ImpersonationExpire int
+
+	indexMu sync.Mutex
+}
+
+// FeatureFlags returns the flags the service is running with
+func (u *AdminUsecase) FeatureFlags() *featureflags.Flags {
+	if u.Flags == nil {
+		return featureflags.Default()
+	}
+	return u.Flags
+}
+
+// MergeAccounts folds an orphaned unverified account into a verified one:
+// the merged user's companies move to the kept user and the merged user is
+// deleted. The repositories share no session, so instead of a transaction
+// the steps run in an order that is safe to retry: reassigning is idempotent
+// and the user is only deleted once nothing points at it.
+func (u *AdminUsecase) MergeAccounts(actorID string, keepEmail string, mergeEmail string) (int64, error) {
+	if keepEmail == "" || mergeEmail == "" {
+		return 0, appErrors.ErrAllFieldsRequired
+	}
+	if keepEmail == mergeEmail {
+		return 0, appErrors.NewBadRequestError("Cannot merge an account into itself")
+	}
+
+	keep, err := u.UserRepo.FindByEmail(keepEmail)
+	if err != nil {
+		return 0, appErrors.ErrUserNotFound
+	}
+	merge, err := u.UserRepo.FindByEmail(mergeEmail)
+	if err != nil {
+		return 0, appErrors.ErrUserNotFound
+	}
+	if !keep.Verified {
+		return 0, appErrors.NewBadRequestError("Account to keep must be verified")
+	}
+	if merge.Verified {
+		return 0, appErrors.NewBadRequestError("Only unverified accounts can be merged")
+	}
+
+	reassigned, err := u.CompanyRepo.ReassignOwner(merge.ID, keep.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := u.UserRepo.Delete(merge.Email); err != nil {
+		return reassigned, err
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_ACCOUNTS_MERGED, keep.ID, map[string]interface{}{
+			"kept_email":           keep.Email,
+			"merged_email":         merge.Email,
+			"merged_user_id":       merge.ID,
+			"companies_reassigned": reassigned,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for account merge: %v", err)
+		}
+	}
+	return reassigned, nil
+}
+
+// RebuildIndexes runs the index creation on demand. Only one rebuild runs at
+// a time, a concurrent call fails with ErrOperationInProgress.
+func (u *AdminUsecase) RebuildIndexes(actorID string) ([]string, error) {
+	if u.BuildIndexes == nil {
+		return nil, appErrors.NewInternalError("Index rebuild is not configured")
+	}
+	if !u.indexMu.TryLock() {
+		return nil, appErrors.ErrOperationInProgress
+	}
+	defer u.indexMu.Unlock()
+
+	timeout := u.IndexTimeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	indexes, err := u.BuildIndexes(ctx)
+	if err != nil {
+		utils.LogError("Index rebuild failed: %v", err)
+		return nil, appErrors.NewInternalError("Failed to rebuild indexes")
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_INDEXES_REBUILT, "", map[string]interface{}{
+			"indexes": indexes,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for index rebuild: %v", err)
+		}
+	}
+	return indexes, nil
+}
+
+// OTP key rotation modes for ReencryptOTPs
+const (
+	OTPModeReencrypt  = "reencrypt"
+	OTPModeInvalidate = "invalidate"
+)
+
+// ReencryptOTPs moves unexpired OTPs to the current DECRYPT_KEY after a
+// rotation. In reencrypt mode each OTP the current key can't open is
+// decrypted with PreviousDecryptKey and encrypted again, OTPs neither key
+// opens are invalidated. In invalidate mode every active OTP is cleared and
+// users simply request a new one.
+func (u *AdminUsecase) ReencryptOTPs(ctx context.Context, actorID, mode string) (*dto.ReencryptOTPsResponse, error) {
+	if mode == "" {
+		mode = OTPModeReencrypt
+	}
+	if mode != OTPModeReencrypt && mode != OTPModeInvalidate {
+		return nil, appErrors.NewValidationError("Mode must be reencrypt or invalidate")
+	}
+	if mode == OTPModeReencrypt && len(u.PreviousDecryptKey) != utils.DecryptKeySize {
+		return nil, appErrors.NewValidationError("DECRYPT_KEY_PREVIOUS must be set to the old key to re-encrypt, or use invalidate mode")
+	}
+
+	result := &dto.ReencryptOTPsResponse{Mode: mode}
+	err := u.UserRepo.ForEachActiveOTP(ctx, func(user *entity.User) error {
+		if mode == OTPModeReencrypt {
+			if _, err := utils.Decrypt(user.OTP); err == nil {
+				result.Skipped++
+				return nil
+			}
+			if otp, err := utils.DecryptWithKey(user.OTP, u.PreviousDecryptKey); err == nil {
+				encrypted, err := utils.Encrypt(otp)
+				if err != nil {
+					return err
+				}
+				user.OTP = encrypted
+				if err := u.UserRepo.UpdateOTP(user); err != nil {
+					return err
+				}
+				result.Reencrypted++
+				return nil
+			}
+		}
+
+		user.OTP = ""
+		user.OTPType = ""
+		user.OTPExpiresAt = time.Time{}
+		user.OTPAttempts = 0
+		if err := u.UserRepo.UpdateOTP(user); err != nil {
+			return err
+		}
+		result.Invalidated++
+		return nil
+	})
+	if err != nil {
+		utils.LogError("OTP %s stopped after %d re-encrypted and %d invalidated: %v", mode, result.Reencrypted, result.Invalidated, err)
+		return nil, appErrors.ErrDatabaseOperation
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_OTPS_REENCRYPTED, "", map[string]interface{}{
+			"mode":        mode,
+			"reencrypted": result.Reencrypted,
+			"invalidated": result.Invalidated,
+			"skipped":     result.Skipped,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for OTP re-encryption: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// ResetOTPAttempts clears the wrong OTP attempts of a locked out user so
+// support can let them retry without waiting for the OTP to expire. The OTP
+// itself is kept, the user can still enter the code they were sent.
+func (u *AdminUsecase) ResetOTPAttempts(actorID, userID string) (*dto.ResetOTPAttemptsResponse, error) {
+	user, err := u.UserRepo.FindByID(userID)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+
+	result := &dto.ResetOTPAttemptsResponse{Email: user.Email, PreviousAttempts: user.OTPAttempts}
+	if user.OTPAttempts == 0 {
+		return result, nil
+	}
+	user.OTPAttempts = 0
+	if err := u.UserRepo.UpdateOTP(user); err != nil {
+		return nil, err
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_OTP_ATTEMPTS_RESET, user.ID, map[string]interface{}{
+			"email":             user.Email,
+			"previous_attempts": result.PreviousAttempts,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for OTP attempts reset: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// toAdminUserResponse is the admin view of user, a user without a role is
+// shown as a plain user
+func toAdminUserResponse(user *entity.User) dto.AdminUserResponse {
+	role := user.Role
+	if role == "" {
+		role = constants.ROLE_USER
+	}
+	response := dto.AdminUserResponse{
+		ID:              user.ID,
+		Fullname:        user.Fullname,
+		Email:           user.Email,
+		PhoneNumber:     user.PhoneNumber,
+		AvatarUrl:       user.AvatarUrl,
+		Verified:        user.Verified,
+		OnBoarded:       user.OnBoarded,
+		Role:            role,
+		CreatedAt:       user.CreatedAt.Format(time.RFC3339),
+		Suspended:       user.Suspended(),
+		SuspendedReason: user.SuspendedReason,
+	}
+	if !user.LastLoginAt.IsZero() {
+		response.LastLoginAt = user.LastLoginAt.Format(time.RFC3339)
+	}
+	if user.Suspended() {
+		response.SuspendedAt = user.SuspendedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// ListUsers returns a page of users matching keyword in their email, phone
+// number or name, optionally narrowed to suspended or active accounts
+func (u *AdminUsecase) ListUsers(keyword string, suspended *bool, limit int64, offset int64) ([]dto.AdminUserResponse, int64, error) {
+	users, rowCount, err := u.UserRepo.FindAll(repository.UserFilter{Keyword: keyword, Suspended: suspended}, limit, offset)
+	if err != nil {
+		return nil, 0, appErrors.ErrFetchFailed
+	}
+	responses := make([]dto.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, toAdminUserResponse(user))
+	}
+	return responses, rowCount, nil
+}
+
+// GetUser returns the user with the id
+func (u *AdminUsecase) GetUser(userID string) (*dto.AdminUserResponse, error) {
+	user, err := u.UserRepo.FindByID(userID)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	response := toAdminUserResponse(user)
+	return &response, nil
+}
+
+// SuspendUser stops the user logging in and bumps their token version, so
+// the tokens they hold are refused at once. Admins can't suspend themselves.
+func (u *AdminUsecase) SuspendUser(actorID, userID, reason string) (*dto.AdminUserResponse, error) {
+	user, err := u.UserRepo.FindByID(userID)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	if user.ID == actorID {
+		return nil, appErrors.NewValidationError("You can't suspend your own account")
+	}
+	if user.Suspended() {
+		return nil, appErrors.NewConflictError("User is already suspended")
+	}
+
+	suspendedAt, reason := time.Now(), strings.TrimSpace(reason)
+	version, err := u.UserRepo.Suspend(user.ID, suspendedAt, reason)
+	if err != nil {
+		utils.LogError("Failed to suspend user %s: %v", user.ID, err)
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	user.SuspendedAt = suspendedAt
+	user.SuspendedReason = reason
+	user.TokenVersion = version
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_USER_SUSPENDED, user.ID, map[string]interface{}{
+			"email":  user.Email,
+			"reason": user.SuspendedReason,
+		})
+		if err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_USER_SUSPENDED, err)
+		}
+	}
+	response := toAdminUserResponse(user)
+	return &response, nil
+}
+
+// ReactivateUser lifts a suspension, the user logs in again to get a token
+func (u *AdminUsecase) ReactivateUser(actorID, userID string) (*dto.AdminUserResponse, error) {
+	user, err := u.UserRepo.FindByID(userID)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	if !user.Suspended() {
+		return nil, appErrors.NewConflictError("User is not suspended")
+	}
+
+	if err := u.UserRepo.Reactivate(user.ID); err != nil {
+		utils.LogError("Failed to reactivate user %s: %v", user.ID, err)
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	user.SuspendedAt = time.Time{}
+	user.SuspendedReason = ""
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_USER_REACTIVATED, user.ID, map[string]interface{}{
+			"email": user.Email,
+		})
+		if err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_USER_REACTIVATED, err)
+		}
+	}
+	response := toAdminUserResponse(user)
+	return &response, nil
+}
+
+// Impersonate issues a short-lived token for the user with the id that also
+// names the admin, so support can see the account as its owner does. Every
+// request made with it is audited. Admins and suspended accounts can't be
+// impersonated.
+func (u *AdminUsecase) Impersonate(actor Actor, userID string) (*dto.ImpersonationResponse, error) {
+	if err := Authorize(actor, constants.PERMISSION_ADMIN_IMPERSONATE); err != nil {
+		return nil, err
+	}
+	user, err := u.UserRepo.FindByID(userID)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	if user.ID == actor.ID {
+		return nil, appErrors.NewValidationError("You can't impersonate yourself")
+	}
+	if user.Role == constants.ROLE_ADMIN {
+		return nil, appErrors.NewValidationError("Admins can't be impersonated")
+	}
+	if user.Suspended() {
+		return nil, appErrors.ErrAccountSuspended
+	}
+
+	minutes := u.ImpersonationExpire
+	if minutes <= 0 {
+		minutes = DefaultImpersonationExpire
+	}
+	token, err := jwt.GenerateImpersonationToken(user.ID, user.Email, user.PhoneNumber, user.Role, user.TokenVersion, actor.ID, u.JWTSecret, minutes)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to issue impersonation token")
+	}
+	expiresAt := time.Now().Add(time.Duration(minutes) * time.Minute)
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actor.ID, constants.AUDIT_IMPERSONATION, user.ID, map[string]interface{}{
+			"email":      user.Email,
+			"expires_at": expiresAt,
+		})
+		if err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_IMPERSONATION, err)
+		}
+	}
+	return &dto.ImpersonationResponse{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Token:     token,
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// assignableRoles are the roles SetRole accepts
+var assignableRoles = map[string]bool{
+	constants.ROLE_USER:  true,
+	constants.ROLE_ADMIN: true,
+}
+
+// SetRole gives the user role and bumps their token version, so tokens
+// carrying the old role are refused at once instead of at expiry. Admins
+// can't change their own role, the last admin would lock everyone out.
+func (u *AdminUsecase) SetRole(actorID, userID, role string) (*dto.SetRoleResponse, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !assignableRoles[role] {
+		return nil, appErrors.NewValidationError(fmt.Sprintf("Unknown role, expected %s or %s", constants.ROLE_USER, constants.ROLE_ADMIN))
+	}
+	user, err := u.UserRepo.FindByID(userID)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	if user.ID == actorID {
+		return nil, appErrors.NewValidationError("You can't change your own role")
+	}
+
+	previousRole := user.Role
+	if previousRole == "" {
+		previousRole = constants.ROLE_USER
+	}
+	result := &dto.SetRoleResponse{Email: user.Email, Role: role, PreviousRole: previousRole, TokenVersion: user.TokenVersion}
+	if role == previousRole {
+		return result, nil
+	}
+
+	version, err := u.UserRepo.UpdateRole(user.ID, role)
+	if err != nil {
+		utils.LogError("Failed to change role of user %s: %v", user.ID, err)
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	user.Role = role
+	user.TokenVersion = version
+	result.TokenVersion = version
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_ROLE_CHANGED, user.ID, map[string]interface{}{
+			"email":         user.Email,
+			"role":          role,
+			"previous_role": previousRole,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for role change: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// ExportUsers writes every user to w as newline-delimited JSON while reading
+// them from the repository, so memory use does not grow with the user count
+func (u *AdminUsecase) ExportUsers(ctx context.Context, actorID string, w io.Writer) (int, error) {
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(interface{ Flush() })
+
+	count := 0
+	err := u.UserRepo.ForEach(ctx, func(user *entity.User) error {
+		record := dto.UserExportRecord{
+			ID:          user.ID,
+			Fullname:    user.Fullname,
+			Email:       user.Email,
+			PhoneNumber: user.PhoneNumber,
+			AvatarUrl:   user.AvatarUrl,
+			OnBoarded:   user.OnBoarded,
+			Verified:    user.Verified,
+			Role:        user.Role,
+			CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+		count++
+		if flusher != nil && count%100 == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if flusher != nil {
+		flusher.Flush()
+	}
+	if err != nil {
+		utils.LogError("User export stopped after %d records: %v", count, err)
+		return count, appErrors.ErrDatabaseOperation
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actorID, constants.AUDIT_USERS_EXPORTED, "", map[string]interface{}{
+			"count": count,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for user export: %v", err)
+		}
+	}
+	return count, nil
+}
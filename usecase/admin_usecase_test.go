@@ -0,0 +1,544 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
+	"github.com/buildyow/byow-user-service/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupAdminUsecase() (*AdminUsecase, *mockUserRepository, *mockCompanyRepository, *mockAuditLogRepository) {
+	userRepo := &mockUserRepository{users: make(map[string]*entity.User)}
+	companyRepo := &mockCompanyRepository{companies: make(map[string]*entity.Company)}
+	auditRepo := &mockAuditLogRepository{}
+	uc := &AdminUsecase{
+		UserRepo:    userRepo,
+		CompanyRepo: companyRepo,
+		Audit:       &AuditUsecase{Repo: auditRepo},
+	}
+	return uc, userRepo, companyRepo, auditRepo
+}
+
+func TestAdminUsecase_MergeAccounts_Success(t *testing.T) {
+	uc, userRepo, companyRepo, auditRepo := setupAdminUsecase()
+
+	userRepo.users["john@example.com"] = &entity.User{ID: "keep-id", Email: "john@example.com", Verified: true}
+	userRepo.users["John@Example.com"] = &entity.User{ID: "merge-id", Email: "John@Example.com"}
+
+	orphaned := []*entity.Company{
+		{ID: primitive.NewObjectID(), UserID: "merge-id", CompanyName: "Orphan One"},
+		{ID: primitive.NewObjectID(), UserID: "merge-id", CompanyName: "Orphan Two"},
+	}
+	untouched := &entity.Company{ID: primitive.NewObjectID(), UserID: "someone-else", CompanyName: "Other"}
+	for _, company := range append(orphaned, untouched) {
+		companyRepo.companies[company.ID.Hex()] = company
+	}
+
+	reassigned, err := uc.MergeAccounts("admin-id", "john@example.com", "John@Example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reassigned != 2 {
+		t.Errorf("Expected 2 companies reassigned, got %d", reassigned)
+	}
+	for _, company := range orphaned {
+		if company.UserID != "keep-id" {
+			t.Errorf("Expected %s to belong to the kept account, got %s", company.CompanyName, company.UserID)
+		}
+	}
+	if untouched.UserID != "someone-else" {
+		t.Error("Expected other users' companies to be left alone")
+	}
+	if _, exists := userRepo.users["John@Example.com"]; exists {
+		t.Error("Expected merged account to be removed")
+	}
+	if _, exists := userRepo.users["john@example.com"]; !exists {
+		t.Error("Expected kept account to remain")
+	}
+
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_ACCOUNTS_MERGED {
+		t.Fatalf("Expected one accounts_merged audit entry, got %v", auditRepo.logs)
+	}
+	if auditRepo.logs[0].ActorID != "admin-id" {
+		t.Errorf("Expected audit actor admin-id, got %s", auditRepo.logs[0].ActorID)
+	}
+}
+
+func TestAdminUsecase_MergeAccounts_Rejections(t *testing.T) {
+	tests := []struct {
+		name       string
+		keep       *entity.User
+		merge      *entity.User
+		keepEmail  string
+		mergeEmail string
+		status     int
+	}{
+		{
+			name:       "same account",
+			keep:       &entity.User{ID: "keep-id", Email: "john@example.com", Verified: true},
+			keepEmail:  "john@example.com",
+			mergeEmail: "john@example.com",
+			status:     400,
+		},
+		{
+			name:       "merged account missing",
+			keep:       &entity.User{ID: "keep-id", Email: "john@example.com", Verified: true},
+			keepEmail:  "john@example.com",
+			mergeEmail: "ghost@example.com",
+			status:     404,
+		},
+		{
+			name:       "kept account unverified",
+			keep:       &entity.User{ID: "keep-id", Email: "john@example.com"},
+			merge:      &entity.User{ID: "merge-id", Email: "dup@example.com"},
+			keepEmail:  "john@example.com",
+			mergeEmail: "dup@example.com",
+			status:     400,
+		},
+		{
+			name:       "merged account verified",
+			keep:       &entity.User{ID: "keep-id", Email: "john@example.com", Verified: true},
+			merge:      &entity.User{ID: "merge-id", Email: "dup@example.com", Verified: true},
+			keepEmail:  "john@example.com",
+			mergeEmail: "dup@example.com",
+			status:     400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, userRepo, _, auditRepo := setupAdminUsecase()
+			userRepo.users[tt.keep.Email] = tt.keep
+			if tt.merge != nil {
+				userRepo.users[tt.merge.Email] = tt.merge
+			}
+			usersBefore := len(userRepo.users)
+
+			_, err := uc.MergeAccounts("admin-id", tt.keepEmail, tt.mergeEmail)
+			appErr, ok := err.(*appErrors.AppError)
+			if !ok || appErr.Status != tt.status {
+				t.Errorf("Expected %d error, got %v", tt.status, err)
+			}
+			if len(userRepo.users) != usersBefore {
+				t.Error("Expected no account to be deleted")
+			}
+			if len(auditRepo.logs) != 0 {
+				t.Error("Expected no audit entry for a rejected merge")
+			}
+		})
+	}
+}
+
+func TestAdminUsecase_RebuildIndexes_Success(t *testing.T) {
+	uc, _, _, auditRepo := setupAdminUsecase()
+	uc.IndexTimeout = time.Second
+	uc.BuildIndexes = func(ctx context.Context) ([]string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected the rebuild to run with a deadline")
+		}
+		return []string{"email_unique", "company_email_unique"}, nil
+	}
+
+	indexes, err := uc.RebuildIndexes("admin-id")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(indexes) != 2 {
+		t.Errorf("Expected 2 index names, got %v", indexes)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_INDEXES_REBUILT {
+		t.Errorf("Expected an index rebuild audit entry, got %v", auditRepo.logs)
+	}
+}
+
+func TestAdminUsecase_RebuildIndexes_Failure(t *testing.T) {
+	uc, _, _, auditRepo := setupAdminUsecase()
+	uc.BuildIndexes = func(ctx context.Context) ([]string, error) {
+		return nil, errors.New("index options conflict")
+	}
+
+	_, err := uc.RebuildIndexes("admin-id")
+	appErr, ok := err.(*appErrors.AppError)
+	if !ok || appErr.Status != 500 {
+		t.Fatalf("Expected 500 error, got %v", err)
+	}
+	if strings.Contains(appErr.Error(), "index options conflict") {
+		t.Errorf("Expected the database error to stay out of the response, got %q", appErr.Error())
+	}
+	if len(auditRepo.logs) != 0 {
+		t.Error("Expected no audit entry for a failed rebuild")
+	}
+}
+
+func TestAdminUsecase_RebuildIndexes_RejectsConcurrent(t *testing.T) {
+	uc, _, _, _ := setupAdminUsecase()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	uc.BuildIndexes = func(ctx context.Context) ([]string, error) {
+		close(started)
+		<-release
+		return []string{"email_unique"}, nil
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := uc.RebuildIndexes("admin-id")
+		done <- err
+	}()
+	<-started
+
+	if _, err := uc.RebuildIndexes("admin-id"); err != appErrors.ErrOperationInProgress {
+		t.Errorf("Expected ErrOperationInProgress while a rebuild runs, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected first rebuild to succeed, got %v", err)
+	}
+}
+
+func TestAdminUsecase_RebuildIndexes_NotConfigured(t *testing.T) {
+	uc, _, _, _ := setupAdminUsecase()
+
+	if _, err := uc.RebuildIndexes("admin-id"); err == nil {
+		t.Error("Expected an error when no index builder is configured")
+	}
+}
+
+func TestAdminUsecase_ExportUsers(t *testing.T) {
+	uc, userRepo, _, auditRepo := setupAdminUsecase()
+	userRepo.users["a@example.com"] = &entity.User{ID: "a", Email: "a@example.com", Password: "hash", OTP: "otp"}
+	userRepo.users["b@example.com"] = &entity.User{ID: "b", Email: "b@example.com", Password: "hash"}
+
+	var buf bytes.Buffer
+	count, err := uc.ExportUsers(context.Background(), "admin-id", &buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 2 || strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("Expected 2 lines, got %d: %s", count, buf.String())
+	}
+	if strings.Contains(buf.String(), "hash") || strings.Contains(buf.String(), "otp") {
+		t.Errorf("Expected sensitive fields to be left out, got %s", buf.String())
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_USERS_EXPORTED {
+		t.Errorf("Expected an export audit entry, got %v", auditRepo.logs)
+	}
+}
+
+// setupRotatedOTPs returns users holding OTPs encrypted under the old key,
+// the new key and neither, plus an expired one, with DECRYPT_KEY rotated
+func setupRotatedOTPs(t *testing.T) (*AdminUsecase, *mockUserRepository, *mockAuditLogRepository) {
+	uc, userRepo, _, auditRepo := setupAdminUsecase()
+	oldKey := "12345678901234567890123456789012"
+	newKey := "abcdefghijklmnopqrstuvwxyz123456"
+	originalKey := os.Getenv("DECRYPT_KEY")
+	t.Cleanup(func() { os.Setenv("DECRYPT_KEY", originalKey) })
+
+	os.Setenv("DECRYPT_KEY", oldKey)
+	underOld, _ := utils.Encrypt("111111")
+	expired, _ := utils.Encrypt("222222")
+	os.Setenv("DECRYPT_KEY", newKey)
+	underNew, _ := utils.Encrypt("333333")
+	uc.PreviousDecryptKey = oldKey
+
+	active := time.Now().Add(5 * time.Minute)
+	userRepo.users["old@example.com"] = &entity.User{ID: "old", Email: "old@example.com", Password: "hashed", PasswordResetTokenHash: "reset-hash", OTP: underOld, OTPType: constants.VERIFICATION, OTPExpiresAt: active, OTPAttempts: 1}
+	userRepo.users["new@example.com"] = &entity.User{ID: "new", Email: "new@example.com", OTP: underNew, OTPType: constants.VERIFICATION, OTPExpiresAt: active}
+	userRepo.users["bad@example.com"] = &entity.User{ID: "bad", Email: "bad@example.com", OTP: "not-encrypted", OTPType: constants.VERIFICATION, OTPExpiresAt: active}
+	userRepo.users["expired@example.com"] = &entity.User{ID: "expired", Email: "expired@example.com", OTP: expired, OTPType: constants.VERIFICATION, OTPExpiresAt: time.Now().Add(-time.Minute)}
+	userRepo.users["none@example.com"] = &entity.User{ID: "none", Email: "none@example.com"}
+	return uc, userRepo, auditRepo
+}
+
+func TestAdminUsecase_ReencryptOTPs_Reencrypt(t *testing.T) {
+	uc, userRepo, auditRepo := setupRotatedOTPs(t)
+	expiredOTP := userRepo.users["expired@example.com"].OTP
+
+	result, err := uc.ReencryptOTPs(context.Background(), "admin-id", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Mode != OTPModeReencrypt || result.Reencrypted != 1 || result.Skipped != 1 || result.Invalidated != 1 {
+		t.Errorf("Unexpected counts %+v", result)
+	}
+
+	old := userRepo.users["old@example.com"]
+	if otp, err := utils.Decrypt(old.OTP); err != nil || otp != "111111" {
+		t.Errorf("Expected the old OTP to open with the new key, got %q, %v", otp, err)
+	}
+	if old.OTPType != constants.VERIFICATION || old.OTPAttempts != 1 {
+		t.Errorf("Expected the rest of the OTP state to be kept, got %+v", old)
+	}
+	if old.Password != "hashed" || old.PasswordResetTokenHash != "reset-hash" {
+		t.Errorf("Expected the password and reset token to be kept, got %+v", old)
+	}
+	if otp, _ := utils.Decrypt(userRepo.users["new@example.com"].OTP); otp != "333333" {
+		t.Errorf("Expected the current OTP to be left alone, got %q", otp)
+	}
+	if bad := userRepo.users["bad@example.com"]; bad.OTP != "" || !bad.OTPExpiresAt.IsZero() {
+		t.Errorf("Expected the unreadable OTP to be invalidated, got %+v", bad)
+	}
+	if userRepo.users["expired@example.com"].OTP != expiredOTP {
+		t.Error("Expected expired OTPs to be left alone")
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_OTPS_REENCRYPTED {
+		t.Errorf("Expected a re-encryption audit entry, got %v", auditRepo.logs)
+	}
+}
+
+func TestAdminUsecase_ReencryptOTPs_Invalidate(t *testing.T) {
+	uc, userRepo, _ := setupRotatedOTPs(t)
+	uc.PreviousDecryptKey = "" // not needed to invalidate
+
+	result, err := uc.ReencryptOTPs(context.Background(), "admin-id", OTPModeInvalidate)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Invalidated != 3 || result.Reencrypted != 0 || result.Skipped != 0 {
+		t.Errorf("Expected every active OTP invalidated, got %+v", result)
+	}
+	for _, email := range []string{"old@example.com", "new@example.com", "bad@example.com"} {
+		user := userRepo.users[email]
+		if user.OTP != "" || user.OTPType != "" || !user.OTPExpiresAt.IsZero() || user.OTPAttempts != 0 {
+			t.Errorf("Expected %s to have no OTP, got %+v", email, user)
+		}
+	}
+	if old := userRepo.users["old@example.com"]; old.Password != "hashed" {
+		t.Errorf("Expected the password to be kept, got %+v", old)
+	}
+}
+
+func TestAdminUsecase_ReencryptOTPs_Rejections(t *testing.T) {
+	uc, _, _ := setupRotatedOTPs(t)
+
+	if _, err := uc.ReencryptOTPs(context.Background(), "admin-id", "rotate"); err == nil {
+		t.Error("Expected an unknown mode to be rejected")
+	}
+
+	uc.PreviousDecryptKey = ""
+	_, err := uc.ReencryptOTPs(context.Background(), "admin-id", OTPModeReencrypt)
+	if appErr, ok := appErrors.IsAppError(err); !ok || appErr.Code != "VALIDATION_ERROR" {
+		t.Errorf("Expected a validation error without the previous key, got %v", err)
+	}
+}
+
+func TestAdminUsecase_ResetOTPAttempts(t *testing.T) {
+	uc, userRepo, _, auditRepo := setupAdminUsecase()
+	users := setupUserUsecase()
+	users.Repo = userRepo
+
+	encryptedOTP, _ := utils.Encrypt("123456")
+	userRepo.users["john@example.com"] = &entity.User{
+		ID:           "user-1",
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPType:      constants.VERIFICATION,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+		OTPAttempts:  constants.MaxOTPAttempts,
+	}
+	if _, err := users.PeekOTP("john@example.com", "123456"); err != appErrors.ErrTooManyOTPAttempts {
+		t.Fatalf("Expected the user to be locked out, got %v", err)
+	}
+
+	result, err := uc.ResetOTPAttempts("admin-id", "user-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.PreviousAttempts != constants.MaxOTPAttempts {
+		t.Errorf("Expected %d previous attempts, got %d", constants.MaxOTPAttempts, result.PreviousAttempts)
+	}
+	if valid, err := users.PeekOTP("john@example.com", "123456"); err != nil || !valid {
+		t.Errorf("Expected the same code to be accepted after the reset, got %v, %v", valid, err)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_OTP_ATTEMPTS_RESET || auditRepo.logs[0].TargetID != "user-1" {
+		t.Errorf("Expected a reset audit entry for the user, got %v", auditRepo.logs)
+	}
+
+	if _, err := uc.ResetOTPAttempts("admin-id", "user-404"); err != appErrors.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestAdminUsecase_SetRole(t *testing.T) {
+	uc, userRepo, _, auditRepo := setupAdminUsecase()
+	userRepo.users["john@example.com"] = &entity.User{ID: "user-1", Email: "john@example.com", Role: constants.ROLE_ADMIN, TokenVersion: 1}
+
+	result, err := uc.SetRole("admin-id", "user-1", " User ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Role != constants.ROLE_USER || result.PreviousRole != constants.ROLE_ADMIN || result.TokenVersion != 2 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	user := userRepo.users["john@example.com"]
+	if user.Role != constants.ROLE_USER || user.TokenVersion != 2 {
+		t.Errorf("Expected the user role stored and the token version bumped, got %q and %d", user.Role, user.TokenVersion)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_ROLE_CHANGED || auditRepo.logs[0].TargetID != "user-1" {
+		t.Errorf("Expected a role change audit entry for the user, got %v", auditRepo.logs)
+	}
+
+	// Setting the role the user already has changes nothing
+	if _, err := uc.SetRole("admin-id", "user-1", constants.ROLE_USER); err != nil || user.TokenVersion != 2 || len(auditRepo.logs) != 1 {
+		t.Errorf("Expected an unchanged role to keep the token version, got %d, %v", user.TokenVersion, err)
+	}
+
+	if _, err := uc.SetRole("admin-id", "user-1", "owner"); err == nil {
+		t.Error("Expected an unknown role to be rejected")
+	}
+	if _, err := uc.SetRole("user-1", "user-1", constants.ROLE_ADMIN); err == nil {
+		t.Error("Expected changing your own role to be rejected")
+	}
+	if _, err := uc.SetRole("admin-id", "user-404", constants.ROLE_ADMIN); err != appErrors.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestAdminUsecase_ListUsers(t *testing.T) {
+	uc, userRepo, _, _ := setupAdminUsecase()
+	userRepo.users["john@example.com"] = &entity.User{ID: "user-1", Email: "john@example.com", Fullname: "John Doe", PhoneNumber: "628111", Password: "hashed"}
+	userRepo.users["jane@example.com"] = &entity.User{ID: "user-2", Email: "jane@example.com", Fullname: "Jane Roe", PhoneNumber: "628222", Role: constants.ROLE_ADMIN}
+	userRepo.users["spam@example.com"] = &entity.User{ID: "user-3", Email: "spam@example.com", Fullname: "Spammer", SuspendedAt: time.Now()}
+
+	users, total, err := uc.ListUsers("", nil, 2, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 3 || len(users) != 2 {
+		t.Errorf("Expected a page of 2 out of 3 users, got %d of %d", len(users), total)
+	}
+
+	tests := []struct {
+		name      string
+		keyword   string
+		suspended *bool
+		expected  string
+	}{
+		{"by email", "jane@", nil, "jane@example.com"},
+		{"by phone", "628111", nil, "john@example.com"},
+		{"by name", "doe", nil, "john@example.com"},
+		{"suspended only", "", func() *bool { b := true; return &b }(), "spam@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, total, err := uc.ListUsers(tt.keyword, tt.suspended, 10, 0)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if total != 1 || users[0].Email != tt.expected {
+				t.Errorf("Expected only %s, got %+v", tt.expected, users)
+			}
+		})
+	}
+
+	users, _, _ = uc.ListUsers("jane", nil, 10, 0)
+	if users[0].Role != constants.ROLE_ADMIN || users[0].Suspended {
+		t.Errorf("Unexpected user %+v", users[0])
+	}
+}
+
+func TestAdminUsecase_SuspendUser(t *testing.T) {
+	uc, userRepo, _, auditRepo := setupAdminUsecase()
+	password, _ := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.MinCost)
+	userRepo.users["john@example.com"] = &entity.User{ID: "user-1", Email: "john@example.com", Password: string(password), Verified: true, TokenVersion: 1}
+	users := &UserUsecase{Repo: userRepo, JWTSecret: "test-secret", JWTExpire: 60}
+
+	suspended, err := uc.SuspendUser("admin-id", "user-1", " Spam reports ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !suspended.Suspended || suspended.SuspendedReason != "Spam reports" || suspended.SuspendedAt == "" {
+		t.Errorf("Unexpected result %+v", suspended)
+	}
+	if userRepo.users["john@example.com"].TokenVersion != 2 {
+		t.Error("Expected the token version to be bumped so existing tokens are refused")
+	}
+	if _, err := users.Login("john@example.com", "Password123!"); err != appErrors.ErrAccountSuspended {
+		t.Errorf("Expected a suspended user's login to be refused, got %v", err)
+	}
+	if _, err := uc.SuspendUser("admin-id", "user-1", ""); err == nil {
+		t.Error("Expected suspending twice to be rejected")
+	}
+	if _, err := uc.SuspendUser("user-1", "user-1", ""); err == nil {
+		t.Error("Expected suspending yourself to be rejected")
+	}
+
+	reactivated, err := uc.ReactivateUser("admin-id", "user-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reactivated.Suspended || reactivated.SuspendedReason != "" {
+		t.Errorf("Expected the suspension to be lifted, got %+v", reactivated)
+	}
+	if _, err := users.Login("john@example.com", "Password123!"); err != nil {
+		t.Errorf("Expected a reactivated user to log in, got %v", err)
+	}
+	if _, err := uc.ReactivateUser("admin-id", "user-1"); err == nil {
+		t.Error("Expected reactivating an active user to be rejected")
+	}
+	if _, err := uc.SuspendUser("admin-id", "user-404", ""); err != appErrors.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	if len(auditRepo.logs) != 2 || auditRepo.logs[0].Action != constants.AUDIT_USER_SUSPENDED || auditRepo.logs[1].Action != constants.AUDIT_USER_REACTIVATED {
+		t.Errorf("Expected the suspension and reactivation to be audited, got %v", auditRepo.logs)
+	}
+}
+
+func TestAdminUsecase_Impersonate(t *testing.T) {
+	uc, userRepo, _, auditRepo := setupAdminUsecase()
+	uc.JWTSecret = "test-secret"
+	userRepo.users["john@example.com"] = &entity.User{ID: "user-1", Email: "john@example.com", TokenVersion: 3}
+	userRepo.users["boss@example.com"] = &entity.User{ID: "admin-1", Email: "boss@example.com", Role: constants.ROLE_ADMIN}
+	userRepo.users["jane@example.com"] = &entity.User{ID: "admin-2", Email: "jane@example.com", Role: constants.ROLE_ADMIN}
+	userRepo.users["spam@example.com"] = &entity.User{ID: "user-2", Email: "spam@example.com", SuspendedAt: time.Now()}
+	admin := Actor{ID: "admin-1", Role: constants.ROLE_ADMIN}
+
+	result, err := uc.Impersonate(admin, "user-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	info, err := jwt.ReadTokenInfo(result.Token)
+	if err != nil || info.UserID != "user-1" {
+		t.Fatalf("Expected a token for John, got %+v, %v", info, err)
+	}
+	if lifetime := info.ExpiresAt.Sub(info.IssuedAt); lifetime != DefaultImpersonationExpire*time.Minute {
+		t.Errorf("Expected the token to last %d minutes, got %v", DefaultImpersonationExpire, lifetime)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_IMPERSONATION || auditRepo.logs[0].ActorID != "admin-1" ||
+		auditRepo.logs[0].RetentionClass != entity.RetentionCritical {
+		t.Errorf("Expected a critical impersonation audit entry, got %+v", auditRepo.logs)
+	}
+
+	tests := []struct {
+		name   string
+		actor  Actor
+		userID string
+	}{
+		{"not an admin", Actor{ID: "user-2", Role: constants.ROLE_USER}, "user-1"},
+		{"yourself", admin, "admin-1"},
+		{"another admin", admin, "admin-2"},
+		{"suspended user", admin, "user-2"},
+		{"unknown user", admin, "user-404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.Impersonate(tt.actor, tt.userID); err == nil {
+				t.Error("Expected the impersonation to be refused")
+			}
+		})
+	}
+}
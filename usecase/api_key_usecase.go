@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/utils"
+)
+
+const (
+	// apiKeyPrefix marks BYOW keys, so a leaked one is easy to recognise
+	apiKeyPrefix = "byow_"
+	// apiKeyShownPrefix is how much of a key is kept in clear to tell keys apart
+	apiKeyShownPrefix = len(apiKeyPrefix) + 6
+)
+
+// APIKeyUsecase manages the keys other BYOW services call internal
+// endpoints with. Listing keys takes PERMISSION_ADMIN_API_KEYS_READ,
+// issuing and revoking them PERMISSION_ADMIN_API_KEYS_WRITE.
+type APIKeyUsecase struct {
+	Repo     repository.APIKeyRepository
+	UserRepo repository.UserRepository
+	Audit    *AuditUsecase
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func toAPIKeyResponse(key *entity.APIKey) dto.APIKeyResponse {
+	response := dto.APIKeyResponse{
+		ID:        key.ID.Hex(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedBy: key.CreatedBy,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.LastUsedAt != nil {
+		response.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+	if key.RevokedAt != nil {
+		response.RevokedAt = key.RevokedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// Create issues a key named name with scopes. The key is returned once and
+// only its hash is stored, a lost key has to be revoked and replaced.
+func (u *APIKeyUsecase) Create(actor Actor, req dto.CreateAPIKeyRequest) (*dto.CreatedAPIKeyResponse, error) {
+	if err := Authorize(actor, constants.PERMISSION_ADMIN_API_KEYS_WRITE); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(req.Scopes) == 0 {
+		return nil, appErrors.ErrAllFieldsRequired
+	}
+	for _, scope := range req.Scopes {
+		if !constants.IsValidAPIKeyScope(scope) {
+			return nil, appErrors.NewBadRequestError("Unknown scope: " + scope)
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, appErrors.NewInternalError("Failed to generate API key")
+	}
+	plain := apiKeyPrefix + hex.EncodeToString(secret)
+	key := &entity.APIKey{
+		Name:      name,
+		Prefix:    plain[:apiKeyShownPrefix],
+		Hash:      hashAPIKey(plain),
+		Scopes:    req.Scopes,
+		CreatedBy: actor.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := u.Repo.Create(key); err != nil {
+		return nil, appErrors.ErrDatabaseOperation
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(actor.ID, constants.AUDIT_API_KEY_CREATED, key.ID.Hex(), map[string]interface{}{
+			"name":   key.Name,
+			"scopes": key.Scopes,
+		})
+		if err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_API_KEY_CREATED, err)
+		}
+	}
+	return &dto.CreatedAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(key), Key: plain}, nil
+}
+
+// List returns every key, revoked ones included, newest first
+func (u *APIKeyUsecase) List(actor Actor) ([]dto.APIKeyResponse, error) {
+	if err := Authorize(actor, constants.PERMISSION_ADMIN_API_KEYS_READ); err != nil {
+		return nil, err
+	}
+	keys, err := u.Repo.List()
+	if err != nil {
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	responses := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, toAPIKeyResponse(key))
+	}
+	return responses, nil
+}
+
+// Revoke stops the key from authenticating straight away
+func (u *APIKeyUsecase) Revoke(actor Actor, id string) error {
+	if err := Authorize(actor, constants.PERMISSION_ADMIN_API_KEYS_WRITE); err != nil {
+		return err
+	}
+	if err := u.Repo.Revoke(id, time.Now()); err != nil {
+		if err == appErrors.ErrAPIKeyNotFound {
+			return err
+		}
+		return appErrors.ErrDatabaseOperation
+	}
+
+	if u.Audit != nil {
+		if err := u.Audit.Record(actor.ID, constants.AUDIT_API_KEY_REVOKED, id, nil); err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_API_KEY_REVOKED, err)
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the unrevoked key matching key, ErrInvalidAPIKey when
+// there is none. Recording the use is best effort, a failure is logged
+// rather than refusing a valid key.
+func (u *APIKeyUsecase) Authenticate(key string) (*entity.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, appErrors.ErrInvalidAPIKey
+	}
+	found, err := u.Repo.FindByHash(hashAPIKey(key))
+	if err != nil {
+		if err == appErrors.ErrInvalidAPIKey {
+			return nil, err
+		}
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	if err := u.Repo.TouchLastUsed(found.ID, time.Now()); err != nil {
+		utils.LogError("Failed to record use of API key %s: %v", found.Prefix, err)
+	}
+	return found, nil
+}
+
+// InternalUser looks a user up by id for another service
+func (u *APIKeyUsecase) InternalUser(id string) (*dto.InternalUserResponse, error) {
+	user, err := u.UserRepo.FindByID(id)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	role := user.Role
+	if role == "" {
+		role = constants.ROLE_USER
+	}
+	return &dto.InternalUserResponse{
+		ID:          user.ID,
+		Fullname:    user.Fullname,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		AvatarUrl:   user.AvatarUrl,
+		Verified:    user.Verified,
+		Role:        role,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock API key repository for testing
+type mockAPIKeyRepository struct {
+	keys []*entity.APIKey
+}
+
+func (m *mockAPIKeyRepository) Create(key *entity.APIKey) error {
+	key.ID = primitive.NewObjectID()
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func (m *mockAPIKeyRepository) FindByHash(hash string) (*entity.APIKey, error) {
+	for _, key := range m.keys {
+		if key.Hash == hash && key.RevokedAt == nil {
+			return key, nil
+		}
+	}
+	return nil, appErrors.ErrInvalidAPIKey
+}
+
+func (m *mockAPIKeyRepository) List() ([]*entity.APIKey, error) {
+	return m.keys, nil
+}
+
+func (m *mockAPIKeyRepository) Revoke(id string, at time.Time) error {
+	for _, key := range m.keys {
+		if key.ID.Hex() == id && key.RevokedAt == nil {
+			key.RevokedAt = &at
+			return nil
+		}
+	}
+	return appErrors.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepository) TouchLastUsed(id primitive.ObjectID, at time.Time) error {
+	for _, key := range m.keys {
+		if key.ID == id {
+			key.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyUsecase(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	auditRepo := &mockAuditLogRepository{}
+	userRepo := &mockUserRepository{users: map[string]*entity.User{
+		"john@example.com": {ID: "user-123", Email: "john@example.com", Fullname: "John Doe", Password: "hashed"},
+	}}
+	uc := &APIKeyUsecase{Repo: repo, UserRepo: userRepo, Audit: &AuditUsecase{Repo: auditRepo}}
+	admin := Actor{ID: "admin-123", Role: constants.ROLE_ADMIN}
+
+	if _, err := uc.Create(Actor{ID: "user-123", Role: constants.ROLE_USER}, dto.CreateAPIKeyRequest{Name: "billing", Scopes: []string{constants.API_SCOPE_USERS_READ}}); err != appErrors.ErrForbidden {
+		t.Fatalf("Expected ErrForbidden for a user without the permission, got %v", err)
+	}
+
+	created, err := uc.Create(admin, dto.CreateAPIKeyRequest{Name: "billing", Scopes: []string{constants.API_SCOPE_USERS_READ}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(created.Key, "byow_") || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Errorf("Expected a byow_ key starting with its prefix, got %q / %q", created.Key, created.Prefix)
+	}
+	if repo.keys[0].Hash == created.Key || strings.Contains(repo.keys[0].Hash, created.Key) {
+		t.Error("Expected only the hash of the key to be stored")
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_API_KEY_CREATED {
+		t.Errorf("Expected the creation to be audited, got %+v", auditRepo.logs)
+	}
+
+	t.Run("rejects unknown scopes", func(t *testing.T) {
+		if _, err := uc.Create(admin, dto.CreateAPIKeyRequest{Name: "x", Scopes: []string{"admin:everything"}}); err == nil {
+			t.Error("Expected an unknown scope to be refused")
+		}
+		if _, err := uc.Create(admin, dto.CreateAPIKeyRequest{Name: " "}); err != appErrors.ErrAllFieldsRequired {
+			t.Errorf("Expected ErrAllFieldsRequired, got %v", err)
+		}
+	})
+
+	t.Run("authenticates the key", func(t *testing.T) {
+		key, err := uc.Authenticate(created.Key)
+		if err != nil {
+			t.Fatalf("Expected the key to authenticate, got %v", err)
+		}
+		if key.Name != "billing" || key.LastUsedAt == nil {
+			t.Errorf("Expected the billing key with its use recorded, got %+v", key)
+		}
+		if _, err := uc.Authenticate(created.Key + "0"); err != appErrors.ErrInvalidAPIKey {
+			t.Errorf("Expected ErrInvalidAPIKey for a wrong key, got %v", err)
+		}
+	})
+
+	t.Run("internal user lookup", func(t *testing.T) {
+		user, err := uc.InternalUser("user-123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if user.Email != "john@example.com" || user.Role != constants.ROLE_USER {
+			t.Errorf("Unexpected user %+v", user)
+		}
+		if _, err := uc.InternalUser("missing"); err != appErrors.ErrUserNotFound {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("revoked key stops working", func(t *testing.T) {
+		if err := uc.Revoke(admin, created.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := uc.Authenticate(created.Key); err != appErrors.ErrInvalidAPIKey {
+			t.Errorf("Expected a revoked key to be refused, got %v", err)
+		}
+		if err := uc.Revoke(admin, created.ID); err != appErrors.ErrAPIKeyNotFound {
+			t.Errorf("Expected ErrAPIKeyNotFound revoking twice, got %v", err)
+		}
+		keys, _ := uc.List(admin)
+		if len(keys) != 1 || keys[0].RevokedAt == "" {
+			t.Errorf("Expected the revoked key to stay listed, got %+v", keys)
+		}
+	})
+}
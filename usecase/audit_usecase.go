@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	"github.com/buildyow/byow-user-service/domain/repository"
+	"github.com/buildyow/byow-user-service/utils"
+)
+
+const (
+	defaultPurgeBatchSize  int64 = 1000
+	defaultPurgeMaxBatches       = 100
+)
+
+// criticalAuditActions are kept for the longer critical retention period
+var criticalAuditActions = map[string]bool{
+	constants.AUDIT_ACCOUNT_DELETED: true,
+	constants.AUDIT_ROLE_CHANGED:    true,
+	constants.AUDIT_IMPERSONATION:   true,
+}
+
+// AuditRetention is how long entries of each retention class are kept,
+// a zero duration keeps them forever
+type AuditRetention struct {
+	Standard time.Duration
+	Critical time.Duration
+}
+
+type AuditUsecase struct {
+	Repo      repository.AuditLogRepository
+	Retention AuditRetention
+	// PurgeBatchSize and PurgeMaxBatches bound how much a single purge run deletes
+	PurgeBatchSize  int64
+	PurgeMaxBatches int
+}
+
+// Record stores an audit entry, picking its retention class from the action
+func (u *AuditUsecase) Record(actorID, action, targetID string, metadata map[string]interface{}) error {
+	retentionClass := entity.RetentionStandard
+	if criticalAuditActions[action] {
+		retentionClass = entity.RetentionCritical
+	}
+	return u.Repo.Create(&entity.AuditLog{
+		ActorID:        actorID,
+		Action:         action,
+		TargetID:       targetID,
+		Metadata:       metadata,
+		RetentionClass: retentionClass,
+		CreatedAt:      time.Now(),
+	})
+}
+
+// RecordImpersonatedRequest flags a request an admin made as another user,
+// for jwt.AuditImpersonation. A failure is logged, the request was already
+// served.
+func (u *AuditUsecase) RecordImpersonatedRequest(impersonatorID, userID, method, route string, status int) {
+	err := u.Record(impersonatorID, constants.AUDIT_IMPERSONATED_CALL, userID, map[string]interface{}{
+		"method": method,
+		"route":  route,
+		"status": status,
+	})
+	if err != nil {
+		utils.LogError("Failed to record %s: %v", constants.AUDIT_IMPERSONATED_CALL, err)
+	}
+}
+
+// ListForTarget returns the most recent entries of action recorded against targetID
+func (u *AuditUsecase) ListForTarget(targetID, action string, limit int64) ([]*entity.AuditLog, error) {
+	return u.Repo.FindByTarget(targetID, action, limit)
+}
+
+// Purge removes entries older than their class retention period relative to now
+func (u *AuditUsecase) Purge(now time.Time) (int64, error) {
+	classes := []struct {
+		name   string
+		period time.Duration
+	}{
+		{entity.RetentionStandard, u.Retention.Standard},
+		{entity.RetentionCritical, u.Retention.Critical},
+	}
+
+	var total int64
+	for _, class := range classes {
+		if class.period <= 0 {
+			continue
+		}
+		cutoff := now.Add(-class.period)
+		removed, err := u.purgeClass(class.name, cutoff)
+		total += removed
+		if err != nil {
+			utils.LogError("Audit log purge failed for %s entries: %v", class.name, err)
+			return total, err
+		}
+		utils.LogInfo("Purged %d %s audit log entries older than %s", removed, class.name, cutoff.Format(time.RFC3339))
+	}
+	return total, nil
+}
+
+func (u *AuditUsecase) purgeClass(retentionClass string, cutoff time.Time) (int64, error) {
+	batchSize := u.PurgeBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+	maxBatches := u.PurgeMaxBatches
+	if maxBatches <= 0 {
+		maxBatches = defaultPurgeMaxBatches
+	}
+
+	var total int64
+	for i := 0; i < maxBatches; i++ {
+		removed, err := u.Repo.DeleteOlderThan(retentionClass, cutoff, batchSize)
+		total += removed
+		if err != nil {
+			return total, err
+		}
+		if removed < batchSize {
+			break
+		}
+	}
+	return total, nil
+}
+
+// StartPurgeWorker runs Purge in the background on every interval
+func (u *AuditUsecase) StartPurgeWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			u.Purge(time.Now())
+		}
+	}()
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+)
+
+// Mock audit log repository for testing
+type mockAuditLogRepository struct {
+	logs []*entity.AuditLog
+}
+
+func (m *mockAuditLogRepository) Create(log *entity.AuditLog) error {
+	m.logs = append(m.logs, log)
+	return nil
+}
+
+func (m *mockAuditLogRepository) DeleteOlderThan(retentionClass string, cutoff time.Time, limit int64) (int64, error) {
+	var kept []*entity.AuditLog
+	var removed int64
+	for _, log := range m.logs {
+		if removed < limit && log.RetentionClass == retentionClass && log.CreatedAt.Before(cutoff) {
+			removed++
+			continue
+		}
+		kept = append(kept, log)
+	}
+	m.logs = kept
+	return removed, nil
+}
+
+func (m *mockAuditLogRepository) FindByTarget(targetID string, action string, limit int64) ([]*entity.AuditLog, error) {
+	var found []*entity.AuditLog
+	for _, log := range m.logs {
+		if log.TargetID == targetID && log.Action == action {
+			found = append(found, log)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].CreatedAt.After(found[j].CreatedAt) })
+	if int64(len(found)) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func setupAuditUsecase() *AuditUsecase {
+	return &AuditUsecase{
+		Repo: &mockAuditLogRepository{},
+		Retention: AuditRetention{
+			Standard: 90 * 24 * time.Hour,
+			Critical: 365 * 24 * time.Hour,
+		},
+	}
+}
+
+func TestAuditUsecase_Record_RetentionClass(t *testing.T) {
+	uc := setupAuditUsecase()
+	repo := uc.Repo.(*mockAuditLogRepository)
+
+	uc.Record("user-1", "company_deleted", "company-1", nil)
+	uc.Record("user-1", constants.AUDIT_ACCOUNT_DELETED, "user-1", nil)
+
+	if len(repo.logs) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d", len(repo.logs))
+	}
+	if repo.logs[0].RetentionClass != entity.RetentionStandard {
+		t.Errorf("Expected standard retention, got %s", repo.logs[0].RetentionClass)
+	}
+	if repo.logs[1].RetentionClass != entity.RetentionCritical {
+		t.Errorf("Expected critical retention for account deletion, got %s", repo.logs[1].RetentionClass)
+	}
+}
+
+func TestAuditUsecase_Purge_RemovesOnlyExpired(t *testing.T) {
+	uc := setupAuditUsecase()
+	repo := uc.Repo.(*mockAuditLogRepository)
+
+	now := time.Now()
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+
+	oldStandard := &entity.AuditLog{Action: "old", RetentionClass: entity.RetentionStandard, CreatedAt: daysAgo(100)}
+	recentStandard := &entity.AuditLog{Action: "recent", RetentionClass: entity.RetentionStandard, CreatedAt: daysAgo(10)}
+	oldCritical := &entity.AuditLog{Action: "old-critical", RetentionClass: entity.RetentionCritical, CreatedAt: daysAgo(100)}
+	expiredCritical := &entity.AuditLog{Action: "expired-critical", RetentionClass: entity.RetentionCritical, CreatedAt: daysAgo(400)}
+	repo.logs = []*entity.AuditLog{oldStandard, recentStandard, oldCritical, expiredCritical}
+
+	removed, err := uc.Purge(now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+
+	remaining := map[string]bool{}
+	for _, log := range repo.logs {
+		remaining[log.Action] = true
+	}
+	if !remaining["recent"] || !remaining["old-critical"] {
+		t.Errorf("Expected recent and critical entries to be kept, got %v", remaining)
+	}
+	if remaining["old"] || remaining["expired-critical"] {
+		t.Errorf("Expected expired entries to be removed, got %v", remaining)
+	}
+}
+
+func TestAuditUsecase_Purge_Bounded(t *testing.T) {
+	uc := setupAuditUsecase()
+	uc.PurgeBatchSize = 2
+	uc.PurgeMaxBatches = 2
+	repo := uc.Repo.(*mockAuditLogRepository)
+
+	old := time.Now().Add(-200 * 24 * time.Hour)
+	for i := 0; i < 10; i++ {
+		repo.logs = append(repo.logs, &entity.AuditLog{RetentionClass: entity.RetentionStandard, CreatedAt: old})
+	}
+
+	removed, err := uc.Purge(time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if removed != 4 {
+		t.Errorf("Expected a single run to remove at most 4 entries, got %d", removed)
+	}
+	if len(repo.logs) != 6 {
+		t.Errorf("Expected 6 entries left for the next run, got %d", len(repo.logs))
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"sync"
+
+	"github.com/buildyow/byow-user-service/dto"
+)
+
+// companyEventBuffer is how many events a slow subscriber may fall behind
+// before it starts missing them
+const companyEventBuffer = 16
+
+// CompanyEventHub fans company change events out to the open streams of the
+// company owner. It is in-process, so each instance only sees its own changes.
+type CompanyEventHub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan dto.CompanyEvent]struct{}
+}
+
+func NewCompanyEventHub() *CompanyEventHub {
+	return &CompanyEventHub{subscribers: make(map[string]map[chan dto.CompanyEvent]struct{})}
+}
+
+// Subscribe returns a channel receiving the events of userID's companies and
+// a function that unsubscribes and closes the channel
+func (h *CompanyEventHub) Subscribe(userID string) (<-chan dto.CompanyEvent, func()) {
+	ch := make(chan dto.CompanyEvent, companyEventBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan dto.CompanyEvent]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers[userID], ch)
+			if len(h.subscribers[userID]) == 0 {
+				delete(h.subscribers, userID)
+			}
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// Publish delivers event to userID's subscribers without blocking, a
+// subscriber whose buffer is full misses the event
+func (h *CompanyEventHub) Publish(userID string, event dto.CompanyEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers returns how many streams userID has open
+func (h *CompanyEventHub) Subscribers(userID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[userID])
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/dto"
+)
+
+func TestCompanyEventHub_ScopedToUser(t *testing.T) {
+	hub := NewCompanyEventHub()
+	mine, unsubscribe := hub.Subscribe("user-1")
+	defer unsubscribe()
+	others, unsubscribeOthers := hub.Subscribe("user-2")
+	defer unsubscribeOthers()
+
+	hub.Publish("user-1", dto.CompanyEvent{Type: constants.COMPANY_EVENT_CREATED})
+
+	select {
+	case event := <-mine:
+		if event.Type != constants.COMPANY_EVENT_CREATED {
+			t.Errorf("Expected created event, got %s", event.Type)
+		}
+	default:
+		t.Error("Expected the owner to receive the event")
+	}
+	select {
+	case event := <-others:
+		t.Errorf("Expected other users to receive nothing, got %+v", event)
+	default:
+	}
+}
+
+func TestCompanyEventHub_UnsubscribeClosesChannel(t *testing.T) {
+	hub := NewCompanyEventHub()
+	events, unsubscribe := hub.Subscribe("user-1")
+
+	unsubscribe()
+	unsubscribe() // safe to call twice
+
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to be closed")
+	}
+	if n := hub.Subscribers("user-1"); n != 0 {
+		t.Errorf("Expected no subscribers left, got %d", n)
+	}
+	// Publishing after everyone left must not panic
+	hub.Publish("user-1", dto.CompanyEvent{Type: constants.COMPANY_EVENT_DELETED})
+}
+
+func TestCompanyEventHub_SlowSubscriberDoesNotBlock(t *testing.T) {
+	hub := NewCompanyEventHub()
+	_, unsubscribe := hub.Subscribe("user-1")
+	defer unsubscribe()
+
+	for i := 0; i < companyEventBuffer*2; i++ {
+		hub.Publish("user-1", dto.CompanyEvent{Type: constants.COMPANY_EVENT_UPDATED})
+	}
+}
@@ -1,12 +1,18 @@
 package usecase
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/buildyow/byow-user-service/constants"
 	"github.com/buildyow/byow-user-service/domain/entity"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/domain/repository"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/featureflags"
+	"github.com/buildyow/byow-user-service/infrastructure/mailer"
+	"github.com/buildyow/byow-user-service/utils"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -14,32 +20,198 @@ import (
 type CompanyUsecase struct {
 	Repo   repository.CompanyRepository
 	UserID func(c *gin.Context) string
+	// Flags toggles optional behaviour, defaults apply when nil
+	Flags *featureflags.Flags
+	// Audit records admin changes, skipped when nil
+	Audit *AuditUsecase
+	// Events streams changes to the owner's open connections, skipped when nil
+	Events *CompanyEventHub
+	// Users looks up company owners, owners are left out when nil
+	Users repository.UserRepository
+	// DeleteLogo removes a stored logo once its company is purged, logos are
+	// kept when nil
+	DeleteLogo func(url string) error
+	// SendEmail delivers best-effort notices to company owners, skipped when nil
+	SendEmail func(to, subject, body string) error
+	// RunAsync runs background work such as owner notices, a goroutine when nil
+	RunAsync func(task func())
 }
 
-func (u *CompanyUsecase) GetAll(c *gin.Context, keyword string, limit int64, offset int64) (*[]dto.CompanyResponse, int64, error) {
-	companies, rowCount, err := u.Repo.FindAll(u.UserID(c), keyword, limit, offset)
+// MaxVerifyBatch caps how many companies SetVerifiedMany accepts per call
+const MaxVerifyBatch = 500
+
+// MaxOwnershipBatch caps how many IDs CheckOwnership accepts per call
+const MaxOwnershipBatch = 500
+
+// MaxUnverifyReasonLength caps the reason given when revoking a verification
+const MaxUnverifyReasonLength = 500
+
+func (u *CompanyUsecase) runAsync(task func()) {
+	if u.RunAsync != nil {
+		u.RunAsync(task)
+		return
+	}
+	go task()
+}
+
+func (u *CompanyUsecase) publish(userID string, eventType string, id primitive.ObjectID) {
+	if u.Events == nil {
+		return
+	}
+	u.Events.Publish(userID, dto.CompanyEvent{
+		Type:      eventType,
+		CompanyID: id,
+		At:        time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SubscribeEvents opens a change stream for the caller's companies, the
+// returned function must be called once the stream ends
+func (u *CompanyUsecase) SubscribeEvents(c *gin.Context) (<-chan dto.CompanyEvent, func(), error) {
+	if u.Events == nil {
+		return nil, nil, appErrors.ErrFeatureDisabled
+	}
+	events, unsubscribe := u.Events.Subscribe(u.UserID(c))
+	return events, unsubscribe, nil
+}
+
+func (u *CompanyUsecase) GetAll(c *gin.Context, keyword string, deleted bool, limit int64, offset int64) (*[]dto.CompanyResponse, int64, error) {
+	filter := repository.CompanyFilter{
+		UserID:  u.UserID(c),
+		Keyword: keyword,
+		Deleted: deleted,
+	}
+	return u.list(filter, limit, offset)
+}
+
+// AdminGetAll lists companies across all owners for moderation, optionally
+// narrowed to one owner or verification status. Callers must be admin-gated.
+func (u *CompanyUsecase) AdminGetAll(keyword string, userID string, verified *bool, limit int64, offset int64) (*[]dto.CompanyResponse, int64, error) {
+	filter := repository.CompanyFilter{
+		UserID:   userID,
+		Keyword:  keyword,
+		Verified: verified,
+	}
+	return u.list(filter, limit, offset)
+}
+
+func (u *CompanyUsecase) list(filter repository.CompanyFilter, limit int64, offset int64) (*[]dto.CompanyResponse, int64, error) {
+	companies, rowCount, err := u.Repo.FindAll(filter, limit, offset)
 	if err != nil {
 		return nil, 0, appErrors.NewNotFoundError("Companies")
 	}
 
 	var companyResponses []dto.CompanyResponse
 	for _, company := range companies {
-		companyResponses = append(companyResponses, dto.CompanyResponse{
-			UserID:         company.UserID,
-			CompanyID:      company.ID,
-			CompanyName:    company.CompanyName,
-			CompanyEmail:   company.CompanyEmail,
-			CompanyPhone:   company.CompanyPhone,
-			CompanyAddress: company.CompanyAddress,
-			CompanyLogo:    company.CompanyLogo,
-			Verified:       company.Verified,
-			CreatedAt:      company.CreatedAt.Format(time.RFC3339),
+		companyResponses = append(companyResponses, ToCompanyResponse(company))
+	}
+
+	return &companyResponses, rowCount, nil
+}
+
+// ToCompanyResponse is the API view of company, shared by the handlers
+func ToCompanyResponse(company *entity.Company) dto.CompanyResponse {
+	return dto.CompanyResponse{
+		UserID:         company.UserID,
+		CompanyID:      company.ID,
+		CompanyName:    company.CompanyName,
+		CompanyEmail:   company.CompanyEmail,
+		CompanyPhone:   company.CompanyPhone,
+		CompanyAddress: company.CompanyAddress,
+		CompanyLogo:    company.CompanyLogo,
+		Verified:       company.Verified,
+		PublicListing:  company.PublicListing,
+		PublicContact:  company.PublicContact,
+		IsPrimary:      company.IsPrimary,
+		CreatedAt:      company.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      company.LastUpdated().Format(time.RFC3339),
+	}
+}
+
+// MaxRecentCompanies caps how many companies GetRecent returns
+const MaxRecentCompanies = 10
+
+// GetRecent returns the caller's most recently updated companies for
+// dashboard widgets, capped at MaxRecentCompanies
+func (u *CompanyUsecase) GetRecent(c *gin.Context, limit int64) (*[]dto.CompanySummaryResponse, error) {
+	if limit <= 0 || limit > MaxRecentCompanies {
+		limit = MaxRecentCompanies
+	}
+	companies, err := u.Repo.FindRecent(u.UserID(c), limit)
+	if err != nil {
+		return nil, appErrors.NewNotFoundError("Companies")
+	}
+
+	companyResponses := []dto.CompanySummaryResponse{}
+	for _, company := range companies {
+		companyResponses = append(companyResponses, dto.CompanySummaryResponse{
+			CompanyID:   company.ID,
+			CompanyName: company.CompanyName,
+			CompanyLogo: company.CompanyLogo,
+			Verified:    company.Verified,
+			UpdatedAt:   company.LastUpdated().Format(time.RFC3339),
 		})
 	}
+	return &companyResponses, nil
+}
+
+// GetPublic lists verified companies that opted into the public directory,
+// across all owners
+func (u *CompanyUsecase) GetPublic(keyword string, limit int64, offset int64) (*[]dto.PublicCompanyResponse, int64, error) {
+	if !u.Flags.Enabled(featureflags.PublicDirectory) {
+		return nil, 0, appErrors.ErrFeatureDisabled
+	}
+	filter := repository.CompanyFilter{
+		Keyword: keyword,
+		Public:  true,
+	}
+	companies, rowCount, err := u.Repo.FindAll(filter, limit, offset)
+	if err != nil {
+		return nil, 0, appErrors.NewNotFoundError("Companies")
+	}
+
+	companyResponses := []dto.PublicCompanyResponse{}
+	for _, company := range companies {
+		companyResponses = append(companyResponses, toPublicCompanyResponse(company))
+	}
 
 	return &companyResponses, rowCount, nil
 }
 
+// GetPublicByID returns one company from the public directory. Companies
+// outside the directory are reported as not found rather than forbidden, so
+// the endpoint doesn't reveal which IDs exist.
+func (u *CompanyUsecase) GetPublicByID(id primitive.ObjectID) (*dto.PublicCompanyResponse, error) {
+	if !u.Flags.Enabled(featureflags.PublicDirectory) {
+		return nil, appErrors.ErrFeatureDisabled
+	}
+	company, err := u.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !company.Verified || !company.PublicListing {
+		return nil, appErrors.NewNotFoundError("Company")
+	}
+	companyResponse := toPublicCompanyResponse(company)
+	return &companyResponse, nil
+}
+
+// toPublicCompanyResponse hides contact details unless the owner opted in
+func toPublicCompanyResponse(company *entity.Company) dto.PublicCompanyResponse {
+	companyResponse := dto.PublicCompanyResponse{
+		CompanyID:      company.ID,
+		CompanyName:    company.CompanyName,
+		CompanyAddress: company.CompanyAddress,
+		CompanyLogo:    company.CompanyLogo,
+		CreatedAt:      company.CreatedAt.Format(time.RFC3339),
+	}
+	if company.PublicContact {
+		companyResponse.CompanyEmail = company.CompanyEmail
+		companyResponse.CompanyPhone = company.CompanyPhone
+	}
+	return companyResponse
+}
+
 func (u *CompanyUsecase) Create(c *gin.Context, req dto.CompanyRequest) (*entity.Company, error) {
 	company := &entity.Company{
 		UserID:         u.UserID(c),
@@ -49,11 +221,14 @@ func (u *CompanyUsecase) Create(c *gin.Context, req dto.CompanyRequest) (*entity
 		CompanyAddress: req.CompanyAddress,
 		CompanyLogo:    req.CompanyLogo,
 		Verified:       false,
+		PublicListing:  req.PublicListing,
+		PublicContact:  req.PublicContact,
 	}
 	err := u.Repo.Create(company)
 	if err != nil {
 		return nil, err
 	}
+	u.publish(company.UserID, constants.COMPANY_EVENT_CREATED, company.ID)
 	return company, nil
 }
 
@@ -64,3 +239,342 @@ func (u *CompanyUsecase) FindByID(id primitive.ObjectID) (*entity.Company, error
 	}
 	return company, nil
 }
+
+// FindByIDWithOwner returns a company with its owner's public profile. The
+// caller must own the company or find it in the public directory, in which
+// case contact details follow the company's PublicContact setting.
+func (u *CompanyUsecase) FindByIDWithOwner(c *gin.Context, id primitive.ObjectID) (*dto.CompanyWithOwnerResponse, error) {
+	company, err := u.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	isOwner := company.UserID == u.UserID(c)
+	listed := company.Verified && company.PublicListing && u.Flags.Enabled(featureflags.PublicDirectory)
+	if !isOwner && !listed {
+		return nil, appErrors.ErrForbidden
+	}
+
+	result := &dto.CompanyWithOwnerResponse{CompanyResponse: ToCompanyResponse(company)}
+	if !isOwner && !company.PublicContact {
+		result.CompanyEmail = ""
+		result.CompanyPhone = ""
+	}
+	if u.Users == nil {
+		return result, nil
+	}
+	owner, err := u.Users.FindByID(company.UserID)
+	if err != nil {
+		if err != appErrors.ErrUserNotFound {
+			utils.LogError("Failed to load owner of company %s: %v", company.ID.Hex(), err)
+		}
+		return result, nil
+	}
+	result.Owner = &dto.CompanyOwnerResponse{
+		Fullname:  owner.Fullname,
+		AvatarUrl: owner.AvatarUrl,
+		Verified:  owner.Verified,
+	}
+	return result, nil
+}
+
+// FindByName returns the caller's company with the given name, ignoring case.
+// Companies of other users are never matched.
+func (u *CompanyUsecase) FindByName(c *gin.Context, name string) (*entity.Company, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, appErrors.NewValidationError("Company name is required")
+	}
+	company, err := u.Repo.FindByNameForUser(u.UserID(c), name)
+	if err != nil {
+		return nil, appErrors.NewNotFoundError("Company")
+	}
+	return company, nil
+}
+
+// CheckAvailability reports whether a company email and phone are still free
+// for a new company. Only non-empty values are checked. Like Create, values
+// must be unique across all companies, so another owner's company counts.
+func (u *CompanyUsecase) CheckAvailability(c *gin.Context, email, phone string) (*dto.CompanyAvailabilityResponse, error) {
+	email = strings.TrimSpace(email)
+	phone = strings.TrimSpace(phone)
+	if email == "" && phone == "" {
+		return nil, appErrors.NewValidationError("Email or phone is required")
+	}
+
+	availability := &dto.CompanyAvailabilityResponse{}
+	if email != "" {
+		available, err := isAvailable(u.Repo.FindByEmail(email))
+		if err != nil {
+			return nil, err
+		}
+		availability.EmailAvailable = &available
+	}
+	if phone != "" {
+		available, err := isAvailable(u.Repo.FindByPhone(phone))
+		if err != nil {
+			return nil, err
+		}
+		availability.PhoneAvailable = &available
+	}
+	return availability, nil
+}
+
+// CheckOwnership reports for each hex ID whether it is an active company of
+// the caller, with one query for the whole batch. Malformed IDs are false.
+func (u *CompanyUsecase) CheckOwnership(c *gin.Context, ids []string) (map[string]bool, error) {
+	if len(ids) == 0 {
+		return nil, appErrors.NewValidationError("At least one company ID is required")
+	}
+	if len(ids) > MaxOwnershipBatch {
+		return nil, appErrors.NewValidationError(fmt.Sprintf("At most %d companies can be checked at once", MaxOwnershipBatch))
+	}
+
+	ownership := make(map[string]bool, len(ids))
+	objectIDs := []primitive.ObjectID{}
+	for _, id := range ids {
+		ownership[id] = false
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	if len(objectIDs) == 0 {
+		return ownership, nil
+	}
+
+	owned, err := u.Repo.FindOwnedIDs(u.UserID(c), objectIDs)
+	if err != nil {
+		utils.LogError("Failed to check company ownership: %v", err)
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	ownedSet := make(map[primitive.ObjectID]bool, len(owned))
+	for _, id := range owned {
+		ownedSet[id] = true
+	}
+	for _, id := range ids {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil && ownedSet[objectID] {
+			ownership[id] = true
+		}
+	}
+	return ownership, nil
+}
+
+// isAvailable turns a lookup result into availability, not found means free
+func isAvailable(company *entity.Company, err error) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+	if appErr, ok := appErrors.IsAppError(err); ok && appErr.Status == 404 {
+		return true, nil
+	}
+	utils.LogError("Failed to check company availability: %v", err)
+	return false, appErrors.ErrDatabaseOperation
+}
+
+// Delete soft-deletes a company owned by the authenticated user
+func (u *CompanyUsecase) Delete(c *gin.Context, id primitive.ObjectID) error {
+	company, err := u.Repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if company.UserID != u.UserID(c) {
+		return appErrors.ErrForbidden
+	}
+	if err := u.Repo.Delete(id); err != nil {
+		return err
+	}
+	u.publish(company.UserID, constants.COMPANY_EVENT_DELETED, id)
+	return nil
+}
+
+// Restore brings back a soft-deleted company owned by the authenticated user
+func (u *CompanyUsecase) Restore(c *gin.Context, id primitive.ObjectID) (*entity.Company, error) {
+	company, err := u.Repo.FindDeletedByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if company.UserID != u.UserID(c) {
+		return nil, appErrors.ErrForbidden
+	}
+	if err := u.Repo.Restore(id); err != nil {
+		return nil, err
+	}
+	company.DeletedAt = nil
+	u.publish(company.UserID, constants.COMPANY_EVENT_RESTORED, id)
+	return company, nil
+}
+
+// SetPrimary makes a company owned by the authenticated user their primary
+// company, replacing any previous primary
+func (u *CompanyUsecase) SetPrimary(c *gin.Context, id primitive.ObjectID) (*entity.Company, error) {
+	company, err := u.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if company.UserID != u.UserID(c) {
+		return nil, appErrors.ErrForbidden
+	}
+	if err := u.Repo.SetPrimary(company.UserID, id); err != nil {
+		return nil, err
+	}
+	company.IsPrimary = true
+	u.publish(company.UserID, constants.COMPANY_EVENT_UPDATED, id)
+	return company, nil
+}
+
+// SetVerifiedMany sets the verification status of many companies at once for
+// moderators and returns how many actually changed
+func (u *CompanyUsecase) SetVerifiedMany(c *gin.Context, ids []primitive.ObjectID, verified bool) (int64, error) {
+	if len(ids) == 0 {
+		return 0, appErrors.NewValidationError("At least one company ID is required")
+	}
+	if len(ids) > MaxVerifyBatch {
+		return 0, appErrors.NewValidationError(fmt.Sprintf("At most %d companies can be updated at once", MaxVerifyBatch))
+	}
+
+	modified, err := u.Repo.SetVerifiedMany(ids, verified)
+	if err != nil {
+		return 0, appErrors.ErrDatabaseOperation
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(u.UserID(c), constants.AUDIT_COMPANIES_VERIFIED, "", map[string]interface{}{
+			"verified":  verified,
+			"requested": len(ids),
+			"modified":  modified,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for batch verification: %v", err)
+		}
+	}
+	return modified, nil
+}
+
+// Unverify revokes a company's verification, recording the moderator and
+// reason in the audit log and emailing the owner. Callers must be admin-gated.
+func (u *CompanyUsecase) Unverify(c *gin.Context, id primitive.ObjectID, reason string) (*entity.Company, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, appErrors.NewValidationError("A reason is required")
+	}
+	if len([]rune(reason)) > MaxUnverifyReasonLength {
+		return nil, appErrors.NewValidationError(fmt.Sprintf("Reason must be at most %d characters", MaxUnverifyReasonLength))
+	}
+
+	company, err := u.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !company.Verified {
+		return nil, appErrors.NewConflictError("Company is not verified")
+	}
+	modified, err := u.Repo.SetVerifiedMany([]primitive.ObjectID{id}, false)
+	if err != nil {
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	// Someone else unverified or deleted it in the meantime
+	if modified == 0 {
+		return nil, appErrors.NewConflictError("Company is not verified")
+	}
+	company.Verified = false
+
+	if u.Audit != nil {
+		err := u.Audit.Record(u.UserID(c), constants.AUDIT_COMPANY_UNVERIFIED, id.Hex(), map[string]interface{}{
+			"reason":   reason,
+			"owner_id": company.UserID,
+		})
+		if err != nil {
+			utils.LogError("Failed to record audit entry for unverifying company %s: %v", id.Hex(), err)
+		}
+	}
+	u.publish(company.UserID, constants.COMPANY_EVENT_UPDATED, id)
+	u.notifyVerificationRevoked(company, reason)
+	return company, nil
+}
+
+// notifyVerificationRevoked emails the owner why their company lost its
+// verification, failures are only logged
+func (u *CompanyUsecase) notifyVerificationRevoked(company *entity.Company, reason string) {
+	if u.SendEmail == nil || u.Users == nil {
+		return
+	}
+	u.runAsync(func() {
+		owner, err := u.Users.FindByID(company.UserID)
+		if err != nil {
+			utils.LogError("Failed to load owner of unverified company %s: %v", company.ID.Hex(), err)
+			return
+		}
+		if !owner.Notifications.Allows(constants.NOTICE_VERIFICATION_REVOKED) {
+			return
+		}
+		lang := mailer.ResolveLanguage("", owner.Preferences.Language)
+		subject, body := mailer.VerificationRevokedNotice(lang, company.CompanyName, reason)
+		if err := u.SendEmail(owner.Email, subject, body); err != nil {
+			utils.LogError("Failed to send verification revoked notice: %v", err)
+		}
+	})
+}
+
+// PurgeOwner permanently removes every company of userID, soft-deleted ones
+// included, along with their logos and returns how many were removed. It is
+// run when the owner's account is deleted for good.
+func (u *CompanyUsecase) PurgeOwner(userID string) (int64, error) {
+	removed, err := u.Repo.DeleteByOwner(userID)
+	if err != nil {
+		utils.LogError("Failed to delete companies of user %s: %v", userID, err)
+		return 0, appErrors.ErrDatabaseOperation
+	}
+	for _, company := range removed {
+		if company.CompanyLogo == "" || u.DeleteLogo == nil {
+			continue
+		}
+		if err := u.DeleteLogo(company.CompanyLogo); err != nil {
+			utils.LogError("Failed to delete logo of removed company %s: %v", company.ID.Hex(), err)
+		}
+	}
+	return int64(len(removed)), nil
+}
+
+// PurgeDeleted permanently removes companies soft-deleted more than days ago
+// along with their logos and returns how many were removed. Callers must be
+// admin-gated.
+func (u *CompanyUsecase) PurgeDeleted(c *gin.Context, days int) (int64, error) {
+	if days <= 0 {
+		return 0, appErrors.NewValidationError("Days must be a positive number")
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	// A purge failing partway has still removed some companies, their logos
+	// and the audit entry are handled before the error is returned
+	purged, purgeErr := u.Repo.PurgeDeletedOlderThan(cutoff)
+	if purgeErr != nil {
+		utils.LogError("Failed to purge deleted companies, %d removed before the failure: %v", len(purged), purgeErr)
+	}
+
+	for _, company := range purged {
+		if company.CompanyLogo == "" || u.DeleteLogo == nil {
+			continue
+		}
+		// The companies are already gone, a stray logo is only wasted storage
+		if err := u.DeleteLogo(company.CompanyLogo); err != nil {
+			utils.LogError("Failed to delete logo of purged company %s: %v", company.ID.Hex(), err)
+		}
+	}
+
+	if u.Audit != nil && (purgeErr == nil || len(purged) > 0) {
+		details := map[string]interface{}{
+			"days":   days,
+			"cutoff": cutoff.UTC().Format(time.RFC3339),
+			"purged": len(purged),
+		}
+		if purgeErr != nil {
+			details["incomplete"] = true
+		}
+		if err := u.Audit.Record(u.UserID(c), constants.AUDIT_COMPANIES_PURGED, "", details); err != nil {
+			utils.LogError("Failed to record audit entry for company purge: %v", err)
+		}
+	}
+	if purgeErr != nil {
+		return int64(len(purged)), appErrors.ErrDatabaseOperation
+	}
+	return int64(len(purged)), nil
+}
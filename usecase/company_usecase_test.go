@@ -2,12 +2,18 @@ package usecase
 
 import (
 	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/buildyow/byow-user-service/constants"
 	"github.com/buildyow/byow-user-service/domain/entity"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/featureflags"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -16,20 +22,37 @@ import (
 type mockCompanyRepository struct {
 	companies map[string]*entity.Company
 	nextID    int
+	// purgeErr fails PurgeDeletedOlderThan after the first company removed
+	purgeErr error
 }
 
-func (m *mockCompanyRepository) FindAll(userID, keyword string, limit, offset int64) ([]*entity.Company, int64, error) {
+func (m *mockCompanyRepository) FindAll(filter repository.CompanyFilter, limit, offset int64) ([]*entity.Company, int64, error) {
 	if m.companies == nil {
 		return []*entity.Company{}, 0, nil
 	}
-	
+
+	userID, keyword := filter.UserID, filter.Keyword
 	var result []*entity.Company
 	for _, company := range m.companies {
+		// Only soft-deleted companies when requested, active ones otherwise
+		if (company.DeletedAt != nil) != filter.Deleted {
+			continue
+		}
+
+		if filter.Verified != nil && company.Verified != *filter.Verified {
+			continue
+		}
+
+		// Public directory only shows verified, opted-in companies
+		if filter.Public && !(company.Verified && company.PublicListing) {
+			continue
+		}
+
 		// Filter by user ID if provided
 		if userID != "" && company.UserID != userID {
 			continue
 		}
-		
+
 		// Filter by keyword if provided (case-insensitive partial match)
 		if keyword != "" {
 			// Simple contains check for testing
@@ -48,27 +71,27 @@ func (m *mockCompanyRepository) FindAll(userID, keyword string, limit, offset in
 				}
 			}
 		}
-		
+
 		result = append(result, company)
 	}
-	
+
 	// Apply pagination
 	total := int64(len(result))
 	start := offset
 	end := offset + limit
-	
+
 	if start > total {
 		return []*entity.Company{}, total, nil
 	}
-	
+
 	if end > total {
 		end = total
 	}
-	
+
 	if limit > 0 {
 		result = result[start:end]
 	}
-	
+
 	return result, total, nil
 }
 
@@ -76,7 +99,7 @@ func (m *mockCompanyRepository) Create(company *entity.Company) error {
 	if m.companies == nil {
 		m.companies = make(map[string]*entity.Company)
 	}
-	
+
 	// Check for duplicates
 	for _, existing := range m.companies {
 		if (company.CompanyEmail != "" && existing.CompanyEmail == company.CompanyEmail) ||
@@ -84,15 +107,15 @@ func (m *mockCompanyRepository) Create(company *entity.Company) error {
 			return appErrors.ErrEmailOrPhoneAlreadyRegistered
 		}
 	}
-	
+
 	// Generate ID and set timestamp
 	company.ID = primitive.NewObjectID()
 	company.CreatedAt = time.Now()
-	
+
 	// Use a unique key for storage
 	key := company.ID.Hex()
 	m.companies[key] = company
-	
+
 	return nil
 }
 
@@ -100,12 +123,62 @@ func (m *mockCompanyRepository) FindByID(id primitive.ObjectID) (*entity.Company
 	if m.companies == nil {
 		return nil, appErrors.NewNotFoundError("Company")
 	}
-	
+
 	key := id.Hex()
-	if company, exists := m.companies[key]; exists {
+	if company, exists := m.companies[key]; exists && company.DeletedAt == nil {
+		return company, nil
+	}
+
+	return nil, appErrors.NewNotFoundError("Company")
+}
+
+func (m *mockCompanyRepository) FindByNameForUser(userID string, name string) (*entity.Company, error) {
+	for _, company := range m.companies {
+		if company.UserID == userID && company.DeletedAt == nil && strings.EqualFold(company.CompanyName, name) {
+			return company, nil
+		}
+	}
+	return nil, appErrors.NewNotFoundError("Company")
+}
+
+func (m *mockCompanyRepository) FindRecent(userID string, limit int64) ([]*entity.Company, error) {
+	lastActivity := func(company *entity.Company) time.Time {
+		if company.UpdatedAt.IsZero() {
+			return company.CreatedAt
+		}
+		return company.UpdatedAt
+	}
+
+	var result []*entity.Company
+	for _, company := range m.companies {
+		if company.UserID == userID && company.DeletedAt == nil {
+			result = append(result, company)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return lastActivity(result[i]).After(lastActivity(result[j]))
+	})
+	if int64(len(result)) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockCompanyRepository) FindOwnedIDs(userID string, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	owned := []primitive.ObjectID{}
+	for _, id := range ids {
+		if company, exists := m.companies[id.Hex()]; exists && company.UserID == userID && company.DeletedAt == nil {
+			owned = append(owned, id)
+		}
+	}
+	return owned, nil
+}
+
+func (m *mockCompanyRepository) FindDeletedByID(id primitive.ObjectID) (*entity.Company, error) {
+	if company, exists := m.companies[id.Hex()]; exists && company.DeletedAt != nil {
 		return company, nil
 	}
-	
+
 	return nil, appErrors.NewNotFoundError("Company")
 }
 
@@ -113,13 +186,13 @@ func (m *mockCompanyRepository) FindByEmail(email string) (*entity.Company, erro
 	if m.companies == nil {
 		return nil, appErrors.NewNotFoundError("Company")
 	}
-	
+
 	for _, company := range m.companies {
 		if company.CompanyEmail == email {
 			return company, nil
 		}
 	}
-	
+
 	return nil, appErrors.NewNotFoundError("Company")
 }
 
@@ -127,13 +200,13 @@ func (m *mockCompanyRepository) FindByPhone(phone string) (*entity.Company, erro
 	if m.companies == nil {
 		return nil, appErrors.NewNotFoundError("Company")
 	}
-	
+
 	for _, company := range m.companies {
 		if company.CompanyPhone == phone {
 			return company, nil
 		}
 	}
-	
+
 	return nil, appErrors.NewNotFoundError("Company")
 }
 
@@ -141,13 +214,13 @@ func (m *mockCompanyRepository) Update(company *entity.Company) error {
 	if m.companies == nil {
 		return appErrors.NewNotFoundError("Company")
 	}
-	
+
 	key := company.ID.Hex()
 	if _, exists := m.companies[key]; exists {
 		m.companies[key] = company
 		return nil
 	}
-	
+
 	return appErrors.NewNotFoundError("Company")
 }
 
@@ -155,16 +228,87 @@ func (m *mockCompanyRepository) Delete(id primitive.ObjectID) error {
 	if m.companies == nil {
 		return appErrors.NewNotFoundError("Company")
 	}
-	
+
 	key := id.Hex()
-	if _, exists := m.companies[key]; exists {
-		delete(m.companies, key)
+	if company, exists := m.companies[key]; exists && company.DeletedAt == nil {
+		now := time.Now()
+		company.DeletedAt = &now
+		return nil
+	}
+
+	return appErrors.NewNotFoundError("Company")
+}
+
+func (m *mockCompanyRepository) Restore(id primitive.ObjectID) error {
+	if company, exists := m.companies[id.Hex()]; exists && company.DeletedAt != nil {
+		company.DeletedAt = nil
 		return nil
 	}
-	
+
 	return appErrors.NewNotFoundError("Company")
 }
 
+func (m *mockCompanyRepository) ReassignOwner(fromUserID string, toUserID string) (int64, error) {
+	var moved int64
+	for _, company := range m.companies {
+		if company.UserID == fromUserID {
+			company.UserID = toUserID
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+func (m *mockCompanyRepository) SetVerifiedMany(ids []primitive.ObjectID, verified bool) (int64, error) {
+	var modified int64
+	for _, id := range ids {
+		company, ok := m.companies[id.Hex()]
+		if ok && company.DeletedAt == nil && company.Verified != verified {
+			company.Verified = verified
+			modified++
+		}
+	}
+	return modified, nil
+}
+
+func (m *mockCompanyRepository) PurgeDeletedOlderThan(cutoff time.Time) ([]*entity.Company, error) {
+	purged := []*entity.Company{}
+	for key, company := range m.companies {
+		if company.DeletedAt != nil && company.DeletedAt.Before(cutoff) {
+			if m.purgeErr != nil && len(purged) == 1 {
+				return purged, m.purgeErr
+			}
+			purged = append(purged, company)
+			delete(m.companies, key)
+		}
+	}
+	return purged, nil
+}
+
+func (m *mockCompanyRepository) DeleteByOwner(userID string) ([]*entity.Company, error) {
+	owned := []*entity.Company{}
+	for key, company := range m.companies {
+		if company.UserID == userID {
+			owned = append(owned, company)
+			delete(m.companies, key)
+		}
+	}
+	return owned, nil
+}
+
+func (m *mockCompanyRepository) SetPrimary(userID string, id primitive.ObjectID) error {
+	target, ok := m.companies[id.Hex()]
+	if !ok || target.UserID != userID || target.DeletedAt != nil {
+		return appErrors.NewNotFoundError("Company")
+	}
+	for _, company := range m.companies {
+		if company.UserID == userID {
+			company.IsPrimary = company.ID == id
+		}
+	}
+	return nil
+}
+
 // Mock function to extract user ID from context
 func mockUserIDFunc(c *gin.Context) string {
 	if userID, exists := c.Get("user_id"); exists {
@@ -190,11 +334,11 @@ func setupGinContext() *gin.Context {
 func TestCompanyUsecase_GetAll_Success(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
+
 	// Create test companies
 	repo := uc.Repo.(*mockCompanyRepository)
 	repo.companies = make(map[string]*entity.Company)
-	
+
 	company1 := &entity.Company{
 		ID:           primitive.NewObjectID(),
 		UserID:       "test-user-123",
@@ -203,43 +347,43 @@ func TestCompanyUsecase_GetAll_Success(t *testing.T) {
 		CreatedAt:    time.Now(),
 	}
 	company2 := &entity.Company{
-		ID:           primitive.NewObjectID(), 
+		ID:           primitive.NewObjectID(),
 		UserID:       "test-user-123",
 		CompanyName:  "Another Company",
 		CompanyEmail: "test2@company.com",
 		CreatedAt:    time.Now(),
 	}
-	
+
 	repo.companies[company1.ID.Hex()] = company1
 	repo.companies[company2.ID.Hex()] = company2
-	
-	responses, count, err := uc.GetAll(c, "", 10, 0)
+
+	responses, count, err := uc.GetAll(c, "", false, 10, 0)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if responses == nil {
 		t.Fatal("Expected responses to be non-nil")
 	}
-	
+
 	if len(*responses) != 2 {
 		t.Errorf("Expected 2 companies, got %d", len(*responses))
 	}
-	
+
 	if count != 2 {
 		t.Errorf("Expected count 2, got %d", count)
 	}
-	
+
 	// Check response structure
 	response := (*responses)[0]
 	if response.UserID == "" {
 		t.Error("Expected UserID to be set")
 	}
-	
+
 	if response.CompanyID.IsZero() {
 		t.Error("Expected CompanyID to be set")
 	}
-	
+
 	if response.CreatedAt == "" {
 		t.Error("Expected CreatedAt to be formatted")
 	}
@@ -248,11 +392,11 @@ func TestCompanyUsecase_GetAll_Success(t *testing.T) {
 func TestCompanyUsecase_GetAll_WithKeyword(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
+
 	// Create test companies
 	repo := uc.Repo.(*mockCompanyRepository)
 	repo.companies = make(map[string]*entity.Company)
-	
+
 	company1 := &entity.Company{
 		ID:          primitive.NewObjectID(),
 		UserID:      "test-user-123",
@@ -261,23 +405,23 @@ func TestCompanyUsecase_GetAll_WithKeyword(t *testing.T) {
 	}
 	company2 := &entity.Company{
 		ID:          primitive.NewObjectID(),
-		UserID:      "test-user-123", 
+		UserID:      "test-user-123",
 		CompanyName: "Marketing Agency",
 		CreatedAt:   time.Now(),
 	}
-	
+
 	repo.companies[company1.ID.Hex()] = company1
 	repo.companies[company2.ID.Hex()] = company2
-	
-	responses, count, err := uc.GetAll(c, "Tech", 10, 0)
+
+	responses, count, err := uc.GetAll(c, "Tech", false, 10, 0)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if count != 1 {
 		t.Errorf("Expected count 1 with keyword filter, got %d", count)
 	}
-	
+
 	if len(*responses) != 1 {
 		t.Errorf("Expected 1 company with keyword filter, got %d", len(*responses))
 	}
@@ -286,11 +430,11 @@ func TestCompanyUsecase_GetAll_WithKeyword(t *testing.T) {
 func TestCompanyUsecase_GetAll_WithPagination(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
+
 	// Create test companies
 	repo := uc.Repo.(*mockCompanyRepository)
 	repo.companies = make(map[string]*entity.Company)
-	
+
 	for i := 0; i < 5; i++ {
 		company := &entity.Company{
 			ID:          primitive.NewObjectID(),
@@ -300,31 +444,31 @@ func TestCompanyUsecase_GetAll_WithPagination(t *testing.T) {
 		}
 		repo.companies[company.ID.Hex()] = company
 	}
-	
+
 	// Test first page
-	responses, count, err := uc.GetAll(c, "", 2, 0)
+	responses, count, err := uc.GetAll(c, "", false, 2, 0)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if count != 5 {
 		t.Errorf("Expected total count 5, got %d", count)
 	}
-	
+
 	if len(*responses) != 2 {
 		t.Errorf("Expected 2 companies on first page, got %d", len(*responses))
 	}
-	
+
 	// Test second page
-	responses, count, err = uc.GetAll(c, "", 2, 2)
+	responses, count, err = uc.GetAll(c, "", false, 2, 2)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if count != 5 {
 		t.Errorf("Expected total count 5, got %d", count)
 	}
-	
+
 	if len(*responses) != 2 {
 		t.Errorf("Expected 2 companies on second page, got %d", len(*responses))
 	}
@@ -333,20 +477,20 @@ func TestCompanyUsecase_GetAll_WithPagination(t *testing.T) {
 func TestCompanyUsecase_GetAll_EmptyResult(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
-	responses, count, err := uc.GetAll(c, "", 10, 0)
+
+	responses, count, err := uc.GetAll(c, "", false, 10, 0)
 	if err != nil {
 		t.Errorf("Expected no error for empty result, got %v", err)
 	}
-	
+
 	if responses == nil {
 		t.Fatal("Expected responses to be non-nil even when empty")
 	}
-	
+
 	if len(*responses) != 0 {
 		t.Errorf("Expected 0 companies, got %d", len(*responses))
 	}
-	
+
 	if count != 0 {
 		t.Errorf("Expected count 0, got %d", count)
 	}
@@ -355,7 +499,7 @@ func TestCompanyUsecase_GetAll_EmptyResult(t *testing.T) {
 func TestCompanyUsecase_Create_Success(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
+
 	req := dto.CompanyRequest{
 		CompanyName:    "New Company",
 		CompanyEmail:   "new@company.com",
@@ -363,36 +507,36 @@ func TestCompanyUsecase_Create_Success(t *testing.T) {
 		CompanyAddress: "123 Main St",
 		CompanyLogo:    "logo.png",
 	}
-	
+
 	company, err := uc.Create(c, req)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if company == nil {
 		t.Fatal("Expected company to be created")
 	}
-	
+
 	if company.CompanyName != req.CompanyName {
 		t.Errorf("Expected company name %s, got %s", req.CompanyName, company.CompanyName)
 	}
-	
+
 	if company.CompanyEmail != req.CompanyEmail {
 		t.Errorf("Expected company email %s, got %s", req.CompanyEmail, company.CompanyEmail)
 	}
-	
+
 	if company.UserID != "test-user-123" {
 		t.Errorf("Expected user ID test-user-123, got %s", company.UserID)
 	}
-	
+
 	if company.Verified {
 		t.Error("Expected company to be unverified initially")
 	}
-	
+
 	if company.ID.IsZero() {
 		t.Error("Expected company ID to be set")
 	}
-	
+
 	if company.CreatedAt.IsZero() {
 		t.Error("Expected CreatedAt to be set")
 	}
@@ -401,24 +545,24 @@ func TestCompanyUsecase_Create_Success(t *testing.T) {
 func TestCompanyUsecase_Create_DuplicateEmail(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
+
 	// Create first company
 	req1 := dto.CompanyRequest{
 		CompanyName:  "Company 1",
 		CompanyEmail: "duplicate@company.com",
 	}
-	
+
 	_, err := uc.Create(c, req1)
 	if err != nil {
 		t.Fatalf("Expected no error creating first company, got %v", err)
 	}
-	
+
 	// Try to create second company with same email
 	req2 := dto.CompanyRequest{
-		CompanyName:  "Company 2", 
+		CompanyName:  "Company 2",
 		CompanyEmail: "duplicate@company.com",
 	}
-	
+
 	_, err = uc.Create(c, req2)
 	if err != appErrors.ErrEmailOrPhoneAlreadyRegistered {
 		t.Errorf("Expected ErrEmailOrPhoneAlreadyRegistered, got %v", err)
@@ -428,24 +572,24 @@ func TestCompanyUsecase_Create_DuplicateEmail(t *testing.T) {
 func TestCompanyUsecase_Create_DuplicatePhone(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
+
 	// Create first company
 	req1 := dto.CompanyRequest{
 		CompanyName:  "Company 1",
 		CompanyPhone: "+1234567890",
 	}
-	
+
 	_, err := uc.Create(c, req1)
 	if err != nil {
 		t.Fatalf("Expected no error creating first company, got %v", err)
 	}
-	
+
 	// Try to create second company with same phone
 	req2 := dto.CompanyRequest{
 		CompanyName:  "Company 2",
 		CompanyPhone: "+1234567890",
 	}
-	
+
 	_, err = uc.Create(c, req2)
 	if err != appErrors.ErrEmailOrPhoneAlreadyRegistered {
 		t.Errorf("Expected ErrEmailOrPhoneAlreadyRegistered, got %v", err)
@@ -454,11 +598,11 @@ func TestCompanyUsecase_Create_DuplicatePhone(t *testing.T) {
 
 func TestCompanyUsecase_FindByID_Success(t *testing.T) {
 	uc := setupCompanyUsecase()
-	
+
 	// Create a company first
 	repo := uc.Repo.(*mockCompanyRepository)
 	repo.companies = make(map[string]*entity.Company)
-	
+
 	originalCompany := &entity.Company{
 		ID:           primitive.NewObjectID(),
 		UserID:       "test-user-123",
@@ -466,23 +610,23 @@ func TestCompanyUsecase_FindByID_Success(t *testing.T) {
 		CompanyEmail: "test@company.com",
 		CreatedAt:    time.Now(),
 	}
-	
+
 	repo.companies[originalCompany.ID.Hex()] = originalCompany
-	
+
 	// Find by ID
 	company, err := uc.FindByID(originalCompany.ID)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if company == nil {
 		t.Fatal("Expected company to be found")
 	}
-	
+
 	if company.ID != originalCompany.ID {
 		t.Errorf("Expected company ID %v, got %v", originalCompany.ID, company.ID)
 	}
-	
+
 	if company.CompanyName != originalCompany.CompanyName {
 		t.Errorf("Expected company name %s, got %s", originalCompany.CompanyName, company.CompanyName)
 	}
@@ -490,14 +634,14 @@ func TestCompanyUsecase_FindByID_Success(t *testing.T) {
 
 func TestCompanyUsecase_FindByID_NotFound(t *testing.T) {
 	uc := setupCompanyUsecase()
-	
+
 	nonExistentID := primitive.NewObjectID()
-	
+
 	_, err := uc.FindByID(nonExistentID)
 	if err == nil {
 		t.Error("Expected error for non-existent company")
 	}
-	
+
 	// The error should be a NotFound error
 	if appErr, ok := err.(*appErrors.AppError); ok {
 		if appErr.Status != 404 {
@@ -506,96 +650,665 @@ func TestCompanyUsecase_FindByID_NotFound(t *testing.T) {
 	}
 }
 
-func TestCompanyUsecase_UserIDExtraction(t *testing.T) {
+func TestCompanyUsecase_Delete_HidesCompany(t *testing.T) {
 	uc := setupCompanyUsecase()
-	
-	// Test with context that has user_id
 	c := setupGinContext()
-	c.Set("user_id", "custom-user-456")
-	
-	userID := uc.UserID(c)
-	if userID != "custom-user-456" {
-		t.Errorf("Expected user ID custom-user-456, got %s", userID)
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+
+	company := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "test-user-123",
+		CompanyName: "Test Company",
+		CreatedAt:   time.Now(),
 	}
-	
-	// Test with context that doesn't have user_id (should return default)
-	c2, _ := gin.CreateTestContext(nil)
-	userID2 := uc.UserID(c2)
-	if userID2 != "test-user-123" {
-		t.Errorf("Expected default user ID test-user-123, got %s", userID2)
+	repo.companies[company.ID.Hex()] = company
+
+	if err := uc.Delete(c, company.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if company.DeletedAt == nil {
+		t.Error("Expected DeletedAt to be set")
+	}
+
+	if _, err := uc.FindByID(company.ID); err == nil {
+		t.Error("Expected soft-deleted company to be hidden from FindByID")
+	}
+
+	responses, count, err := uc.GetAll(c, "", false, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 0 || len(*responses) != 0 {
+		t.Errorf("Expected soft-deleted company to be hidden from GetAll, got %d", count)
+	}
+
+	responses, count, err = uc.GetAll(c, "", true, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 1 || len(*responses) != 1 {
+		t.Errorf("Expected 1 deleted company, got %d", count)
 	}
 }
 
-func TestCompanyUsecase_ResponseMapping(t *testing.T) {
+func TestCompanyUsecase_Restore_Success(t *testing.T) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
-	// Create a company with all fields
-	testTime := time.Now()
-	company := &entity.Company{
-		ID:             primitive.NewObjectID(),
-		UserID:         "test-user-123",
-		CompanyName:    "Full Company",
-		CompanyEmail:   "full@company.com",
-		CompanyPhone:   "+1234567890",
-		CompanyAddress: "123 Full Street",
-		CompanyLogo:    "full-logo.png",
-		Verified:       true,
-		CreatedAt:      testTime,
-	}
-	
+
 	repo := uc.Repo.(*mockCompanyRepository)
 	repo.companies = make(map[string]*entity.Company)
+
+	deletedAt := time.Now()
+	company := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "test-user-123",
+		CompanyName: "Test Company",
+		CreatedAt:   time.Now(),
+		DeletedAt:   &deletedAt,
+	}
 	repo.companies[company.ID.Hex()] = company
-	
-	responses, _, err := uc.GetAll(c, "", 10, 0)
+
+	restored, err := uc.Restore(c, company.ID)
 	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
 	}
-	
-	if len(*responses) != 1 {
-		t.Fatalf("Expected 1 response, got %d", len(*responses))
+	if restored.DeletedAt != nil {
+		t.Error("Expected DeletedAt to be cleared")
 	}
-	
-	response := (*responses)[0]
-	
-	// Verify all fields are mapped correctly
-	if response.UserID != company.UserID {
-		t.Errorf("Expected UserID %s, got %s", company.UserID, response.UserID)
+
+	if _, err := uc.FindByID(company.ID); err != nil {
+		t.Errorf("Expected restored company to be found, got %v", err)
 	}
-	
-	if response.CompanyID != company.ID {
-		t.Errorf("Expected CompanyID %v, got %v", company.ID, response.CompanyID)
+}
+
+func TestCompanyUsecase_Restore_NotDeleted(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+
+	company := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "test-user-123",
+		CompanyName: "Test Company",
 	}
-	
-	if response.CompanyName != company.CompanyName {
-		t.Errorf("Expected CompanyName %s, got %s", company.CompanyName, response.CompanyName)
+	repo.companies[company.ID.Hex()] = company
+
+	_, err := uc.Restore(c, company.ID)
+	if appErr, ok := err.(*appErrors.AppError); !ok || appErr.Status != 404 {
+		t.Errorf("Expected 404 error restoring an active company, got %v", err)
 	}
-	
-	if response.CompanyEmail != company.CompanyEmail {
-		t.Errorf("Expected CompanyEmail %s, got %s", company.CompanyEmail, response.CompanyEmail)
+}
+
+func TestCompanyUsecase_DeleteRestore_Forbidden(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+
+	company := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "other-user",
+		CompanyName: "Someone Else's Company",
 	}
-	
-	if response.CompanyPhone != company.CompanyPhone {
-		t.Errorf("Expected CompanyPhone %s, got %s", company.CompanyPhone, response.CompanyPhone)
+	repo.companies[company.ID.Hex()] = company
+
+	if err := uc.Delete(c, company.ID); err != appErrors.ErrForbidden {
+		t.Errorf("Expected ErrForbidden on delete, got %v", err)
 	}
-	
-	if response.CompanyAddress != company.CompanyAddress {
-		t.Errorf("Expected CompanyAddress %s, got %s", company.CompanyAddress, response.CompanyAddress)
+	if company.DeletedAt != nil {
+		t.Error("Expected company to remain active")
 	}
-	
-	if response.CompanyLogo != company.CompanyLogo {
-		t.Errorf("Expected CompanyLogo %s, got %s", company.CompanyLogo, response.CompanyLogo)
+
+	deletedAt := time.Now()
+	company.DeletedAt = &deletedAt
+	if _, err := uc.Restore(c, company.ID); err != appErrors.ErrForbidden {
+		t.Errorf("Expected ErrForbidden on restore, got %v", err)
 	}
-	
-	if response.Verified != company.Verified {
-		t.Errorf("Expected Verified %v, got %v", company.Verified, response.Verified)
+	if company.DeletedAt == nil {
+		t.Error("Expected company to remain deleted")
 	}
-	
-	// Check time formatting
-	expectedTime := company.CreatedAt.Format(time.RFC3339)
-	if response.CreatedAt != expectedTime {
-		t.Errorf("Expected CreatedAt %s, got %s", expectedTime, response.CreatedAt)
+}
+
+func TestCompanyUsecase_GetPublic_Disabled(t *testing.T) {
+	uc := setupCompanyUsecase()
+	uc.Flags = &featureflags.Flags{PublicDirectory: false}
+
+	_, _, err := uc.GetPublic("", 10, 0)
+	if err != appErrors.ErrFeatureDisabled {
+		t.Errorf("Expected ErrFeatureDisabled, got %v", err)
+	}
+}
+
+func TestCompanyUsecase_GetPublicByID(t *testing.T) {
+	uc := setupCompanyUsecase()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	listed := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Listed Co", CompanyEmail: "listed@company.com", Verified: true, PublicListing: true}
+	unverified := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Unverified Co", PublicListing: true}
+	optedOut := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Private Co", Verified: true}
+	for _, company := range []*entity.Company{listed, unverified, optedOut} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	result, err := uc.GetPublicByID(listed.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.CompanyName != "Listed Co" || result.CompanyEmail != "" {
+		t.Errorf("Expected the redacted public view, got %+v", result)
+	}
+
+	for _, id := range []primitive.ObjectID{unverified.ID, optedOut.ID, primitive.NewObjectID()} {
+		_, err := uc.GetPublicByID(id)
+		if appErr, ok := appErrors.IsAppError(err); !ok || appErr.Code != "NOT_FOUND" {
+			t.Errorf("Expected not found for %s, got %v", id.Hex(), err)
+		}
+	}
+
+	uc.Flags = &featureflags.Flags{PublicDirectory: false}
+	if _, err := uc.GetPublicByID(listed.ID); err != appErrors.ErrFeatureDisabled {
+		t.Errorf("Expected ErrFeatureDisabled, got %v", err)
+	}
+}
+
+func TestCompanyUsecase_GetPublic_FiltersAndRedacts(t *testing.T) {
+	uc := setupCompanyUsecase()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+
+	listed := &entity.Company{
+		ID:            primitive.NewObjectID(),
+		UserID:        "owner-1",
+		CompanyName:   "Listed Co",
+		CompanyEmail:  "listed@company.com",
+		CompanyPhone:  "628111111111",
+		Verified:      true,
+		PublicListing: true,
+	}
+	withContact := &entity.Company{
+		ID:            primitive.NewObjectID(),
+		UserID:        "owner-2",
+		CompanyName:   "Contact Co",
+		CompanyEmail:  "contact@company.com",
+		CompanyPhone:  "628122222222",
+		Verified:      true,
+		PublicListing: true,
+		PublicContact: true,
+	}
+	unverified := &entity.Company{
+		ID:            primitive.NewObjectID(),
+		CompanyName:   "Unverified Co",
+		PublicListing: true,
+	}
+	optedOut := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		CompanyName: "Private Co",
+		Verified:    true,
+	}
+	for _, company := range []*entity.Company{listed, withContact, unverified, optedOut} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	responses, count, err := uc.GetPublic("", 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 2 || len(*responses) != 2 {
+		t.Fatalf("Expected 2 public companies, got %d", count)
+	}
+
+	for _, resp := range *responses {
+		switch resp.CompanyID {
+		case listed.ID:
+			if resp.CompanyEmail != "" || resp.CompanyPhone != "" {
+				t.Error("Expected contact details to be hidden without opt-in")
+			}
+		case withContact.ID:
+			if resp.CompanyEmail != withContact.CompanyEmail || resp.CompanyPhone != withContact.CompanyPhone {
+				t.Error("Expected contact details for opted-in company")
+			}
+		default:
+			t.Errorf("Unexpected company in public listing: %s", resp.CompanyName)
+		}
+	}
+
+	data, _ := json.Marshal((*responses)[0])
+	if strings.Contains(string(data), "user_id") {
+		t.Error("Expected public response to omit user_id")
+	}
+}
+
+func TestCompanyUsecase_AdminGetAll_CrossUser(t *testing.T) {
+	uc := setupCompanyUsecase()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	for _, company := range []*entity.Company{
+		{ID: primitive.NewObjectID(), UserID: "owner-1", CompanyName: "First Co", Verified: true},
+		{ID: primitive.NewObjectID(), UserID: "owner-2", CompanyName: "Second Co"},
+		{ID: primitive.NewObjectID(), UserID: "owner-2", CompanyName: "Third Co", Verified: true},
+	} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	responses, count, err := uc.AdminGetAll("", "", nil, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected companies of every owner, got %d", count)
+	}
+	for _, resp := range *responses {
+		if resp.UserID == "" {
+			t.Error("Expected owner user_id in admin response")
+		}
+	}
+
+	verified := true
+	responses, count, err = uc.AdminGetAll("", "owner-2", &verified, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 1 || (*responses)[0].CompanyName != "Third Co" {
+		t.Errorf("Expected only Third Co for owner-2 and verified, got %d", count)
+	}
+
+	// The regular listing stays scoped to the caller
+	c := setupGinContext()
+	c.Set("user_id", "owner-1")
+	_, count, err = uc.GetAll(c, "", false, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected GetAll to stay owner-scoped, got %d", count)
+	}
+}
+
+func TestCompanyUsecase_FindByName(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+
+	own := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CompanyName: "Cemerlang Jaya"}
+	foreign := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyName: "Sinar Abadi"}
+	repo.companies[own.ID.Hex()] = own
+	repo.companies[foreign.ID.Hex()] = foreign
+
+	company, err := uc.FindByName(c, "  cemerlang JAYA ")
+	if err != nil {
+		t.Fatalf("Expected a match, got %v", err)
+	}
+	if company.ID != own.ID {
+		t.Errorf("Expected %s, got %s", own.ID.Hex(), company.ID.Hex())
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"no match", "Cemerlang"},
+		{"other user's company", "Sinar Abadi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			company, err := uc.FindByName(c, tt.query)
+			if company != nil {
+				t.Fatalf("Expected no company, got %s", company.CompanyName)
+			}
+			appErr, ok := err.(*appErrors.AppError)
+			if !ok || appErr.Status != 404 {
+				t.Errorf("Expected 404, got %v", err)
+			}
+		})
+	}
+
+	if _, err := uc.FindByName(c, " "); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+}
+
+func TestCompanyUsecase_GetRecent_Ordering(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+
+	now := time.Now()
+	oldButEdited := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "test-user-123",
+		CompanyName: "Old but edited",
+		CreatedAt:   now.Add(-72 * time.Hour),
+		UpdatedAt:   now.Add(-1 * time.Hour),
+	}
+	newNeverEdited := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "test-user-123",
+		CompanyName: "New never edited",
+		CreatedAt:   now.Add(-2 * time.Hour),
+	}
+	oldNeverEdited := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "test-user-123",
+		CompanyName: "Old never edited",
+		CreatedAt:   now.Add(-48 * time.Hour),
+	}
+	otherUser := &entity.Company{
+		ID:          primitive.NewObjectID(),
+		UserID:      "other-user",
+		CompanyName: "Someone else",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, company := range []*entity.Company{oldButEdited, newNeverEdited, oldNeverEdited, otherUser} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	responses, err := uc.GetRecent(c, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"Old but edited", "New never edited", "Old never edited"}
+	if len(*responses) != len(expected) {
+		t.Fatalf("Expected %d companies, got %d", len(expected), len(*responses))
+	}
+	for i, name := range expected {
+		if (*responses)[i].CompanyName != name {
+			t.Errorf("Expected %s at position %d, got %s", name, i, (*responses)[i].CompanyName)
+		}
+	}
+	if (*responses)[1].UpdatedAt != newNeverEdited.CreatedAt.Format(time.RFC3339) {
+		t.Error("Expected never-edited company to report its creation time")
+	}
+
+	// Editing a company moves it to the front
+	oldNeverEdited.UpdatedAt = now
+	responses, _ = uc.GetRecent(c, 1)
+	if len(*responses) != 1 || (*responses)[0].CompanyName != "Old never edited" {
+		t.Errorf("Expected freshly edited company first, got %v", *responses)
+	}
+}
+
+func TestCompanyUsecase_GetRecent_CapsLimit(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	for i := 0; i < MaxRecentCompanies+5; i++ {
+		company := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CreatedAt: time.Now()}
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	responses, err := uc.GetRecent(c, 100)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*responses) != MaxRecentCompanies {
+		t.Errorf("Expected limit to be capped at %d, got %d", MaxRecentCompanies, len(*responses))
+	}
+}
+
+func TestCompanyUsecase_SetVerifiedMany(t *testing.T) {
+	uc := setupCompanyUsecase()
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+
+	pending := []*entity.Company{
+		{ID: primitive.NewObjectID(), CompanyName: "Pending One"},
+		{ID: primitive.NewObjectID(), CompanyName: "Pending Two"},
+	}
+	alreadyVerified := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Verified", Verified: true}
+	untouched := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Not in batch"}
+	for _, company := range append(pending, alreadyVerified, untouched) {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	ids := []primitive.ObjectID{pending[0].ID, pending[1].ID, alreadyVerified.ID, primitive.NewObjectID()}
+	modified, err := uc.SetVerifiedMany(c, ids, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if modified != 2 {
+		t.Errorf("Expected 2 companies modified, got %d", modified)
+	}
+	if !pending[0].Verified || !pending[1].Verified {
+		t.Error("Expected pending companies to be verified")
+	}
+	if untouched.Verified {
+		t.Error("Expected companies outside the batch to be left alone")
+	}
+
+	if len(auditRepo.logs) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(auditRepo.logs))
+	}
+	entry := auditRepo.logs[0]
+	if entry.ActorID != "test-user-123" || entry.Action != constants.AUDIT_COMPANIES_VERIFIED || entry.Metadata["modified"] != int64(2) {
+		t.Errorf("Unexpected audit entry %+v", entry)
+	}
+}
+
+func TestCompanyUsecase_SetVerifiedMany_Validation(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	if _, err := uc.SetVerifiedMany(c, nil, true); err == nil {
+		t.Error("Expected an error for an empty batch")
+	}
+
+	tooMany := make([]primitive.ObjectID, MaxVerifyBatch+1)
+	if _, err := uc.SetVerifiedMany(c, tooMany, true); err == nil {
+		t.Error("Expected an error for an oversized batch")
+	}
+}
+
+func TestCompanyUsecase_SetPrimary_SwitchesPrimary(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	first := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CompanyName: "First"}
+	second := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CompanyName: "Second"}
+	otherUsers := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyName: "Other", IsPrimary: true}
+	for _, company := range []*entity.Company{first, second, otherUsers} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	if _, err := uc.SetPrimary(c, first.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !first.IsPrimary {
+		t.Error("Expected first company to be primary")
+	}
+
+	company, err := uc.SetPrimary(c, second.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !company.IsPrimary || !second.IsPrimary {
+		t.Error("Expected second company to be primary")
+	}
+	if first.IsPrimary {
+		t.Error("Expected previous primary to be unset")
+	}
+	if !otherUsers.IsPrimary {
+		t.Error("Expected other users' primary company to be left alone")
+	}
+}
+
+func TestCompanyUsecase_SetPrimary_Forbidden(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	company := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyName: "Other"}
+	repo.companies[company.ID.Hex()] = company
+
+	if _, err := uc.SetPrimary(c, company.ID); err != appErrors.ErrForbidden {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+	if company.IsPrimary {
+		t.Error("Expected company of another user to stay non-primary")
+	}
+}
+
+func TestCompanyUsecase_CheckAvailability(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	taken := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyEmail: "taken@company.com", CompanyPhone: "628111111111"}
+	repo.companies[taken.ID.Hex()] = taken
+
+	tests := []struct {
+		name           string
+		email, phone   string
+		emailAvailable *bool
+		phoneAvailable *bool
+	}{
+		{"both available", "free@company.com", "628122222222", boolPtr(true), boolPtr(true)},
+		{"both taken", "taken@company.com", "628111111111", boolPtr(false), boolPtr(false)},
+		{"email only", "taken@company.com", "", boolPtr(false), nil},
+		{"phone only", "", "628122222222", nil, boolPtr(true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			availability, err := uc.CheckAvailability(c, tt.email, tt.phone)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !equalBoolPtr(availability.EmailAvailable, tt.emailAvailable) || !equalBoolPtr(availability.PhoneAvailable, tt.phoneAvailable) {
+				t.Errorf("Unexpected availability %+v", availability)
+			}
+		})
+	}
+
+	if _, err := uc.CheckAvailability(c, " ", ""); err == nil {
+		t.Error("Expected an error when neither email nor phone is given")
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func equalBoolPtr(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func TestCompanyUsecase_UserIDExtraction(t *testing.T) {
+	uc := setupCompanyUsecase()
+
+	// Test with context that has user_id
+	c := setupGinContext()
+	c.Set("user_id", "custom-user-456")
+
+	userID := uc.UserID(c)
+	if userID != "custom-user-456" {
+		t.Errorf("Expected user ID custom-user-456, got %s", userID)
+	}
+
+	// Test with context that doesn't have user_id (should return default)
+	c2, _ := gin.CreateTestContext(nil)
+	userID2 := uc.UserID(c2)
+	if userID2 != "test-user-123" {
+		t.Errorf("Expected default user ID test-user-123, got %s", userID2)
+	}
+}
+
+func TestCompanyUsecase_ResponseMapping(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	// Create a company with all fields
+	testTime := time.Now()
+	company := &entity.Company{
+		ID:             primitive.NewObjectID(),
+		UserID:         "test-user-123",
+		CompanyName:    "Full Company",
+		CompanyEmail:   "full@company.com",
+		CompanyPhone:   "+1234567890",
+		CompanyAddress: "123 Full Street",
+		CompanyLogo:    "full-logo.png",
+		Verified:       true,
+		CreatedAt:      testTime,
+	}
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	repo.companies[company.ID.Hex()] = company
+
+	responses, _, err := uc.GetAll(c, "", false, 10, 0)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if len(*responses) != 1 {
+		t.Fatalf("Expected 1 response, got %d", len(*responses))
+	}
+
+	response := (*responses)[0]
+
+	// Verify all fields are mapped correctly
+	if response.UserID != company.UserID {
+		t.Errorf("Expected UserID %s, got %s", company.UserID, response.UserID)
+	}
+
+	if response.CompanyID != company.ID {
+		t.Errorf("Expected CompanyID %v, got %v", company.ID, response.CompanyID)
+	}
+
+	if response.CompanyName != company.CompanyName {
+		t.Errorf("Expected CompanyName %s, got %s", company.CompanyName, response.CompanyName)
+	}
+
+	if response.CompanyEmail != company.CompanyEmail {
+		t.Errorf("Expected CompanyEmail %s, got %s", company.CompanyEmail, response.CompanyEmail)
+	}
+
+	if response.CompanyPhone != company.CompanyPhone {
+		t.Errorf("Expected CompanyPhone %s, got %s", company.CompanyPhone, response.CompanyPhone)
+	}
+
+	if response.CompanyAddress != company.CompanyAddress {
+		t.Errorf("Expected CompanyAddress %s, got %s", company.CompanyAddress, response.CompanyAddress)
+	}
+
+	if response.CompanyLogo != company.CompanyLogo {
+		t.Errorf("Expected CompanyLogo %s, got %s", company.CompanyLogo, response.CompanyLogo)
+	}
+
+	if response.Verified != company.Verified {
+		t.Errorf("Expected Verified %v, got %v", company.Verified, response.Verified)
+	}
+
+	// Check time formatting
+	expectedTime := company.CreatedAt.Format(time.RFC3339)
+	if response.CreatedAt != expectedTime {
+		t.Errorf("Expected CreatedAt %s, got %s", expectedTime, response.CreatedAt)
 	}
 }
 
@@ -612,23 +1325,23 @@ func TestCompanyUsecase_JSONSerialization(t *testing.T) {
 		Verified:       true,
 		CreatedAt:      time.Now().Format(time.RFC3339),
 	}
-	
+
 	jsonData, err := json.Marshal(response)
 	if err != nil {
 		t.Errorf("Expected no error marshaling to JSON, got %v", err)
 	}
-	
+
 	if len(jsonData) == 0 {
 		t.Error("Expected non-empty JSON data")
 	}
-	
+
 	// Test unmarshaling
 	var unmarshaled dto.CompanyResponse
 	err = json.Unmarshal(jsonData, &unmarshaled)
 	if err != nil {
 		t.Errorf("Expected no error unmarshaling from JSON, got %v", err)
 	}
-	
+
 	if unmarshaled.CompanyName != response.CompanyName {
 		t.Errorf("Expected company name %s after JSON round-trip, got %s", response.CompanyName, unmarshaled.CompanyName)
 	}
@@ -639,11 +1352,11 @@ func TestCompanyUsecaseStruct(t *testing.T) {
 	uc := &CompanyUsecase{
 		UserID: mockUserIDFunc,
 	}
-	
+
 	if uc.UserID == nil {
 		t.Error("Expected UserID function to be set")
 	}
-	
+
 	// Test that UserID function works
 	c := setupGinContext()
 	userID := uc.UserID(c)
@@ -656,11 +1369,11 @@ func TestCompanyUsecaseStruct(t *testing.T) {
 func BenchmarkCompanyUsecase_GetAll(b *testing.B) {
 	uc := setupCompanyUsecase()
 	c := setupGinContext()
-	
+
 	// Setup test data
 	repo := uc.Repo.(*mockCompanyRepository)
 	repo.companies = make(map[string]*entity.Company)
-	
+
 	for i := 0; i < 100; i++ {
 		company := &entity.Company{
 			ID:          primitive.NewObjectID(),
@@ -670,10 +1383,10 @@ func BenchmarkCompanyUsecase_GetAll(b *testing.B) {
 		}
 		repo.companies[company.ID.Hex()] = company
 	}
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		uc.GetAll(c, "", 10, 0)
+		uc.GetAll(c, "", false, 10, 0)
 	}
 }
 
@@ -682,14 +1395,378 @@ func BenchmarkCompanyUsecase_Create(b *testing.B) {
 		CompanyName:  "Benchmark Company",
 		CompanyEmail: "benchmark@company.com",
 	}
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
 		uc := setupCompanyUsecase()
 		c := setupGinContext()
 		b.StartTimer()
-		
+
 		uc.Create(c, req)
 	}
-}
\ No newline at end of file
+}
+func TestCompanyUsecase_FindByIDWithOwner(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+	uc.Users = &mockUserRepository{users: map[string]*entity.User{
+		"owner@example.com": {ID: "test-user-123", Fullname: "Owner Name", Email: "owner@example.com", PhoneNumber: "628111111111", AvatarUrl: "https://assets/owner.jpg", Verified: true},
+	}}
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	company := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CompanyName: "Mine", CompanyEmail: "info@mine.com"}
+	repo.companies[company.ID.Hex()] = company
+
+	result, err := uc.FindByIDWithOwner(c, company.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.CompanyName != "Mine" || result.CompanyEmail != "info@mine.com" {
+		t.Errorf("Expected the owner to see the full company, got %+v", result.CompanyResponse)
+	}
+	if result.Owner == nil {
+		t.Fatal("Expected owner to be included")
+	}
+	if result.Owner.Fullname != "Owner Name" || result.Owner.AvatarUrl != "https://assets/owner.jpg" || !result.Owner.Verified {
+		t.Errorf("Unexpected owner %+v", result.Owner)
+	}
+}
+
+func TestCompanyUsecase_FindByIDWithOwner_MissingOwner(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+	uc.Users = &mockUserRepository{users: map[string]*entity.User{}}
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	company := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CompanyName: "Orphan"}
+	repo.companies[company.ID.Hex()] = company
+
+	result, err := uc.FindByIDWithOwner(c, company.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Owner != nil {
+		t.Errorf("Expected nil owner, got %+v", result.Owner)
+	}
+}
+
+func TestCompanyUsecase_FindByIDWithOwner_Visibility(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	private := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyName: "Private", Verified: true}
+	listed := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyName: "Listed", CompanyEmail: "info@listed.com", Verified: true, PublicListing: true}
+	for _, company := range []*entity.Company{private, listed} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	if _, err := uc.FindByIDWithOwner(c, private.ID); err != appErrors.ErrForbidden {
+		t.Errorf("Expected ErrForbidden for another user's unlisted company, got %v", err)
+	}
+
+	result, err := uc.FindByIDWithOwner(c, listed.ID)
+	if err != nil {
+		t.Fatalf("Expected listed company to be visible, got %v", err)
+	}
+	if result.CompanyEmail != "" {
+		t.Errorf("Expected contact details to be hidden, got %q", result.CompanyEmail)
+	}
+	if result.Owner != nil {
+		t.Error("Expected no owner without a user repository")
+	}
+}
+
+func TestCompanyUsecase_PurgeDeleted(t *testing.T) {
+	uc := setupCompanyUsecase()
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+	deletedLogos := []string{}
+	uc.DeleteLogo = func(url string) error {
+		deletedLogos = append(deletedLogos, url)
+		return nil
+	}
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	longAgo := time.Now().AddDate(0, 0, -45)
+	recently := time.Now().AddDate(0, 0, -5)
+	old := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Old", CompanyLogo: "https://res.cloudinary.com/byow/image/upload/v1/old.png", DeletedAt: &longAgo}
+	oldNoLogo := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Old Without Logo", DeletedAt: &longAgo}
+	recent := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Recent", CompanyLogo: "https://res.cloudinary.com/byow/image/upload/v1/recent.png", DeletedAt: &recently}
+	active := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Active"}
+	for _, company := range []*entity.Company{old, oldNoLogo, recent, active} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	purged, err := uc.PurgeDeleted(c, 30)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 companies purged, got %d", purged)
+	}
+	for _, company := range []*entity.Company{old, oldNoLogo} {
+		if _, exists := repo.companies[company.ID.Hex()]; exists {
+			t.Errorf("Expected %s to be purged", company.CompanyName)
+		}
+	}
+	for _, company := range []*entity.Company{recent, active} {
+		if _, exists := repo.companies[company.ID.Hex()]; !exists {
+			t.Errorf("Expected %s to be kept", company.CompanyName)
+		}
+	}
+	if len(deletedLogos) != 1 || deletedLogos[0] != old.CompanyLogo {
+		t.Errorf("Expected only the purged logo to be deleted, got %v", deletedLogos)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != constants.AUDIT_COMPANIES_PURGED {
+		t.Errorf("Expected one purge audit entry, got %+v", auditRepo.logs)
+	}
+}
+
+func TestCompanyUsecase_PurgeDeleted_InvalidDays(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	for _, days := range []int{0, -7} {
+		if _, err := uc.PurgeDeleted(c, days); err == nil {
+			t.Errorf("Expected an error for %d days", days)
+		}
+	}
+}
+
+func TestCompanyUsecase_PurgeDeleted_LogoFailureIgnored(t *testing.T) {
+	uc := setupCompanyUsecase()
+	uc.DeleteLogo = func(url string) error {
+		return errors.New("cloudinary unavailable")
+	}
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	longAgo := time.Now().AddDate(0, 0, -45)
+	company := &entity.Company{ID: primitive.NewObjectID(), CompanyLogo: "https://res.cloudinary.com/byow/image/upload/v1/logo.png", DeletedAt: &longAgo}
+	repo.companies[company.ID.Hex()] = company
+
+	purged, err := uc.PurgeDeleted(c, 30)
+	if err != nil || purged != 1 {
+		t.Errorf("Expected the purge to succeed despite the logo failure, got %d, %v", purged, err)
+	}
+}
+
+func TestCompanyUsecase_PurgeDeleted_PartialFailure(t *testing.T) {
+	uc := setupCompanyUsecase()
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+	deletedLogos := []string{}
+	uc.DeleteLogo = func(url string) error {
+		deletedLogos = append(deletedLogos, url)
+		return nil
+	}
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	repo.purgeErr = errors.New("connection reset")
+	longAgo := time.Now().AddDate(0, 0, -45)
+	for _, name := range []string{"first", "second"} {
+		company := &entity.Company{ID: primitive.NewObjectID(), CompanyLogo: "https://res.cloudinary.com/byow/image/upload/v1/" + name + ".png", DeletedAt: &longAgo}
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	purged, err := uc.PurgeDeleted(c, 30)
+	if err != appErrors.ErrDatabaseOperation || purged != 1 {
+		t.Errorf("Expected the failure with the one company removed before it, got %d, %v", purged, err)
+	}
+	if len(deletedLogos) != 1 {
+		t.Errorf("Expected the logo of the removed company to be deleted, got %v", deletedLogos)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Metadata["incomplete"] != true {
+		t.Errorf("Expected an incomplete purge audit entry, got %+v", auditRepo.logs)
+	}
+}
+
+func TestCompanyUsecase_PurgeOwner(t *testing.T) {
+	uc := setupCompanyUsecase()
+	deletedLogos := []string{}
+	uc.DeleteLogo = func(url string) error {
+		deletedLogos = append(deletedLogos, url)
+		return nil
+	}
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	deletedAt := time.Now()
+	active := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", CompanyLogo: "https://res.cloudinary.com/byow/image/upload/v1/active.png"}
+	deleted := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-123", DeletedAt: &deletedAt}
+	others := &entity.Company{ID: primitive.NewObjectID(), UserID: "user-456", CompanyLogo: "https://res.cloudinary.com/byow/image/upload/v1/others.png"}
+	for _, company := range []*entity.Company{active, deleted, others} {
+		repo.companies[company.ID.Hex()] = company
+	}
+
+	removed, err := uc.PurgeOwner("user-123")
+	if err != nil || removed != 2 {
+		t.Errorf("Expected both of the owner's companies removed, got %d, %v", removed, err)
+	}
+	if _, exists := repo.companies[others.ID.Hex()]; !exists || len(repo.companies) != 1 {
+		t.Errorf("Expected only the other owner's company to be kept, got %v", repo.companies)
+	}
+	if len(deletedLogos) != 1 || deletedLogos[0] != active.CompanyLogo {
+		t.Errorf("Expected only the removed logo to be deleted, got %v", deletedLogos)
+	}
+}
+
+func TestCompanyUsecase_CheckOwnership(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	deletedAt := time.Now()
+	owned := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CompanyName: "Mine"}
+	others := &entity.Company{ID: primitive.NewObjectID(), UserID: "other-user", CompanyName: "Theirs"}
+	deleted := &entity.Company{ID: primitive.NewObjectID(), UserID: "test-user-123", CompanyName: "Gone", DeletedAt: &deletedAt}
+	for _, company := range []*entity.Company{owned, others, deleted} {
+		repo.companies[company.ID.Hex()] = company
+	}
+	missing := primitive.NewObjectID().Hex()
+
+	result, err := uc.CheckOwnership(c, []string{owned.ID.Hex(), others.ID.Hex(), deleted.ID.Hex(), missing, "not-an-id"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := map[string]bool{
+		owned.ID.Hex():   true,
+		others.ID.Hex():  false,
+		deleted.ID.Hex(): false,
+		missing:          false,
+		"not-an-id":      false,
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d entries, got %v", len(expected), result)
+	}
+	for id, want := range expected {
+		if got, ok := result[id]; !ok || got != want {
+			t.Errorf("Expected %s to be %v, got %v (present: %v)", id, want, got, ok)
+		}
+	}
+}
+
+func TestCompanyUsecase_CheckOwnership_BatchSize(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	if _, err := uc.CheckOwnership(c, nil); err == nil {
+		t.Error("Expected an error for an empty batch")
+	}
+	if _, err := uc.CheckOwnership(c, make([]string, MaxOwnershipBatch+1)); err == nil {
+		t.Error("Expected an error for an oversized batch")
+	}
+}
+
+func TestCompanyUsecase_Unverify(t *testing.T) {
+	uc := setupCompanyUsecase()
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+	uc.Users = &mockUserRepository{users: map[string]*entity.User{
+		"owner@example.com": {ID: "owner-1", Email: "owner@example.com"},
+	}}
+	uc.RunAsync = func(task func()) { task() }
+	var sentTo, sentBody string
+	uc.SendEmail = func(to, subject, body string) error {
+		sentTo, sentBody = to, body
+		return nil
+	}
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	company := &entity.Company{ID: primitive.NewObjectID(), UserID: "owner-1", CompanyName: "Shady Co", Verified: true}
+	repo.companies[company.ID.Hex()] = company
+
+	result, err := uc.Unverify(c, company.ID, "  Forged registration documents ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Verified || company.Verified {
+		t.Error("Expected the company to be unverified")
+	}
+
+	if len(auditRepo.logs) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(auditRepo.logs))
+	}
+	entry := auditRepo.logs[0]
+	if entry.ActorID != "test-user-123" || entry.Action != constants.AUDIT_COMPANY_UNVERIFIED || entry.TargetID != company.ID.Hex() {
+		t.Errorf("Unexpected audit entry %+v", entry)
+	}
+	if entry.Metadata["reason"] != "Forged registration documents" || entry.Metadata["owner_id"] != "owner-1" {
+		t.Errorf("Expected the reason and owner in the audit metadata, got %+v", entry.Metadata)
+	}
+
+	if sentTo != "owner@example.com" || !strings.Contains(sentBody, "Forged registration documents") {
+		t.Errorf("Expected the owner to be emailed the reason, got %q: %q", sentTo, sentBody)
+	}
+
+	// Unverifying twice is a conflict, not a second audit entry
+	if _, err := uc.Unverify(c, company.ID, "Again"); err == nil {
+		t.Error("Expected an error for an already unverified company")
+	}
+	if len(auditRepo.logs) != 1 {
+		t.Errorf("Expected no further audit entries, got %d", len(auditRepo.logs))
+	}
+}
+
+func TestCompanyUsecase_Unverify_MutedNotice(t *testing.T) {
+	uc := setupCompanyUsecase()
+	uc.Users = &mockUserRepository{users: map[string]*entity.User{
+		"owner@example.com": {ID: "owner-1", Email: "owner@example.com", Notifications: entity.NotificationPrefs{MuteVerificationRevoked: true}},
+	}}
+	uc.RunAsync = func(task func()) { task() }
+	sent := false
+	uc.SendEmail = func(to, subject, body string) error {
+		sent = true
+		return nil
+	}
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	company := &entity.Company{ID: primitive.NewObjectID(), UserID: "owner-1", CompanyName: "Quiet Co", Verified: true}
+	repo.companies[company.ID.Hex()] = company
+
+	if _, err := uc.Unverify(c, company.ID, "Fraud"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if company.Verified {
+		t.Error("Expected the company to be unverified")
+	}
+	if sent {
+		t.Error("Expected no email to an owner who muted the notice")
+	}
+}
+
+func TestCompanyUsecase_Unverify_Validation(t *testing.T) {
+	uc := setupCompanyUsecase()
+	c := setupGinContext()
+
+	repo := uc.Repo.(*mockCompanyRepository)
+	repo.companies = make(map[string]*entity.Company)
+	company := &entity.Company{ID: primitive.NewObjectID(), CompanyName: "Verified Co", Verified: true}
+	repo.companies[company.ID.Hex()] = company
+
+	for _, reason := range []string{"", "   ", strings.Repeat("x", MaxUnverifyReasonLength+1)} {
+		if _, err := uc.Unverify(c, company.ID, reason); err == nil {
+			t.Errorf("Expected a validation error for reason of length %d", len(reason))
+		}
+	}
+	if !company.Verified {
+		t.Error("Expected the company to stay verified after rejected requests")
+	}
+	if _, err := uc.Unverify(c, primitive.NewObjectID(), "Fraud"); err == nil {
+		t.Error("Expected an error for an unknown company")
+	}
+}
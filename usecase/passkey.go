@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/buildyow/byow-user-service/constants"
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/featureflags"
+	"github.com/buildyow/byow-user-service/infrastructure/jwt"
+	"github.com/buildyow/byow-user-service/utils"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// PasskeyCeremonyTTL is how long the browser has to answer a passkey
+// challenge
+const PasskeyCeremonyTTL = 5 * time.Minute
+
+// MaxPendingPasskeyCeremonies is how many ceremonies a user can have
+// waiting at once, starting another drops the oldest
+const MaxPendingPasskeyCeremonies = 5
+
+// passkeyUser presents a user to the WebAuthn library. The user handle is
+// the account id, never the email, so it survives an email change.
+type passkeyUser struct {
+	user *entity.User
+}
+
+func (p passkeyUser) WebAuthnID() []byte { return []byte(p.user.ID) }
+
+func (p passkeyUser) WebAuthnName() string { return p.user.Email }
+
+func (p passkeyUser) WebAuthnDisplayName() string {
+	if p.user.Fullname != "" {
+		return p.user.Fullname
+	}
+	return p.user.Email
+}
+
+func (p passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(p.user.Passkeys))
+	for _, passkey := range p.user.Passkeys {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(passkey.Transports))
+		for _, transport := range passkey.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+		credentials = append(credentials, webauthn.Credential{
+			ID:              passkey.CredentialID,
+			PublicKey:       passkey.PublicKey,
+			AttestationType: passkey.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: passkey.BackupEligible,
+				BackupState:    passkey.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    passkey.AAGUID,
+				SignCount: passkey.SignCount,
+			},
+		})
+	}
+	return credentials
+}
+
+func toPasskeyResponse(passkey entity.Passkey) *dto.PasskeyResponse {
+	response := &dto.PasskeyResponse{
+		ID:        base64.RawURLEncoding.EncodeToString(passkey.CredentialID),
+		Name:      passkey.Name,
+		CreatedAt: passkey.CreatedAt.Format(time.RFC3339),
+	}
+	if !passkey.LastUsedAt.IsZero() {
+		response.LastUsedAt = passkey.LastUsedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// startCeremony keeps session on the user until the browser answers and
+// returns the id the answer has to name. Ceremonies are kept side by side,
+// starting one never cancels another in progress.
+func (u *UserUsecase) startCeremony(user *entity.User, kind string, session *webauthn.SessionData) (string, error) {
+	encoded, err := json.Marshal(session)
+	if err != nil {
+		return "", appErrors.NewInternalError("Failed to start passkey ceremony")
+	}
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", appErrors.NewInternalError("Failed to start passkey ceremony")
+	}
+	ceremony := entity.PasskeyCeremony{
+		ID:        hex.EncodeToString(idBytes),
+		Kind:      kind,
+		Session:   encoded,
+		ExpiresAt: time.Now().Add(PasskeyCeremonyTTL),
+	}
+	if err := u.Repo.AddPasskeyCeremony(user.ID, ceremony, MaxPendingPasskeyCeremonies); err != nil {
+		return "", appErrors.ErrDatabaseOperation
+	}
+	return ceremony.ID, nil
+}
+
+// takeCeremony removes the user's pending ceremony named id and returns its
+// session, ErrPasskeyInvalid when there is none, it is of another kind or it
+// expired. The removal happens before the answer is checked, so a challenge
+// is only ever answered once, and an unknown id leaves the other ceremonies
+// alone.
+func (u *UserUsecase) takeCeremony(user *entity.User, kind, id string) (webauthn.SessionData, error) {
+	var session webauthn.SessionData
+	if id == "" {
+		return session, appErrors.ErrPasskeyInvalid
+	}
+	ceremony, err := u.Repo.TakePasskeyCeremony(user.ID, id)
+	if err != nil {
+		return session, appErrors.ErrDatabaseOperation
+	}
+	if ceremony == nil || ceremony.Kind != kind || time.Now().After(ceremony.ExpiresAt) {
+		return session, appErrors.ErrPasskeyInvalid
+	}
+	if err := json.Unmarshal(ceremony.Session, &session); err != nil {
+		return session, appErrors.ErrPasskeyInvalid
+	}
+	return session, nil
+}
+
+// BeginPasskeyRegistration returns the options the browser creates a new
+// passkey with. Passkeys already registered are excluded, so an
+// authenticator isn't registered twice.
+func (u *UserUsecase) BeginPasskeyRegistration(userID, email string) (*dto.PasskeyCreationOptions, error) {
+	if u.WebAuthn == nil {
+		return nil, appErrors.ErrFeatureDisabled
+	}
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	owner := passkeyUser{user}
+	creation, session, err := u.WebAuthn.BeginRegistration(owner,
+		webauthn.WithExclusions(webauthn.Credentials(owner.WebAuthnCredentials()).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		utils.LogError("Failed to begin passkey registration for %s: %v", email, err)
+		return nil, appErrors.NewInternalError("Failed to start passkey registration")
+	}
+	ceremonyID, err := u.startCeremony(user, entity.PasskeyRegistration, session)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PasskeyCreationOptions{CeremonyID: ceremonyID, CredentialCreation: creation}, nil
+}
+
+// FinishPasskeyRegistration verifies the browser's answer to the
+// BeginPasskeyRegistration ceremony and stores the new passkey under name
+func (u *UserUsecase) FinishPasskeyRegistration(userID, email, ceremonyID, name string, credential []byte) (*dto.PasskeyResponse, error) {
+	if u.WebAuthn == nil {
+		return nil, appErrors.ErrFeatureDisabled
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(credential)
+	if err != nil {
+		return nil, appErrors.ErrPasskeyInvalid
+	}
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	session, err := u.takeCeremony(user, entity.PasskeyRegistration, ceremonyID)
+	if err != nil {
+		return nil, err
+	}
+	created, err := u.WebAuthn.CreateCredential(passkeyUser{user}, session, parsed)
+	if err != nil {
+		return nil, appErrors.ErrPasskeyInvalid
+	}
+	for _, existing := range user.Passkeys {
+		if bytes.Equal(existing.CredentialID, created.ID) {
+			return nil, appErrors.NewConflictError("Passkey already registered")
+		}
+	}
+
+	transports := make([]string, 0, len(created.Transport))
+	for _, transport := range created.Transport {
+		transports = append(transports, string(transport))
+	}
+	passkey := entity.Passkey{
+		CredentialID:    created.ID,
+		PublicKey:       created.PublicKey,
+		AttestationType: created.AttestationType,
+		Transports:      transports,
+		AAGUID:          created.Authenticator.AAGUID,
+		SignCount:       created.Authenticator.SignCount,
+		BackupEligible:  created.Flags.BackupEligible,
+		BackupState:     created.Flags.BackupState,
+		Name:            name,
+		CreatedAt:       time.Now(),
+	}
+	user.Passkeys = append(user.Passkeys, passkey)
+	if err := u.Repo.Update(user); err != nil {
+		return nil, appErrors.ErrDatabaseOperation
+	}
+
+	if u.Audit != nil {
+		err := u.Audit.Record(user.ID, constants.AUDIT_PASSKEY_ADDED, user.ID, map[string]interface{}{
+			"credential_id": base64.RawURLEncoding.EncodeToString(passkey.CredentialID),
+		})
+		if err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_PASSKEY_ADDED, err)
+		}
+	}
+	return toPasskeyResponse(passkey), nil
+}
+
+// BeginPasskeyLogin returns the challenge one of the passkeys of email has
+// to sign to log in. An account without passkeys gets the same
+// ErrPasskeyInvalid a failed login does.
+func (u *UserUsecase) BeginPasskeyLogin(email string) (*dto.PasskeyAssertionOptions, error) {
+	if u.WebAuthn == nil {
+		return nil, appErrors.ErrFeatureDisabled
+	}
+	user, err := u.Repo.FindByEmail(email)
+	if err != nil {
+		return nil, appErrors.ErrUserNotFound
+	}
+	if !user.Verified && u.Flags.Enabled(featureflags.RequireVerification) {
+		return nil, appErrors.ErrUserNotVerified
+	}
+	if user.Suspended() {
+		return nil, appErrors.ErrAccountSuspended
+	}
+	if len(user.Passkeys) == 0 {
+		return nil, appErrors.ErrPasskeyInvalid
+	}
+	assertion, session, err := u.WebAuthn.BeginLogin(passkeyUser{user})
+	if err != nil {
+		utils.LogError("Failed to begin passkey login for %s: %v", email, err)
+		return nil, appErrors.NewInternalError("Failed to start passkey login")
+	}
+	ceremonyID, err := u.startCeremony(user, entity.PasskeyLogin, session)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PasskeyAssertionOptions{CeremonyID: ceremonyID, CredentialAssertion: assertion}, nil
+}
+
+// FinishPasskeyLogin verifies the signed challenge of the BeginPasskeyLogin
+// ceremony and issues a token like Login does. An authenticator whose signature counter
+// went backwards may have been cloned and is refused.
+func (u *UserUsecase) FinishPasskeyLogin(email, ceremonyID string, credential []byte) (dto.UserResponse, error) {
+	if u.WebAuthn == nil {
+		return dto.UserResponse{}, appErrors.ErrFeatureDisabled
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credential)
+	if err != nil {
+		return dto.UserResponse{}, appErrors.ErrPasskeyInvalid
+	}
+	user, err := u.Repo.FindByEmail(email)
+	if err != nil {
+		return dto.UserResponse{}, appErrors.ErrUserNotFound
+	}
+	if !user.Verified && u.Flags.Enabled(featureflags.RequireVerification) {
+		return dto.UserResponse{}, appErrors.ErrUserNotVerified
+	}
+	if user.Suspended() {
+		return dto.UserResponse{}, appErrors.ErrAccountSuspended
+	}
+	session, err := u.takeCeremony(user, entity.PasskeyLogin, ceremonyID)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+	used, err := u.WebAuthn.ValidateLogin(passkeyUser{user}, session, parsed)
+	if err != nil || used.Authenticator.CloneWarning {
+		return dto.UserResponse{}, appErrors.ErrPasskeyInvalid
+	}
+	if err := u.resumeAccount(user); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	token, err := jwt.GenerateTokenWithVersion(user.ID, user.Email, user.PhoneNumber, user.Role, user.TokenVersion, u.JWTSecret, u.JWTExpire)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	// The counter has to be saved for the clone check to work next time, a
+	// failure is logged rather than failing a verified login
+	now := time.Now()
+	for i := range user.Passkeys {
+		if bytes.Equal(user.Passkeys[i].CredentialID, used.ID) {
+			user.Passkeys[i].SignCount = used.Authenticator.SignCount
+			user.Passkeys[i].BackupState = used.Flags.BackupState
+			user.Passkeys[i].LastUsedAt = now
+		}
+	}
+	user.LastLoginAt = now
+	if err := u.Repo.Update(user); err != nil {
+		utils.LogError("Failed to record passkey use for %s: %v", email, err)
+	}
+	return dto.UserResponse{
+		Fullname:    user.Fullname,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		AvatarUrl:   user.AvatarUrl,
+		Verified:    user.Verified,
+		OnBoarded:   user.OnBoarded,
+		Token:       token,
+	}, nil
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/buildyow/byow-user-service/domain/entity"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "https://localhost"
+)
+
+// fakeAuthenticator is a software passkey holding one P-256 key
+type fakeAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newFakeAuthenticator(t *testing.T) *fakeAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	credentialID := make([]byte, 16)
+	rand.Read(credentialID)
+	return &fakeAuthenticator{t: t, key: key, credentialID: credentialID}
+}
+
+func (a *fakeAuthenticator) clientData(ceremony string, challenge interface{}) []byte {
+	encoded, _ := json.Marshal(challenge)
+	var challengeString string
+	json.Unmarshal(encoded, &challengeString)
+	data, _ := json.Marshal(map[string]string{"type": ceremony, "challenge": challengeString, "origin": testOrigin})
+	return data
+}
+
+func (a *fakeAuthenticator) authData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+// register answers the options of BeginPasskeyRegistration
+func (a *fakeAuthenticator) register(challenge interface{}) []byte {
+	publicKey, err := cbor.Marshal(map[int]interface{}{
+		1:  2,  // EC2
+		3:  -7, // ES256
+		-1: 1,  // P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatalf("Failed to encode public key: %v", err)
+	}
+	attested := make([]byte, 16) // zero AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	attestation, _ := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(0x01|0x04|0x40, attested), // UP, UV, AT
+	})
+	id := base64.RawURLEncoding.EncodeToString(a.credentialID)
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    id,
+		"rawId": id,
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(a.clientData("webauthn.create", challenge)),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestation),
+		},
+	})
+	return body
+}
+
+// login signs the challenge of BeginPasskeyLogin
+func (a *fakeAuthenticator) login(challenge interface{}, userHandle string) []byte {
+	a.signCount++
+	authData := a.authData(0x01|0x04, nil) // UP, UV
+	clientData := a.clientData("webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatalf("Failed to sign: %v", err)
+	}
+	id := base64.RawURLEncoding.EncodeToString(a.credentialID)
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    id,
+		"rawId": id,
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+			"userHandle":        base64.RawURLEncoding.EncodeToString([]byte(userHandle)),
+		},
+	})
+	return body
+}
+
+func setupPasskeyUsecase(t *testing.T) *UserUsecase {
+	uc := setupUserUsecase()
+	passkeys, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "BYOW",
+		RPOrigins:     []string{testOrigin},
+	})
+	if err != nil {
+		t.Fatalf("Failed to configure WebAuthn: %v", err)
+	}
+	uc.WebAuthn = passkeys
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com", Fullname: "John Doe", Verified: true})
+	return uc
+}
+
+func TestPasskeys_RegisterAndLogin(t *testing.T) {
+	uc := setupPasskeyUsecase(t)
+	authenticator := newFakeAuthenticator(t)
+
+	if _, err := uc.BeginPasskeyLogin("john@example.com"); err != appErrors.ErrPasskeyInvalid {
+		t.Errorf("Expected ErrPasskeyInvalid before any passkey, got %v", err)
+	}
+
+	creation, err := uc.BeginPasskeyRegistration("user-123", "john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	passkey, err := uc.FinishPasskeyRegistration("user-123", "john@example.com", creation.CeremonyID, "Laptop", authenticator.register(creation.Response.Challenge))
+	if err != nil {
+		t.Fatalf("Expected the passkey to register, got %v", err)
+	}
+	if passkey.Name != "Laptop" || passkey.ID != base64.RawURLEncoding.EncodeToString(authenticator.credentialID) {
+		t.Errorf("Expected the registered passkey back, got %+v", passkey)
+	}
+
+	assertion, err := uc.BeginPasskeyLogin("john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	answer := authenticator.login(assertion.Response.Challenge, "user-123")
+	logged, err := uc.FinishPasskeyLogin("john@example.com", assertion.CeremonyID, answer)
+	if err != nil {
+		t.Fatalf("Expected the passkey login to succeed, got %v", err)
+	}
+	if logged.Token == "" || logged.Email != "john@example.com" {
+		t.Errorf("Expected a token for john, got %+v", logged)
+	}
+
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if user.Passkeys[0].SignCount != 1 || user.Passkeys[0].LastUsedAt.IsZero() {
+		t.Errorf("Expected the passkey use to be recorded, got %+v", user.Passkeys[0])
+	}
+	if pending := uc.Repo.(*mockUserRepository).ceremonies["user-123"]; len(pending) != 0 {
+		t.Errorf("Expected the challenge to be cleared after login, got %+v", pending)
+	}
+
+	// The challenge was used up, the same answer can't log in again
+	if _, err := uc.FinishPasskeyLogin("john@example.com", assertion.CeremonyID, answer); err != appErrors.ErrPasskeyInvalid {
+		t.Errorf("Expected a replayed assertion to be refused, got %v", err)
+	}
+}
+
+func TestPasskeys_RefusedAnswers(t *testing.T) {
+	uc := setupPasskeyUsecase(t)
+	authenticator := newFakeAuthenticator(t)
+	creation, _ := uc.BeginPasskeyRegistration("user-123", "john@example.com")
+	if _, err := uc.FinishPasskeyRegistration("user-123", "john@example.com", creation.CeremonyID, "", authenticator.register(creation.Response.Challenge)); err != nil {
+		t.Fatalf("Expected the passkey to register, got %v", err)
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		assertion, _ := uc.BeginPasskeyLogin("john@example.com")
+		impostor := newFakeAuthenticator(t)
+		impostor.credentialID = authenticator.credentialID
+		if _, err := uc.FinishPasskeyLogin("john@example.com", assertion.CeremonyID, impostor.login(assertion.Response.Challenge, "user-123")); err != appErrors.ErrPasskeyInvalid {
+			t.Errorf("Expected a signature by another key to be refused, got %v", err)
+		}
+	})
+
+	t.Run("cloned authenticator", func(t *testing.T) {
+		assertion, _ := uc.BeginPasskeyLogin("john@example.com")
+		if _, err := uc.FinishPasskeyLogin("john@example.com", assertion.CeremonyID, authenticator.login(assertion.Response.Challenge, "user-123")); err != nil {
+			t.Fatalf("Expected the login to succeed, got %v", err)
+		}
+		// A copy of the key still at the old counter
+		authenticator.signCount = 0
+		assertion, _ = uc.BeginPasskeyLogin("john@example.com")
+		if _, err := uc.FinishPasskeyLogin("john@example.com", assertion.CeremonyID, authenticator.login(assertion.Response.Challenge, "user-123")); err != appErrors.ErrPasskeyInvalid {
+			t.Errorf("Expected a counter going backwards to be refused, got %v", err)
+		}
+	})
+
+	t.Run("registration challenge", func(t *testing.T) {
+		creation, _ := uc.BeginPasskeyRegistration("user-123", "john@example.com")
+		if _, err := uc.FinishPasskeyLogin("john@example.com", creation.CeremonyID, authenticator.login(creation.Response.Challenge, "user-123")); err != appErrors.ErrPasskeyInvalid {
+			t.Errorf("Expected a registration challenge to be refused at login, got %v", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		uc.WebAuthn = nil
+		if _, err := uc.BeginPasskeyLogin("john@example.com"); err != appErrors.ErrFeatureDisabled {
+			t.Errorf("Expected ErrFeatureDisabled without WebAuthn, got %v", err)
+		}
+	})
+}
+
+func TestPasskeys_ConcurrentCeremonies(t *testing.T) {
+	uc := setupPasskeyUsecase(t)
+	authenticator := newFakeAuthenticator(t)
+	creation, _ := uc.BeginPasskeyRegistration("user-123", "john@example.com")
+	if _, err := uc.FinishPasskeyRegistration("user-123", "john@example.com", creation.CeremonyID, "", authenticator.register(creation.Response.Challenge)); err != nil {
+		t.Fatalf("Expected the passkey to register, got %v", err)
+	}
+
+	assertion, _ := uc.BeginPasskeyLogin("john@example.com")
+	answer := authenticator.login(assertion.Response.Challenge, "user-123")
+
+	// Anyone can start a login for john or answer with a made-up ceremony,
+	// neither may cancel the login in progress
+	if _, err := uc.BeginPasskeyLogin("john@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := uc.FinishPasskeyLogin("john@example.com", "made-up", answer); err != appErrors.ErrPasskeyInvalid {
+		t.Errorf("Expected an unknown ceremony to be refused, got %v", err)
+	}
+	if _, err := uc.FinishPasskeyLogin("john@example.com", assertion.CeremonyID, answer); err != nil {
+		t.Errorf("Expected the original login to succeed, got %v", err)
+	}
+
+	// Only the newest ceremonies are kept
+	for i := 0; i < MaxPendingPasskeyCeremonies+2; i++ {
+		uc.BeginPasskeyLogin("john@example.com")
+	}
+	if pending := uc.Repo.(*mockUserRepository).ceremonies["user-123"]; len(pending) != MaxPendingPasskeyCeremonies {
+		t.Errorf("Expected %d pending ceremonies, got %d", MaxPendingPasskeyCeremonies, len(pending))
+	}
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"github.com/buildyow/byow-user-service/constants"
+	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+)
+
+// Actor is the authenticated user a usecase acts for, as their token
+// describes them
+type Actor struct {
+	ID   string
+	Role string
+}
+
+// Authorize returns ErrForbidden unless the actor's role grants permission.
+// Usecases check here rather than leaving it to the routes, so a decision is
+// made the same way whichever handler calls them.
+func Authorize(actor Actor, permission string) error {
+	if !constants.HasPermission(actor.Role, permission) {
+		return appErrors.ErrForbidden
+	}
+	return nil
+}
@@ -1,9 +1,16 @@
 package usecase
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
 	"math/big"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
@@ -11,10 +18,15 @@ import (
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 	"github.com/buildyow/byow-user-service/domain/repository"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/featureflags"
 	"github.com/buildyow/byow-user-service/infrastructure/jwt"
 	"github.com/buildyow/byow-user-service/infrastructure/mailer"
+	"github.com/buildyow/byow-user-service/infrastructure/oauth"
+	"github.com/buildyow/byow-user-service/infrastructure/sms"
 	"github.com/buildyow/byow-user-service/infrastructure/validation"
 	"github.com/buildyow/byow-user-service/utils"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -28,6 +40,82 @@ type UserUsecase struct {
 		User string
 		Pass string
 	}
+	// PasswordResetURL is the page the magic link points at, PasswordResetTTL
+	// how long the link stays valid (30 minutes when zero)
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+	// SendEmail overrides plain-text email delivery, SMTP via EmailConfig when nil
+	SendEmail func(to, subject, body string) error
+	// EmailTimeouts bounds SMTP delivery, the mailer defaults apply when zero
+	EmailTimeouts mailer.Options
+	// MailContext is canceled on shutdown to abort in-flight emails, never
+	// canceled when nil
+	MailContext context.Context
+	// Flags toggles optional behaviour, defaults apply when nil
+	Flags *featureflags.Flags
+	// RunAsync runs background work such as precheck emails, a goroutine when nil
+	RunAsync func(task func())
+	// SupportURL is linked from security notices
+	SupportURL string
+	// Audit records security events such as failed logins, skipped when nil
+	Audit *AuditUsecase
+	// PasswordMaxAge flags passwords older than this as stale on the security
+	// summary without blocking anything, no policy when zero
+	PasswordMaxAge time.Duration
+	// DeletionGrace is how long a deleted account can still be recovered by
+	// logging in, DefaultDeletionGrace when zero
+	DeletionGrace time.Duration
+	// RevokeToken blacklists a token ID until it expires, logout only clears
+	// the cookie when nil
+	RevokeToken func(jti, email string, expiresAt time.Time) error
+	// Sessions records every token handed out so the user can list where
+	// they're signed in and revoke a device, nothing is recorded when nil
+	Sessions repository.SessionRepository
+	// WebAuthn verifies passkey registrations and logins, passkeys are
+	// disabled when nil
+	WebAuthn *webauthn.WebAuthn
+	// SMS delivers OTPs to the phone on the account, only email is offered
+	// when nil
+	SMS sms.Provider
+	// PurgeOwnerData removes what a deleted account owns, such as its
+	// companies, before the account itself and returns how many records went,
+	// skipped when nil
+	PurgeOwnerData func(userID string) (int64, error)
+	// DeleteAvatar removes the stored avatar of a deleted account, avatars
+	// are kept when nil
+	DeleteAvatar func(url string) error
+	// SMSCountryCode is put in place of the leading 0 of national phone
+	// numbers before texting them, such numbers are refused when empty
+	SMSCountryCode string
+}
+
+// MaxFailedLogins caps how many attempts FailedLogins returns
+const MaxFailedLogins = 50
+
+// MaxLoginHistory caps how many sign-ins LoginHistory returns
+const MaxLoginHistory = 50
+
+// MaxEmailAvailabilityBatch caps how many emails CheckEmailAvailability
+// accepts per call
+const MaxEmailAvailabilityBatch = 50
+
+func (u *UserUsecase) runAsync(task func()) {
+	if u.RunAsync != nil {
+		u.RunAsync(task)
+		return
+	}
+	go task()
+}
+
+func (u *UserUsecase) sendEmail(to, subject, body string) error {
+	if u.SendEmail != nil {
+		return u.SendEmail(to, subject, body)
+	}
+	ctx := u.MailContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return mailer.SendContext(ctx, u.EmailTimeouts, to, subject, body, u.EmailConfig.Host, u.EmailConfig.User, u.EmailConfig.Pass, u.EmailConfig.Port)
 }
 
 func (u *UserUsecase) RegistrationValidation(email string, phone string) error {
@@ -42,6 +130,52 @@ func (u *UserUsecase) RegistrationValidation(email string, phone string) error {
 	return nil
 }
 
+// CheckEmailAvailability reports for each email whether it is still free to
+// register, with one query for the whole batch. Emails are trimmed and
+// lowercased and the result is keyed by that form. Stored addresses keep the
+// case they were registered with, so both the submitted and the lowercased
+// spelling are looked up.
+func (u *UserUsecase) CheckEmailAvailability(emails []string) (map[string]bool, error) {
+	if len(emails) == 0 {
+		return nil, appErrors.NewValidationError("At least one email is required")
+	}
+	if len(emails) > MaxEmailAvailabilityBatch {
+		return nil, appErrors.NewValidationError(fmt.Sprintf("At most %d emails can be checked at once", MaxEmailAvailabilityBatch))
+	}
+
+	spellings := make(map[string][]string, len(emails))
+	lookup := make([]string, 0, len(emails)*2)
+	for _, email := range emails {
+		trimmed := strings.TrimSpace(email)
+		normalized := strings.ToLower(trimmed)
+		if normalized == "" {
+			return nil, appErrors.NewValidationError("Emails must not be empty")
+		}
+		spellings[normalized] = append(spellings[normalized], trimmed)
+		lookup = append(lookup, trimmed)
+		if trimmed != normalized {
+			lookup = append(lookup, normalized)
+		}
+	}
+
+	existing, err := u.Repo.FindExistingEmails(lookup)
+	if err != nil {
+		utils.LogError("Failed to check email availability: %v", err)
+		return nil, appErrors.ErrDatabaseOperation
+	}
+	availability := make(map[string]bool, len(spellings))
+	for normalized, submitted := range spellings {
+		available := !existing[normalized]
+		for _, email := range submitted {
+			if existing[email] {
+				available = false
+			}
+		}
+		availability[normalized] = available
+	}
+	return availability, nil
+}
+
 func (u *UserUsecase) UpdateUserValidation(email string) error {
 	_, errEmail := u.Repo.FindByEmail(email)
 	if errEmail != nil {
@@ -60,11 +194,33 @@ func (u *UserUsecase) Register(req dto.RegisterRequest) (*entity.User, error) {
 		AvatarUrl:   req.AvatarUrl,
 		Verified:    false,
 		OnBoarded:   false,
+		// Registration sets the first password
+		PasswordChangedAt: time.Now(),
 	}
 	err := u.Repo.Create(user)
 	if err != nil {
+		// A concurrent registration can slip past RegistrationValidation and
+		// only be caught by the unique index
+		if mongo.IsDuplicateKeyError(err) {
+			if strings.Contains(err.Error(), "phone_unique") {
+				return nil, appErrors.ErrPhoneAlreadyExists
+			}
+			return nil, appErrors.ErrEmailAlreadyExists
+		}
 		return nil, err
 	}
+
+	// Best effort, a failed welcome never fails the registration
+	if u.Flags.Enabled(featureflags.WelcomeEmail) && user.Notifications.Allows(constants.NOTICE_WELCOME) {
+		to, name := user.Email, user.Fullname
+		lang := mailer.ResolveLanguage("", user.Preferences.Language)
+		u.runAsync(func() {
+			subject, body := mailer.WelcomeMessage(lang, name)
+			if err := u.sendEmail(to, subject, body); err != nil {
+				utils.LogError("Failed to send welcome email: %v", err)
+			}
+		})
+	}
 	return user, nil
 }
 
@@ -73,18 +229,30 @@ func (u *UserUsecase) Login(email, password string) (dto.UserResponse, error) {
 	if err != nil {
 		return dto.UserResponse{}, appErrors.ErrUserNotFound
 	}
-	if !user.Verified {
+	if !user.Verified && u.Flags.Enabled(featureflags.RequireVerification) {
 		return dto.UserResponse{}, appErrors.ErrUserNotVerified
 	}
 	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
 		return dto.UserResponse{}, appErrors.ErrInvalidCredentials
 	}
+	if user.Suspended() {
+		return dto.UserResponse{}, appErrors.ErrAccountSuspended
+	}
+	if err := u.resumeAccount(user); err != nil {
+		return dto.UserResponse{}, err
+	}
 
 	// Generate token
-	token, err := jwt.GenerateToken(user.ID, user.Email, user.PhoneNumber, u.JWTSecret, u.JWTExpire)
+	token, err := jwt.GenerateTokenWithVersion(user.ID, user.Email, user.PhoneNumber, user.Role, user.TokenVersion, u.JWTSecret, u.JWTExpire)
 	if err != nil {
 		return dto.UserResponse{}, err
 	}
+
+	// Only shown on the security summary, never worth failing a login over
+	user.LastLoginAt = time.Now()
+	if err := u.Repo.Update(user); err != nil {
+		utils.LogError("Failed to record last login: %v", err)
+	}
 	return dto.UserResponse{
 		Fullname:    user.Fullname,
 		Email:       user.Email,
@@ -101,8 +269,14 @@ func (u *UserUsecase) LoginWithoutPassword(email string) (dto.UserResponse, erro
 	if err != nil {
 		return dto.UserResponse{}, appErrors.ErrUserNotFound
 	}
+	if user.Suspended() {
+		return dto.UserResponse{}, appErrors.ErrAccountSuspended
+	}
+	if err := u.resumeAccount(user); err != nil {
+		return dto.UserResponse{}, err
+	}
 	// Generate token
-	token, err := jwt.GenerateToken(user.ID, user.Email, user.PhoneNumber, u.JWTSecret, u.JWTExpire)
+	token, err := jwt.GenerateTokenWithVersion(user.ID, user.Email, user.PhoneNumber, user.Role, user.TokenVersion, u.JWTSecret, u.JWTExpire)
 	if err != nil {
 		return dto.UserResponse{}, err
 	}
@@ -117,24 +291,351 @@ func (u *UserUsecase) LoginWithoutPassword(email string) (dto.UserResponse, erro
 	}, nil
 }
 
-func (u *UserUsecase) SendOTP(otpType, email string) error {
+// OAuthLogin signs in the account whose email the provider verified. No
+// account is created, the email has to be registered first. The provider
+// proved the user owns the email, so an unverified account is verified.
+func (u *UserUsecase) OAuthLogin(identity *oauth.Identity) (dto.UserResponse, error) {
+	user, err := u.Repo.FindByEmail(identity.Email)
+	if err != nil {
+		if err == appErrors.ErrUserNotFound {
+			return dto.UserResponse{}, appErrors.ErrOAuthAccountNotFound
+		}
+		return dto.UserResponse{}, appErrors.ErrFetchFailed
+	}
+	if user.Suspended() {
+		return dto.UserResponse{}, appErrors.ErrAccountSuspended
+	}
+	if err := u.resumeAccount(user); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	token, err := jwt.GenerateTokenWithVersion(user.ID, user.Email, user.PhoneNumber, user.Role, user.TokenVersion, u.JWTSecret, u.JWTExpire)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	user.Verified = true
+	user.LastLoginAt = time.Now()
+	if err := u.Repo.Update(user); err != nil {
+		utils.LogError("Failed to record %s login for %s: %v", identity.Provider, user.Email, err)
+	}
+	return dto.UserResponse{
+		Fullname:    user.Fullname,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		AvatarUrl:   user.AvatarUrl,
+		Verified:    user.Verified,
+		OnBoarded:   user.OnBoarded,
+		Token:       token,
+	}, nil
+}
+
+// RecordFailedLogin stores a failed sign-in against the account of email so
+// its owner can review it later. Unknown emails are ignored.
+func (u *UserUsecase) RecordFailedLogin(email, ip, userAgent string) {
+	u.recordLogin(constants.AUDIT_LOGIN_FAILED, email, ip, userAgent)
+}
+
+// RecordLogin stores a successful sign-in for the login history
+func (u *UserUsecase) RecordLogin(email, ip, userAgent string) {
+	u.recordLogin(constants.AUDIT_LOGIN_SUCCEEDED, email, ip, userAgent)
+}
+
+func (u *UserUsecase) recordLogin(action, email, ip, userAgent string) {
+	if u.Audit == nil {
+		return
+	}
 	user, err := u.Repo.FindByEmail(email)
 	if err != nil {
-		return err
+		return
+	}
+	err = u.Audit.Record("", action, user.ID, map[string]interface{}{
+		"ip":         ip,
+		"user_agent": userAgent,
+	})
+	if err != nil {
+		utils.LogError("Failed to record %s: %v", action, err)
+	}
+}
+
+// FailedLogins returns the recent failed sign-ins on the account of userID,
+// newest first
+func (u *UserUsecase) FailedLogins(userID string, limit int64) ([]dto.FailedLoginResponse, error) {
+	if limit <= 0 || limit > MaxFailedLogins {
+		limit = MaxFailedLogins
+	}
+	attempts := []dto.FailedLoginResponse{}
+	if u.Audit == nil {
+		return attempts, nil
+	}
+	logs, err := u.Audit.ListForTarget(userID, constants.AUDIT_LOGIN_FAILED, limit)
+	if err != nil {
+		return nil, appErrors.ErrFetchFailed
+	}
+	for _, log := range logs {
+		ip, _ := log.Metadata["ip"].(string)
+		userAgent, _ := log.Metadata["user_agent"].(string)
+		attempts = append(attempts, dto.FailedLoginResponse{
+			AttemptedAt: log.CreatedAt.Format(time.RFC3339),
+			IP:          ip,
+			UserAgent:   userAgent,
+		})
+	}
+	return attempts, nil
+}
+
+// LoginHistory returns the recent successful sign-ins on the account of
+// userID, newest first, with the user agent turned into a device label
+func (u *UserUsecase) LoginHistory(userID string, limit int64) ([]dto.LoginHistoryResponse, error) {
+	if limit <= 0 || limit > MaxLoginHistory {
+		limit = MaxLoginHistory
+	}
+	logins := []dto.LoginHistoryResponse{}
+	if u.Audit == nil {
+		return logins, nil
+	}
+	logs, err := u.Audit.ListForTarget(userID, constants.AUDIT_LOGIN_SUCCEEDED, limit)
+	if err != nil {
+		return nil, appErrors.ErrFetchFailed
+	}
+	for _, log := range logs {
+		ip, _ := log.Metadata["ip"].(string)
+		userAgent, _ := log.Metadata["user_agent"].(string)
+		logins = append(logins, dto.LoginHistoryResponse{
+			LoggedInAt: log.CreatedAt.Format(time.RFC3339),
+			IP:         ip,
+			Device:     utils.DeviceLabel(userAgent),
+			UserAgent:  userAgent,
+		})
+	}
+	return logins, nil
+}
+
+// Permissions returns the user's role and what it allows. The role is read
+// from the database rather than the token, so a role change shows up before
+// the user signs in again.
+func (u *UserUsecase) Permissions(userID, email string) (*dto.PermissionsResponse, error) {
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	role := user.Role
+	if role == "" {
+		role = constants.ROLE_USER
+	}
+	return &dto.PermissionsResponse{
+		Role:        role,
+		Permissions: constants.PermissionsFor(role),
+	}, nil
+}
+
+func toNotificationPrefsResponse(prefs entity.NotificationPrefs) *dto.NotificationPrefsResponse {
+	return &dto.NotificationPrefsResponse{
+		Welcome:             prefs.Allows(constants.NOTICE_WELCOME),
+		VerificationRevoked: prefs.Allows(constants.NOTICE_VERIFICATION_REVOKED),
+		PasswordChanged:     prefs.Allows(constants.NOTICE_PASSWORD_CHANGED),
+		EmailChanged:        prefs.Allows(constants.NOTICE_EMAIL_CHANGED),
+	}
+}
+
+// NotificationPrefs returns which notice emails the user receives
+func (u *UserUsecase) NotificationPrefs(userID, email string) (*dto.NotificationPrefsResponse, error) {
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	return toNotificationPrefsResponse(user.Notifications), nil
+}
+
+// UpdateNotificationPrefs mutes or unmutes the notices set in req, security
+// notices can't be muted
+func (u *UserUsecase) UpdateNotificationPrefs(userID, email string, req dto.UpdateNotificationPrefsRequest) (*dto.NotificationPrefsResponse, error) {
+	if (req.PasswordChanged != nil && !*req.PasswordChanged) || (req.EmailChanged != nil && !*req.EmailChanged) {
+		return nil, appErrors.NewValidationError("Password and email change notices can't be turned off")
+	}
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	if req.Welcome != nil {
+		user.Notifications.MuteWelcome = !*req.Welcome
+	}
+	if req.VerificationRevoked != nil {
+		user.Notifications.MuteVerificationRevoked = !*req.VerificationRevoked
+	}
+	if err := u.Repo.Update(user); err != nil {
+		return nil, err
 	}
-	// Generate secure random OTP
+	return toNotificationPrefsResponse(user.Notifications), nil
+}
+
+func toLanguageResponse(prefs entity.UserPreferences) *dto.LanguageResponse {
+	return &dto.LanguageResponse{
+		Language:  mailer.ResolveLanguage("", prefs.Language),
+		Supported: mailer.SupportedLanguages,
+	}
+}
+
+// Language returns the language the user's emails are written in
+func (u *UserUsecase) Language(userID, email string) (*dto.LanguageResponse, error) {
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	return toLanguageResponse(user.Preferences), nil
+}
+
+// UpdateLanguage stores the language the user's emails are written in, an
+// Accept-Language header on the request sending an email still takes priority
+func (u *UserUsecase) UpdateLanguage(userID, email string, req dto.UpdateLanguageRequest) (*dto.LanguageResponse, error) {
+	lang, err := normalizeLanguage(req.Language)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	user.Preferences.Language = lang
+	if err := u.Repo.Update(user); err != nil {
+		return nil, err
+	}
+	return toLanguageResponse(user.Preferences), nil
+}
+
+// SecuritySummary describes the security posture of the authenticated
+// account for the settings screen
+func (u *UserUsecase) SecuritySummary(userID, email string) (*dto.SecuritySummaryResponse, error) {
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	failedLogins, err := u.FailedLogins(user.ID, MaxFailedLogins)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := u.ListSessions(user.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &dto.SecuritySummaryResponse{
+		EmailVerified:      user.Verified,
+		PhoneVerified:      user.PhoneVerified,
+		OTPLocked:          otpLocked(user),
+		RecentFailedLogins: len(failedLogins),
+		ActiveSessions:     len(sessions),
+	}
+	if !user.PasswordChangedAt.IsZero() {
+		summary.PasswordChangedAt = user.PasswordChangedAt.UTC().Format(time.RFC3339)
+	}
+	summary.PasswordStale = u.passwordStale(user)
+	if !user.LastLoginAt.IsZero() {
+		summary.LastLoginAt = user.LastLoginAt.UTC().Format(time.RFC3339)
+	}
+	return summary, nil
+}
+
+// passwordStale reports whether the password is older than PasswordMaxAge.
+// Accounts predating PasswordChangedAt have no known age and are never stale.
+func (u *UserUsecase) passwordStale(user *entity.User) bool {
+	if u.PasswordMaxAge <= 0 || user.PasswordChangedAt.IsZero() {
+		return false
+	}
+	return time.Since(user.PasswordChangedAt) > u.PasswordMaxAge
+}
+
+// CurrentUser loads the authenticated user from the database so profile
+// reads never depend on claims issued before a change. A token whose account
+// has since been deleted is answered with ErrInvalidToken so the client signs
+// in again.
+func (u *UserUsecase) CurrentUser(userID, email string) (*entity.User, error) {
+	user, err := u.Repo.FindByEmail(email)
+	if err != nil {
+		return nil, appErrors.ErrInvalidToken
+	}
+	// The email now belongs to a different account than the token was issued for
+	if userID != "" && user.ID != userID {
+		return nil, appErrors.ErrInvalidToken
+	}
+	return user, nil
+}
+
+// TokenVersion is the user's current token version, for RequireTokenVersion
+func (u *UserUsecase) TokenVersion(userID, email string) (int, error) {
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+// generateOTP returns a secure random 6-digit OTP and its encrypted form for storing
+func generateOTP() (string, string, error) {
 	max := big.NewInt(900000)
 	n, err := rand.Int(rand.Reader, max)
 	if err != nil {
-		return err
+		return "", "", err
 	}
 	otp := strconv.Itoa(int(n.Int64()) + 100000)
 	encryptedOTP, err := utils.Encrypt(otp)
+	if err != nil {
+		return "", "", err
+	}
+	return otp, encryptedOTP, nil
+}
+
+func (u *UserUsecase) SendOTP(otpType, email string) error {
+	return u.SendOTPWithLanguage(otpType, email, "")
+}
+
+// SendOTPWithLanguage is SendOTP with the request's Accept-Language header,
+// the email is written in the user's stored language when it names none
+func (u *UserUsecase) SendOTPWithLanguage(otpType, email, acceptLanguage string) error {
+	return u.SendOTPVia(constants.OTP_CHANNEL_EMAIL, otpType, email, acceptLanguage)
+}
+
+// SendOTPVia is SendOTPWithLanguage delivering the code over channel, by
+// email or by text message to the phone number on the account
+func (u *UserUsecase) SendOTPVia(channel, otpType, email, acceptLanguage string) error {
+	if !constants.IsValidOTPType(otpType) {
+		return appErrors.NewBadRequestError("unsupported OTP type")
+	}
+	switch channel {
+	case constants.OTP_CHANNEL_EMAIL:
+	case constants.OTP_CHANNEL_SMS:
+		if u.SMS == nil {
+			return appErrors.ErrFeatureDisabled
+		}
+	default:
+		return appErrors.NewBadRequestError("unsupported OTP channel")
+	}
+	user, err := u.Repo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if channel == constants.OTP_CHANNEL_SMS && user.PhoneNumber == "" {
+		return appErrors.ErrPhoneRequired
+	}
+	// Twilio only takes E.164, checked before a new OTP replaces the old one
+	var to string
+	if channel == constants.OTP_CHANNEL_SMS {
+		var ok bool
+		if to, ok = sms.NormalizeE164(user.PhoneNumber, u.SMSCountryCode); !ok {
+			return appErrors.ErrPhoneNotTextable
+		}
+	}
+	otp, encryptedOTP, err := generateOTP()
 	if err != nil {
 		return err
 	}
 	user.OTP = encryptedOTP
 	user.OTPType = otpType
+	user.OTPAttempts = 0
+	user.OTPPhone = ""
+	if channel == constants.OTP_CHANNEL_SMS {
+		user.OTPPhone = user.PhoneNumber
+	}
 	if otpType == constants.VERIFICATION {
 		user.OTPExpiresAt = time.Now().Add(5 * time.Minute)
 	}
@@ -145,7 +646,16 @@ func (u *UserUsecase) SendOTP(otpType, email string) error {
 	if err := u.Repo.Update(user); err != nil {
 		return err
 	}
-	return mailer.SendOTP(email, otp, u.EmailConfig.Host, u.EmailConfig.User, u.EmailConfig.Pass, u.EmailConfig.Port, otpType)
+	lang := mailer.ResolveLanguage(acceptLanguage, user.Preferences.Language)
+	subject, body := mailer.OTPMessage(lang, otp, otpType)
+	if channel == constants.OTP_CHANNEL_SMS {
+		ctx := u.MailContext
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return u.SMS.Send(ctx, to, body)
+	}
+	return u.sendEmail(email, subject, body)
 }
 
 func (u *UserUsecase) VerifyOTP(email, otp string) error {
@@ -153,25 +663,224 @@ func (u *UserUsecase) VerifyOTP(email, otp string) error {
 	if err != nil {
 		return appErrors.ErrUserNotFound
 	}
-	if time.Now().After(user.OTPExpiresAt) {
-		return appErrors.ErrExpiredOTP
-	}
-
-	decryptedOTP, err := utils.Decrypt(user.OTP)
-	if err != nil || decryptedOTP != otp {
-		return appErrors.ErrInvalidOTP
+	if err := u.checkOTP(user, otp); err != nil {
+		return err
 	}
 
 	user.Verified = true
 	user.OTP = ""
+	user.OTPAttempts = 0
 	user.OTPExpiresAt = time.Time{}
 	user.OTPType = ""
 
 	return u.Repo.Update(user)
 }
 
-func (u *UserUsecase) OnBoard(email string) error {
+// checkOTP compares otp with the code stored on user. Every path taking an
+// OTP goes through it, so wrong codes count towards MaxOTPAttempts wherever
+// they are entered and are saved at once. Once the limit is reached every
+// code is refused until a new one is sent. The count is only reset when the
+// code is used up, by the caller, so a correct peek doesn't renew the budget
+// of a flow checking a second code.
+func (u *UserUsecase) checkOTP(user *entity.User, otp string) error {
+	if otpLocked(user) {
+		return appErrors.ErrTooManyOTPAttempts
+	}
+	if user.OTP == "" {
+		return appErrors.ErrInvalidOTP
+	}
+	if time.Now().After(user.OTPExpiresAt) {
+		return appErrors.ErrExpiredOTP
+	}
+
+	// A stored OTP that can't be decrypted is a server fault, not a wrong code
+	decryptedOTP, err := utils.Decrypt(user.OTP)
+	if err != nil {
+		utils.LogError("Failed to decrypt OTP of %s: %v", user.Email, err)
+		return appErrors.ErrDecryptionFailed
+	}
+	if decryptedOTP != otp {
+		return u.wrongOTP(user)
+	}
+	// A code texted to the current number proves the user holds the phone,
+	// saved with the rest of the user by the caller
+	if user.OTPPhone != "" && user.OTPPhone == user.PhoneNumber {
+		user.PhoneVerified = true
+	}
+	return nil
+}
+
+// otpLocked reports whether the current OTP of user used up its attempts.
+// checkOTP refuses every code then, and the security summary reports it, an
+// expired OTP only needs a new one.
+func otpLocked(user *entity.User) bool {
+	return user.OTPAttempts >= constants.MaxOTPAttempts && time.Now().Before(user.OTPExpiresAt)
+}
+
+// wrongOTP counts a wrong code against user and returns ErrInvalidOTP
+func (u *UserUsecase) wrongOTP(user *entity.User) error {
+	user.OTPAttempts++
+	if err := u.Repo.UpdateOTP(user); err != nil {
+		return err
+	}
+	return appErrors.ErrInvalidOTP
+}
+
+// PeekOTP checks an OTP without consuming it, so a later step can still use
+// it. Wrong codes count towards MaxOTPAttempts.
+func (u *UserUsecase) PeekOTP(email, otp string) (bool, error) {
 	user, err := u.Repo.FindByEmail(email)
+	if err != nil {
+		return false, appErrors.ErrUserNotFound
+	}
+	if err := u.checkOTP(user, otp); err != nil {
+		if err == appErrors.ErrInvalidOTP {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Logout revokes the access token so a copy of it outlives the cookie for
+// no longer than the request. Tokens without a jti can't be revoked.
+func (u *UserUsecase) Logout(jti, email string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	if u.RevokeToken != nil {
+		if expiresAt.IsZero() {
+			expiresAt = time.Now().Add(time.Duration(u.JWTExpire) * time.Minute)
+		}
+		if err := u.RevokeToken(jti, email, expiresAt); err != nil {
+			utils.LogError("Failed to revoke token on logout for %s: %v", email, err)
+			return appErrors.ErrDatabaseOperation
+		}
+	}
+	u.endSession(jti)
+	return nil
+}
+
+// LogoutAll signs the user out on every device by moving their token version
+// on, RequireTokenVersion then refuses every token issued so far. Use it after
+// a password change or when the account may be compromised.
+func (u *UserUsecase) LogoutAll(userID, email string) error {
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return err
+	}
+	version, err := u.Repo.RevokeTokens(user.ID)
+	if err != nil {
+		utils.LogError("Failed to log out all devices for %s: %v", email, err)
+		return appErrors.ErrDatabaseOperation
+	}
+	user.TokenVersion = version
+	if u.Sessions != nil {
+		if err := u.Sessions.RevokeAllByUser(user.ID, time.Now()); err != nil {
+			utils.LogError("Failed to end sessions of user %s: %v", user.ID, err)
+		}
+	}
+	if u.Audit != nil {
+		err := u.Audit.Record(user.ID, constants.AUDIT_LOGGED_OUT_ALL, user.ID, map[string]interface{}{
+			"token_version": user.TokenVersion,
+		})
+		if err != nil {
+			utils.LogError("Failed to record %s: %v", constants.AUDIT_LOGGED_OUT_ALL, err)
+		}
+	}
+	return nil
+}
+
+// StartSession records the device a token was just issued to. Tracking is
+// best effort, a failure is logged and the sign-in goes ahead.
+func (u *UserUsecase) StartSession(token, ip, userAgent string) {
+	if u.Sessions == nil {
+		return
+	}
+	info, err := jwt.ReadTokenInfo(token)
+	if err != nil {
+		utils.LogError("Failed to read issued token for session: %v", err)
+		return
+	}
+	session := &entity.Session{
+		UserID:    info.UserID,
+		JTI:       info.JTI,
+		IP:        ip,
+		UserAgent: userAgent,
+		IssuedAt:  info.IssuedAt,
+		ExpiresAt: info.ExpiresAt,
+	}
+	if err := u.Sessions.Create(session); err != nil {
+		utils.LogError("Failed to record session for user %s: %v", info.UserID, err)
+	}
+}
+
+// ListSessions returns the active sessions of userID, newest first.
+// currentJTI is the token of the request, its session is marked current.
+func (u *UserUsecase) ListSessions(userID, currentJTI string) ([]dto.SessionResponse, error) {
+	sessions := []dto.SessionResponse{}
+	if u.Sessions == nil {
+		return sessions, nil
+	}
+	found, err := u.Sessions.FindActiveByUser(userID, time.Now())
+	if err != nil {
+		return nil, appErrors.ErrFetchFailed
+	}
+	for _, session := range found {
+		sessions = append(sessions, dto.SessionResponse{
+			ID:        session.ID.Hex(),
+			Device:    utils.DeviceLabel(session.UserAgent),
+			IP:        session.IP,
+			UserAgent: session.UserAgent,
+			IssuedAt:  session.IssuedAt.Format(time.RFC3339),
+			ExpiresAt: session.ExpiresAt.Format(time.RFC3339),
+			Current:   session.JTI == currentJTI,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs a device out by blacklisting the session's token,
+// which JWTMiddleware then refuses. Sessions of other users, and ones
+// already revoked or expired, are reported as not found.
+func (u *UserUsecase) RevokeSession(userID, email, sessionID string) error {
+	if u.Sessions == nil {
+		return appErrors.ErrSessionNotFound
+	}
+	session, err := u.Sessions.FindByID(sessionID)
+	if err != nil {
+		if err == appErrors.ErrSessionNotFound {
+			return err
+		}
+		return appErrors.ErrFetchFailed
+	}
+	if session.UserID != userID || !session.Active(time.Now()) {
+		return appErrors.ErrSessionNotFound
+	}
+	if u.RevokeToken != nil {
+		if err := u.RevokeToken(session.JTI, email, session.ExpiresAt); err != nil {
+			utils.LogError("Failed to revoke session %s for %s: %v", sessionID, email, err)
+			return appErrors.ErrDatabaseOperation
+		}
+	}
+	if err := u.Sessions.RevokeByJTI(session.JTI, time.Now()); err != nil {
+		return appErrors.ErrDatabaseOperation
+	}
+	return nil
+}
+
+// endSession marks the session of jti revoked once its token is
+func (u *UserUsecase) endSession(jti string) {
+	if u.Sessions == nil {
+		return
+	}
+	if err := u.Sessions.RevokeByJTI(jti, time.Now()); err != nil {
+		utils.LogError("Failed to end session %s: %v", jti, err)
+	}
+}
+
+func (u *UserUsecase) OnBoard(email string) error {
+	user, err := u.CurrentUser("", email)
 	if err != nil {
 		return err
 	}
@@ -182,6 +891,81 @@ func (u *UserUsecase) OnBoard(email string) error {
 	return nil
 }
 
+// userThemes are the accepted values of the theme preference
+var userThemes = map[string]bool{"light": true, "dark": true, "system": true}
+
+// normalizeLanguage lower-cases lang and checks it is one of the languages
+// emails are written in, the only use of the stored language
+func normalizeLanguage(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if !mailer.IsSupportedLanguage(lang) {
+		return "", appErrors.NewValidationError(fmt.Sprintf("Unsupported language, expected one of %s", strings.Join(mailer.SupportedLanguages, ", ")))
+	}
+	return lang, nil
+}
+
+// validatePreferences checks the preferences set, leaving empty ones alone,
+// and normalizes the language
+func validatePreferences(prefs *dto.UserPreferences) error {
+	if prefs.Language != "" {
+		lang, err := normalizeLanguage(prefs.Language)
+		if err != nil {
+			return err
+		}
+		prefs.Language = lang
+	}
+	if prefs.Theme != "" && !userThemes[prefs.Theme] {
+		return appErrors.NewValidationError("Invalid theme, expected light, dark or system")
+	}
+	if prefs.Timezone != "" {
+		if _, err := time.LoadLocation(prefs.Timezone); err != nil || prefs.Timezone == "Local" {
+			return appErrors.NewValidationError("Invalid timezone, expected an IANA name like Asia/Jakarta")
+		}
+	}
+	return nil
+}
+
+// CompleteOnboarding saves the optional profile data and marks the user
+// onboarded. It refuses with ErrAlreadyOnboarded once done, so a resubmitted
+// form can't overwrite what the user has changed since.
+func (u *UserUsecase) CompleteOnboarding(userID, email string, req dto.OnboardRequest) (*entity.User, error) {
+	req.Fullname = strings.TrimSpace(req.Fullname)
+	if req.Fullname != "" {
+		if valid, message := validation.ValidateFullName(req.Fullname); !valid {
+			return nil, appErrors.NewValidationError(message)
+		}
+	}
+	if req.Preferences != nil {
+		if err := validatePreferences(req.Preferences); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := u.CurrentUser(userID, email)
+	if err != nil {
+		return nil, err
+	}
+	if user.OnBoarded {
+		return nil, appErrors.ErrAlreadyOnboarded
+	}
+
+	if req.Fullname != "" {
+		user.Fullname = req.Fullname
+	}
+	if req.Preferences != nil {
+		user.Preferences = entity.UserPreferences{
+			Language: req.Preferences.Language,
+			Theme:    req.Preferences.Theme,
+			Timezone: req.Preferences.Timezone,
+		}
+	}
+	user.OnBoarded = true
+	if err := u.Repo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (u *UserUsecase) ChangePasswordWithOTP(req dto.ChangePasswordRequest) error {
 	// Validate password strength first
 	if valid, message := validation.ValidatePassword(req.Password); !valid {
@@ -192,26 +976,153 @@ func (u *UserUsecase) ChangePasswordWithOTP(req dto.ChangePasswordRequest) error
 	if err != nil {
 		return appErrors.ErrUserNotFound
 	}
-	if time.Now().After(user.OTPExpiresAt) {
-		return appErrors.ErrExpiredOTP
-	}
-
-	decryptedOTP, err := utils.Decrypt(user.OTP)
-	if err != nil || decryptedOTP != req.OTP {
-		return appErrors.ErrInvalidOTP
+	if err := u.checkOTP(user, req.OTP); err != nil {
+		return err
 	}
 
 	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), 12)
 	if err != nil {
 		return appErrors.NewInternalError("Failed to hash password")
 	}
-	
+
 	user.Password = string(hashed)
+	user.PasswordChangedAt = time.Now()
 	user.OTP = ""
+	user.OTPAttempts = 0
 	user.OTPExpiresAt = time.Time{}
 	user.OTPType = ""
 
-	return u.Repo.Update(user)
+	if err := u.Repo.Update(user); err != nil {
+		return err
+	}
+	u.notifyPasswordChanged(user)
+	return nil
+}
+
+// SendPasswordResetLink emails a single-use magic link for resetting the
+// password. Only the token's hash is stored on the user.
+func (u *UserUsecase) SendPasswordResetLink(email string) error {
+	user, err := u.Repo.FindByEmail(email)
+	if err != nil {
+		return appErrors.ErrUserNotFound
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return appErrors.NewInternalError("Failed to generate reset token")
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	ttl := u.PasswordResetTTL
+	if ttl <= 0 {
+		ttl = 30 * time.Minute
+	}
+	user.PasswordResetTokenHash = hashResetToken(token)
+	user.PasswordResetExpiresAt = time.Now().Add(ttl)
+	if err := u.Repo.Update(user); err != nil {
+		return err
+	}
+
+	link := u.PasswordResetURL + "?email=" + url.QueryEscape(email) + "&token=" + token
+	body := fmt.Sprintf("Reset your password using this link, it expires in %d minutes and can only be used once:\n%s", int(ttl.Minutes()), link)
+	return u.sendEmail(email, "Reset your password", body)
+}
+
+// RequestPasswordResetLink sends the link of SendPasswordResetLink without
+// revealing whether the account exists, the lookup and email happen in the
+// background so callers see the same result and timing for every address
+func (u *UserUsecase) RequestPasswordResetLink(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return appErrors.ErrEmailRequired
+	}
+
+	u.runAsync(func() {
+		if err := u.SendPasswordResetLink(email); err != nil && err != appErrors.ErrUserNotFound {
+			utils.LogError("Failed to send password reset link: %v", err)
+		}
+	})
+	return nil
+}
+
+// PrecheckPasswordReset starts the forgot-password flow without revealing
+// whether the account exists. The lookup and email happen in the background
+// so callers see the same result and timing for every address.
+func (u *UserUsecase) PrecheckPasswordReset(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return appErrors.ErrEmailRequired
+	}
+
+	u.runAsync(func() {
+		user, err := u.Repo.FindByEmail(email)
+		if err != nil || !user.Verified {
+			return
+		}
+		if err := u.SendPasswordResetLink(email); err != nil {
+			utils.LogError("Failed to send password reset link from precheck: %v", err)
+		}
+	})
+	return nil
+}
+
+// ResetPasswordWithToken sets a new password from a magic-link token and
+// clears the stored hash so the link cannot be used again
+func (u *UserUsecase) ResetPasswordWithToken(req dto.ResetPasswordWithTokenRequest) error {
+	// Validate new password strength first
+	if valid, message := validation.ValidatePassword(req.NewPassword); !valid {
+		return appErrors.NewValidationError(message)
+	}
+
+	user, err := u.Repo.FindByEmail(req.Email)
+	if err != nil {
+		return appErrors.ErrInvalidToken
+	}
+	if user.PasswordResetTokenHash == "" ||
+		subtle.ConstantTimeCompare([]byte(user.PasswordResetTokenHash), []byte(hashResetToken(req.Token))) != 1 {
+		return appErrors.ErrInvalidToken
+	}
+	if time.Now().After(user.PasswordResetExpiresAt) {
+		return appErrors.ErrInvalidToken
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 12)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to hash password")
+	}
+
+	user.Password = string(hashed)
+	user.PasswordChangedAt = time.Now()
+	user.PasswordResetTokenHash = ""
+	user.PasswordResetExpiresAt = time.Time{}
+
+	if err := u.Repo.Update(user); err != nil {
+		return err
+	}
+	u.notifyPasswordChanged(user)
+	return nil
+}
+
+// notifyPasswordChanged emails a best-effort notice after a password change
+// so the owner can react if it wasn't them
+func (u *UserUsecase) notifyPasswordChanged(user *entity.User) {
+	if !u.Flags.Enabled(featureflags.PasswordChangedNotice) || !user.Notifications.Allows(constants.NOTICE_PASSWORD_CHANGED) {
+		return
+	}
+	email := user.Email
+	lang := mailer.ResolveLanguage("", user.Preferences.Language)
+	changedAt := time.Now()
+	u.runAsync(func() {
+		subject, body := mailer.PasswordChangedNotice(lang, changedAt, u.SupportURL)
+		if err := u.sendEmail(email, subject, body); err != nil {
+			utils.LogError("Failed to send password changed notice: %v", err)
+		}
+	})
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func (u *UserUsecase) ChangePasswordWithOldPassword(email string, req dto.ChangePasswordWithOldPasswordRequest) error {
@@ -220,9 +1131,9 @@ func (u *UserUsecase) ChangePasswordWithOldPassword(email string, req dto.Change
 		return appErrors.NewValidationError(message)
 	}
 
-	user, err := u.Repo.FindByEmail(email)
+	user, err := u.CurrentUser("", email)
 	if err != nil {
-		return appErrors.ErrUserNotFound
+		return err
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)) != nil {
@@ -233,10 +1144,53 @@ func (u *UserUsecase) ChangePasswordWithOldPassword(email string, req dto.Change
 	if err != nil {
 		return appErrors.NewInternalError("Failed to hash password")
 	}
-	
+
 	user.Password = string(hashed)
+	user.PasswordChangedAt = time.Now()
 
-	return u.Repo.Update(user)
+	if err := u.Repo.Update(user); err != nil {
+		return err
+	}
+	u.notifyPasswordChanged(user)
+	return nil
+}
+
+// ChangePasswordStepUp sets a new password for an authenticated user without
+// the old one, gated by a FORGOT_PASSWORD OTP sent to their own email
+func (u *UserUsecase) ChangePasswordStepUp(email string, req dto.ChangePasswordStepUpRequest) error {
+	// Validate new password strength first
+	if valid, message := validation.ValidatePassword(req.NewPassword); !valid {
+		return appErrors.NewValidationError(message)
+	}
+
+	user, err := u.CurrentUser("", email)
+	if err != nil {
+		return err
+	}
+	if user.OTPType != constants.FORGOT_PASSWORD {
+		return appErrors.ErrInvalidOTP
+	}
+	if err := u.checkOTP(user, req.OTP); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 12)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to hash password")
+	}
+
+	user.Password = string(hashed)
+	user.PasswordChangedAt = time.Now()
+	user.OTP = ""
+	user.OTPAttempts = 0
+	user.OTPExpiresAt = time.Time{}
+	user.OTPType = ""
+
+	if err := u.Repo.Update(user); err != nil {
+		return err
+	}
+	u.notifyPasswordChanged(user)
+	return nil
 }
 
 func (u *UserUsecase) UpdateUser(req dto.RegisterRequest) (*entity.User, error) {
@@ -248,11 +1202,11 @@ func (u *UserUsecase) UpdateUser(req dto.RegisterRequest) (*entity.User, error)
 		req.AvatarUrl = user.AvatarUrl
 	}
 	utils.LogWarn("Updating user with email:", req.Email, "and fullname:", req.Fullname)
-	
+
 	// Update existing user object to preserve all fields including CreatedAt
 	user.Fullname = req.Fullname
 	user.AvatarUrl = req.AvatarUrl
-	
+
 	err = u.Repo.Update(user)
 	if err != nil {
 		return nil, err
@@ -260,34 +1214,94 @@ func (u *UserUsecase) UpdateUser(req dto.RegisterRequest) (*entity.User, error)
 	return user, nil
 }
 
+// SendOTPNewEmail sends a confirmation code to the address the user wants to
+// move to, UpdateUserByEmail only switches once that code comes back
+func (u *UserUsecase) SendOTPNewEmail(email, newEmail string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if !validation.ValidateEmail(newEmail) {
+		return appErrors.NewValidationError("Invalid email format")
+	}
+	if newEmail == email {
+		return appErrors.NewBadRequestError("New email must differ from the current one")
+	}
+	user, err := u.Repo.FindByEmail(email)
+	if err != nil {
+		return appErrors.ErrUserNotFound
+	}
+	if _, err := u.Repo.FindByEmail(newEmail); err == nil {
+		return appErrors.ErrEmailAlreadyExists
+	}
+
+	otp, encryptedOTP, err := generateOTP()
+	if err != nil {
+		return err
+	}
+	user.PendingEmail = newEmail
+	user.PendingEmailOTP = encryptedOTP
+	user.PendingEmailExpiresAt = time.Now().Add(10 * time.Minute)
+	if err := u.Repo.Update(user); err != nil {
+		return err
+	}
+	subject, body := mailer.NewEmailConfirmationMessage(otp)
+	return u.sendEmail(newEmail, subject, body)
+}
+
 func (u *UserUsecase) UpdateUserByEmail(req dto.ChangeEmailRequest, oldEmail string) error {
 	userOldEmail, err := u.Repo.FindByEmail(oldEmail)
 	if err != nil {
 		return appErrors.ErrUserNotFound
 	}
-	decryptedOTP, err := utils.Decrypt(userOldEmail.OTP)
-	if err != nil || decryptedOTP != req.OTP {
-		return appErrors.ErrInvalidOTP
+	if err := u.checkOTP(userOldEmail, req.OTP); err != nil {
+		return err
+	}
+
+	// The OTP above proves the old address, the new one is proven by the
+	// code SendOTPNewEmail sent to it
+	if userOldEmail.PendingEmail == "" || userOldEmail.PendingEmail != req.NewEmail {
+		return appErrors.ErrNewEmailNotConfirmed
 	}
-	if time.Now().After(userOldEmail.OTPExpiresAt) {
+	if time.Now().After(userOldEmail.PendingEmailExpiresAt) {
 		return appErrors.ErrExpiredOTP
 	}
+	// A wrong code for the new address counts against the same attempts as
+	// the OTP above, so neither can be guessed
+	pendingOTP, err := utils.Decrypt(userOldEmail.PendingEmailOTP)
+	if err != nil || pendingOTP != req.NewEmailOTP {
+		return u.wrongOTP(userOldEmail)
+	}
 
 	_, err = u.Repo.FindByEmail(req.NewEmail)
 	if err == nil {
 		return appErrors.ErrEmailAlreadyExists
 	}
-	
+
 	// Update existing user object to preserve all fields including CreatedAt
 	userOldEmail.Email = req.NewEmail
 	userOldEmail.OTP = ""
+	userOldEmail.OTPAttempts = 0
 	userOldEmail.OTPExpiresAt = time.Time{}
 	userOldEmail.OTPType = ""
-	
+	userOldEmail.PendingEmail = ""
+	userOldEmail.PendingEmailOTP = ""
+	userOldEmail.PendingEmailExpiresAt = time.Time{}
+
+	// With the flag on the account goes back to unverified and repeats the
+	// regular verification at the new address
+	reverify := u.Flags.Enabled(featureflags.ReverifyOnEmailChange)
+	if reverify {
+		userOldEmail.Verified = false
+	}
+
 	err = u.Repo.UpdateEmail(userOldEmail, oldEmail)
 	if err != nil {
 		return err
 	}
+	if reverify {
+		if err := u.SendOTP(constants.VERIFICATION, req.NewEmail); err != nil {
+			// The user can request another one through /verification/users/send-otp
+			utils.LogError("Failed to send verification OTP after email change: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -296,25 +1310,23 @@ func (u *UserUsecase) UpdateUserByPhone(req dto.ChangePhoneRequest, oldPhone str
 	if err != nil {
 		return appErrors.ErrUserNotFound
 	}
-	decryptedOTP, err := utils.Decrypt(userOldPhone.OTP)
-	if err != nil || decryptedOTP != req.OTP {
-		return appErrors.ErrInvalidOTP
-	}
-	if time.Now().After(userOldPhone.OTPExpiresAt) {
-		return appErrors.ErrExpiredOTP
+	if err := u.checkOTP(userOldPhone, req.OTP); err != nil {
+		return err
 	}
 
 	_, err = u.Repo.FindByPhone(req.NewPhone)
 	if err == nil {
 		return appErrors.ErrPhoneAlreadyExists
 	}
-	
+
 	// Update existing user object to preserve all fields including CreatedAt
 	userOldPhone.PhoneNumber = req.NewPhone
+	userOldPhone.PhoneVerified = false
 	userOldPhone.OTP = ""
+	userOldPhone.OTPAttempts = 0
 	userOldPhone.OTPExpiresAt = time.Time{}
 	userOldPhone.OTPType = ""
-	
+
 	err = u.Repo.UpdatePhone(userOldPhone, oldPhone)
 	if err != nil {
 		return err
@@ -1,23 +1,43 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/buildyow/byow-user-service/constants"
 	"github.com/buildyow/byow-user-service/domain/entity"
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
+	"github.com/buildyow/byow-user-service/domain/repository"
 	"github.com/buildyow/byow-user-service/dto"
+	"github.com/buildyow/byow-user-service/infrastructure/featureflags"
+	"github.com/buildyow/byow-user-service/infrastructure/mailer"
+	"github.com/buildyow/byow-user-service/infrastructure/oauth"
+	"github.com/buildyow/byow-user-service/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // Mock repository for testing
 type mockUserRepository struct {
-	users map[string]*entity.User
+	users     map[string]*entity.User
+	createErr error
+	// existingEmailsCalls counts FindExistingEmails queries
+	existingEmailsCalls int
+	// ceremonies holds pending passkey ceremonies by user id
+	ceremonies map[string][]entity.PasskeyCeremony
 }
 
 func (m *mockUserRepository) Create(user *entity.User) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
 	if m.users == nil {
 		m.users = make(map[string]*entity.User)
 	}
@@ -33,6 +53,62 @@ func (m *mockUserRepository) FindByEmail(email string) (*entity.User, error) {
 	return nil, appErrors.ErrUserNotFound
 }
 
+func (m *mockUserRepository) FindExistingEmails(emails []string) (map[string]bool, error) {
+	m.existingEmailsCalls++
+	existing := map[string]bool{}
+	for _, email := range emails {
+		if _, exists := m.users[email]; exists {
+			existing[email] = true
+		}
+	}
+	return existing, nil
+}
+
+func (m *mockUserRepository) FindDeletionDue(before time.Time, limit int64) ([]*entity.User, error) {
+	due := []*entity.User{}
+	for _, user := range m.users {
+		if !user.DeletionScheduledAt.IsZero() && !user.DeletionScheduledAt.After(before) && int64(len(due)) < limit {
+			due = append(due, user)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockUserRepository) FindAll(filter repository.UserFilter, limit int64, offset int64) ([]*entity.User, int64, error) {
+	keyword := strings.ToLower(filter.Keyword)
+	matched := []*entity.User{}
+	for _, user := range m.users {
+		if keyword != "" && !strings.Contains(strings.ToLower(user.Email), keyword) &&
+			!strings.Contains(user.PhoneNumber, keyword) && !strings.Contains(strings.ToLower(user.Fullname), keyword) {
+			continue
+		}
+		if filter.Suspended != nil && user.Suspended() != *filter.Suspended {
+			continue
+		}
+		matched = append(matched, user)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Email < matched[j].Email })
+
+	total := int64(len(matched))
+	if offset >= total {
+		return []*entity.User{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matched[offset:end], total, nil
+}
+
+func (m *mockUserRepository) FindByID(id string) (*entity.User, error) {
+	for _, user := range m.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, appErrors.ErrUserNotFound
+}
+
 func (m *mockUserRepository) FindByPhone(phone string) (*entity.User, error) {
 	for _, user := range m.users {
 		if user.PhoneNumber == phone {
@@ -61,7 +137,8 @@ func (m *mockUserRepository) UpdateEmail(user *entity.User, oldEmail string) err
 
 func (m *mockUserRepository) UpdatePhone(user *entity.User, oldPhone string) error {
 	for email, u := range m.users {
-		if u.PhoneNumber == oldPhone {
+		// The stored user may be the one just changed in place
+		if u == user || u.PhoneNumber == oldPhone {
 			m.users[email] = user
 			return nil
 		}
@@ -69,10 +146,141 @@ func (m *mockUserRepository) UpdatePhone(user *entity.User, oldPhone string) err
 	return appErrors.ErrUserNotFound
 }
 
+func (m *mockUserRepository) Delete(email string) error {
+	if _, exists := m.users[email]; exists {
+		delete(m.users, email)
+		return nil
+	}
+	return appErrors.ErrUserNotFound
+}
+
+func (m *mockUserRepository) ForEach(ctx context.Context, fn func(user *entity.User) error) error {
+	emails := make([]string, 0, len(m.users))
+	for email := range m.users {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	for _, email := range emails {
+		if err := fn(m.users[email]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEachActiveOTP hands out copies without the password and reset token,
+// like the projection of the mongo repository
+func (m *mockUserRepository) ForEachActiveOTP(ctx context.Context, fn func(user *entity.User) error) error {
+	now := time.Now()
+	return m.ForEach(ctx, func(user *entity.User) error {
+		if user.OTP == "" || !user.OTPExpiresAt.After(now) {
+			return nil
+		}
+		projected := *user
+		projected.Password = ""
+		projected.PasswordResetTokenHash = ""
+		return fn(&projected)
+	})
+}
+
+func (m *mockUserRepository) UpdateOTP(user *entity.User) error {
+	stored, exists := m.users[user.Email]
+	if !exists {
+		return appErrors.ErrUserNotFound
+	}
+	stored.OTP = user.OTP
+	stored.OTPType = user.OTPType
+	stored.OTPExpiresAt = user.OTPExpiresAt
+	stored.OTPAttempts = user.OTPAttempts
+	return nil
+}
+
+func (m *mockUserRepository) UpdateRole(userID, role string) (int, error) {
+	stored, err := m.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.Role = role
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (m *mockUserRepository) RevokeTokens(userID string) (int, error) {
+	stored, err := m.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (m *mockUserRepository) Suspend(userID string, at time.Time, reason string) (int, error) {
+	stored, err := m.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.SuspendedAt = at
+	stored.SuspendedReason = reason
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (m *mockUserRepository) Reactivate(userID string) error {
+	stored, err := m.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	stored.SuspendedAt = time.Time{}
+	stored.SuspendedReason = ""
+	return nil
+}
+
+func (m *mockUserRepository) ScheduleDeletion(userID string, at time.Time) (int, error) {
+	stored, err := m.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	stored.DeletionScheduledAt = at
+	stored.TokenVersion++
+	return stored.TokenVersion, nil
+}
+
+func (m *mockUserRepository) CancelDeletion(userID string) error {
+	stored, err := m.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	stored.DeletionScheduledAt = time.Time{}
+	return nil
+}
+
+func (m *mockUserRepository) AddPasskeyCeremony(userID string, ceremony entity.PasskeyCeremony, keep int) error {
+	if m.ceremonies == nil {
+		m.ceremonies = map[string][]entity.PasskeyCeremony{}
+	}
+	pending := append(m.ceremonies[userID], ceremony)
+	if len(pending) > keep {
+		pending = pending[len(pending)-keep:]
+	}
+	m.ceremonies[userID] = pending
+	return nil
+}
+
+func (m *mockUserRepository) TakePasskeyCeremony(userID, ceremonyID string) (*entity.PasskeyCeremony, error) {
+	pending := m.ceremonies[userID]
+	for i, ceremony := range pending {
+		if ceremony.ID == ceremonyID {
+			m.ceremonies[userID] = append(pending[:i:i], pending[i+1:]...)
+			return &ceremony, nil
+		}
+	}
+	return nil, nil
+}
+
 func setupUserUsecase() *UserUsecase {
 	// Set up test environment variables
 	os.Setenv("DECRYPT_KEY", "12345678901234567890123456789012") // 32 bytes for AES
-	
+
 	return &UserUsecase{
 		Repo:      &mockUserRepository{},
 		JWTSecret: "test-secret",
@@ -93,7 +301,7 @@ func setupUserUsecase() *UserUsecase {
 
 func TestRegistrationValidation_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	err := uc.RegistrationValidation("new@example.com", "+1234567890")
 	if err != nil {
 		t.Errorf("Expected no error for new user, got %v", err)
@@ -102,14 +310,14 @@ func TestRegistrationValidation_Success(t *testing.T) {
 
 func TestRegistrationValidation_EmailExists(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	// Create a user first
 	user := &entity.User{
 		Email:       "existing@example.com",
 		PhoneNumber: "+1111111111",
 	}
 	uc.Repo.Create(user)
-	
+
 	err := uc.RegistrationValidation("existing@example.com", "+2222222222")
 	if err != appErrors.ErrEmailAlreadyExists {
 		t.Errorf("Expected ErrEmailAlreadyExists, got %v", err)
@@ -118,14 +326,14 @@ func TestRegistrationValidation_EmailExists(t *testing.T) {
 
 func TestRegistrationValidation_PhoneExists(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	// Create a user first
 	user := &entity.User{
 		Email:       "test1@example.com",
 		PhoneNumber: "+1111111111",
 	}
 	uc.Repo.Create(user)
-	
+
 	err := uc.RegistrationValidation("test2@example.com", "+1111111111")
 	if err != appErrors.ErrPhoneAlreadyExists {
 		t.Errorf("Expected ErrPhoneAlreadyExists, got %v", err)
@@ -134,14 +342,14 @@ func TestRegistrationValidation_PhoneExists(t *testing.T) {
 
 func TestUpdateUserValidation_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	// Create a user first
 	user := &entity.User{
 		Email:       "existing@example.com",
 		PhoneNumber: "+1111111111",
 	}
 	uc.Repo.Create(user)
-	
+
 	err := uc.UpdateUserValidation("existing@example.com")
 	if err != nil {
 		t.Errorf("Expected no error for existing user, got %v", err)
@@ -150,16 +358,98 @@ func TestUpdateUserValidation_Success(t *testing.T) {
 
 func TestUpdateUserValidation_UserNotFound(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	err := uc.UpdateUserValidation("nonexistent@example.com")
 	if err != appErrors.ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
 	}
 }
 
+func TestCheckEmailAvailability(t *testing.T) {
+	uc := setupUserUsecase()
+	repo := &mockUserRepository{users: map[string]*entity.User{
+		"john@example.com": {Email: "john@example.com"},
+		"Jane@Example.com": {Email: "Jane@Example.com"},
+	}}
+	uc.Repo = repo
+
+	availability, err := uc.CheckEmailAvailability([]string{
+		"john@example.com",
+		"  JOHN@example.com ",
+		"Jane@Example.com",
+		"new@example.com",
+		"Other@Example.com",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]bool{
+		"john@example.com":  false,
+		"jane@example.com":  false,
+		"new@example.com":   true,
+		"other@example.com": true,
+	}
+	if len(availability) != len(expected) {
+		t.Errorf("Expected %d normalized emails, got %v", len(expected), availability)
+	}
+	for email, available := range expected {
+		if got, ok := availability[email]; !ok || got != available {
+			t.Errorf("Expected %s available=%v, got %v (present=%v)", email, available, got, ok)
+		}
+	}
+	if repo.existingEmailsCalls != 1 {
+		t.Errorf("Expected the batch to be resolved in one query, got %d", repo.existingEmailsCalls)
+	}
+}
+
+func TestCheckEmailAvailability_BatchSize(t *testing.T) {
+	uc := setupUserUsecase()
+
+	if _, err := uc.CheckEmailAvailability(nil); err == nil {
+		t.Error("Expected an empty batch to be rejected")
+	}
+	if _, err := uc.CheckEmailAvailability(make([]string, MaxEmailAvailabilityBatch+1)); err == nil {
+		t.Error("Expected an oversized batch to be rejected")
+	}
+	if _, err := uc.CheckEmailAvailability([]string{"john@example.com", " "}); err == nil {
+		t.Error("Expected a blank email to be rejected")
+	}
+}
+
+func TestRegister_WelcomeEmail(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.RunAsync = func(task func()) { task() }
+	var sentTo []string
+	uc.SendEmail = func(to, subject, body string) error {
+		sentTo = append(sentTo, to)
+		return nil
+	}
+
+	if _, err := uc.Register(dto.RegisterRequest{Fullname: "John Doe", Email: "john@example.com", Password: "Password123!"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sentTo) != 0 {
+		t.Errorf("Expected no welcome email with the flag off, got %v", sentTo)
+	}
+
+	uc.Flags = &featureflags.Flags{WelcomeEmail: true}
+	if _, err := uc.Register(dto.RegisterRequest{Fullname: "Jane Doe", Email: "jane@example.com", Password: "Password123!"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sentTo) != 1 || sentTo[0] != "jane@example.com" {
+		t.Errorf("Expected a welcome email to jane, got %v", sentTo)
+	}
+
+	uc.SendEmail = func(to, subject, body string) error { return errors.New("smtp down") }
+	if _, err := uc.Register(dto.RegisterRequest{Fullname: "Budi", Email: "budi@example.com", Password: "Password123!"}); err != nil {
+		t.Errorf("Expected a failed welcome email not to fail the registration, got %v", err)
+	}
+}
+
 func TestRegister_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	req := dto.RegisterRequest{
 		Fullname:    "John Doe",
 		Email:       "john@example.com",
@@ -167,41 +457,82 @@ func TestRegister_Success(t *testing.T) {
 		PhoneNumber: "+1234567890",
 		AvatarUrl:   "https://example.com/avatar.jpg",
 	}
-	
+
 	user, err := uc.Register(req)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if user == nil {
 		t.Fatal("Expected user to be created")
 	}
-	
+
 	if user.Fullname != req.Fullname {
 		t.Errorf("Expected fullname %s, got %s", req.Fullname, user.Fullname)
 	}
-	
+
 	if user.Email != req.Email {
 		t.Errorf("Expected email %s, got %s", req.Email, user.Email)
 	}
-	
+
 	if user.Verified {
 		t.Error("Expected user to be unverified")
 	}
-	
+
 	if user.OnBoarded {
 		t.Error("Expected user to be not onboarded")
 	}
-	
+
 	// Check password is hashed
 	if user.Password == req.Password {
 		t.Error("Expected password to be hashed")
 	}
 }
 
+func TestRegister_DuplicateKeyRace(t *testing.T) {
+	tests := []struct {
+		name     string
+		index    string
+		expected *appErrors.AppError
+	}{
+		{"email", "email_unique", appErrors.ErrEmailAlreadyExists},
+		{"phone", "phone_unique", appErrors.ErrPhoneAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := setupUserUsecase()
+			uc.Repo.(*mockUserRepository).createErr = mongo.WriteException{
+				WriteErrors: mongo.WriteErrors{{
+					Code:    11000,
+					Message: "E11000 duplicate key error collection: users_collections index: " + tt.index,
+				}},
+			}
+
+			req := dto.RegisterRequest{
+				Fullname:    "John Doe",
+				Email:       "john@example.com",
+				Password:    "Password123!",
+				PhoneNumber: "+1234567890",
+			}
+
+			user, err := uc.Register(req)
+			if user != nil {
+				t.Error("Expected no user on duplicate key error")
+			}
+			if err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if tt.expected.Status != 409 {
+				t.Errorf("Expected 409 status, got %d", tt.expected.Status)
+			}
+		})
+	}
+}
+
 func TestLogin_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	// Create and verify a user
 	password := "Password123!"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
@@ -216,16 +547,16 @@ func TestLogin_Success(t *testing.T) {
 		OnBoarded:   true,
 	}
 	uc.Repo.Create(user)
-	
+
 	response, err := uc.Login("john@example.com", password)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if response.Email != user.Email {
 		t.Errorf("Expected email %s, got %s", user.Email, response.Email)
 	}
-	
+
 	if response.Token == "" {
 		t.Error("Expected token to be generated")
 	}
@@ -233,7 +564,7 @@ func TestLogin_Success(t *testing.T) {
 
 func TestLogin_UserNotFound(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	_, err := uc.Login("nonexistent@example.com", "password")
 	if err != appErrors.ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
@@ -242,7 +573,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 func TestLogin_UserNotVerified(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	password := "Password123!"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
 	user := &entity.User{
@@ -251,16 +582,37 @@ func TestLogin_UserNotVerified(t *testing.T) {
 		Verified: false,
 	}
 	uc.Repo.Create(user)
-	
+
 	_, err := uc.Login("unverified@example.com", password)
 	if err != appErrors.ErrUserNotVerified {
 		t.Errorf("Expected ErrUserNotVerified, got %v", err)
 	}
 }
 
+func TestLogin_UserNotVerified_RequirementDisabled(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Flags = &featureflags.Flags{RequireVerification: false}
+
+	password := "Password123!"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
+	uc.Repo.Create(&entity.User{
+		Email:    "unverified@example.com",
+		Password: string(hashedPassword),
+		Verified: false,
+	})
+
+	response, err := uc.Login("unverified@example.com", password)
+	if err != nil {
+		t.Fatalf("Expected login without verification when the flag is off, got %v", err)
+	}
+	if response.Token == "" {
+		t.Error("Expected token to be generated")
+	}
+}
+
 func TestLogin_InvalidCredentials(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	password := "Password123!"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
 	user := &entity.User{
@@ -269,7 +621,7 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 		Verified: true,
 	}
 	uc.Repo.Create(user)
-	
+
 	_, err := uc.Login("john@example.com", "wrongpassword")
 	if err != appErrors.ErrInvalidCredentials {
 		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
@@ -278,7 +630,7 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 
 func TestLoginWithoutPassword_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
 		ID:          "user123",
 		Fullname:    "John Doe",
@@ -289,16 +641,16 @@ func TestLoginWithoutPassword_Success(t *testing.T) {
 		OnBoarded:   true,
 	}
 	uc.Repo.Create(user)
-	
+
 	response, err := uc.LoginWithoutPassword("john@example.com")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if response.Email != user.Email {
 		t.Errorf("Expected email %s, got %s", user.Email, response.Email)
 	}
-	
+
 	if response.Token == "" {
 		t.Error("Expected token to be generated")
 	}
@@ -306,7 +658,7 @@ func TestLoginWithoutPassword_Success(t *testing.T) {
 
 func TestLoginWithoutPassword_UserNotFound(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	_, err := uc.LoginWithoutPassword("nonexistent@example.com")
 	if err != appErrors.ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
@@ -315,29 +667,29 @@ func TestLoginWithoutPassword_UserNotFound(t *testing.T) {
 
 func TestSendOTP_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
 		Email:       "john@example.com",
 		PhoneNumber: "+1234567890",
 	}
 	uc.Repo.Create(user)
-	
+
 	// This will fail due to SMTP but should not panic and should set OTP fields
 	err := uc.SendOTP(constants.VERIFICATION, "john@example.com")
 	if err == nil {
 		t.Error("Expected SMTP error but got none")
 	}
-	
+
 	// Check that user OTP fields were set
 	updatedUser, _ := uc.Repo.FindByEmail("john@example.com")
 	if updatedUser.OTP == "" {
 		t.Error("Expected OTP to be set")
 	}
-	
+
 	if updatedUser.OTPType != constants.VERIFICATION {
 		t.Errorf("Expected OTP type %s, got %s", constants.VERIFICATION, updatedUser.OTPType)
 	}
-	
+
 	if updatedUser.OTPExpiresAt.IsZero() {
 		t.Error("Expected OTP expiration to be set")
 	}
@@ -345,51 +697,51 @@ func TestSendOTP_Success(t *testing.T) {
 
 func TestSendOTP_VerificationExpiry(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
 		Email: "john@example.com",
 	}
 	uc.Repo.Create(user)
-	
+
 	// Test VERIFICATION OTP type (5 minutes expiry)
 	uc.SendOTP(constants.VERIFICATION, "john@example.com")
 	updatedUser, _ := uc.Repo.FindByEmail("john@example.com")
-	
+
 	// Check that expiry is set and is in the future (allow for test execution time)
 	if updatedUser.OTPExpiresAt.IsZero() {
 		t.Error("Expected OTP expiration to be set")
 	}
-	
-	if updatedUser.OTPExpiresAt.Before(time.Now().Add(4*time.Minute)) {
+
+	if updatedUser.OTPExpiresAt.Before(time.Now().Add(4 * time.Minute)) {
 		t.Error("Expected OTP to expire in approximately 5 minutes")
 	}
 }
 
 func TestSendOTP_ForgotPasswordExpiry(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
 		Email: "john@example.com",
 	}
 	uc.Repo.Create(user)
-	
+
 	// Test FORGOT_PASSWORD OTP type (10 minutes expiry)
 	uc.SendOTP(constants.FORGOT_PASSWORD, "john@example.com")
 	updatedUser, _ := uc.Repo.FindByEmail("john@example.com")
-	
+
 	// Check that expiry is set and is in the future (allow for test execution time)
 	if updatedUser.OTPExpiresAt.IsZero() {
 		t.Error("Expected OTP expiration to be set")
 	}
-	
-	if updatedUser.OTPExpiresAt.Before(time.Now().Add(9*time.Minute)) {
+
+	if updatedUser.OTPExpiresAt.Before(time.Now().Add(9 * time.Minute)) {
 		t.Error("Expected OTP to expire in approximately 10 minutes")
 	}
 }
 
 func TestSendOTP_UserNotFound(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	err := uc.SendOTP(constants.VERIFICATION, "nonexistent@example.com")
 	if err != appErrors.ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
@@ -398,16 +750,16 @@ func TestSendOTP_UserNotFound(t *testing.T) {
 
 func TestVerifyOTP_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
-		Email:     "john@example.com",
-		OTP:       "encrypted-123456", // This would be encrypted in real scenario
-		OTPType:   constants.VERIFICATION,
+		Email:        "john@example.com",
+		OTP:          "encrypted-123456", // This would be encrypted in real scenario
+		OTPType:      constants.VERIFICATION,
 		OTPExpiresAt: time.Now().Add(5 * time.Minute),
-		Verified:  false,
+		Verified:     false,
 	}
 	uc.Repo.Create(user)
-	
+
 	// Since we can't easily mock the encryption, we'll test the error case
 	err := uc.VerifyOTP("john@example.com", "123456")
 	// This will fail due to encryption but should still test the logic flow
@@ -418,16 +770,16 @@ func TestVerifyOTP_Success(t *testing.T) {
 
 func TestVerifyOTP_ExpiredOTP(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
-		Email:     "john@example.com",
-		OTP:       "encrypted-123456",
-		OTPType:   constants.VERIFICATION,
+		Email:        "john@example.com",
+		OTP:          "encrypted-123456",
+		OTPType:      constants.VERIFICATION,
 		OTPExpiresAt: time.Now().Add(-5 * time.Minute), // Expired
-		Verified:  false,
+		Verified:     false,
 	}
 	uc.Repo.Create(user)
-	
+
 	err := uc.VerifyOTP("john@example.com", "123456")
 	if err != appErrors.ErrExpiredOTP {
 		t.Errorf("Expected ErrExpiredOTP, got %v", err)
@@ -436,156 +788,949 @@ func TestVerifyOTP_ExpiredOTP(t *testing.T) {
 
 func TestVerifyOTP_UserNotFound(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	err := uc.VerifyOTP("nonexistent@example.com", "123456")
 	if err != appErrors.ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
 	}
 }
 
-func TestOnBoard_Success(t *testing.T) {
+func TestFailedLogins_ScopedToUserNewestFirst(t *testing.T) {
 	uc := setupUserUsecase()
-	
-	user := &entity.User{
-		Email:     "john@example.com",
-		OnBoarded: false,
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+
+	uc.Repo.Create(&entity.User{ID: "user-1", Email: "john@example.com"})
+	uc.RecordFailedLogin("john@example.com", "203.0.113.7", "curl/8.0")
+	uc.RecordFailedLogin("nobody@example.com", "203.0.113.8", "curl/8.0")
+	if len(auditRepo.logs) != 1 {
+		t.Fatalf("Expected only the known account's attempt to be recorded, got %d", len(auditRepo.logs))
 	}
-	uc.Repo.Create(user)
-	
-	err := uc.OnBoard("john@example.com")
+
+	now := time.Now()
+	auditRepo.logs = []*entity.AuditLog{
+		{Action: constants.AUDIT_LOGIN_FAILED, TargetID: "user-1", CreatedAt: now.Add(-2 * time.Hour), Metadata: map[string]interface{}{"ip": "10.0.0.1"}},
+		{Action: constants.AUDIT_LOGIN_FAILED, TargetID: "user-2", CreatedAt: now.Add(-30 * time.Minute), Metadata: map[string]interface{}{"ip": "10.0.0.2"}},
+		{Action: constants.AUDIT_LOGIN_FAILED, TargetID: "user-1", CreatedAt: now.Add(-1 * time.Hour), Metadata: map[string]interface{}{"ip": "10.0.0.3", "user_agent": "Mozilla/5.0"}},
+		{Action: constants.AUDIT_ACCOUNT_DELETED, TargetID: "user-1", CreatedAt: now},
+	}
+
+	attempts, err := uc.FailedLogins("user-1", 0)
 	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
 	}
-	
-	updatedUser, _ := uc.Repo.FindByEmail("john@example.com")
-	if !updatedUser.OnBoarded {
-		t.Error("Expected user to be onboarded")
+	if len(attempts) != 2 {
+		t.Fatalf("Expected 2 attempts for user-1, got %d", len(attempts))
+	}
+	if attempts[0].IP != "10.0.0.3" || attempts[0].UserAgent != "Mozilla/5.0" || attempts[1].IP != "10.0.0.1" {
+		t.Errorf("Expected user-1 attempts newest first, got %+v", attempts)
 	}
 }
 
-func TestOnBoard_UserNotFound(t *testing.T) {
+func TestLoginHistory_ScopedToCallerWithDeviceLabels(t *testing.T) {
 	uc := setupUserUsecase()
-	
-	err := uc.OnBoard("nonexistent@example.com")
-	if err != appErrors.ErrUserNotFound {
-		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+
+	chrome := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	safari := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+
+	uc.Repo.Create(&entity.User{ID: "user-1", Email: "john@example.com"})
+	uc.Repo.Create(&entity.User{ID: "user-2", Email: "jane@example.com"})
+	uc.RecordLogin("john@example.com", "10.0.0.1", chrome)
+	uc.RecordLogin("jane@example.com", "10.0.0.2", chrome)
+	uc.RecordFailedLogin("john@example.com", "10.0.0.9", "curl/8.0")
+	uc.RecordLogin("nobody@example.com", "10.0.0.8", chrome)
+	if len(auditRepo.logs) != 3 {
+		t.Fatalf("Expected 3 recorded sign-ins, got %d", len(auditRepo.logs))
 	}
-}
 
-func TestChangePasswordWithOTP_Success(t *testing.T) {
-	uc := setupUserUsecase()
-	
-	user := &entity.User{
-		Email:     "john@example.com",
-		OTP:       "encrypted-123456",
-		OTPType:   constants.FORGOT_PASSWORD,
-		OTPExpiresAt: time.Now().Add(10 * time.Minute),
+	// Newer sign-in from another device
+	auditRepo.logs = append(auditRepo.logs, &entity.AuditLog{
+		Action:    constants.AUDIT_LOGIN_SUCCEEDED,
+		TargetID:  "user-1",
+		CreatedAt: time.Now().Add(time.Minute),
+		Metadata:  map[string]interface{}{"ip": "10.0.0.3", "user_agent": safari},
+	})
+
+	logins, err := uc.LoginHistory("user-1", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
 	}
-	uc.Repo.Create(user)
-	
-	req := dto.ChangePasswordRequest{
-		Email:    "john@example.com",
-		OTP:      "123456",
-		Password: "NewPassword123!",
+	if len(logins) != 2 {
+		t.Fatalf("Expected 2 sign-ins for user-1, got %+v", logins)
 	}
-	
-	err := uc.ChangePasswordWithOTP(req)
-	// This will fail due to encryption/OTP validation but tests the flow
-	if err != appErrors.ErrInvalidOTP {
-		t.Logf("Got error (expected due to encryption): %v", err)
+	if logins[0].IP != "10.0.0.3" || logins[0].Device != "Safari on iPhone" || logins[0].UserAgent != safari {
+		t.Errorf("Expected the iPhone sign-in first, got %+v", logins[0])
+	}
+	if logins[1].IP != "10.0.0.1" || logins[1].Device != "Chrome on Windows" || logins[1].LoggedInAt == "" {
+		t.Errorf("Expected the Windows sign-in second, got %+v", logins[1])
 	}
 }
 
-func TestChangePasswordWithOTP_WeakPassword(t *testing.T) {
+func TestSecuritySummary_ReflectsUserState(t *testing.T) {
 	uc := setupUserUsecase()
-	
-	req := dto.ChangePasswordRequest{
-		Email:    "john@example.com",
-		OTP:      "123456",
-		Password: "weak",
+	auditRepo := &mockAuditLogRepository{}
+	uc.Audit = &AuditUsecase{Repo: auditRepo}
+
+	password := "Password123!"
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
+	uc.Repo.Create(&entity.User{ID: "user-1", Email: "john@example.com", Password: string(hashed), Verified: true})
+
+	summary, err := uc.SecuritySummary("user-1", "john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
 	}
-	
-	err := uc.ChangePasswordWithOTP(req)
-	if err == nil {
-		t.Error("Expected validation error for weak password")
+	if !summary.EmailVerified || summary.OTPLocked || summary.RecentFailedLogins != 0 {
+		t.Errorf("Unexpected summary for a fresh account %+v", summary)
+	}
+	if summary.LastLoginAt != "" || summary.PasswordChangedAt != "" {
+		t.Errorf("Expected no timestamps before any login or password change, got %+v", summary)
 	}
-}
 
-func TestChangePasswordWithOldPassword_Success(t *testing.T) {
-	uc := setupUserUsecase()
-	
-	oldPassword := "OldPassword123!"
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(oldPassword), 12)
-	user := &entity.User{
-		Email:    "john@example.com",
-		Password: string(hashedPassword),
+	uc.RecordFailedLogin("john@example.com", "203.0.113.7", "curl/8.0")
+	if _, err := uc.Login("john@example.com", password); err != nil {
+		t.Fatalf("Expected login to succeed, got %v", err)
 	}
-	uc.Repo.Create(user)
-	
-	req := dto.ChangePasswordWithOldPasswordRequest{
-		OldPassword: oldPassword,
-		NewPassword: "NewPassword123!",
+	err = uc.ChangePasswordWithOldPassword("john@example.com", dto.ChangePasswordWithOldPasswordRequest{OldPassword: password, NewPassword: "NewPassword123!"})
+	if err != nil {
+		t.Fatalf("Expected password change to succeed, got %v", err)
 	}
-	
-	err := uc.ChangePasswordWithOldPassword("john@example.com", req)
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	user.OTPAttempts = constants.MaxOTPAttempts
+	user.OTPExpiresAt = time.Now().Add(5 * time.Minute)
+
+	summary, err = uc.SecuritySummary("user-1", "john@example.com")
 	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
 	}
-	
-	// Verify password was changed
-	updatedUser, _ := uc.Repo.FindByEmail("john@example.com")
-	if updatedUser.Password == string(hashedPassword) {
-		t.Error("Expected password to be changed")
+	if summary.LastLoginAt == "" || summary.PasswordChangedAt == "" {
+		t.Errorf("Expected login and password change times, got %+v", summary)
 	}
-}
-
+	if !summary.OTPLocked {
+		t.Error("Expected OTP lockout to be reported")
+	}
+	// Reported locked means every path taking the OTP refuses it
+	if err := uc.VerifyOTP("john@example.com", "123456"); err != appErrors.ErrTooManyOTPAttempts {
+		t.Errorf("Expected verification to be refused while locked, got %v", err)
+	}
+	if err := uc.ChangePasswordWithOTP(dto.ChangePasswordRequest{Email: "john@example.com", OTP: "123456", Password: "OtherPassword123!"}); err != appErrors.ErrTooManyOTPAttempts {
+		t.Errorf("Expected the password change to be refused while locked, got %v", err)
+	}
+	if summary.RecentFailedLogins != 1 {
+		t.Errorf("Expected 1 failed login, got %d", summary.RecentFailedLogins)
+	}
+
+	// An expired OTP can't be locked any more
+	user.OTPExpiresAt = time.Now().Add(-time.Minute)
+	if summary, _ := uc.SecuritySummary("user-1", "john@example.com"); summary.OTPLocked {
+		t.Error("Expected no lockout once the OTP expired")
+	}
+}
+
+func TestSecuritySummary_PhoneAndSessions(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Sessions = &mockSessionRepository{}
+	uc.SendEmail = func(to, subject, body string) error { return nil }
+	uc.SMS = &stubSMS{sent: map[string]string{}}
+	uc.Repo.Create(&entity.User{ID: "user-1", Email: "john@example.com", PhoneNumber: "+6281234567890", Verified: true})
+
+	summary, err := uc.SecuritySummary("user-1", "john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if summary.PhoneVerified || summary.ActiveSessions != 0 {
+		t.Errorf("Expected an unproven phone and no sessions, got %+v", summary)
+	}
+
+	for i := 0; i < 2; i++ {
+		logged, _ := uc.LoginWithoutPassword("john@example.com")
+		uc.StartSession(logged.Token, "203.0.113.7", "")
+	}
+	// An emailed code says nothing about the phone
+	uc.SendOTPVia(constants.OTP_CHANNEL_EMAIL, constants.VERIFICATION, "john@example.com", "")
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	otp, _ := utils.Decrypt(user.OTP)
+	uc.VerifyOTP("john@example.com", otp)
+	if summary, _ := uc.SecuritySummary("user-1", "john@example.com"); summary.PhoneVerified || summary.ActiveSessions != 2 {
+		t.Errorf("Expected two sessions and the phone still unproven, got %+v", summary)
+	}
+
+	uc.SendOTPVia(constants.OTP_CHANNEL_SMS, constants.VERIFICATION, "john@example.com", "")
+	otp, _ = utils.Decrypt(user.OTP)
+	if err := uc.VerifyOTP("john@example.com", otp); err != nil {
+		t.Fatalf("Expected the texted code to be accepted, got %v", err)
+	}
+	if summary, _ := uc.SecuritySummary("user-1", "john@example.com"); !summary.PhoneVerified {
+		t.Errorf("Expected the phone verified by the texted code, got %+v", summary)
+	}
+
+	// A new number has to be proven again
+	uc.SendOTPVia(constants.OTP_CHANNEL_EMAIL, constants.PHONE_CHANGED, "john@example.com", "")
+	otp, _ = utils.Decrypt(user.OTP)
+	if err := uc.UpdateUserByPhone(dto.ChangePhoneRequest{NewPhone: "+6281234567899", OTP: otp}, "+6281234567890"); err != nil {
+		t.Fatalf("Expected the phone change to succeed, got %v", err)
+	}
+	if summary, _ := uc.SecuritySummary("user-1", "john@example.com"); summary.PhoneVerified {
+		t.Error("Expected the new number to be unverified")
+	}
+}
+
+func TestSecuritySummary_UnknownUser(t *testing.T) {
+	uc := setupUserUsecase()
+
+	if _, err := uc.SecuritySummary("user-1", "nobody@example.com"); err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestSendOTP_UnsupportedType(t *testing.T) {
+	uc := setupUserUsecase()
+	sent := false
+	uc.SendEmail = func(to, subject, body string) error {
+		sent = true
+		return nil
+	}
+
+	user := &entity.User{Email: "john@example.com"}
+	uc.Repo.Create(user)
+
+	err := uc.SendOTP("bogus", "john@example.com")
+	appErr, ok := appErrors.IsAppError(err)
+	if !ok || appErr.Status != 400 || appErr.Message != "unsupported OTP type" {
+		t.Fatalf("Expected a bad request for an unsupported OTP type, got %v", err)
+	}
+	if user.OTP != "" || !user.OTPExpiresAt.IsZero() || user.OTPType != "" {
+		t.Error("Expected no OTP to be generated")
+	}
+	if sent {
+		t.Error("Expected no email to be sent")
+	}
+}
+
+func TestVerifyOTP_CorruptedCiphertext(t *testing.T) {
+	uc := setupUserUsecase()
+
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          "not-base64!!",
+		OTPType:      constants.VERIFICATION,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	err := uc.VerifyOTP("john@example.com", "123456")
+	if err != appErrors.ErrDecryptionFailed {
+		t.Errorf("Expected ErrDecryptionFailed, got %v", err)
+	}
+	if user.Verified {
+		t.Error("Expected user to stay unverified")
+	}
+}
+
+func TestVerifyOTP_WrongCode(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, err := utils.Encrypt("123456")
+	if err != nil {
+		t.Fatalf("Failed to encrypt OTP: %v", err)
+	}
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPType:      constants.VERIFICATION,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	err = uc.VerifyOTP("john@example.com", "654321")
+	if err != appErrors.ErrInvalidOTP {
+		t.Errorf("Expected ErrInvalidOTP, got %v", err)
+	}
+	if user.Verified {
+		t.Error("Expected user to stay unverified")
+	}
+}
+
+func TestPeekOTP_ValidLeavesOTPUsable(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, err := utils.Encrypt("123456")
+	if err != nil {
+		t.Fatalf("Failed to encrypt OTP: %v", err)
+	}
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPType:      constants.VERIFICATION,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	valid, err := uc.PeekOTP("john@example.com", "123456")
+	if err != nil || !valid {
+		t.Fatalf("Expected valid peek, got valid=%v err=%v", valid, err)
+	}
+	if user.OTP == "" || user.Verified {
+		t.Error("Expected peek to leave the OTP in place and the user unverified")
+	}
+
+	// The same OTP still works for the real verification step
+	if err := uc.VerifyOTP("john@example.com", "123456"); err != nil {
+		t.Errorf("Expected OTP to remain usable after peek, got %v", err)
+	}
+	if !user.Verified {
+		t.Error("Expected user to be verified")
+	}
+}
+
+func TestPeekOTP_InvalidIncrementsAttempts(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, _ := utils.Encrypt("123456")
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	valid, err := uc.PeekOTP("john@example.com", "000000")
+	if err != nil || valid {
+		t.Fatalf("Expected invalid peek without error, got valid=%v err=%v", valid, err)
+	}
+	if user.OTPAttempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", user.OTPAttempts)
+	}
+}
+
+func TestPeekOTP_TooManyAttempts(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, _ := utils.Encrypt("123456")
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	for i := 0; i < constants.MaxOTPAttempts; i++ {
+		uc.PeekOTP("john@example.com", "000000")
+	}
+
+	// Even the correct code is refused once the limit is reached
+	if _, err := uc.PeekOTP("john@example.com", "123456"); err != appErrors.ErrTooManyOTPAttempts {
+		t.Errorf("Expected ErrTooManyOTPAttempts, got %v", err)
+	}
+}
+
+func TestPeekOTP_Expired(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, _ := utils.Encrypt("123456")
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPExpiresAt: time.Now().Add(-1 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	if _, err := uc.PeekOTP("john@example.com", "123456"); err != appErrors.ErrExpiredOTP {
+		t.Errorf("Expected ErrExpiredOTP, got %v", err)
+	}
+}
+
+func TestOTPAttemptLimit_EveryPath(t *testing.T) {
+	paths := []struct {
+		name    string
+		otpType string
+		consume func(uc *UserUsecase, otp string) error
+	}{
+		{"verify", constants.VERIFICATION, func(uc *UserUsecase, otp string) error {
+			return uc.VerifyOTP("john@example.com", otp)
+		}},
+		{"change password", constants.FORGOT_PASSWORD, func(uc *UserUsecase, otp string) error {
+			return uc.ChangePasswordWithOTP(dto.ChangePasswordRequest{Email: "john@example.com", OTP: otp, Password: "NewPassword123!"})
+		}},
+		{"change password step-up", constants.FORGOT_PASSWORD, func(uc *UserUsecase, otp string) error {
+			return uc.ChangePasswordStepUp("john@example.com", dto.ChangePasswordStepUpRequest{OTP: otp, NewPassword: "NewPassword123!"})
+		}},
+		{"change email", constants.EMAIL_CHANGED, func(uc *UserUsecase, otp string) error {
+			return uc.UpdateUserByEmail(dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: otp, NewEmailOTP: "654321"}, "john@example.com")
+		}},
+		{"change phone", constants.PHONE_CHANGED, func(uc *UserUsecase, otp string) error {
+			return uc.UpdateUserByPhone(dto.ChangePhoneRequest{NewPhone: "+6281234567890", OTP: otp}, "+6280000000000")
+		}},
+	}
+
+	for _, path := range paths {
+		t.Run(path.name, func(t *testing.T) {
+			uc := setupUserUsecase()
+			encryptedOTP, _ := utils.Encrypt("123456")
+			pendingOTP, _ := utils.Encrypt("654321")
+			uc.Repo.Create(&entity.User{
+				ID:                    "user-123",
+				Email:                 "john@example.com",
+				PhoneNumber:           "+6280000000000",
+				OTP:                   encryptedOTP,
+				OTPType:               path.otpType,
+				OTPExpiresAt:          time.Now().Add(10 * time.Minute),
+				PendingEmail:          "new@example.com",
+				PendingEmailOTP:       pendingOTP,
+				PendingEmailExpiresAt: time.Now().Add(10 * time.Minute),
+			})
+
+			for i := 0; i < constants.MaxOTPAttempts; i++ {
+				if err := path.consume(uc, "000000"); err != appErrors.ErrInvalidOTP {
+					t.Fatalf("Expected ErrInvalidOTP for wrong code %d, got %v", i+1, err)
+				}
+			}
+			// Even the correct code is refused once the limit is reached
+			if err := path.consume(uc, "123456"); err != appErrors.ErrTooManyOTPAttempts {
+				t.Errorf("Expected ErrTooManyOTPAttempts, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyOTP_SuccessResetsAttempts(t *testing.T) {
+	uc := setupUserUsecase()
+	encryptedOTP, _ := utils.Encrypt("123456")
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPType:      constants.VERIFICATION,
+		OTPExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	uc.VerifyOTP("john@example.com", "000000")
+	if user.OTPAttempts != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", user.OTPAttempts)
+	}
+	if err := uc.VerifyOTP("john@example.com", "123456"); err != nil {
+		t.Fatalf("Expected the correct code to verify, got %v", err)
+	}
+	if user.OTPAttempts != 0 {
+		t.Errorf("Expected the attempts to be reset once the code is used, got %d", user.OTPAttempts)
+	}
+}
+
+func TestOnBoard_Success(t *testing.T) {
+	uc := setupUserUsecase()
+
+	user := &entity.User{
+		Email:     "john@example.com",
+		OnBoarded: false,
+	}
+	uc.Repo.Create(user)
+
+	err := uc.OnBoard("john@example.com")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	updatedUser, _ := uc.Repo.FindByEmail("john@example.com")
+	if !updatedUser.OnBoarded {
+		t.Error("Expected user to be onboarded")
+	}
+}
+
+func TestOnBoard_UserNotFound(t *testing.T) {
+	uc := setupUserUsecase()
+
+	// The token outlived its account, the client has to sign in again
+	err := uc.OnBoard("nonexistent@example.com")
+	if err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestCompleteOnboarding_SavesProfileData(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com", Fullname: "john"})
+
+	user, err := uc.CompleteOnboarding("user-123", "john@example.com", dto.OnboardRequest{
+		Fullname:    "  John Doe ",
+		Preferences: &dto.UserPreferences{Language: "ID", Theme: "dark", Timezone: "Asia/Jakarta"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !user.OnBoarded || user.Fullname != "John Doe" {
+		t.Errorf("Expected an onboarded John Doe, got %+v", user)
+	}
+	expected := entity.UserPreferences{Language: "id", Theme: "dark", Timezone: "Asia/Jakarta"}
+	if user.Preferences != expected {
+		t.Errorf("Expected preferences %+v, got %+v", expected, user.Preferences)
+	}
+}
+
+func TestCompleteOnboarding_InvalidData(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.OnboardRequest
+	}{
+		{"full name", dto.OnboardRequest{Fullname: "J0hn"}},
+		{"language", dto.OnboardRequest{Preferences: &dto.UserPreferences{Language: "english"}}},
+		{"unsupported language", dto.OnboardRequest{Preferences: &dto.UserPreferences{Language: "fr"}}},
+		{"theme", dto.OnboardRequest{Preferences: &dto.UserPreferences{Theme: "neon"}}},
+		{"timezone", dto.OnboardRequest{Preferences: &dto.UserPreferences{Timezone: "Mars/Olympus"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := setupUserUsecase()
+			uc.Repo.Create(&entity.User{Email: "john@example.com", Fullname: "John"})
+
+			_, err := uc.CompleteOnboarding("", "john@example.com", tt.req)
+			if appErr, ok := appErrors.IsAppError(err); !ok || appErr.Code != "VALIDATION_ERROR" {
+				t.Fatalf("Expected a validation error, got %v", err)
+			}
+			user, _ := uc.Repo.FindByEmail("john@example.com")
+			if user.OnBoarded || user.Fullname != "John" {
+				t.Errorf("Expected the user to be left untouched, got %+v", user)
+			}
+		})
+	}
+}
+
+func TestCompleteOnboarding_AlreadyOnboarded(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Repo.Create(&entity.User{Email: "john@example.com"})
+
+	first := dto.OnboardRequest{Fullname: "John Doe", Preferences: &dto.UserPreferences{Theme: "dark"}}
+	if _, err := uc.CompleteOnboarding("", "john@example.com", first); err != nil {
+		t.Fatalf("Expected first onboarding to succeed, got %v", err)
+	}
+
+	// A resubmitted form must not overwrite the saved profile
+	second := dto.OnboardRequest{Fullname: "Someone Else", Preferences: &dto.UserPreferences{Theme: "light"}}
+	if _, err := uc.CompleteOnboarding("", "john@example.com", second); err != appErrors.ErrAlreadyOnboarded {
+		t.Errorf("Expected ErrAlreadyOnboarded, got %v", err)
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if user.Fullname != "John Doe" || user.Preferences.Theme != "dark" {
+		t.Errorf("Expected the first submission to be kept, got %+v", user)
+	}
+}
+
+func TestChangePasswordWithOTP_Success(t *testing.T) {
+	uc := setupUserUsecase()
+
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          "encrypted-123456",
+		OTPType:      constants.FORGOT_PASSWORD,
+		OTPExpiresAt: time.Now().Add(10 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	req := dto.ChangePasswordRequest{
+		Email:    "john@example.com",
+		OTP:      "123456",
+		Password: "NewPassword123!",
+	}
+
+	err := uc.ChangePasswordWithOTP(req)
+	// This will fail due to encryption/OTP validation but tests the flow
+	if err != appErrors.ErrInvalidOTP {
+		t.Logf("Got error (expected due to encryption): %v", err)
+	}
+}
+
+func TestChangePassword_SendsNotice(t *testing.T) {
+	oldPassword := "OldPassword123!"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(oldPassword), 10)
+	encryptedOTP, _ := utils.Encrypt("123456")
+
+	tests := []struct {
+		name   string
+		change func(uc *UserUsecase) error
+	}{
+		{"with OTP", func(uc *UserUsecase) error {
+			return uc.ChangePasswordWithOTP(dto.ChangePasswordRequest{Email: "john@example.com", OTP: "123456", Password: "NewPassword123!"})
+		}},
+		{"with old password", func(uc *UserUsecase) error {
+			return uc.ChangePasswordWithOldPassword("john@example.com", dto.ChangePasswordWithOldPasswordRequest{OldPassword: oldPassword, NewPassword: "NewPassword123!"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, enabled := range []bool{true, false} {
+				uc := setupUserUsecase()
+				uc.Flags = &featureflags.Flags{PasswordChangedNotice: enabled}
+				uc.SupportURL = "https://support.example.com"
+				uc.RunAsync = func(task func()) { task() }
+
+				var notices []string
+				uc.SendEmail = func(to, subject, body string) error {
+					notices = append(notices, body)
+					if to != "john@example.com" || !strings.Contains(body, "https://support.example.com") {
+						t.Errorf("Unexpected notice to %s: %s", to, body)
+					}
+					return nil
+				}
+				uc.Repo.Create(&entity.User{
+					Email:        "john@example.com",
+					Password:     string(hashedPassword),
+					OTP:          encryptedOTP,
+					OTPType:      constants.FORGOT_PASSWORD,
+					OTPExpiresAt: time.Now().Add(10 * time.Minute),
+				})
+
+				if err := tt.change(uc); err != nil {
+					t.Fatalf("Expected password change to succeed, got %v", err)
+				}
+				if enabled && len(notices) != 1 {
+					t.Errorf("Expected one notice with the flag on, got %d", len(notices))
+				}
+				if !enabled && len(notices) != 0 {
+					t.Errorf("Expected no notice with the flag off, got %d", len(notices))
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordChangedAt_SetOnRegisterAndChange(t *testing.T) {
+	uc := setupUserUsecase()
+
+	before := time.Now()
+	user, err := uc.Register(dto.RegisterRequest{Fullname: "John Doe", Email: "john@example.com", Password: "OldPassword123!", PhoneNumber: "+1234567890"})
+	if err != nil {
+		t.Fatalf("Expected registration to succeed, got %v", err)
+	}
+	if user.PasswordChangedAt.Before(before) {
+		t.Errorf("Expected PasswordChangedAt to be set at registration, got %v", user.PasswordChangedAt)
+	}
+
+	encryptedOTP, _ := utils.Encrypt("123456")
+	changes := []struct {
+		name   string
+		change func() error
+	}{
+		{"with old password", func() error {
+			return uc.ChangePasswordWithOldPassword("john@example.com", dto.ChangePasswordWithOldPasswordRequest{OldPassword: "OldPassword123!", NewPassword: "NewPassword123!"})
+		}},
+		{"with OTP", func() error {
+			user.OTP = encryptedOTP
+			user.OTPType = constants.FORGOT_PASSWORD
+			user.OTPExpiresAt = time.Now().Add(10 * time.Minute)
+			return uc.ChangePasswordWithOTP(dto.ChangePasswordRequest{Email: "john@example.com", OTP: "123456", Password: "OtherPassword123!"})
+		}},
+	}
+	for _, tt := range changes {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := time.Now().Add(-90 * 24 * time.Hour)
+			user.PasswordChangedAt = previous
+			if err := tt.change(); err != nil {
+				t.Fatalf("Expected password change to succeed, got %v", err)
+			}
+			if !user.PasswordChangedAt.After(previous) {
+				t.Errorf("Expected PasswordChangedAt to move forward, still %v", user.PasswordChangedAt)
+			}
+		})
+	}
+}
+
+func TestSecuritySummary_PasswordStale(t *testing.T) {
+	uc := setupUserUsecase()
+	user := &entity.User{ID: "user-1", Email: "john@example.com", PasswordChangedAt: time.Now().Add(-100 * 24 * time.Hour)}
+	uc.Repo.Create(user)
+
+	summary, _ := uc.SecuritySummary("user-1", "john@example.com")
+	if summary.PasswordStale {
+		t.Error("Expected no stale flag without a max age policy")
+	}
+
+	uc.PasswordMaxAge = 90 * 24 * time.Hour
+	summary, _ = uc.SecuritySummary("user-1", "john@example.com")
+	if !summary.PasswordStale {
+		t.Error("Expected a 100 day old password to be stale with a 90 day policy")
+	}
+
+	user.PasswordChangedAt = time.Time{}
+	summary, _ = uc.SecuritySummary("user-1", "john@example.com")
+	if summary.PasswordStale {
+		t.Error("Expected an unknown password age never to be stale")
+	}
+}
+
+func TestChangePassword_NoticeFailureIsIgnored(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Flags = &featureflags.Flags{PasswordChangedNotice: true}
+	uc.RunAsync = func(task func()) { task() }
+	uc.SendEmail = func(to, subject, body string) error {
+		return errors.New("smtp down")
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("OldPassword123!"), 10)
+	uc.Repo.Create(&entity.User{Email: "john@example.com", Password: string(hashedPassword)})
+
+	req := dto.ChangePasswordWithOldPasswordRequest{OldPassword: "OldPassword123!", NewPassword: "NewPassword123!"}
+	if err := uc.ChangePasswordWithOldPassword("john@example.com", req); err != nil {
+		t.Errorf("Expected a failed notice not to fail the change, got %v", err)
+	}
+}
+
+func TestChangePasswordWithOTP_WeakPassword(t *testing.T) {
+	uc := setupUserUsecase()
+
+	req := dto.ChangePasswordRequest{
+		Email:    "john@example.com",
+		OTP:      "123456",
+		Password: "weak",
+	}
+
+	err := uc.ChangePasswordWithOTP(req)
+	if err == nil {
+		t.Error("Expected validation error for weak password")
+	}
+}
+
+func TestChangePasswordWithOldPassword_Success(t *testing.T) {
+	uc := setupUserUsecase()
+
+	oldPassword := "OldPassword123!"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(oldPassword), 12)
+	user := &entity.User{
+		Email:    "john@example.com",
+		Password: string(hashedPassword),
+	}
+	uc.Repo.Create(user)
+
+	req := dto.ChangePasswordWithOldPasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: "NewPassword123!",
+	}
+
+	err := uc.ChangePasswordWithOldPassword("john@example.com", req)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Verify password was changed
+	updatedUser, _ := uc.Repo.FindByEmail("john@example.com")
+	if updatedUser.Password == string(hashedPassword) {
+		t.Error("Expected password to be changed")
+	}
+}
+
 func TestChangePasswordWithOldPassword_InvalidOldPassword(t *testing.T) {
 	uc := setupUserUsecase()
-	
-	oldPassword := "OldPassword123!"
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(oldPassword), 12)
+
+	oldPassword := "OldPassword123!"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(oldPassword), 12)
+	user := &entity.User{
+		Email:    "john@example.com",
+		Password: string(hashedPassword),
+	}
+	uc.Repo.Create(user)
+
+	req := dto.ChangePasswordWithOldPasswordRequest{
+		OldPassword: "WrongPassword123!",
+		NewPassword: "NewPassword123!",
+	}
+
+	err := uc.ChangePasswordWithOldPassword("john@example.com", req)
+	if err != appErrors.ErrInvalidOldPassword {
+		t.Errorf("Expected ErrInvalidOldPassword, got %v", err)
+	}
+}
+
+func TestChangePasswordWithOldPassword_DeletedUser(t *testing.T) {
+	uc := setupUserUsecase()
+
+	req := dto.ChangePasswordWithOldPasswordRequest{OldPassword: "OldPassword123!", NewPassword: "NewPassword123!"}
+	if err := uc.ChangePasswordWithOldPassword("deleted@example.com", req); err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+	if err := uc.ChangePasswordStepUp("deleted@example.com", dto.ChangePasswordStepUpRequest{OTP: "123456", NewPassword: "NewPassword123!"}); err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken from step-up, got %v", err)
+	}
+}
+
+func TestChangePasswordStepUp_Success(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, err := utils.Encrypt("123456")
+	if err != nil {
+		t.Fatalf("Failed to encrypt OTP: %v", err)
+	}
+	user := &entity.User{
+		Email:        "john@example.com",
+		Password:     "old-hash",
+		OTP:          encryptedOTP,
+		OTPType:      constants.FORGOT_PASSWORD,
+		OTPExpiresAt: time.Now().Add(10 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	req := dto.ChangePasswordStepUpRequest{OTP: "123456", NewPassword: "NewPassword123!"}
+	if err := uc.ChangePasswordStepUp("john@example.com", req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.NewPassword)) != nil {
+		t.Error("Expected password to be updated")
+	}
+	if user.OTP != "" || user.OTPType != "" {
+		t.Error("Expected OTP to be cleared")
+	}
+}
+
+func TestChangePasswordStepUp_WrongOTPType(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, _ := utils.Encrypt("123456")
+	user := &entity.User{
+		Email:        "john@example.com",
+		Password:     "old-hash",
+		OTP:          encryptedOTP,
+		OTPType:      constants.EMAIL_CHANGED,
+		OTPExpiresAt: time.Now().Add(10 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	req := dto.ChangePasswordStepUpRequest{OTP: "123456", NewPassword: "NewPassword123!"}
+	if err := uc.ChangePasswordStepUp("john@example.com", req); err != appErrors.ErrInvalidOTP {
+		t.Errorf("Expected ErrInvalidOTP, got %v", err)
+	}
+	if user.Password != "old-hash" {
+		t.Error("Expected password to be unchanged")
+	}
+}
+
+func TestChangePasswordStepUp_ExpiredOTP(t *testing.T) {
+	uc := setupUserUsecase()
+
+	encryptedOTP, _ := utils.Encrypt("123456")
+	user := &entity.User{
+		Email:        "john@example.com",
+		OTP:          encryptedOTP,
+		OTPType:      constants.FORGOT_PASSWORD,
+		OTPExpiresAt: time.Now().Add(-1 * time.Minute),
+	}
+	uc.Repo.Create(user)
+
+	req := dto.ChangePasswordStepUpRequest{OTP: "123456", NewPassword: "NewPassword123!"}
+	if err := uc.ChangePasswordStepUp("john@example.com", req); err != appErrors.ErrExpiredOTP {
+		t.Errorf("Expected ErrExpiredOTP, got %v", err)
+	}
+}
+
+func TestChangePasswordStepUp_WeakPassword(t *testing.T) {
+	uc := setupUserUsecase()
+
+	req := dto.ChangePasswordStepUpRequest{OTP: "123456", NewPassword: "weak"}
+	if err := uc.ChangePasswordStepUp("john@example.com", req); err == nil {
+		t.Error("Expected validation error for weak password")
+	}
+}
+
+func TestPasswordResetLink_StoresOnlyHashAndIsSingleUse(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.PasswordResetURL = "https://app.example.com/reset-password"
+
+	var sentBody string
+	uc.SendEmail = func(to, subject, body string) error {
+		sentBody = body
+		return nil
+	}
+
+	user := &entity.User{Email: "john@example.com", Password: "old-hash", Verified: true}
+	uc.Repo.Create(user)
+
+	if err := uc.SendPasswordResetLink("john@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Pull the raw token out of the emailed link
+	idx := strings.Index(sentBody, "token=")
+	if idx == -1 {
+		t.Fatalf("Expected reset link with token in email, got %q", sentBody)
+	}
+	token := strings.Fields(sentBody[idx+len("token="):])[0]
+
+	if user.PasswordResetTokenHash == "" {
+		t.Fatal("Expected token hash to be stored")
+	}
+	if user.PasswordResetTokenHash == token || strings.Contains(fmt.Sprintf("%+v", *user), token) {
+		t.Error("Expected raw token not to be persisted on the user")
+	}
+
+	req := dto.ResetPasswordWithTokenRequest{Email: "john@example.com", Token: token, NewPassword: "NewPassword123!"}
+	if err := uc.ResetPasswordWithToken(req); err != nil {
+		t.Fatalf("Expected reset to succeed, got %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.NewPassword)) != nil {
+		t.Error("Expected password to be updated")
+	}
+	if user.PasswordResetTokenHash != "" {
+		t.Error("Expected token hash to be cleared after use")
+	}
+
+	// The same link cannot be used twice
+	req.NewPassword = "AnotherPassword123!"
+	if err := uc.ResetPasswordWithToken(req); err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken on reuse, got %v", err)
+	}
+}
+
+func TestResetPasswordWithToken_WrongOrExpired(t *testing.T) {
+	uc := setupUserUsecase()
+
 	user := &entity.User{
-		Email:    "john@example.com",
-		Password: string(hashedPassword),
+		Email:                  "john@example.com",
+		Password:               "old-hash",
+		PasswordResetTokenHash: hashResetToken("right-token"),
+		PasswordResetExpiresAt: time.Now().Add(10 * time.Minute),
 	}
 	uc.Repo.Create(user)
-	
-	req := dto.ChangePasswordWithOldPasswordRequest{
-		OldPassword: "WrongPassword123!",
-		NewPassword: "NewPassword123!",
+
+	req := dto.ResetPasswordWithTokenRequest{Email: "john@example.com", Token: "wrong-token", NewPassword: "NewPassword123!"}
+	if err := uc.ResetPasswordWithToken(req); err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for wrong token, got %v", err)
 	}
-	
-	err := uc.ChangePasswordWithOldPassword("john@example.com", req)
-	if err != appErrors.ErrInvalidOldPassword {
-		t.Errorf("Expected ErrInvalidOldPassword, got %v", err)
+
+	user.PasswordResetExpiresAt = time.Now().Add(-1 * time.Minute)
+	req.Token = "right-token"
+	if err := uc.ResetPasswordWithToken(req); err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for expired token, got %v", err)
+	}
+	if user.Password != "old-hash" {
+		t.Error("Expected password to be unchanged")
 	}
 }
 
 func TestUpdateUser_Success(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
 		Email:     "john@example.com",
 		Fullname:  "John Doe",
 		AvatarUrl: "old-avatar.jpg",
 	}
 	uc.Repo.Create(user)
-	
+
 	req := dto.RegisterRequest{
 		Email:     "john@example.com",
 		Fullname:  "John Updated",
 		AvatarUrl: "new-avatar.jpg",
 	}
-	
+
 	updatedUser, err := uc.UpdateUser(req)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if updatedUser.Fullname != req.Fullname {
 		t.Errorf("Expected fullname %s, got %s", req.Fullname, updatedUser.Fullname)
 	}
-	
+
 	if updatedUser.AvatarUrl != req.AvatarUrl {
 		t.Errorf("Expected avatar URL %s, got %s", req.AvatarUrl, updatedUser.AvatarUrl)
 	}
@@ -593,38 +1738,219 @@ func TestUpdateUser_Success(t *testing.T) {
 
 func TestUpdateUser_EmptyAvatarUrl(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	user := &entity.User{
 		Email:     "john@example.com",
 		Fullname:  "John Doe",
 		AvatarUrl: "existing-avatar.jpg",
 	}
 	uc.Repo.Create(user)
-	
+
 	req := dto.RegisterRequest{
 		Email:     "john@example.com",
 		Fullname:  "John Updated",
 		AvatarUrl: "", // Empty avatar URL should preserve existing
 	}
-	
+
 	updatedUser, err := uc.UpdateUser(req)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	if updatedUser.AvatarUrl != "existing-avatar.jpg" {
 		t.Errorf("Expected avatar URL to be preserved, got %s", updatedUser.AvatarUrl)
 	}
 }
 
+func TestUpdateUserByEmail_Reverify(t *testing.T) {
+	for _, reverify := range []bool{true, false} {
+		uc := setupUserUsecase()
+		uc.Flags = &featureflags.Flags{ReverifyOnEmailChange: reverify}
+
+		var sentTo, sentBody string
+		uc.SendEmail = func(to, subject, body string) error {
+			sentTo, sentBody = to, body
+			return nil
+		}
+
+		encryptedOTP, _ := utils.Encrypt("123456")
+		pendingOTP, _ := utils.Encrypt("654321")
+		uc.Repo.Create(&entity.User{
+			Email:                 "old@example.com",
+			Verified:              true,
+			OTP:                   encryptedOTP,
+			OTPType:               constants.EMAIL_CHANGED,
+			OTPExpiresAt:          time.Now().Add(10 * time.Minute),
+			PendingEmail:          "new@example.com",
+			PendingEmailOTP:       pendingOTP,
+			PendingEmailExpiresAt: time.Now().Add(10 * time.Minute),
+		})
+
+		req := dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "654321"}
+		if err := uc.UpdateUserByEmail(req, "old@example.com"); err != nil {
+			t.Fatalf("Expected email change to succeed, got %v", err)
+		}
+
+		user, err := uc.Repo.FindByEmail("new@example.com")
+		if err != nil {
+			t.Fatalf("Expected user under the new email, got %v", err)
+		}
+
+		if reverify {
+			if user.Verified {
+				t.Error("Expected account to be unverified after the email change")
+			}
+			if sentTo != "new@example.com" || !strings.Contains(sentBody, constants.VERIFICATION) {
+				t.Errorf("Expected verification OTP sent to the new email, got %q: %q", sentTo, sentBody)
+			}
+			if user.OTPType != constants.VERIFICATION || user.OTP == "" {
+				t.Error("Expected a verification OTP to be stored")
+			}
+		} else {
+			// The code sent to the new address already proved it
+			if !user.Verified {
+				t.Error("Expected verification to be preserved with the flag off")
+			}
+			if sentTo != "" {
+				t.Errorf("Expected no OTP with the flag off, got one to %s", sentTo)
+			}
+		}
+	}
+}
+
+func TestUpdateUserByEmail_NewEmailCodeAttempts(t *testing.T) {
+	uc := setupUserUsecase()
+	encryptedOTP, _ := utils.Encrypt("123456")
+	pendingOTP, _ := utils.Encrypt("654321")
+	uc.Repo.Create(&entity.User{
+		Email:                 "old@example.com",
+		OTP:                   encryptedOTP,
+		OTPType:               constants.EMAIL_CHANGED,
+		OTPExpiresAt:          time.Now().Add(10 * time.Minute),
+		PendingEmail:          "new@example.com",
+		PendingEmailOTP:       pendingOTP,
+		PendingEmailExpiresAt: time.Now().Add(10 * time.Minute),
+	})
+
+	for i := 0; i < constants.MaxOTPAttempts; i++ {
+		req := dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "000000"}
+		if err := uc.UpdateUserByEmail(req, "old@example.com"); err != appErrors.ErrInvalidOTP {
+			t.Fatalf("Expected ErrInvalidOTP for wrong new email code %d, got %v", i+1, err)
+		}
+	}
+	req := dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "654321"}
+	if err := uc.UpdateUserByEmail(req, "old@example.com"); err != appErrors.ErrTooManyOTPAttempts {
+		t.Errorf("Expected ErrTooManyOTPAttempts once the attempts are used up, got %v", err)
+	}
+}
+
+func TestSendOTPNewEmail(t *testing.T) {
+	uc := setupUserUsecase()
+	var sentTo, sentBody string
+	uc.SendEmail = func(to, subject, body string) error {
+		sentTo, sentBody = to, body
+		return nil
+	}
+	uc.Repo.Create(&entity.User{Email: "old@example.com"})
+	uc.Repo.Create(&entity.User{Email: "taken@example.com"})
+
+	if err := uc.SendOTPNewEmail("old@example.com", "taken@example.com"); err != appErrors.ErrEmailAlreadyExists {
+		t.Errorf("Expected ErrEmailAlreadyExists, got %v", err)
+	}
+	if err := uc.SendOTPNewEmail("old@example.com", "not-an-email"); err == nil {
+		t.Error("Expected an invalid address to be rejected")
+	}
+	if err := uc.SendOTPNewEmail("old@example.com", "old@example.com"); err == nil {
+		t.Error("Expected the current address to be rejected")
+	}
+	if sentTo != "" {
+		t.Fatalf("Expected no email for rejected addresses, got one to %s", sentTo)
+	}
+
+	if err := uc.SendOTPNewEmail("old@example.com", "new@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	user, _ := uc.Repo.FindByEmail("old@example.com")
+	if sentTo != "new@example.com" {
+		t.Errorf("Expected the code to go to the new address, got %s", sentTo)
+	}
+	code, err := utils.Decrypt(user.PendingEmailOTP)
+	if err != nil || !strings.Contains(sentBody, code) {
+		t.Errorf("Expected the stored code %q in the email %q", code, sentBody)
+	}
+	if user.PendingEmail != "new@example.com" || user.PendingEmailExpiresAt.Before(time.Now()) {
+		t.Errorf("Expected a pending change to new@example.com, got %+v", user)
+	}
+	if user.OTP != "" {
+		t.Error("Expected the current-address OTP to be untouched")
+	}
+}
+
+func TestUpdateUserByEmail_RequiresNewEmailConfirmation(t *testing.T) {
+	setup := func(pending string, expiresIn time.Duration) *UserUsecase {
+		uc := setupUserUsecase()
+		encryptedOTP, _ := utils.Encrypt("123456")
+		pendingOTP, _ := utils.Encrypt("654321")
+		uc.Repo.Create(&entity.User{
+			Email:                 "old@example.com",
+			OTP:                   encryptedOTP,
+			OTPType:               constants.EMAIL_CHANGED,
+			OTPExpiresAt:          time.Now().Add(10 * time.Minute),
+			PendingEmail:          pending,
+			PendingEmailOTP:       pendingOTP,
+			PendingEmailExpiresAt: time.Now().Add(expiresIn),
+		})
+		return uc
+	}
+
+	tests := []struct {
+		name      string
+		pending   string
+		expiresIn time.Duration
+		req       dto.ChangeEmailRequest
+		expected  error
+	}{
+		{"no code requested", "", 0, dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "654321"}, appErrors.ErrNewEmailNotConfirmed},
+		{"code for another address", "other@example.com", 10 * time.Minute, dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "654321"}, appErrors.ErrNewEmailNotConfirmed},
+		{"wrong new email code", "new@example.com", 10 * time.Minute, dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "000000"}, appErrors.ErrInvalidOTP},
+		{"expired new email code", "new@example.com", -time.Minute, dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "654321"}, appErrors.ErrExpiredOTP},
+		{"wrong old email OTP", "new@example.com", 10 * time.Minute, dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "000000", NewEmailOTP: "654321"}, appErrors.ErrInvalidOTP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := setup(tt.pending, tt.expiresIn)
+			if err := uc.UpdateUserByEmail(tt.req, "old@example.com"); err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if _, err := uc.Repo.FindByEmail("old@example.com"); err != nil {
+				t.Error("Expected the email to stay unchanged")
+			}
+		})
+	}
+
+	uc := setup("new@example.com", 10*time.Minute)
+	req := dto.ChangeEmailRequest{NewEmail: "new@example.com", OTP: "123456", NewEmailOTP: "654321"}
+	if err := uc.UpdateUserByEmail(req, "old@example.com"); err != nil {
+		t.Fatalf("Expected the confirmed change to succeed, got %v", err)
+	}
+	user, err := uc.Repo.FindByEmail("new@example.com")
+	if err != nil {
+		t.Fatalf("Expected user under the new email, got %v", err)
+	}
+	if user.PendingEmail != "" || user.PendingEmailOTP != "" || !user.PendingEmailExpiresAt.IsZero() {
+		t.Errorf("Expected the pending change to be cleared, got %+v", user)
+	}
+}
+
 func TestUpdateUserByEmail_UserNotFound(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	req := dto.ChangeEmailRequest{
 		NewEmail: "new@example.com",
 		OTP:      "123456",
 	}
-	
+
 	err := uc.UpdateUserByEmail(req, "nonexistent@example.com")
 	if err != appErrors.ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
@@ -633,12 +1959,12 @@ func TestUpdateUserByEmail_UserNotFound(t *testing.T) {
 
 func TestUpdateUserByPhone_UserNotFound(t *testing.T) {
 	uc := setupUserUsecase()
-	
+
 	req := dto.ChangePhoneRequest{
 		NewPhone: "+9876543210",
 		OTP:      "123456",
 	}
-	
+
 	err := uc.UpdateUserByPhone(req, "+1234567890")
 	if err != appErrors.ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
@@ -651,11 +1977,11 @@ func TestUserUsecaseStruct(t *testing.T) {
 		JWTSecret: "test-secret",
 		JWTExpire: 60,
 	}
-	
+
 	if uc.JWTSecret != "test-secret" {
 		t.Errorf("Expected JWT secret %s, got %s", "test-secret", uc.JWTSecret)
 	}
-	
+
 	if uc.JWTExpire != 60 {
 		t.Errorf("Expected JWT expire %d, got %d", 60, uc.JWTExpire)
 	}
@@ -664,4 +1990,435 @@ func TestUserUsecaseStruct(t *testing.T) {
 // Cleanup
 func TestCleanup(t *testing.T) {
 	os.Unsetenv("DECRYPT_KEY")
-}
\ No newline at end of file
+}
+func TestLogout_RevokeToken(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.JWTExpire = 60
+
+	// Nothing to revoke without a blacklist or a jti
+	if err := uc.Logout("jti-1", "john@example.com", time.Time{}); err != nil {
+		t.Errorf("Expected no error without RevokeToken, got %v", err)
+	}
+
+	revoked := map[string]time.Time{}
+	uc.RevokeToken = func(jti, email string, expiresAt time.Time) error {
+		revoked[jti] = expiresAt
+		return nil
+	}
+	if err := uc.Logout("", "john@example.com", time.Time{}); err != nil || len(revoked) != 0 {
+		t.Errorf("Expected a token without jti to be skipped, got %v, %v", err, revoked)
+	}
+
+	// A missing expiry falls back to the configured token lifetime
+	if err := uc.Logout("jti-1", "john@example.com", time.Time{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if until := time.Until(revoked["jti-1"]); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("Expected the jti to be blacklisted for about an hour, got %v", until)
+	}
+
+	uc.RevokeToken = func(jti, email string, expiresAt time.Time) error {
+		return errors.New("mongo unavailable")
+	}
+	if err := uc.Logout("jti-2", "john@example.com", time.Now().Add(time.Hour)); err != appErrors.ErrDatabaseOperation {
+		t.Errorf("Expected ErrDatabaseOperation, got %v", err)
+	}
+}
+
+// mockSessionRepository keeps sessions in memory, keyed by id
+type mockSessionRepository struct {
+	sessions map[string]*entity.Session
+}
+
+func (m *mockSessionRepository) Create(session *entity.Session) error {
+	if m.sessions == nil {
+		m.sessions = make(map[string]*entity.Session)
+	}
+	session.ID = primitive.NewObjectID()
+	m.sessions[session.ID.Hex()] = session
+	return nil
+}
+
+func (m *mockSessionRepository) FindActiveByUser(userID string, now time.Time) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].IssuedAt.After(sessions[j].IssuedAt) })
+	return sessions, nil
+}
+
+func (m *mockSessionRepository) FindByID(id string) (*entity.Session, error) {
+	if session, ok := m.sessions[id]; ok {
+		return session, nil
+	}
+	return nil, appErrors.ErrSessionNotFound
+}
+
+func (m *mockSessionRepository) RevokeByJTI(jti string, at time.Time) error {
+	for _, session := range m.sessions {
+		if session.JTI == jti && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *mockSessionRepository) RevokeAllByUser(userID string, at time.Time) error {
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestSessions(t *testing.T) {
+	uc := setupUserUsecase()
+	sessions := &mockSessionRepository{}
+	uc.Sessions = sessions
+	revoked := map[string]bool{}
+	uc.RevokeToken = func(jti, email string, expiresAt time.Time) error {
+		revoked[jti] = true
+		return nil
+	}
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com", Verified: true})
+
+	laptop, err := uc.LoginWithoutPassword("john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	uc.StartSession(laptop.Token, "203.0.113.7", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/124.0.0.0 Safari/537.36")
+	phone, _ := uc.LoginWithoutPassword("john@example.com")
+	uc.StartSession(phone.Token, "198.51.100.2", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Safari/604.1")
+	uc.StartSession("not-a-token", "198.51.100.2", "")
+
+	var laptopJTI string
+	for _, session := range sessions.sessions {
+		if session.IP == "203.0.113.7" {
+			laptopJTI = session.JTI
+		}
+	}
+	listed, err := uc.ListSessions("user-123", laptopJTI)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(listed) != 2 {
+		t.Fatalf("Expected 2 sessions, got %+v", listed)
+	}
+	var current, other dto.SessionResponse
+	for _, session := range listed {
+		if session.Current {
+			current = session
+		} else {
+			other = session
+		}
+	}
+	if current.IP != "203.0.113.7" || current.Device != "Chrome on Windows" {
+		t.Errorf("Expected the laptop to be the current session, got %+v", current)
+	}
+
+	// Other users' sessions look like unknown ones
+	if err := uc.RevokeSession("user-456", "jane@example.com", other.ID); err != appErrors.ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound for another user's session, got %v", err)
+	}
+	if err := uc.RevokeSession("user-123", "john@example.com", other.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(revoked) != 1 {
+		t.Errorf("Expected the session's token to be blacklisted, got %v", revoked)
+	}
+	if err := uc.RevokeSession("user-123", "john@example.com", other.ID); err != appErrors.ErrSessionNotFound {
+		t.Errorf("Expected a revoked session to be gone, got %v", err)
+	}
+
+	// Logging out ends the current session too
+	if err := uc.Logout(laptopJTI, "john@example.com", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if listed, _ := uc.ListSessions("user-123", ""); len(listed) != 0 {
+		t.Errorf("Expected no active sessions left, got %+v", listed)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	uc := setupUserUsecase()
+	sessions := &mockSessionRepository{}
+	uc.Sessions = sessions
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com", Verified: true})
+	for i := 0; i < 2; i++ {
+		logged, _ := uc.LoginWithoutPassword("john@example.com")
+		uc.StartSession(logged.Token, "203.0.113.7", "")
+	}
+
+	if err := uc.LogoutAll("user-123", "john@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if version, _ := uc.TokenVersion("user-123", "john@example.com"); version != 1 {
+		t.Errorf("Expected the token version to move on to 1, got %d", version)
+	}
+	if listed, _ := uc.ListSessions("user-123", ""); len(listed) != 0 {
+		t.Errorf("Expected every session to be ended, got %+v", listed)
+	}
+
+	if err := uc.LogoutAll("user-456", "jane@example.com"); err != appErrors.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for an unknown user, got %v", err)
+	}
+}
+
+func TestUpdateNotificationPrefs(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com"})
+
+	prefs, err := uc.NotificationPrefs("user-123", "john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *prefs != (dto.NotificationPrefsResponse{Welcome: true, VerificationRevoked: true, PasswordChanged: true, EmailChanged: true}) {
+		t.Errorf("Expected every notice enabled by default, got %+v", prefs)
+	}
+
+	off, on := false, true
+	prefs, err = uc.UpdateNotificationPrefs("user-123", "john@example.com", dto.UpdateNotificationPrefsRequest{Welcome: &off, PasswordChanged: &on})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if prefs.Welcome || !prefs.VerificationRevoked {
+		t.Errorf("Expected only the welcome notice muted, got %+v", prefs)
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if !user.Notifications.MuteWelcome || user.Notifications.MuteVerificationRevoked {
+		t.Errorf("Expected the change to be saved, got %+v", user.Notifications)
+	}
+
+	// Omitted notices keep their setting
+	if prefs, _ = uc.UpdateNotificationPrefs("user-123", "john@example.com", dto.UpdateNotificationPrefsRequest{VerificationRevoked: &off}); prefs.Welcome {
+		t.Error("Expected the welcome notice to stay muted")
+	}
+
+	for _, req := range []dto.UpdateNotificationPrefsRequest{{PasswordChanged: &off}, {EmailChanged: &off}} {
+		if _, err := uc.UpdateNotificationPrefs("user-123", "john@example.com", req); err == nil {
+			t.Errorf("Expected security notices to stay on, got no error for %+v", req)
+		}
+	}
+}
+
+func TestUpdateLanguage(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com"})
+
+	lang, err := uc.Language("user-123", "john@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lang.Language != mailer.DefaultLanguage {
+		t.Errorf("Expected the default language before any choice, got %q", lang.Language)
+	}
+
+	lang, err = uc.UpdateLanguage("user-123", "john@example.com", dto.UpdateLanguageRequest{Language: " ID "})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lang.Language != mailer.Indonesian {
+		t.Errorf("Expected id, got %q", lang.Language)
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if user.Preferences.Language != mailer.Indonesian {
+		t.Errorf("Expected the language to be saved, got %q", user.Preferences.Language)
+	}
+}
+
+func TestUpdateLanguage_Unsupported(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com", Preferences: entity.UserPreferences{Language: "en"}})
+
+	for _, lang := range []string{"fr", "en-US", ""} {
+		_, err := uc.UpdateLanguage("user-123", "john@example.com", dto.UpdateLanguageRequest{Language: lang})
+		if appErr, ok := appErrors.IsAppError(err); !ok || appErr.Status != 400 {
+			t.Errorf("Expected %q to be rejected, got %v", lang, err)
+		}
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	if user.Preferences.Language != "en" {
+		t.Errorf("Expected the stored language to be kept, got %q", user.Preferences.Language)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com"})
+	uc.Repo.Create(&entity.User{ID: "admin-123", Email: "admin@example.com", Role: constants.ROLE_ADMIN})
+
+	userPermissions := []string{
+		constants.PERMISSION_PROFILE_READ,
+		constants.PERMISSION_PROFILE_UPDATE,
+		constants.PERMISSION_COMPANIES_READ,
+		constants.PERMISSION_COMPANIES_CREATE,
+		constants.PERMISSION_COMPANIES_DELETE,
+	}
+	adminPermissions := append(append([]string{}, userPermissions...),
+		constants.PERMISSION_ADMIN_ACCESS,
+		constants.PERMISSION_ADMIN_USERS_READ,
+		constants.PERMISSION_ADMIN_USERS_WRITE,
+		constants.PERMISSION_ADMIN_COMPANIES_READ,
+		constants.PERMISSION_ADMIN_COMPANIES_WRITE,
+		constants.PERMISSION_ADMIN_FLAGS_READ,
+		constants.PERMISSION_ADMIN_SYSTEM_WRITE,
+		constants.PERMISSION_ADMIN_API_KEYS_READ,
+		constants.PERMISSION_ADMIN_API_KEYS_WRITE,
+		constants.PERMISSION_ADMIN_IMPERSONATE,
+	)
+
+	tests := []struct {
+		email       string
+		role        string
+		permissions []string
+	}{
+		{"john@example.com", constants.ROLE_USER, userPermissions},
+		{"admin@example.com", constants.ROLE_ADMIN, adminPermissions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			result, err := uc.Permissions("", tt.email)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Role != tt.role {
+				t.Errorf("Expected role %q, got %q", tt.role, result.Role)
+			}
+			if strings.Join(result.Permissions, ",") != strings.Join(tt.permissions, ",") {
+				t.Errorf("Expected %v, got %v", tt.permissions, result.Permissions)
+			}
+		})
+	}
+
+	// The stored role wins over whatever the token was issued with
+	admin, _ := uc.Repo.FindByEmail("admin@example.com")
+	admin.Role = ""
+	uc.Repo.Update(admin)
+	result, _ := uc.Permissions("", "admin@example.com")
+	if result.Role != constants.ROLE_USER || len(result.Permissions) != len(userPermissions) {
+		t.Errorf("Expected a demoted admin to lose admin permissions, got %+v", result)
+	}
+}
+
+func TestSendOTP_UsesStoredLanguage(t *testing.T) {
+	uc := setupUserUsecase()
+	var subjects []string
+	uc.SendEmail = func(to, subject, body string) error {
+		subjects = append(subjects, subject)
+		return nil
+	}
+	uc.Repo.Create(&entity.User{Email: "john@example.com", Preferences: entity.UserPreferences{Language: "id"}})
+
+	if err := uc.SendOTP(constants.VERIFICATION, "john@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The request's Accept-Language wins over the stored preference
+	if err := uc.SendOTPWithLanguage(constants.VERIFICATION, "john@example.com", "en-GB,en;q=0.8"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// An unsupported header falls back to the stored preference
+	if err := uc.SendOTPWithLanguage(constants.VERIFICATION, "john@example.com", "fr-FR"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	idSubject, _ := mailer.OTPMessage(mailer.Indonesian, "", constants.VERIFICATION)
+	enSubject, _ := mailer.OTPMessage(mailer.English, "", constants.VERIFICATION)
+	expected := []string{idSubject, enSubject, idSubject}
+	if len(subjects) != len(expected) {
+		t.Fatalf("Expected %d emails, got %v", len(expected), subjects)
+	}
+	for i := range expected {
+		if subjects[i] != expected[i] {
+			t.Errorf("Email %d: expected subject %q, got %q", i, expected[i], subjects[i])
+		}
+	}
+}
+
+func TestOAuthLogin(t *testing.T) {
+	uc := setupUserUsecase()
+	uc.Repo.Create(&entity.User{ID: "user-123", Email: "john@example.com"})
+
+	logged, err := uc.OAuthLogin(&oauth.Identity{Provider: "github", Subject: "42", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if logged.Token == "" || !logged.Verified {
+		t.Errorf("Expected a token for a verified john, got %+v", logged)
+	}
+	if user, _ := uc.Repo.FindByEmail("john@example.com"); !user.Verified || user.LastLoginAt.IsZero() {
+		t.Errorf("Expected the provider's verified email to verify the account, got %+v", user)
+	}
+
+	if _, err := uc.OAuthLogin(&oauth.Identity{Provider: "github", Subject: "43", Email: "jane@example.com"}); err != appErrors.ErrOAuthAccountNotFound {
+		t.Errorf("Expected ErrOAuthAccountNotFound without an account, got %v", err)
+	}
+}
+
+// stubSMS records the texts it was asked to send
+type stubSMS struct {
+	sent map[string]string
+}
+
+func (s *stubSMS) Send(ctx context.Context, to, body string) error {
+	s.sent[to] = body
+	return nil
+}
+
+func TestSendOTPVia(t *testing.T) {
+	uc := setupUserUsecase()
+	emails := 0
+	uc.SendEmail = func(to, subject, body string) error {
+		emails++
+		return nil
+	}
+	uc.Repo.Create(&entity.User{Email: "john@example.com", PhoneNumber: "+6281234567890"})
+	uc.Repo.Create(&entity.User{Email: "jane@example.com"})
+
+	if err := uc.SendOTPVia(constants.OTP_CHANNEL_SMS, constants.PHONE_CHANGED, "john@example.com", ""); err != appErrors.ErrFeatureDisabled {
+		t.Errorf("Expected ErrFeatureDisabled without an SMS provider, got %v", err)
+	}
+
+	texts := &stubSMS{sent: map[string]string{}}
+	uc.SMS = texts
+	if err := uc.SendOTPVia(constants.OTP_CHANNEL_SMS, constants.PHONE_CHANGED, "john@example.com", ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	user, _ := uc.Repo.FindByEmail("john@example.com")
+	otp, _ := utils.Decrypt(user.OTP)
+	if body := texts.sent["+6281234567890"]; otp == "" || !strings.Contains(body, otp) {
+		t.Errorf("Expected the OTP texted to the phone, got %q", body)
+	}
+	if emails != 0 {
+		t.Errorf("Expected no email for an SMS OTP, got %d", emails)
+	}
+
+	if err := uc.SendOTPVia(constants.OTP_CHANNEL_SMS, constants.VERIFICATION, "jane@example.com", ""); err != appErrors.ErrPhoneRequired {
+		t.Errorf("Expected ErrPhoneRequired without a phone number, got %v", err)
+	}
+
+	// Numbers are texted in E.164, national ones only with a country code
+	uc.Repo.Create(&entity.User{Email: "budi@example.com", PhoneNumber: "08112123123"})
+	uc.Repo.Create(&entity.User{Email: "sari@example.com", PhoneNumber: "628112123124"})
+	if err := uc.SendOTPVia(constants.OTP_CHANNEL_SMS, constants.VERIFICATION, "budi@example.com", ""); err != appErrors.ErrPhoneNotTextable {
+		t.Errorf("Expected ErrPhoneNotTextable for a national number without a country code, got %v", err)
+	}
+	if budi, _ := uc.Repo.FindByEmail("budi@example.com"); budi.OTP != "" {
+		t.Error("Expected no OTP issued for a number that can't be texted")
+	}
+	uc.SMSCountryCode = "62"
+	uc.SendOTPVia(constants.OTP_CHANNEL_SMS, constants.VERIFICATION, "budi@example.com", "")
+	uc.SendOTPVia(constants.OTP_CHANNEL_SMS, constants.VERIFICATION, "sari@example.com", "")
+	if texts.sent["+628112123123"] == "" || texts.sent["+628112123124"] == "" {
+		t.Errorf("Expected both numbers texted in E.164, got %v", texts.sent)
+	}
+	if err := uc.SendOTPVia("pigeon", constants.VERIFICATION, "john@example.com", ""); err == nil {
+		t.Error("Expected an unknown channel to be refused")
+	}
+	if err := uc.SendOTPVia(constants.OTP_CHANNEL_EMAIL, constants.VERIFICATION, "john@example.com", ""); err != nil || emails != 1 {
+		t.Errorf("Expected the email channel to send an email, got %v after %d emails", err, emails)
+	}
+}
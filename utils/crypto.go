@@ -5,49 +5,85 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 	"io"
 	"os"
 
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
 )
 
-func Encrypt(text string) (string, error) {
-	key := []byte(os.Getenv("DECRYPT_KEY"))
-	plaintext := []byte(text)
+// DecryptKeySize is the required DECRYPT_KEY length in bytes (AES-256)
+const DecryptKeySize = 32
+
+// ValidateDecryptKey checks that DECRYPT_KEY is set and exactly
+// DecryptKeySize bytes, call it at startup to fail fast
+func ValidateDecryptKey() error {
+	key := os.Getenv("DECRYPT_KEY")
+	if key == "" {
+		return fmt.Errorf("DECRYPT_KEY is not set, it must be exactly %d bytes", DecryptKeySize)
+	}
+	if len(key) != DecryptKeySize {
+		return fmt.Errorf("DECRYPT_KEY is %d bytes, it must be exactly %d bytes", len(key), DecryptKeySize)
+	}
+	return nil
+}
 
-	block, err := aes.NewCipher(key)
+func newGCM() (cipher.AEAD, error) {
+	if err := ValidateDecryptKey(); err != nil {
+		return nil, err
+	}
+	return newGCMWithKey(os.Getenv("DECRYPT_KEY"))
+}
+
+func newGCMWithKey(key string) (cipher.AEAD, error) {
+	if len(key) != DecryptKeySize {
+		return nil, fmt.Errorf("key is %d bytes, it must be exactly %d bytes", len(key), DecryptKeySize)
+	}
+	block, err := aes.NewCipher([]byte(key))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return cipher.NewGCM(block)
+}
 
-	aesGCM, err := cipher.NewGCM(block)
+func Encrypt(text string) (string, error) {
+	aesGCM, err := newGCM()
 	if err != nil {
-		return "", err
+		LogError("Encryption unavailable: %v", err)
+		return "", appErrors.ErrEncryptionFailed
 	}
 
 	nonce := make([]byte, aesGCM.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+		return "", appErrors.ErrEncryptionFailed
 	}
-	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, nil)
+	ciphertext := aesGCM.Seal(nonce, nonce, []byte(text), nil)
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
 func Decrypt(encrypted string) (string, error) {
-	key := []byte(os.Getenv("DECRYPT_KEY"))
-	data, err := base64.StdEncoding.DecodeString(encrypted)
+	aesGCM, err := newGCM()
 	if err != nil {
-		return "", err
+		LogError("Decryption unavailable: %v", err)
+		return "", appErrors.ErrDecryptionFailed
 	}
+	return open(aesGCM, encrypted)
+}
 
-	block, err := aes.NewCipher(key)
+// DecryptWithKey decrypts a value encrypted under key rather than
+// DECRYPT_KEY, such as one from before a key rotation
+func DecryptWithKey(encrypted, key string) (string, error) {
+	aesGCM, err := newGCMWithKey(key)
 	if err != nil {
-		return "", err
+		return "", appErrors.ErrDecryptionFailed
 	}
+	return open(aesGCM, encrypted)
+}
 
-	aesGCM, err := cipher.NewGCM(block)
+func open(aesGCM cipher.AEAD, encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
 	if err != nil {
-		return "", err
+		return "", appErrors.ErrDecryptionFailed
 	}
 
 	nonceSize := aesGCM.NonceSize()
@@ -58,7 +94,7 @@ func Decrypt(encrypted string) (string, error) {
 	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
 	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		return "", err
+		return "", appErrors.ErrDecryptionFailed
 	}
 	return string(plaintext), nil
 }
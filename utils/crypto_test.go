@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	appErrors "github.com/buildyow/byow-user-service/domain/errors"
@@ -186,7 +187,7 @@ func TestDecryptWithCorruptedCiphertext(t *testing.T) {
 
 	// Corrupt the encrypted message by changing one character
 	corrupted := encrypted[:len(encrypted)-4] + "XXXX"
-	
+
 	_, err = Decrypt(corrupted)
 	if err == nil {
 		t.Error("Expected error with corrupted ciphertext")
@@ -225,4 +226,69 @@ func TestEncryptDecryptWithMissingKey(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error with missing key")
 	}
-}
\ No newline at end of file
+}
+func TestValidateDecryptKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"missing", "", true},
+		{"short", "short", true},
+		{"long", "123456789012345678901234567890123", true},
+		{"aes-128 length", "1234567890123456", true},
+		{"correct", "12345678901234567890123456789012", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DECRYPT_KEY", tt.key)
+			err := ValidateDecryptKey()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDecryptKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "DECRYPT_KEY") {
+				t.Errorf("Expected the error to name DECRYPT_KEY, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestEncryptDecrypt_TypedErrorsForBadKey(t *testing.T) {
+	for _, key := range []string{"", "short", "1234567890123456"} {
+		t.Setenv("DECRYPT_KEY", key)
+
+		if _, err := Encrypt("test message"); err != appErrors.ErrEncryptionFailed {
+			t.Errorf("Encrypt with key %q: expected ErrEncryptionFailed, got %v", key, err)
+		}
+		if _, err := Decrypt("dGVzdA=="); err != appErrors.ErrDecryptionFailed {
+			t.Errorf("Decrypt with key %q: expected ErrDecryptionFailed, got %v", key, err)
+		}
+	}
+}
+
+func TestDecryptWithKey(t *testing.T) {
+	originalKey := os.Getenv("DECRYPT_KEY")
+	defer os.Setenv("DECRYPT_KEY", originalKey)
+
+	oldKey := "12345678901234567890123456789012"
+	os.Setenv("DECRYPT_KEY", oldKey)
+	encrypted, err := Encrypt("123456")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// After rotation only the old key opens it
+	os.Setenv("DECRYPT_KEY", "abcdefghijklmnopqrstuvwxyz123456")
+	if _, err := Decrypt(encrypted); err != appErrors.ErrDecryptionFailed {
+		t.Errorf("Expected the new key to fail, got %v", err)
+	}
+	decrypted, err := DecryptWithKey(encrypted, oldKey)
+	if err != nil || decrypted != "123456" {
+		t.Errorf("DecryptWithKey() = %q, %v, expected 123456", decrypted, err)
+	}
+
+	if _, err := DecryptWithKey(encrypted, "short"); err != appErrors.ErrDecryptionFailed {
+		t.Errorf("Expected ErrDecryptionFailed for a bad key, got %v", err)
+	}
+}
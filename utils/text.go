@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldedLetters covers letters that have no decomposed form, so stripping
+// combining marks alone would leave them untouched
+var foldedLetters = strings.NewReplacer(
+	"ß", "ss",
+	"æ", "ae",
+	"œ", "oe",
+	"ø", "o",
+	"ł", "l",
+	"đ", "d",
+	"ı", "i",
+)
+
+// FoldText lowercases s and strips diacritics so "José" and "München" compare
+// equal to "jose" and "munchen". Use it for the normalized fields searches run
+// against and for the keyword before querying them.
+func FoldText(s string) string {
+	stripMarks := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(stripMarks, strings.ToLower(s))
+	if err != nil {
+		folded = strings.ToLower(s)
+	}
+	return foldedLetters.Replace(folded)
+}
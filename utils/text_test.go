@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestFoldText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"José", "jose"},
+		{"München", "munchen"},
+		{"Crème Brûlée", "creme brulee"},
+		{"Ålborg Søren", "alborg soren"},
+		{"Straße", "strasse"},
+		{"Łódź", "lodz"},
+		{"ACME Corp", "acme corp"},
+		{"東京", "東京"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := FoldText(tt.input); got != tt.expected {
+				t.Errorf("FoldText(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
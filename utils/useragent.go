@@ -0,0 +1,65 @@
+package utils
+
+import "strings"
+
+// uaMatch maps a User-Agent token to a display name. Order matters, browsers
+// embed the tokens of the ones they descend from (Edge says "Chrome" and
+// "Safari", Chrome says "Safari").
+type uaMatch struct {
+	token string
+	name  string
+}
+
+var uaBrowsers = []uaMatch{
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"postmanruntime/", "Postman"},
+	{"curl/", "curl"},
+	{"python-requests/", "Python"},
+	{"okhttp/", "OkHttp"},
+}
+
+var uaPlatforms = []uaMatch{
+	{"iphone", "iPhone"},
+	{"ipad", "iPad"},
+	{"android", "Android"},
+	{"windows", "Windows"},
+	{"cros", "ChromeOS"},
+	{"mac os x", "macOS"},
+	{"linux", "Linux"},
+}
+
+func firstMatch(ua string, matches []uaMatch) string {
+	for _, m := range matches {
+		if strings.Contains(ua, m.token) {
+			return m.name
+		}
+	}
+	return ""
+}
+
+// DeviceLabel turns a raw User-Agent into a short label such as
+// "Chrome on Windows" or "Safari on iPhone" for showing in login history.
+// Whichever part can't be recognised is left out, "Unknown device" when
+// neither is.
+func DeviceLabel(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	browser := firstMatch(ua, uaBrowsers)
+	platform := firstMatch(ua, uaPlatforms)
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform + " device"
+	default:
+		return "Unknown device"
+	}
+}